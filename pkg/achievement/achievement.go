@@ -0,0 +1,146 @@
+package achievement
+
+import (
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/achievement/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/reputation"
+)
+
+// PerfectMonthGames is how many games a user must attend in a calendar
+// month, without a no-show, for a perfect attendance month.
+const PerfectMonthGames = 4
+
+// Rule is how one badge is earned: Progress reaching Target. Rules are
+// evaluated again on any of their Events.
+type Rule struct {
+	Badge       string
+	Name        string
+	Description string
+	Target      int
+	Events      []string
+	Progress    func(s model.Stats) int
+}
+
+var Rules = []Rule{
+	{
+		Badge:       model.BadgeFirstGame,
+		Name:        "First Game",
+		Description: "Play your first game",
+		Target:      1,
+		Events:      []string{model.EventGamePlayed, model.EventAttendance},
+		Progress:    func(s model.Stats) int { return s.GamesPlayed },
+	},
+	{
+		Badge:       model.BadgeTenGames,
+		Name:        "Regular",
+		Description: "Play 10 games",
+		Target:      10,
+		Events:      []string{model.EventGamePlayed, model.EventAttendance},
+		Progress:    func(s model.Stats) int { return s.GamesPlayed },
+	},
+	{
+		Badge:       model.BadgeFiveSports,
+		Name:        "All-Rounder",
+		Description: "Play 5 different sports",
+		Target:      5,
+		Events:      []string{model.EventGamePlayed, model.EventAttendance},
+		Progress:    func(s model.Stats) int { return s.Sports },
+	},
+	{
+		Badge:       model.BadgePerfectMonth,
+		Name:        "Perfect Attendance",
+		Description: "Turn up to 4 games in a calendar month without a no-show",
+		Target:      PerfectMonthGames,
+		Events:      []string{model.EventMonthSettled},
+		Progress:    bestMonth,
+	},
+	{
+		Badge:       model.BadgeTournamentWinner,
+		Name:        "Champion",
+		Description: "Win a tournament",
+		Target:      1,
+		Events:      []string{model.EventTournamentWon},
+		Progress:    func(s model.Stats) int { return s.TournamentsWon },
+	},
+}
+
+// bestMonth is the most games attended in a settled month without a
+// no-show.
+func bestMonth(s model.Stats) int {
+	best := 0
+	for _, m := range s.Months {
+		if m.Settled && m.NoShows == 0 {
+			best = max(best, m.Attended)
+		}
+	}
+	return best
+}
+
+// MonthSettled reports whether the attendance of m is final at now: the
+// calendar month is over and the window to take attendance has closed for
+// every game in it.
+func MonthSettled(m model.Month, now time.Time) bool {
+	start, err := time.Parse("2006-01", m.Month)
+	if err != nil {
+		return false
+	}
+	return !now.Before(start.AddDate(0, 1, 0)) && !now.Before(m.LastGameEnds.Add(reputation.ReviewWindow))
+}
+
+// Progress lists s's progress toward every badge, with when the ones in
+// earned were earned. Earned badges stay complete even if the stats that
+// earned them change, such as a game later marked a no-show.
+func Progress(s model.Stats, earned map[string]time.Time) []model.Achievement {
+	out := make([]model.Achievement, len(Rules))
+	for i, r := range Rules {
+		a := model.Achievement{
+			Badge:       r.Badge,
+			Name:        r.Name,
+			Description: r.Description,
+			Progress:    min(r.Progress(s), r.Target),
+			Target:      r.Target,
+		}
+		if at, ok := earned[r.Badge]; ok {
+			a.Progress = r.Target
+			a.EarnedAt = &at
+		}
+		out[i] = a
+	}
+	return out
+}
+
+// Earned returns the badges that event may have earned a user with stats
+// s who has not earned them yet.
+func Earned(event string, s model.Stats, earned map[string]time.Time) []Rule {
+	var out []Rule
+	for _, r := range Rules {
+		if _, ok := earned[r.Badge]; ok || !handles(r, event) {
+			continue
+		}
+		if r.Progress(s) >= r.Target {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func handles(r Rule, event string) bool {
+	for _, e := range r.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Badges lists the badges in earned in the order of Rules.
+func Badges(earned map[string]time.Time) []model.Badge {
+	out := []model.Badge{}
+	for _, r := range Rules {
+		if at, ok := earned[r.Badge]; ok {
+			out = append(out, model.Badge{Badge: r.Badge, Name: r.Name, EarnedAt: at})
+		}
+	}
+	return out
+}
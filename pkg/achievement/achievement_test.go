@@ -0,0 +1,135 @@
+package achievement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/achievement/model"
+)
+
+var earnedAt = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func badges(rules []Rule) []string {
+	out := make([]string, len(rules))
+	for i, r := range rules {
+		out[i] = r.Badge
+	}
+	return out
+}
+
+func sameBadges(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEarned(t *testing.T) {
+	perfect := []model.Month{{Month: "2026-04", Attended: 4, Settled: true}}
+	inProgress := []model.Month{{Month: "2026-05", Attended: 4}}
+	tests := []struct {
+		name   string
+		event  string
+		stats  model.Stats
+		earned map[string]time.Time
+		want   []string
+	}{
+		{"Nothing played", model.EventGamePlayed, model.Stats{}, nil, nil},
+		{"First game", model.EventGamePlayed, model.Stats{GamesPlayed: 1, Sports: 1}, nil,
+			[]string{model.BadgeFirstGame}},
+		{"First game already earned", model.EventGamePlayed, model.Stats{GamesPlayed: 2, Sports: 1},
+			map[string]time.Time{model.BadgeFirstGame: earnedAt}, nil},
+		{"Tenth game", model.EventGamePlayed, model.Stats{GamesPlayed: 10, Sports: 2},
+			map[string]time.Time{model.BadgeFirstGame: earnedAt}, []string{model.BadgeTenGames}},
+		{"Catching up on several", model.EventGamePlayed, model.Stats{GamesPlayed: 12, Sports: 5}, nil,
+			[]string{model.BadgeFirstGame, model.BadgeTenGames, model.BadgeFiveSports}},
+		{"Four sports", model.EventGamePlayed, model.Stats{GamesPlayed: 4, Sports: 4},
+			map[string]time.Time{model.BadgeFirstGame: earnedAt}, nil},
+		{"Perfect month", model.EventMonthSettled, model.Stats{GamesPlayed: 4, Sports: 1, Months: perfect},
+			map[string]time.Time{model.BadgeFirstGame: earnedAt}, []string{model.BadgePerfectMonth}},
+		{"Perfect month waits for the month to settle", model.EventAttendance, model.Stats{GamesPlayed: 4, Sports: 1, Months: perfect},
+			map[string]time.Time{model.BadgeFirstGame: earnedAt}, nil},
+		{"Month in progress", model.EventMonthSettled, model.Stats{GamesPlayed: 4, Sports: 1, Months: inProgress},
+			map[string]time.Time{model.BadgeFirstGame: earnedAt}, nil},
+		{"Month with a no-show", model.EventMonthSettled, model.Stats{
+			GamesPlayed: 5, Sports: 1, Months: []model.Month{{Month: "2026-04", Attended: 5, NoShows: 1, Settled: true}},
+		}, map[string]time.Time{model.BadgeFirstGame: earnedAt}, nil},
+		{"Tournament won", model.EventTournamentWon, model.Stats{TournamentsWon: 1}, nil,
+			[]string{model.BadgeTournamentWinner}},
+		{"Tournament event skips game badges", model.EventTournamentWon, model.Stats{GamesPlayed: 3, TournamentsWon: 1}, nil,
+			[]string{model.BadgeTournamentWinner}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := badges(Earned(tt.event, tt.stats, tt.earned)); !sameBadges(got, tt.want) {
+				t.Errorf("Earned = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonthSettled(t *testing.T) {
+	april := model.Month{Month: "2026-04", Attended: 4, LastGameEnds: time.Date(2026, 4, 28, 20, 0, 0, 0, time.UTC)}
+	lateGame := april
+	lateGame.LastGameEnds = time.Date(2026, 4, 30, 23, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		month model.Month
+		now   time.Time
+		want  bool
+	}{
+		{"Month in progress", april, time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC), false},
+		{"Month over", april, time.Date(2026, 5, 6, 0, 0, 0, 0, time.UTC), true},
+		{"Attendance still open", lateGame, time.Date(2026, 5, 6, 0, 0, 0, 0, time.UTC), false},
+		{"Attendance closed", lateGame, time.Date(2026, 5, 7, 23, 0, 0, 0, time.UTC), true},
+		{"Not a month", model.Month{Month: "April"}, earnedAt, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MonthSettled(tt.month, tt.now); got != tt.want {
+				t.Errorf("MonthSettled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	stats := model.Stats{
+		GamesPlayed: 14,
+		Sports:      3,
+		Months: []model.Month{
+			{Month: "2026-03", Attended: 6, NoShows: 1, Settled: true},
+			{Month: "2026-04", Attended: 3, Settled: true},
+			{Month: "2026-05", Attended: 5},
+		},
+	}
+	// A no-show after earning a badge does not take it away.
+	earned := map[string]time.Time{model.BadgeFirstGame: earnedAt, model.BadgeTournamentWinner: earnedAt}
+	got := Progress(stats, earned)
+	if len(got) != len(Rules) {
+		t.Fatalf("Progress returned %d achievements, want %d", len(got), len(Rules))
+	}
+
+	want := map[string]struct {
+		progress int
+		earned   bool
+	}{
+		model.BadgeFirstGame:        {1, true},
+		model.BadgeTenGames:         {10, false},
+		model.BadgeFiveSports:       {3, false},
+		model.BadgePerfectMonth:     {3, false},
+		model.BadgeTournamentWinner: {1, true},
+	}
+	for _, a := range got {
+		w := want[a.Badge]
+		if a.Progress != w.progress || (a.EarnedAt != nil) != w.earned {
+			t.Errorf("%s: progress %d/%d earned %v, want %d earned %v",
+				a.Badge, a.Progress, a.Target, a.EarnedAt != nil, w.progress, w.earned)
+		}
+	}
+}
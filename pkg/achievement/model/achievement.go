@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+const (
+	BadgeFirstGame        = "first_game"
+	BadgeTenGames         = "ten_games"
+	BadgeFiveSports       = "five_sports"
+	BadgePerfectMonth     = "perfect_attendance_month"
+	BadgeTournamentWinner = "tournament_winner"
+
+	// Events are what happened to a user that may earn them a badge.
+	EventGamePlayed    = "game_played"
+	EventAttendance    = "attendance"
+	EventTournamentWon = "tournament_won"
+	// EventMonthSettled is a calendar month the user played in becoming
+	// final, once attendance can no longer be taken at any of its games.
+	EventMonthSettled = "month_settled"
+)
+
+// Badge is a badge a user has earned.
+type Badge struct {
+	Badge    string    `json:"badge"`
+	Name     string    `json:"name"`
+	EarnedAt time.Time `json:"earnedAt"`
+}
+
+// Achievement is a user's progress toward one badge.
+type Achievement struct {
+	Badge       string     `json:"badge"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Progress    int        `json:"progress"`
+	Target      int        `json:"target"`
+	EarnedAt    *time.Time `json:"earnedAt,omitempty"`
+}
+
+// Stats is the record of a user that badges are earned from.
+type Stats struct {
+	// GamesPlayed counts games that have ended, were not cancelled and the
+	// user was not a no-show at.
+	GamesPlayed int
+	Sports      int
+	// Months is the attendance taken at the user's games, by calendar
+	// month.
+	Months         []Month
+	TournamentsWon int
+}
+
+// Month is the attendance of one user's games in one calendar month.
+type Month struct {
+	Month    string
+	Attended int
+	NoShows  int
+	// LastGameEnds is when the last of the user's games in the month ends.
+	LastGameEnds time.Time
+	// Settled months are over and can no longer have attendance taken, so
+	// their record is final.
+	Settled bool
+}
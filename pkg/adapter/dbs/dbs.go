@@ -36,7 +36,7 @@ func New(ctx context.Context) *Service {
 	db, err := sql.Open(
 		"mysql",
 		fmt.Sprintf(
-			"%s:%s@tcp(%s:%s)/%s",
+			"%s:%s@tcp(%s:%s)/%s?parseTime=true",
 			dbConfig.DBUsername, // Username
 			dbConfig.DBPassword, // Password
 			dbConfig.DBHost,     // Host
@@ -0,0 +1,213 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/achievement"
+	"github.com/dudeiebot/sportPeerGo/pkg/achievement/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/reputation"
+	reputationmodel "github.com/dudeiebot/sportPeerGo/pkg/reputation/model"
+	tournamentmodel "github.com/dudeiebot/sportPeerGo/pkg/tournament/model"
+)
+
+// endedGamesBatch caps how many ended games one run of the achievements
+// job goes through.
+const endedGamesBatch = 200
+
+// monthSweepLookback is how many months back the month sweep looks for
+// months that have settled.
+const monthSweepLookback = 3
+
+// AchievementStatsQuery loads the record of userID that badges are earned
+// from, as of now.
+func AchievementStatsQuery(ctx context.Context, d *dbs.Service, userID int64, now time.Time) (model.Stats, error) {
+	var s model.Stats
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*), COUNT(DISTINCT g.sport)
+		FROM game_participants p
+		JOIN games g ON g.id = p.game_id
+		WHERE p.user_id = ? AND g.status <> ? AND g.ends_at <= ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM game_attendance a
+			WHERE a.game_id = p.game_id AND a.user_id = p.user_id AND a.status = ?
+		  )`,
+		userID, gamemodel.StatusCancelled, now, reputationmodel.AttendanceNoShow,
+	).Scan(&s.GamesPlayed, &s.Sports)
+	if err != nil {
+		return s, fmt.Errorf("error querying games played: %w", err)
+	}
+
+	err = d.DB.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM tournaments t
+		JOIN tournament_entrant_players ep ON ep.entrant_id = t.champion_id
+		WHERE t.status = ? AND ep.user_id = ?`,
+		tournamentmodel.StatusCompleted, userID,
+	).Scan(&s.TournamentsWon)
+	if err != nil {
+		return s, fmt.Errorf("error querying tournaments won: %w", err)
+	}
+
+	// Every game the user played counts toward its month being settled,
+	// including ones that have no attendance taken yet.
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT DATE_FORMAT(g.starts_at, '%Y-%m') AS month,
+			COALESCE(SUM(a.status = ?), 0), COALESCE(SUM(a.status = ?), 0), MAX(g.ends_at)
+		FROM game_participants p
+		JOIN games g ON g.id = p.game_id
+		LEFT JOIN game_attendance a ON a.game_id = p.game_id AND a.user_id = p.user_id
+		WHERE p.user_id = ? AND g.status <> ?
+		GROUP BY month
+		ORDER BY month`,
+		reputationmodel.AttendancePresent, reputationmodel.AttendanceNoShow, userID, gamemodel.StatusCancelled,
+	)
+	if err != nil {
+		return s, fmt.Errorf("error querying monthly attendance: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m model.Month
+		if err := rows.Scan(&m.Month, &m.Attended, &m.NoShows, &m.LastGameEnds); err != nil {
+			return s, fmt.Errorf("error scanning monthly attendance: %w", err)
+		}
+		m.Settled = achievement.MonthSettled(m, now)
+		s.Months = append(s.Months, m)
+	}
+	return s, rows.Err()
+}
+
+// EarnedBadgesQuery returns when userID earned each of their badges.
+func EarnedBadgesQuery(ctx context.Context, d *dbs.Service, userID int64) (map[string]time.Time, error) {
+	rows, err := d.DB.QueryContext(ctx, `SELECT badge, earned_at FROM user_badges WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying badges: %w", err)
+	}
+	defer rows.Close()
+
+	earned := make(map[string]time.Time)
+	for rows.Next() {
+		var badge string
+		var at time.Time
+		if err := rows.Scan(&badge, &at); err != nil {
+			return nil, fmt.Errorf("error scanning badge: %w", err)
+		}
+		earned[badge] = at
+	}
+	return earned, rows.Err()
+}
+
+// AwardBadgeQuery gives userID a badge. It reports false if they already
+// had it, so each badge is only ever awarded once.
+func AwardBadgeQuery(ctx context.Context, d *dbs.Service, userID int64, badge string, now time.Time) (bool, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`INSERT IGNORE INTO user_badges (user_id, badge, earned_at) VALUES (?, ?, ?)`,
+		userID, badge, now,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error awarding badge: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// EndedGamesQuery returns the players of games that have ended and not yet
+// had their achievements evaluated, by game.
+func EndedGamesQuery(ctx context.Context, d *dbs.Service, now time.Time) (map[int64][]int64, error) {
+	games, err := gameUserIDs(
+		ctx, d.DB,
+		`SELECT id FROM games
+		WHERE status <> ? AND ends_at <= ? AND achievements_evaluated_at IS NULL
+		ORDER BY ends_at, id
+		LIMIT ?`,
+		gamemodel.StatusCancelled, now, endedGamesBatch,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	players := make(map[int64][]int64, len(games))
+	for _, gameID := range games {
+		ids, err := gameUserIDs(ctx, d.DB, `SELECT user_id FROM game_participants WHERE game_id = ?`, gameID)
+		if err != nil {
+			return nil, err
+		}
+		players[gameID] = ids
+	}
+	return players, nil
+}
+
+func MarkAchievementsEvaluatedQuery(ctx context.Context, d *dbs.Service, gameID int64, now time.Time) error {
+	_, err := d.DB.ExecContext(ctx, `UPDATE games SET achievements_evaluated_at = ? WHERE id = ?`, now, gameID)
+	if err != nil {
+		return fmt.Errorf("error marking game evaluated: %w", err)
+	}
+	return nil
+}
+
+// SettledMonthsQuery returns the recent calendar months that have become
+// final since they were last swept: they are over and attendance can no
+// longer be taken at any of their games. Months from before the lookback
+// were evaluated when the sweep was introduced or earlier.
+func SettledMonthsQuery(ctx context.Context, d *dbs.Service, now time.Time) ([]string, error) {
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT DATE_FORMAT(g.starts_at, '%Y-%m') AS month
+		FROM games g
+		WHERE g.status <> ? AND g.starts_at >= ? AND g.starts_at < ?
+		GROUP BY month
+		HAVING MAX(g.ends_at) <= ?
+		   AND month NOT IN (SELECT month FROM achievement_month_sweeps)
+		ORDER BY month`,
+		gamemodel.StatusCancelled, thisMonth.AddDate(0, -monthSweepLookback, 0), thisMonth,
+		now.Add(-reputation.ReviewWindow),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying settled months: %w", err)
+	}
+	defer rows.Close()
+
+	var months []string
+	for rows.Next() {
+		var month string
+		if err := rows.Scan(&month); err != nil {
+			return nil, fmt.Errorf("error scanning settled month: %w", err)
+		}
+		months = append(months, month)
+	}
+	return months, rows.Err()
+}
+
+// MonthPlayersQuery returns the users marked present at a game in month.
+func MonthPlayersQuery(ctx context.Context, d *dbs.Service, month string) ([]int64, error) {
+	return gameUserIDs(
+		ctx, d.DB,
+		`SELECT DISTINCT a.user_id
+		FROM game_attendance a
+		JOIN games g ON g.id = a.game_id
+		WHERE DATE_FORMAT(g.starts_at, '%Y-%m') = ? AND g.status <> ? AND a.status = ?`,
+		month, gamemodel.StatusCancelled, reputationmodel.AttendancePresent,
+	)
+}
+
+func MarkMonthSweptQuery(ctx context.Context, d *dbs.Service, month string, now time.Time) error {
+	_, err := d.DB.ExecContext(
+		ctx,
+		`INSERT IGNORE INTO achievement_month_sweeps (month, swept_at) VALUES (?, ?)`,
+		month, now,
+	)
+	if err != nil {
+		return fmt.Errorf("error marking month swept: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+const bookingColumns = `
+	b.id, b.venue_id, b.court_id, b.user_id, b.game_id, b.starts_at, b.ends_at,
+	b.status, b.hold_expires_at, b.late_cancel
+`
+
+func scanBooking(row rowScanner) (*model.Booking, error) {
+	var b model.Booking
+	var gameID sql.NullInt64
+	var holdExpires sql.NullTime
+	err := row.Scan(
+		&b.ID, &b.VenueID, &b.CourtID, &b.UserID, &gameID, &b.StartsAt, &b.EndsAt,
+		&b.Status, &holdExpires, &b.LateCancel,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, venue.ErrBookingNotFound
+		}
+		return nil, fmt.Errorf("error scanning booking: %w", err)
+	}
+	if gameID.Valid {
+		b.GameID = &gameID.Int64
+	}
+	if holdExpires.Valid {
+		b.HoldExpiresAt = &holdExpires.Time
+	}
+	return &b, nil
+}
+
+func queryBookings(ctx context.Context, q querier, queri string, args ...interface{}) ([]model.Booking, error) {
+	rows, err := q.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying bookings: %w", err)
+	}
+	defer rows.Close()
+
+	bookings := []model.Booking{}
+	for rows.Next() {
+		b, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, *b)
+	}
+	return bookings, rows.Err()
+}
+
+// reserveCourt stores b if the court is free for its slot. The court row is
+// locked FOR UPDATE first, so two transactions booking the same court are
+// serialized and the second one sees the first one's booking when it checks
+// for overlaps.
+func reserveCourt(ctx context.Context, tx *sql.Tx, b model.Booking, now time.Time) (int64, error) {
+	var court model.Court
+	err := tx.QueryRowContext(
+		ctx,
+		`SELECT id, venue_id, name, sport, surface, active FROM venue_courts WHERE id = ? FOR UPDATE`,
+		b.CourtID,
+	).Scan(&court.ID, &court.VenueID, &court.Name, &court.Sport, &court.Surface, &court.Active)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, venue.ErrCourtNotFound
+		}
+		return 0, fmt.Errorf("error locking court: %w", err)
+	}
+	if court.VenueID != b.VenueID {
+		return 0, venue.ErrCourtNotFound
+	}
+
+	v, err := scanVenue(tx.QueryRowContext(ctx, `SELECT `+venueColumns+` FROM venues v WHERE v.id = ?`, b.VenueID))
+	if err != nil {
+		return 0, err
+	}
+
+	queri := `
+		SELECT ` + bookingColumns + `
+		FROM court_bookings b
+		WHERE b.court_id = ? AND b.status IN (?, ?) AND b.starts_at < ? AND b.ends_at > ?
+	`
+	existing, err := queryBookings(
+		ctx, tx, queri,
+		b.CourtID, model.BookingHeld, model.BookingConfirmed, b.EndsAt, b.StartsAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := venue.CheckBooking(*v, court, b, existing, now); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO court_bookings
+			(venue_id, court_id, user_id, game_id, starts_at, ends_at, status, hold_expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		b.VenueID, b.CourtID, b.UserID, b.GameID, b.StartsAt, b.EndsAt, b.Status, b.HoldExpiresAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting booking: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// HoldCourtQuery reserves the slot for HoldWindow; the booking must be
+// confirmed before then or the court is released.
+func HoldCourtQuery(ctx context.Context, d *dbs.Service, b model.Booking) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	expires := now.Add(venue.HoldWindow)
+	b.Status = model.BookingHeld
+	b.HoldExpiresAt = &expires
+
+	id, err := reserveCourt(ctx, tx, b, now)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func GetBookingQuery(ctx context.Context, d *dbs.Service, id int64) (*model.Booking, error) {
+	queri := `SELECT ` + bookingColumns + ` FROM court_bookings b WHERE b.id = ?`
+	return scanBooking(d.DB.QueryRowContext(ctx, queri, id))
+}
+
+// lockBooking reads the booking FOR UPDATE together with the cancellation
+// policy of its venue.
+func lockBooking(ctx context.Context, tx *sql.Tx, id int64) (*model.Booking, string, error) {
+	queri := `SELECT ` + bookingColumns + ` FROM court_bookings b WHERE b.id = ? FOR UPDATE`
+	b, err := scanBooking(tx.QueryRowContext(ctx, queri, id))
+	if err != nil {
+		return nil, "", err
+	}
+	var policy string
+	err = tx.QueryRowContext(ctx, `SELECT cancellation_policy FROM venues WHERE id = ?`, b.VenueID).
+		Scan(&policy)
+	if err != nil {
+		return nil, "", fmt.Errorf("error querying cancellation policy: %w", err)
+	}
+	return b, policy, nil
+}
+
+func saveBookingStatus(ctx context.Context, tx *sql.Tx, b *model.Booking) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`UPDATE court_bookings SET status = ?, hold_expires_at = ?, late_cancel = ? WHERE id = ?`,
+		b.Status, b.HoldExpiresAt, b.LateCancel, b.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating booking: %w", err)
+	}
+	return nil
+}
+
+func ConfirmBookingQuery(ctx context.Context, d *dbs.Service, id, userID int64) (*model.Booking, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	b, _, err := lockBooking(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != userID {
+		return nil, venue.ErrBookingNotFound
+	}
+	if err := venue.Confirm(b, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := saveBookingStatus(ctx, tx, b); err != nil {
+		return nil, err
+	}
+	return b, tx.Commit()
+}
+
+func CancelBookingQuery(ctx context.Context, d *dbs.Service, id, userID int64) (*model.Booking, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	b, policy, err := lockBooking(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != userID {
+		return nil, venue.ErrBookingNotFound
+	}
+	if err := venue.Cancel(b, policy, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := saveBookingStatus(ctx, tx, b); err != nil {
+		return nil, err
+	}
+	return b, tx.Commit()
+}
+
+// cancelGameBookings releases the courts booked for a cancelled game. A
+// booking the venue's policy no longer lets go of stays in place.
+func cancelGameBookings(ctx context.Context, tx *sql.Tx, gameID int64, now time.Time) error {
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT id FROM court_bookings WHERE game_id = ? AND status IN (?, ?)`,
+		gameID, model.BookingHeld, model.BookingConfirmed,
+	)
+	if err != nil {
+		return fmt.Errorf("error querying game bookings: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning game booking: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		b, policy, err := lockBooking(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := venue.Cancel(b, policy, now); err != nil {
+			continue
+		}
+		if err := saveBookingStatus(ctx, tx, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func UserBookingsQuery(ctx context.Context, d *dbs.Service, userID int64, from time.Time) ([]model.Booking, error) {
+	queri := `
+		SELECT ` + bookingColumns + `
+		FROM court_bookings b
+		WHERE b.user_id = ? AND b.ends_at >= ?
+		ORDER BY b.starts_at, b.id
+	`
+	return queryBookings(ctx, d.DB, queri, userID, from)
+}
+
+// VenueBookingsQuery returns the bookings at the venue that may still hold a
+// court in [from, to).
+func VenueBookingsQuery(ctx context.Context, d *dbs.Service, venueID int64, from, to time.Time) ([]model.Booking, error) {
+	queri := `
+		SELECT ` + bookingColumns + `
+		FROM court_bookings b
+		WHERE b.venue_id = ? AND b.status IN (?, ?) AND b.starts_at < ? AND b.ends_at > ?
+		ORDER BY b.starts_at, b.id
+	`
+	return queryBookings(ctx, d.DB, queri, venueID, model.BookingHeld, model.BookingConfirmed, to, from)
+}
+
+// ExpireCourtHoldsQuery marks lapsed holds as expired. Lapsed holds already
+// stop blocking their court; this only keeps the stored status honest.
+func ExpireCourtHoldsQuery(ctx context.Context, d *dbs.Service, now time.Time) (int64, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`UPDATE court_bookings SET status = ?, hold_expires_at = NULL WHERE status = ? AND hold_expires_at <= ?`,
+		model.BookingExpired, model.BookingHeld, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error expiring court holds: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+var ErrFeedNotFound = errors.New("calendar feed not found")
+
+// CreateFeedTokenQuery stores a new feed token for the user, revoking any
+// token issued before it.
+func CreateFeedTokenQuery(ctx context.Context, d *dbs.Service, userID int64, tokenHash string) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE calendar_feeds SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+		time.Now(), userID,
+	)
+	if err != nil {
+		return fmt.Errorf("error revoking old feed tokens: %w", err)
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO calendar_feeds (user_id, token_hash) VALUES (?, ?)`,
+		userID, tokenHash,
+	)
+	if err != nil {
+		return fmt.Errorf("error storing feed token: %w", err)
+	}
+	return tx.Commit()
+}
+
+func RevokeFeedTokensQuery(ctx context.Context, d *dbs.Service, userID int64) (int64, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`UPDATE calendar_feeds SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+		time.Now(), userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error revoking feed tokens: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func FeedUserQuery(ctx context.Context, d *dbs.Service, tokenHash string) (int64, error) {
+	var userID int64
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT user_id FROM calendar_feeds WHERE token_hash = ? AND revoked_at IS NULL`,
+		tokenHash,
+	).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrFeedNotFound
+		}
+		return 0, fmt.Errorf("error querying feed token: %w", err)
+	}
+	return userID, nil
+}
+
+// CalendarGamesQuery returns the games the user organizes or has joined that
+// start after from, cancelled ones included so calendar apps drop them.
+func CalendarGamesQuery(ctx context.Context, d *dbs.Service, userID int64, from time.Time) ([]model.Game, error) {
+	queri := `
+		SELECT ` + gameColumns + `
+		FROM games g
+		WHERE g.starts_at >= ?
+		  AND (
+			g.organizer_id = ?
+			OR EXISTS (SELECT 1 FROM game_participants p WHERE p.game_id = g.id AND p.user_id = ?)
+		  )
+		ORDER BY g.starts_at
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, from, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying calendar games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []model.Game
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, *g)
+	}
+	return games, rows.Err()
+}
@@ -0,0 +1,371 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/chat"
+	"github.com/dudeiebot/sportPeerGo/pkg/chat/model"
+)
+
+// senderNotBlocked hides messages from users on either side of a block with
+// the viewer. It takes the viewer's id twice and expects messages as m.
+const senderNotBlocked = `
+	NOT EXISTS (
+		SELECT 1 FROM user_blocks b
+		WHERE (b.blocker_id = ? AND b.blocked_id = m.sender_id)
+		   OR (b.blocker_id = m.sender_id AND b.blocked_id = ?)
+	)
+`
+
+// DirectConversationQuery returns the direct conversation between two users,
+// starting it if they have never talked before.
+func DirectConversationQuery(ctx context.Context, d *dbs.Service, userID, otherID int64) (int64, error) {
+	var exists bool
+	err := d.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)`, otherID).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("error checking user: %w", err)
+	}
+	if !exists {
+		return 0, fmt.Errorf("User Not Found")
+	}
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	key := chat.DirectKey(userID, otherID)
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT IGNORE INTO conversations (kind, direct_key) VALUES (?, ?)`,
+		model.KindDirect, key,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error creating conversation: %w", err)
+	}
+	created, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM conversations WHERE direct_key = ?`, key).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error loading conversation: %w", err)
+	}
+	if created == 1 {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO conversation_members (conversation_id, user_id) VALUES (?, ?), (?, ?)`,
+			id, userID, id, otherID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("error adding conversation members: %w", err)
+		}
+	}
+	return id, tx.Commit()
+}
+
+// GameConversationQuery returns the group conversation of a game, whose
+// members are always the game's current participants.
+func GameConversationQuery(ctx context.Context, d *dbs.Service, gameID int64) (int64, error) {
+	return groupConversation(ctx, d, model.KindGame, "game_id", gameID)
+}
+
+// TeamConversationQuery returns the group conversation of a team, whose
+// members are always the team's current members.
+func TeamConversationQuery(ctx context.Context, d *dbs.Service, teamID int64) (int64, error) {
+	return groupConversation(ctx, d, model.KindTeam, "team_id", teamID)
+}
+
+func groupConversation(ctx context.Context, d *dbs.Service, kind, column string, id int64) (int64, error) {
+	_, err := d.DB.ExecContext(
+		ctx,
+		`INSERT IGNORE INTO conversations (kind, `+column+`) VALUES (?, ?)`,
+		kind, id,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error creating conversation: %w", err)
+	}
+	var convID int64
+	err = d.DB.QueryRowContext(ctx, `SELECT id FROM conversations WHERE `+column+` = ?`, id).Scan(&convID)
+	if err != nil {
+		return 0, fmt.Errorf("error loading conversation: %w", err)
+	}
+	return convID, nil
+}
+
+func GetConversationQuery(ctx context.Context, d *dbs.Service, id int64) (*model.Conversation, error) {
+	var c model.Conversation
+	var gameID, teamID sql.NullInt64
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT id, kind, game_id, team_id, created_at FROM conversations WHERE id = ?`,
+		id,
+	).Scan(&c.ID, &c.Kind, &gameID, &teamID, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, chat.ErrConversationNotFound
+		}
+		return nil, fmt.Errorf("error loading conversation: %w", err)
+	}
+	if gameID.Valid {
+		c.GameID = &gameID.Int64
+	}
+	if teamID.Valid {
+		c.TeamID = &teamID.Int64
+	}
+	return &c, nil
+}
+
+// ConversationMembersQuery returns who may read and post in c.
+func ConversationMembersQuery(ctx context.Context, d *dbs.Service, c *model.Conversation) ([]int64, error) {
+	var queri string
+	var args []interface{}
+	switch c.Kind {
+	case model.KindGame:
+		queri = `SELECT user_id FROM game_participants WHERE game_id = ?`
+		args = []interface{}{*c.GameID}
+	case model.KindTeam:
+		queri = `SELECT user_id FROM team_members WHERE team_id = ?`
+		args = []interface{}{*c.TeamID}
+	default:
+		queri = `SELECT user_id FROM conversation_members WHERE conversation_id = ?`
+		args = []interface{}{c.ID}
+	}
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying conversation members: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning conversation member: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ConversationsQuery lists userID's conversations, most recently active
+// first, with the last message and unread count each. Direct conversations
+// with users on either side of a block are left out.
+func ConversationsQuery(ctx context.Context, d *dbs.Service, userID int64) ([]model.Conversation, error) {
+	queri := `
+		SELECT c.id, c.kind, c.game_id, c.team_id, c.created_at,
+			COALESCE(CASE c.kind
+				WHEN 'direct' THEN (
+					SELECT u.username FROM conversation_members om JOIN users u ON u.id = om.user_id
+					WHERE om.conversation_id = c.id AND om.user_id <> ? LIMIT 1
+				)
+				WHEN 'game' THEN (SELECT CONCAT(g.sport, ' game') FROM games g WHERE g.id = c.game_id)
+				WHEN 'team' THEN (SELECT t.name FROM teams t WHERE t.id = c.team_id)
+			END, ''),
+			lm.id, lm.sender_id, lu.username, lm.body, lm.created_at,
+			(SELECT COUNT(*) FROM messages m
+			 WHERE m.conversation_id = c.id AND m.sender_id <> ?
+			   AND m.id > COALESCE((
+					SELECT r.last_read_id FROM conversation_reads r
+					WHERE r.conversation_id = c.id AND r.user_id = ?
+			   ), 0)
+			   AND ` + senderNotBlocked + `)
+		FROM conversations c
+		LEFT JOIN messages lm ON lm.id = (
+			SELECT MAX(m.id) FROM messages m WHERE m.conversation_id = c.id AND ` + senderNotBlocked + `
+		)
+		LEFT JOIN users lu ON lu.id = lm.sender_id
+		WHERE (c.kind = 'direct'
+				AND EXISTS (SELECT 1 FROM conversation_members cm WHERE cm.conversation_id = c.id AND cm.user_id = ?)
+				AND NOT EXISTS (
+					SELECT 1 FROM conversation_members om
+					JOIN user_blocks b ON (b.blocker_id = ? AND b.blocked_id = om.user_id)
+						OR (b.blocker_id = om.user_id AND b.blocked_id = ?)
+					WHERE om.conversation_id = c.id
+				))
+			OR (c.kind = 'game'
+				AND EXISTS (SELECT 1 FROM game_participants p WHERE p.game_id = c.game_id AND p.user_id = ?))
+			OR (c.kind = 'team'
+				AND EXISTS (SELECT 1 FROM team_members tm WHERE tm.team_id = c.team_id AND tm.user_id = ?))
+		ORDER BY COALESCE(lm.id, 0) DESC, c.id DESC
+	`
+	rows, err := d.DB.QueryContext(
+		ctx, queri,
+		userID, userID, userID, userID, userID, userID, userID,
+		userID, userID, userID, userID, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying conversations: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := []model.Conversation{}
+	for rows.Next() {
+		var c model.Conversation
+		var gameID, teamID, lastID, lastSender sql.NullInt64
+		var lastName, lastBody sql.NullString
+		var lastAt sql.NullTime
+		err := rows.Scan(
+			&c.ID, &c.Kind, &gameID, &teamID, &c.CreatedAt, &c.Title,
+			&lastID, &lastSender, &lastName, &lastBody, &lastAt, &c.Unread,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning conversation: %w", err)
+		}
+		if gameID.Valid {
+			c.GameID = &gameID.Int64
+		}
+		if teamID.Valid {
+			c.TeamID = &teamID.Int64
+		}
+		if lastID.Valid {
+			c.LastMessage = &model.Message{
+				ID:             lastID.Int64,
+				ConversationID: c.ID,
+				SenderID:       lastSender.Int64,
+				SenderName:     lastName.String,
+				Body:           lastBody.String,
+				CreatedAt:      lastAt.Time,
+			}
+		}
+		conversations = append(conversations, c)
+	}
+	return conversations, rows.Err()
+}
+
+func CreateMessageQuery(ctx context.Context, d *dbs.Service, m model.Message) (*model.Message, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`INSERT INTO messages (conversation_id, sender_id, body) VALUES (?, ?, ?)`,
+		m.ConversationID, m.SenderID, m.Body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting message: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	queri := `
+		SELECT m.id, m.conversation_id, m.sender_id, u.username, m.body, m.created_at
+		FROM messages m
+		JOIN users u ON u.id = m.sender_id
+		WHERE m.id = ?
+	`
+	var out model.Message
+	err = d.DB.QueryRowContext(ctx, queri, id).Scan(
+		&out.ID, &out.ConversationID, &out.SenderID, &out.SenderName, &out.Body, &out.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error loading message: %w", err)
+	}
+	return &out, nil
+}
+
+// MessagesQuery returns up to limit messages of a conversation older than
+// the before cursor, newest first. A zero cursor starts from the newest.
+func MessagesQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	conversationID, viewerID, before int64,
+	limit int,
+) ([]model.Message, error) {
+	queri := `
+		SELECT m.id, m.conversation_id, m.sender_id, u.username, m.body, m.created_at
+		FROM messages m
+		JOIN users u ON u.id = m.sender_id
+		WHERE m.conversation_id = ? AND (? = 0 OR m.id < ?) AND ` + senderNotBlocked + `
+		ORDER BY m.id DESC
+		LIMIT ?
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, conversationID, before, before, viewerID, viewerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []model.Message{}
+	for rows.Next() {
+		var m model.Message
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.SenderName, &m.Body, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// MarkReadQuery moves userID's read receipt forward to messageID. Receipts
+// never move backwards, so a stale client cannot mark messages unread.
+func MarkReadQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	conversationID, userID, messageID int64,
+) (*model.Receipt, error) {
+	var exists bool
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM messages WHERE id = ? AND conversation_id = ?)`,
+		messageID, conversationID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error checking message: %w", err)
+	}
+	if !exists {
+		return nil, chat.ErrMessageNotFound
+	}
+
+	// read_at is updated first, while last_read_id still holds the old value.
+	_, err = d.DB.ExecContext(
+		ctx,
+		`INSERT INTO conversation_reads (conversation_id, user_id, last_read_id, read_at)
+		VALUES (?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE
+			read_at = IF(VALUES(last_read_id) > last_read_id, VALUES(read_at), read_at),
+			last_read_id = GREATEST(last_read_id, VALUES(last_read_id))`,
+		conversationID, userID, messageID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error updating read receipt: %w", err)
+	}
+
+	r := model.Receipt{ConversationID: conversationID, UserID: userID}
+	err = d.DB.QueryRowContext(
+		ctx,
+		`SELECT last_read_id, read_at FROM conversation_reads WHERE conversation_id = ? AND user_id = ?`,
+		conversationID, userID,
+	).Scan(&r.LastReadID, &r.ReadAt)
+	if err != nil {
+		return nil, fmt.Errorf("error loading read receipt: %w", err)
+	}
+	return &r, nil
+}
+
+func ReceiptsQuery(ctx context.Context, d *dbs.Service, conversationID int64) ([]model.Receipt, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT conversation_id, user_id, last_read_id, read_at FROM conversation_reads WHERE conversation_id = ?`,
+		conversationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying read receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts := []model.Receipt{}
+	for rows.Next() {
+		var r model.Receipt
+		if err := rows.Scan(&r.ConversationID, &r.UserID, &r.LastReadID, &r.ReadAt); err != nil {
+			return nil, fmt.Errorf("error scanning read receipt: %w", err)
+		}
+		receipts = append(receipts, r)
+	}
+	return receipts, rows.Err()
+}
@@ -0,0 +1,193 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/feed"
+	"github.com/dudeiebot/sportPeerGo/pkg/feed/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	socialmodel "github.com/dudeiebot/sportPeerGo/pkg/social/model"
+)
+
+// RecordActivityQuery saves an activity for the feeds of its actor's
+// friends or its team's members. Recording the same key again replaces the
+// activity, so retried events and corrected results don't repeat.
+func RecordActivityQuery(ctx context.Context, d *dbs.Service, a model.Activity) error {
+	var data interface{}
+	if len(a.Data) > 0 {
+		data = string(a.Data)
+	}
+	_, err := d.DB.ExecContext(
+		ctx,
+		`INSERT INTO activities (activity_key, type, actor_id, team_id, game_id, data, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE actor_id = VALUES(actor_id), team_id = VALUES(team_id), data = VALUES(data)`,
+		a.Key, a.Type, a.ActorID, a.TeamID, a.GameID, data,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording activity: %w", err)
+	}
+	return nil
+}
+
+func DeleteActivityQuery(ctx context.Context, d *dbs.Service, key string) error {
+	if _, err := d.DB.ExecContext(ctx, `DELETE FROM activities WHERE activity_key = ?`, key); err != nil {
+		return fmt.Errorf("error deleting activity: %w", err)
+	}
+	return nil
+}
+
+// FeedViewerQuery loads who userID is friends with, blocks and plays
+// alongside, and where they are.
+func FeedViewerQuery(ctx context.Context, d *dbs.Service, userID int64) (feed.Viewer, error) {
+	v := feed.Viewer{UserID: userID, Teams: make(map[int64]bool)}
+	var err error
+	if v.Friends, err = FriendIDsQuery(ctx, d, userID); err != nil {
+		return v, err
+	}
+	if v.Blocked, err = BlockedIDsQuery(ctx, d, userID); err != nil {
+		return v, err
+	}
+
+	rows, err := d.DB.QueryContext(ctx, `SELECT team_id FROM team_members WHERE user_id = ?`, userID)
+	if err != nil {
+		return v, fmt.Errorf("error querying teams: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return v, fmt.Errorf("error scanning team: %w", err)
+		}
+		v.Teams[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return v, err
+	}
+
+	var lat, lng sql.NullFloat64
+	err = d.DB.QueryRowContext(ctx, `SELECT latitude, longitude FROM users WHERE id = ?`, userID).Scan(&lat, &lng)
+	if err != nil && err != sql.ErrNoRows {
+		return v, fmt.Errorf("error querying location: %w", err)
+	}
+	if lat.Valid && lng.Valid {
+		v.Location = &geo.Point{Lat: lat.Float64, Lng: lng.Float64}
+	}
+	return v, nil
+}
+
+// feedSources are the activities a feed is made of: the viewer's own,
+// their friends' and their teams'. Each is read newest first on its own
+// index, (actor_id, id) or (team_id, id), rather than as one OR the
+// database would have to scan.
+var feedSources = []string{
+	`SELECT a.id FROM activities a WHERE a.actor_id = ?`,
+	`SELECT a.id FROM activities a JOIN friendships f ON f.friend_id = a.actor_id WHERE f.user_id = ?`,
+	`SELECT a.id FROM activities a JOIN team_members m ON m.team_id = a.team_id WHERE m.user_id = ?`,
+}
+
+// FeedActivitiesQuery returns activities of viewerID, their friends and
+// their teams with IDs below before, newest first, with what feed.Visible
+// needs to filter them. Each source is cut to the newest limit before they
+// are merged, which is all a page of limit can need.
+func FeedActivitiesQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	viewerID, before int64,
+	limit int,
+) ([]model.Activity, error) {
+	defaults := socialmodel.DefaultPrivacy(viewerID)
+	args := []interface{}{
+		defaults.Profile, defaults.Games,
+		viewerID, viewerID, viewerID, viewerID, viewerID,
+	}
+	recent := make([]string, len(feedSources))
+	for i, source := range feedSources {
+		args = append(args, viewerID)
+		if before > 0 {
+			source += ` AND a.id < ?`
+			args = append(args, before)
+		}
+		recent[i] = `(` + source + ` ORDER BY a.id DESC LIMIT ?)`
+		args = append(args, limit)
+	}
+	args = append(args, limit)
+
+	queri := `
+		SELECT
+			a.id, a.type, a.actor_id, COALESCE(u.username, ''), a.team_id, COALESCE(t.name, ''),
+			a.game_id, a.data, a.created_at,
+			COALESCE(ps.profile, ?), COALESCE(ps.games, ?),
+			g.organizer_id, g.sport, g.visibility, g.status, v.latitude, v.longitude,
+			EXISTS (SELECT 1 FROM game_participants p WHERE p.game_id = a.game_id AND p.user_id = a.actor_id),
+			EXISTS (SELECT 1 FROM game_participants p WHERE p.game_id = a.game_id AND p.user_id = ?),
+			EXISTS (SELECT 1 FROM game_invites i WHERE i.game_id = a.game_id AND i.user_id = ?),
+			EXISTS (SELECT 1 FROM friendships f WHERE f.user_id = ? AND f.friend_id = g.organizer_id),
+			EXISTS (
+				SELECT 1 FROM user_blocks b
+				WHERE (b.blocker_id = g.organizer_id AND b.blocked_id = ?)
+				   OR (b.blocker_id = ? AND b.blocked_id = g.organizer_id)
+			)
+		FROM (` + strings.Join(recent, ` UNION `) + `) recent
+		JOIN activities a ON a.id = recent.id
+		LEFT JOIN users u ON u.id = a.actor_id
+		LEFT JOIN teams t ON t.id = a.team_id
+		LEFT JOIN privacy_settings ps ON ps.user_id = a.actor_id
+		LEFT JOIN games g ON g.id = a.game_id
+		LEFT JOIN venues v ON v.id = g.venue_id
+		ORDER BY a.id DESC
+		LIMIT ?
+	`
+
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying feed: %w", err)
+	}
+	defer rows.Close()
+
+	activities := []model.Activity{}
+	for rows.Next() {
+		var a model.Activity
+		var g model.Game
+		var actorID, teamID, gameID, organizerID sql.NullInt64
+		var data []byte
+		var sport, visibility, status sql.NullString
+		var lat, lng sql.NullFloat64
+		err := rows.Scan(
+			&a.ID, &a.Type, &actorID, &a.ActorName, &teamID, &a.TeamName,
+			&gameID, &data, &a.CreatedAt,
+			&a.ActorPrivacy.Profile, &a.ActorPrivacy.Games,
+			&organizerID, &sport, &visibility, &status, &lat, &lng,
+			&g.ActorPlaying, &g.ViewerPlaying, &g.ViewerInvited, &g.OrganizerFriend, &g.OrganizerBlocked,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning activity: %w", err)
+		}
+		if actorID.Valid {
+			a.ActorID = &actorID.Int64
+		}
+		if teamID.Valid {
+			a.TeamID = &teamID.Int64
+		}
+		if len(data) > 0 {
+			a.Data = data
+		}
+		if gameID.Valid {
+			a.GameID = &gameID.Int64
+		}
+		if organizerID.Valid {
+			g.OrganizerID = organizerID.Int64
+			g.Sport, g.Visibility, g.Status = sport.String, visibility.String, status.String
+			if lat.Valid && lng.Valid {
+				g.Location = &geo.Point{Lat: lat.Float64, Lng: lng.Float64}
+			}
+			a.Game = &g
+		}
+		activities = append(activities, a)
+	}
+	return activities, rows.Err()
+}
@@ -0,0 +1,798 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/rating"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue"
+	venuemodel "github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+const gameColumns = `
+	g.id, g.organizer_id, g.sport, g.venue_id, g.location, g.starts_at, g.ends_at,
+	g.capacity, g.skill_min, g.skill_max, g.visibility, g.fee_cents, g.status, g.series_id, g.occurrence_at,
+	(SELECT COUNT(*) FROM game_participants gp WHERE gp.game_id = g.id),
+	(SELECT cb.court_id FROM court_bookings cb WHERE cb.game_id = g.id AND cb.status = 'confirmed' LIMIT 1)
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanGame(row rowScanner) (*model.Game, error) {
+	var g model.Game
+	var venueID, courtID, seriesID sql.NullInt64
+	var occurrenceAt sql.NullTime
+	err := row.Scan(
+		&g.ID, &g.OrganizerID, &g.Sport, &venueID, &g.Location, &g.StartsAt, &g.EndsAt,
+		&g.Capacity, &g.SkillMin, &g.SkillMax, &g.Visibility, &g.FeeCents, &g.Status, &seriesID, &occurrenceAt,
+		&g.Participants, &courtID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, game.ErrGameNotFound
+		}
+		return nil, fmt.Errorf("error scanning game: %w", err)
+	}
+	if venueID.Valid {
+		g.VenueID = &venueID.Int64
+	}
+	if courtID.Valid {
+		g.CourtID = &courtID.Int64
+	}
+	if seriesID.Valid {
+		g.SeriesID = &seriesID.Int64
+		g.OccurrenceAt = &occurrenceAt.Time
+	}
+	return &g, nil
+}
+
+func CreateGameQuery(ctx context.Context, d *dbs.Service, g model.Game) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := insertGame(ctx, tx, g)
+	if err != nil {
+		return 0, err
+	}
+	if g.CourtID != nil {
+		b := venuemodel.Booking{
+			VenueID:  *g.VenueID,
+			CourtID:  *g.CourtID,
+			UserID:   g.OrganizerID,
+			GameID:   &id,
+			StartsAt: g.StartsAt,
+			EndsAt:   g.EndsAt,
+			Status:   venuemodel.BookingConfirmed,
+		}
+		if _, err := reserveCourt(ctx, tx, b, time.Now()); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// insertGame stores g and adds the organizer as its first participant.
+func insertGame(ctx context.Context, q querier, g model.Game) (int64, error) {
+	queri := `
+		INSERT INTO games
+			(organizer_id, sport, venue_id, location, starts_at, ends_at,
+			 capacity, skill_min, skill_max, visibility, fee_cents, status, series_id, occurrence_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := q.ExecContext(
+		ctx, queri,
+		g.OrganizerID, g.Sport, g.VenueID, g.Location, g.StartsAt, g.EndsAt,
+		g.Capacity, g.SkillMin, g.SkillMax, g.Visibility, g.FeeCents, model.StatusOpen, g.SeriesID, g.OccurrenceAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting game: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.ExecContext(
+		ctx,
+		`INSERT INTO game_participants (game_id, user_id) VALUES (?, ?)`,
+		id, g.OrganizerID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error adding organizer: %w", err)
+	}
+	return id, nil
+}
+
+func GetGameQuery(ctx context.Context, d *dbs.Service, id int64) (*model.Game, error) {
+	queri := `SELECT ` + gameColumns + ` FROM games g WHERE g.id = ?`
+	return scanGame(d.DB.QueryRowContext(ctx, queri, id))
+}
+
+// GameViewerQuery describes how userID relates to the game.
+func GameViewerQuery(ctx context.Context, d *dbs.Service, gameID, userID int64) (game.Viewer, error) {
+	return gameViewer(ctx, d.DB, gameID, userID)
+}
+
+func gameViewer(ctx context.Context, q querier, gameID, userID int64) (game.Viewer, error) {
+	queri := `
+		SELECT
+			EXISTS (SELECT 1 FROM game_participants WHERE game_id = ? AND user_id = ?),
+			EXISTS (SELECT 1 FROM game_waitlist WHERE game_id = ? AND user_id = ?),
+			EXISTS (SELECT 1 FROM game_invites WHERE game_id = ? AND user_id = ?),
+			EXISTS (
+				SELECT 1 FROM friendships f JOIN games g ON g.organizer_id = f.friend_id
+				WHERE g.id = ? AND f.user_id = ?
+			),
+			EXISTS (
+				SELECT 1 FROM user_blocks b JOIN games g ON g.id = ?
+				WHERE (b.blocker_id = g.organizer_id AND b.blocked_id = ?)
+				   OR (b.blocker_id = ? AND b.blocked_id = g.organizer_id)
+			)
+	`
+	v := game.Viewer{UserID: userID}
+	err := q.QueryRowContext(
+		ctx, queri,
+		gameID, userID, gameID, userID, gameID, userID, gameID, userID, gameID, userID, userID,
+	).Scan(&v.Participant, &v.Waitlisted, &v.Invited, &v.Friend, &v.Blocked)
+	if err != nil {
+		return v, fmt.Errorf("error querying game membership: %w", err)
+	}
+	return v, nil
+}
+
+// gameVisibleTo is the SQL form of game.CanView for the games aliased g. It
+// takes the arguments returned by gameVisibleArgs.
+const gameVisibleTo = `(
+	g.organizer_id = ?
+	OR (
+		NOT EXISTS (
+			SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = g.organizer_id AND b.blocked_id = ?)
+			   OR (b.blocker_id = ? AND b.blocked_id = g.organizer_id)
+		)
+		AND (
+			g.visibility = ?
+			OR EXISTS (SELECT 1 FROM game_participants p WHERE p.game_id = g.id AND p.user_id = ?)
+			OR EXISTS (SELECT 1 FROM game_invites i WHERE i.game_id = g.id AND i.user_id = ?)
+			OR (g.visibility = ? AND EXISTS (
+				SELECT 1 FROM friendships f WHERE f.user_id = ? AND f.friend_id = g.organizer_id
+			))
+		)
+	)
+)`
+
+func gameVisibleArgs(viewerID int64) []interface{} {
+	return []interface{}{
+		viewerID,
+		viewerID, viewerID,
+		model.VisibilityPublic, viewerID, viewerID,
+		model.VisibilityFriends, viewerID,
+	}
+}
+
+func ListGamesQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	viewerID int64,
+	f model.Filter,
+) ([]model.Game, error) {
+	queri := `
+		SELECT ` + gameColumns + `
+		FROM games g
+		WHERE g.status = ?
+		  AND g.starts_at >= ?
+		  AND ` + gameVisibleTo + `
+	`
+	args := []interface{}{model.StatusOpen, f.From}
+	args = append(args, gameVisibleArgs(viewerID)...)
+	if !f.To.IsZero() {
+		queri += ` AND g.starts_at < ?`
+		args = append(args, f.To)
+	}
+	if f.Sport != "" {
+		queri += ` AND g.sport = ?`
+		args = append(args, f.Sport)
+	}
+	if f.Skill != 0 {
+		queri += ` AND ((g.skill_min = 0 AND g.skill_max = 0) OR ? BETWEEN g.skill_min AND g.skill_max)`
+		args = append(args, f.Skill)
+	}
+	queri += ` ORDER BY g.starts_at, g.id LIMIT ? OFFSET ?`
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying games: %w", err)
+	}
+	defer rows.Close()
+
+	games := []model.Game{}
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, *g)
+	}
+	return games, rows.Err()
+}
+
+// lockGame reads the game row with FOR UPDATE so that every join, leave and
+// edit of the same game is serialized, then loads its roster. The roster is
+// read with separate locking reads because a plain subquery would see the
+// transaction's snapshot rather than the latest committed rows.
+func lockGame(ctx context.Context, tx *sql.Tx, id int64) (*model.Game, *game.Roster, error) {
+	queri := `
+		SELECT g.id, g.organizer_id, g.sport, g.venue_id, g.location, g.starts_at, g.ends_at,
+			g.capacity, g.skill_min, g.skill_max, g.visibility, g.fee_cents, g.status, g.series_id, g.occurrence_at, 0,
+			(SELECT cb.court_id FROM court_bookings cb WHERE cb.game_id = g.id AND cb.status = 'confirmed' LIMIT 1)
+		FROM games g
+		WHERE g.id = ?
+		FOR UPDATE
+	`
+	g, err := scanGame(tx.QueryRowContext(ctx, queri, id))
+	if err != nil {
+		return nil, nil, err
+	}
+	r := &game.Roster{Capacity: g.Capacity, StartsAt: g.StartsAt}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT user_id FROM game_participants WHERE game_id = ? ORDER BY joined_at, user_id FOR UPDATE`,
+		id,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error locking participants: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, nil, fmt.Errorf("error scanning participant: %w", err)
+		}
+		r.Participants = append(r.Participants, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	g.Participants = len(r.Participants)
+
+	r.Waitlist, err = waitlist(ctx, tx, id, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g, r, nil
+}
+
+func waitlist(ctx context.Context, q querier, gameID int64, lock bool) ([]game.WaitEntry, error) {
+	queri := `
+		SELECT user_id, offer_expires_at
+		FROM game_waitlist
+		WHERE game_id = ?
+		ORDER BY id
+	`
+	if lock {
+		queri += ` FOR UPDATE`
+	}
+	rows, err := q.QueryContext(ctx, queri, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []game.WaitEntry{}
+	for rows.Next() {
+		var e game.WaitEntry
+		var expires sql.NullTime
+		if err := rows.Scan(&e.UserID, &expires); err != nil {
+			return nil, fmt.Errorf("error scanning waitlist: %w", err)
+		}
+		if expires.Valid {
+			e.Offered = true
+			e.OfferExpiresAt = expires.Time
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// saveRosterChange persists the rows touched by a Roster operation.
+func saveRosterChange(ctx context.Context, tx *sql.Tx, gameID int64, c game.Change) error {
+	for _, id := range c.Left {
+		_, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM game_participants WHERE game_id = ? AND user_id = ?`,
+			gameID, id,
+		)
+		if err != nil {
+			return fmt.Errorf("error removing participant: %w", err)
+		}
+	}
+	for _, id := range append(c.Dropped, c.Joined...) {
+		_, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM game_waitlist WHERE game_id = ? AND user_id = ?`,
+			gameID, id,
+		)
+		if err != nil {
+			return fmt.Errorf("error removing waitlist entry: %w", err)
+		}
+	}
+	for _, id := range c.Joined {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO game_participants (game_id, user_id) VALUES (?, ?)`,
+			gameID, id,
+		)
+		if err != nil {
+			return fmt.Errorf("error adding participant: %w", err)
+		}
+	}
+	for _, id := range c.Waitlisted {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO game_waitlist (game_id, user_id) VALUES (?, ?)`,
+			gameID, id,
+		)
+		if err != nil {
+			return fmt.Errorf("error adding waitlist entry: %w", err)
+		}
+	}
+	for _, o := range c.Offered {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE game_waitlist SET offered_at = ?, offer_expires_at = ? WHERE game_id = ? AND user_id = ?`,
+			time.Now(), o.ExpiresAt, gameID, o.UserID,
+		)
+		if err != nil {
+			return fmt.Errorf("error offering seat: %w", err)
+		}
+	}
+	return nil
+}
+
+func UpdateGameQuery(ctx context.Context, d *dbs.Service, g model.Game, organizerID int64) (game.Change, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return game.Change{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, r, err := lockGame(ctx, tx, g.ID)
+	if err != nil {
+		return game.Change{}, err
+	}
+	if current.OrganizerID != organizerID {
+		return game.Change{}, game.ErrNotOrganizer
+	}
+	c, err := updateGame(ctx, tx, current, r, g)
+	if err != nil {
+		return game.Change{}, err
+	}
+	return c, tx.Commit()
+}
+
+// updateGame edits the locked game current into g after checking the edit
+// against its players and court booking, and offers any extra seats to the
+// waitlist.
+func updateGame(ctx context.Context, tx *sql.Tx, current *model.Game, r *game.Roster, g model.Game) (game.Change, error) {
+	var err error
+	skills := make(map[int64]int, len(r.Participants))
+	if g.HasSkillRange() {
+		for _, id := range r.Participants {
+			if id == current.OrganizerID {
+				continue
+			}
+			if skills[id], err = userSkill(ctx, tx, id, g.Sport); err != nil {
+				return game.Change{}, err
+			}
+		}
+	}
+	if err := game.CheckUpdate(*current, g, skills, time.Now()); err != nil {
+		return game.Change{}, err
+	}
+	if current.CourtID != nil && (!g.StartsAt.Equal(current.StartsAt) || !g.EndsAt.Equal(current.EndsAt) ||
+		g.VenueID == nil || *g.VenueID != *current.VenueID) {
+		return game.Change{}, venue.ErrCourtBooked
+	}
+
+	queri := `
+		UPDATE games
+		SET sport = ?, venue_id = ?, location = ?, starts_at = ?, ends_at = ?,
+			capacity = ?, skill_min = ?, skill_max = ?, visibility = ?, fee_cents = ?
+		WHERE id = ?
+	`
+	_, err = tx.ExecContext(
+		ctx, queri,
+		g.Sport, g.VenueID, g.Location, g.StartsAt, g.EndsAt,
+		g.Capacity, g.SkillMin, g.SkillMax, g.Visibility, g.FeeCents, current.ID,
+	)
+	if err != nil {
+		return game.Change{}, fmt.Errorf("error updating game: %w", err)
+	}
+
+	// Extra seats go down the waitlist like seats freed by a leave.
+	r.Capacity, r.StartsAt = g.Capacity, g.StartsAt
+	c := r.Expire(time.Now(), game.ConfirmWindow)
+	if err := saveRosterChange(ctx, tx, current.ID, c); err != nil {
+		return game.Change{}, err
+	}
+	return c, nil
+}
+
+func CancelGameQuery(ctx context.Context, d *dbs.Service, gameID, organizerID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queri := `UPDATE games SET status = ? WHERE id = ? AND organizer_id = ? AND status = ?`
+	res, err := tx.ExecContext(ctx, queri, model.StatusCancelled, gameID, organizerID, model.StatusOpen)
+	if err != nil {
+		return fmt.Errorf("error cancelling game: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return game.ErrNotOrganizer
+	}
+	if err := cancelGameBookings(ctx, tx, gameID, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// JoinGameQuery adds userID to the game, or to its waitlist once it is full.
+// Capacity is checked while holding the game row lock, so concurrent joins
+// cannot overbook it. A seat in a game with a fee comes with a pending
+// payment to the named provider.
+func JoinGameQuery(ctx context.Context, d *dbs.Service, gameID, userID int64, provider string) (game.Change, int, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return game.Change{}, 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	g, r, err := lockGame(ctx, tx, gameID)
+	if err != nil {
+		return game.Change{}, 0, err
+	}
+	v, err := gameViewer(ctx, tx, gameID, userID)
+	if err != nil {
+		return game.Change{}, 0, err
+	}
+	skill, err := userSkill(ctx, tx, userID, g.Sport)
+	if err != nil {
+		return game.Change{}, 0, err
+	}
+	now := time.Now()
+	if v.Restricted, err = noShowRestricted(ctx, tx, userID, now); err != nil {
+		return game.Change{}, 0, err
+	}
+	if err := game.CheckJoin(*g, v, skill, now); err != nil {
+		return game.Change{}, 0, err
+	}
+
+	c, err := r.Join(userID, now, game.ConfirmWindow)
+	if err != nil {
+		return c, 0, err
+	}
+	if err := saveRosterChange(ctx, tx, gameID, c); err != nil {
+		return c, 0, err
+	}
+	if err := holdFees(ctx, tx, g, c.Joined, provider); err != nil {
+		return c, 0, err
+	}
+	return c, r.Position(userID), tx.Commit()
+}
+
+// LeaveGameQuery removes userID from the game or its waitlist, offering any
+// freed seat to the next waiter in the same transaction.
+func LeaveGameQuery(ctx context.Context, d *dbs.Service, gameID, userID int64) (game.Change, error) {
+	return updateRoster(ctx, d, gameID, func(g *model.Game, r *game.Roster, now time.Time) (game.Change, error) {
+		v := game.Viewer{
+			UserID:      userID,
+			Participant: containsID(r.Participants, userID),
+			Waitlisted:  r.Position(userID) > 0,
+		}
+		if err := game.CheckLeave(*g, v, now); err != nil {
+			return game.Change{}, err
+		}
+		return r.Leave(userID, now, game.ConfirmWindow)
+	})
+}
+
+// ConfirmWaitlistQuery claims a seat offered to userID, holding its fee like
+// JoinGameQuery. Lapsed offers expired along the way are saved even when
+// the claim fails.
+func ConfirmWaitlistQuery(ctx context.Context, d *dbs.Service, gameID, userID int64, provider string) (game.Change, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return game.Change{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	g, r, err := lockGame(ctx, tx, gameID)
+	if err != nil {
+		return game.Change{}, err
+	}
+	var c game.Change
+	confirmErr := game.ErrGameClosed
+	if g.Status == model.StatusOpen {
+		c, confirmErr = r.Confirm(userID, time.Now(), game.ConfirmWindow)
+	}
+	if err := saveRosterChange(ctx, tx, gameID, c); err != nil {
+		return c, err
+	}
+	if err := holdFees(ctx, tx, g, c.Joined, provider); err != nil {
+		return c, err
+	}
+	if err := tx.Commit(); err != nil {
+		return c, err
+	}
+	return c, confirmErr
+}
+
+// ExpireWaitlistOffersQuery passes every lapsed offer on to the next waiter
+// and returns the changes per game.
+func ExpireWaitlistOffersQuery(ctx context.Context, d *dbs.Service, now time.Time) (map[int64]game.Change, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT DISTINCT game_id FROM game_waitlist WHERE offer_expires_at <= ?`,
+		now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying expired offers: %w", err)
+	}
+	var gameIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning expired offer: %w", err)
+		}
+		gameIDs = append(gameIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	changes := make(map[int64]game.Change)
+	for _, id := range gameIDs {
+		c, err := updateRoster(ctx, d, id, func(g *model.Game, r *game.Roster, now time.Time) (game.Change, error) {
+			return r.Expire(now, game.ConfirmWindow), nil
+		})
+		if err != nil {
+			return changes, err
+		}
+		changes[id] = c
+	}
+	return changes, nil
+}
+
+func WaitlistQuery(ctx context.Context, d *dbs.Service, gameID int64) ([]game.WaitEntry, error) {
+	return waitlist(ctx, d.DB, gameID, false)
+}
+
+// updateRoster runs fn against the locked roster of gameID and saves whatever
+// it changed. The change is saved even when fn fails, since expiring lapsed
+// offers along the way must not be lost.
+func updateRoster(
+	ctx context.Context,
+	d *dbs.Service,
+	gameID int64,
+	fn func(g *model.Game, r *game.Roster, now time.Time) (game.Change, error),
+) (game.Change, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return game.Change{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	g, r, err := lockGame(ctx, tx, gameID)
+	if err != nil {
+		return game.Change{}, err
+	}
+	c, fnErr := fn(g, r, time.Now())
+	if err := saveRosterChange(ctx, tx, gameID, c); err != nil {
+		return c, err
+	}
+	if err := tx.Commit(); err != nil {
+		return c, err
+	}
+	return c, fnErr
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func InviteToGameQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	gameID, organizerID int64,
+	userIDs []int64,
+) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	g, _, err := lockGame(ctx, tx, gameID)
+	if err != nil {
+		return err
+	}
+	if g.OrganizerID != organizerID {
+		return game.ErrNotOrganizer
+	}
+
+	for _, id := range userIDs {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT IGNORE INTO game_invites (game_id, user_id, invited_by) VALUES (?, ?, ?)`,
+			gameID, id, organizerID,
+		)
+		if err != nil {
+			return fmt.Errorf("error inviting user %d: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func GameParticipantsQuery(ctx context.Context, d *dbs.Service, gameID int64) ([]model.Participant, error) {
+	queri := `
+		SELECT p.user_id, u.username, p.joined_at
+		FROM game_participants p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.game_id = ?
+		ORDER BY p.joined_at, p.user_id
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying participants: %w", err)
+	}
+	defer rows.Close()
+
+	participants := []model.Participant{}
+	for rows.Next() {
+		var p model.Participant
+		if err := rows.Scan(&p.UserID, &p.Username, &p.JoinedAt); err != nil {
+			return nil, fmt.Errorf("error scanning participant: %w", err)
+		}
+		participants = append(participants, p)
+	}
+	return participants, rows.Err()
+}
+
+// CoPlayersQuery counts the games userID has played with each other user.
+func CoPlayersQuery(ctx context.Context, d *dbs.Service, userID int64) (map[int64]int, error) {
+	queri := `
+		SELECT other.user_id, COUNT(*)
+		FROM game_participants me
+		JOIN game_participants other ON other.game_id = me.game_id AND other.user_id <> me.user_id
+		WHERE me.user_id = ?
+		GROUP BY other.user_id
+	`
+
+	rows, err := d.DB.QueryContext(ctx, queri, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying play history: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("error scanning play history: %w", err)
+		}
+		out[id] = count
+	}
+	return out, rows.Err()
+}
+
+// userSkill returns the skill level of userID in sport, going by their
+// rating once it has settled and by what they reported until then.
+func userSkill(ctx context.Context, q querier, userID int64, sport string) (int, error) {
+	var skill sql.NullInt64
+	var r, rd sql.NullFloat64
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT
+			(SELECT skill FROM user_sports WHERE user_id = ? AND sport = ?),
+			(SELECT rating FROM player_ratings WHERE user_id = ? AND sport = ?),
+			(SELECT rd FROM player_ratings WHERE user_id = ? AND sport = ?)`,
+		userID, sport, userID, sport, userID, sport,
+	).Scan(&skill, &r, &rd)
+	if err != nil {
+		return 0, fmt.Errorf("error querying skill: %w", err)
+	}
+	if !r.Valid {
+		return int(skill.Int64), nil
+	}
+	return rating.EffectiveSkill(int(skill.Int64), &rating.Rating{Rating: r.Float64, RD: rd.Float64}), nil
+}
+
+func UserSkillQuery(ctx context.Context, d *dbs.Service, userID int64, sport string) (int, error) {
+	return userSkill(ctx, d.DB, userID, sport)
+}
+
+func UserEmailsQuery(ctx context.Context, d *dbs.Service, ids []int64) (map[int64]string, error) {
+	if len(ids) == 0 {
+		return map[int64]string{}, nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	queri := `SELECT id, email FROM users WHERE id IN (` + placeholders(len(ids)) + `)`
+
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var email string
+		if err := rows.Scan(&id, &email); err != nil {
+			return nil, fmt.Errorf("error scanning email: %w", err)
+		}
+		emails[id] = email
+	}
+	return emails, rows.Err()
+}
+
+// UserGamesQuery returns the open games from "from" on that userID takes part
+// in and viewerID is allowed to see.
+func UserGamesQuery(ctx context.Context, d *dbs.Service, userID, viewerID int64, from time.Time) ([]model.Game, error) {
+	queri := `
+		SELECT ` + gameColumns + `
+		FROM games g
+		JOIN game_participants me ON me.game_id = g.id AND me.user_id = ?
+		WHERE g.status = ? AND g.starts_at >= ? AND ` + gameVisibleTo + `
+		ORDER BY g.starts_at, g.id
+	`
+	args := append([]interface{}{userID, model.StatusOpen, from}, gameVisibleArgs(viewerID)...)
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying user games: %w", err)
+	}
+	defer rows.Close()
+
+	games := []model.Game{}
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, *g)
+	}
+	return games, rows.Err()
+}
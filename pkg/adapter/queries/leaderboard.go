@@ -0,0 +1,213 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/leaderboard"
+	"github.com/dudeiebot/sportPeerGo/pkg/leaderboard/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/rating"
+	socialmodel "github.com/dudeiebot/sportPeerGo/pkg/social/model"
+)
+
+// leaderboardBatch is how many entries go into one INSERT.
+const leaderboardBatch = 500
+
+// LeaderboardRowsQuery computes every player's standing in metric since the
+// given time, across all sports. Users who hide their profile are left off
+// leaderboards.
+func LeaderboardRowsQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	metric string,
+	since time.Time,
+) ([]leaderboard.Row, error) {
+	var queri string
+	var args []interface{}
+	switch metric {
+	case model.MetricRating:
+		queri = `
+			SELECT pr.user_id, u.username, COALESCE(u.city, ''), pr.sport, pr.rating, pr.rd, COUNT(*)
+			FROM player_ratings pr
+			JOIN users u ON u.id = pr.user_id
+			JOIN rating_history h ON h.user_id = pr.user_id AND h.sport = pr.sport AND h.created_at >= ?
+			LEFT JOIN privacy_settings ps ON ps.user_id = pr.user_id
+			WHERE COALESCE(ps.profile, ?) = ?
+			GROUP BY pr.user_id, u.username, u.city, pr.sport, pr.rating, pr.rd
+		`
+		args = []interface{}{since, socialmodel.AudienceEveryone, socialmodel.AudienceEveryone}
+	case model.MetricActivity:
+		queri = `
+			SELECT gp.user_id, u.username, COALESCE(u.city, ''), g.sport, COUNT(*), 0, COUNT(*)
+			FROM game_participants gp
+			JOIN games g ON g.id = gp.game_id
+			JOIN users u ON u.id = gp.user_id
+			LEFT JOIN privacy_settings ps ON ps.user_id = gp.user_id
+			WHERE g.status <> ? AND g.ends_at >= ? AND g.ends_at <= ?
+			  AND COALESCE(ps.profile, ?) = ?
+			GROUP BY gp.user_id, u.username, u.city, g.sport
+		`
+		args = []interface{}{
+			gamemodel.StatusCancelled, since, time.Now(),
+			socialmodel.AudienceEveryone, socialmodel.AudienceEveryone,
+		}
+	default:
+		return nil, fmt.Errorf("unknown leaderboard metric %q", metric)
+	}
+
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying leaderboard standings: %w", err)
+	}
+	defer rows.Close()
+
+	var out []leaderboard.Row
+	for rows.Next() {
+		var r leaderboard.Row
+		var rd float64
+		if err := rows.Scan(&r.UserID, &r.Username, &r.City, &r.Sport, &r.Score, &rd, &r.Games); err != nil {
+			return nil, fmt.Errorf("error scanning leaderboard standing: %w", err)
+		}
+		if metric == model.MetricRating {
+			if (rating.Rating{RD: rd}).Provisional() {
+				continue
+			}
+			r.Score = math.Round(r.Score)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ReplaceLeaderboardQuery swaps every board of metric and window for the
+// freshly ranked boards of period, in one transaction so readers never see
+// a half-written board.
+func ReplaceLeaderboardQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	metric, window, period string,
+	boards map[leaderboard.Key][]model.Entry,
+) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`DELETE FROM leaderboard_entries WHERE metric = ? AND time_window = ?`,
+		metric, window,
+	)
+	if err != nil {
+		return fmt.Errorf("error clearing leaderboard: %w", err)
+	}
+
+	now := time.Now()
+	var values []string
+	var args []interface{}
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO leaderboard_entries
+				(metric, time_window, period, sport, city, position, user_rank, user_id, score, games, refreshed_at)
+			VALUES `+strings.Join(values, ", "),
+			args...,
+		)
+		values, args = values[:0], args[:0]
+		if err != nil {
+			return fmt.Errorf("error inserting leaderboard entries: %w", err)
+		}
+		return nil
+	}
+	for key, entries := range boards {
+		for i, e := range entries {
+			values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			args = append(args, metric, window, period, key.Sport, key.City, i+1, e.Rank, e.UserID, e.Score, e.Games, now)
+			if len(values) == leaderboardBatch {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const leaderboardColumns = `le.user_rank, le.user_id, u.username, le.city, le.score, le.games, le.refreshed_at`
+
+func scanLeaderboardEntry(row rowScanner, refreshed *time.Time) (model.Entry, error) {
+	var e model.Entry
+	err := row.Scan(&e.Rank, &e.UserID, &e.Username, &e.City, &e.Score, &e.Games, refreshed)
+	return e, err
+}
+
+// LeaderboardQuery reads a page of the board picked by q for period, along
+// with the entry of userID wherever it ranks.
+func LeaderboardQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	q model.Query,
+	period string,
+	userID int64,
+	limit, offset int,
+) (*model.Board, error) {
+	board := &model.Board{
+		Sport: q.Sport, City: q.City, Metric: q.Metric, Window: q.Window, Period: period,
+		Entries: []model.Entry{},
+	}
+	where := `
+		FROM leaderboard_entries le
+		JOIN users u ON u.id = le.user_id
+		WHERE le.metric = ? AND le.time_window = ? AND le.period = ? AND le.sport = ? AND le.city = ?
+	`
+	args := []interface{}{q.Metric, q.Window, period, q.Sport, q.City}
+
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT `+leaderboardColumns+where+` ORDER BY le.position LIMIT ? OFFSET ?`,
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying leaderboard: %w", err)
+	}
+	defer rows.Close()
+	var refreshed time.Time
+	for rows.Next() {
+		e, err := scanLeaderboardEntry(rows, &refreshed)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning leaderboard entry: %w", err)
+		}
+		board.Entries = append(board.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	me, err := scanLeaderboardEntry(
+		d.DB.QueryRowContext(ctx, `SELECT `+leaderboardColumns+where+` AND le.user_id = ?`, append(args, userID)...),
+		&refreshed,
+	)
+	switch {
+	case err == nil:
+		board.Me = &me
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("error querying leaderboard rank: %w", err)
+	}
+	if !refreshed.IsZero() {
+		board.RefreshedAt = &refreshed
+	}
+	return board, nil
+}
@@ -0,0 +1,777 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/league"
+	"github.com/dudeiebot/sportPeerGo/pkg/league/model"
+)
+
+// fixtureBatch is how many fixtures go into one INSERT.
+const fixtureBatch = 500
+
+const leagueColumns = `id, organizer_id, name, sport, entrant_type, created_at`
+
+func scanLeague(row rowScanner) (*model.League, error) {
+	var l model.League
+	err := row.Scan(&l.ID, &l.OrganizerID, &l.Name, &l.Sport, &l.EntrantType, &l.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, league.ErrLeagueNotFound
+		}
+		return nil, fmt.Errorf("error scanning league: %w", err)
+	}
+	return &l, nil
+}
+
+func CreateLeagueQuery(ctx context.Context, d *dbs.Service, l model.League) (int64, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`INSERT INTO leagues (organizer_id, name, sport, entrant_type) VALUES (?, ?, ?, ?)`,
+		l.OrganizerID, l.Name, l.Sport, l.EntrantType,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting league: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func GetLeagueQuery(ctx context.Context, d *dbs.Service, id int64) (*model.League, error) {
+	return scanLeague(d.DB.QueryRowContext(ctx, `SELECT `+leagueColumns+` FROM leagues WHERE id = ?`, id))
+}
+
+func ListLeaguesQuery(ctx context.Context, d *dbs.Service, f model.Filter) ([]model.League, error) {
+	queri := `SELECT ` + leagueColumns + ` FROM leagues WHERE 1 = 1`
+	var args []interface{}
+	if f.Sport != "" {
+		queri += ` AND sport = ?`
+		args = append(args, f.Sport)
+	}
+	queri += ` ORDER BY name, id LIMIT ? OFFSET ?`
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying leagues: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.League{}
+	for rows.Next() {
+		l, err := scanLeague(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *l)
+	}
+	return out, rows.Err()
+}
+
+const seasonColumns = `id, league_id, name, status, rules, plan, created_at`
+
+func scanSeason(row rowScanner) (*model.Season, error) {
+	var s model.Season
+	var rules, plan []byte
+	err := row.Scan(&s.ID, &s.LeagueID, &s.Name, &s.Status, &rules, &plan, &s.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, league.ErrSeasonNotFound
+		}
+		return nil, fmt.Errorf("error scanning season: %w", err)
+	}
+	if err := json.Unmarshal(rules, &s.Rules); err != nil {
+		return nil, fmt.Errorf("error decoding rules of season %d: %w", s.ID, err)
+	}
+	if err := json.Unmarshal(plan, &s.Plan); err != nil {
+		return nil, fmt.Errorf("error decoding plan of season %d: %w", s.ID, err)
+	}
+	s.Divisions = []model.Division{}
+	return &s, nil
+}
+
+// seasonDivisions fills in the divisions of s, top first, with their
+// members.
+func seasonDivisions(ctx context.Context, q querier, s *model.Season) error {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT id, season_id, name, level FROM league_divisions WHERE season_id = ? ORDER BY level`,
+		s.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error querying divisions: %w", err)
+	}
+	defer rows.Close()
+
+	s.Divisions = []model.Division{}
+	index := make(map[int64]int)
+	for rows.Next() {
+		var dv model.Division
+		if err := rows.Scan(&dv.ID, &dv.SeasonID, &dv.Name, &dv.Level); err != nil {
+			return fmt.Errorf("error scanning division: %w", err)
+		}
+		dv.Members = []model.Member{}
+		index[dv.ID] = len(s.Divisions)
+		s.Divisions = append(s.Divisions, dv)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	members, err := seasonMembers(ctx, q, `d.season_id = ?`, s.ID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		i := index[m.DivisionID]
+		s.Divisions[i].Members = append(s.Divisions[i].Members, m)
+	}
+	return nil
+}
+
+const memberColumns = `
+	m.id, m.division_id, m.user_id, m.team_id, COALESCE(u.username, tm.name, ''), m.home_venue_id
+	FROM league_members m
+	JOIN league_divisions d ON d.id = m.division_id
+	LEFT JOIN users u ON u.id = m.user_id
+	LEFT JOIN teams tm ON tm.id = m.team_id
+`
+
+func seasonMembers(ctx context.Context, q querier, where string, args ...interface{}) ([]model.Member, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+memberColumns+` WHERE `+where+` ORDER BY m.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying league members: %w", err)
+	}
+	defer rows.Close()
+
+	var out []model.Member
+	for rows.Next() {
+		var m model.Member
+		var userID, teamID, venueID sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.DivisionID, &userID, &teamID, &m.Name, &venueID); err != nil {
+			return nil, fmt.Errorf("error scanning league member: %w", err)
+		}
+		if userID.Valid {
+			m.UserID = &userID.Int64
+		}
+		if teamID.Valid {
+			m.TeamID = &teamID.Int64
+		}
+		if venueID.Valid {
+			m.HomeVenueID = &venueID.Int64
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// GetSeasonQuery loads a season of leagueID with its divisions and members.
+func GetSeasonQuery(ctx context.Context, d *dbs.Service, leagueID, seasonID int64) (*model.Season, error) {
+	s, err := scanSeason(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+seasonColumns+` FROM league_seasons WHERE league_id = ? AND id = ?`,
+		leagueID, seasonID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := seasonDivisions(ctx, d.DB, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LastSeasonQuery loads the latest season of leagueID, with its divisions
+// and members.
+func LastSeasonQuery(ctx context.Context, d *dbs.Service, leagueID int64) (*model.Season, error) {
+	var id int64
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT id FROM league_seasons WHERE league_id = ? ORDER BY id DESC LIMIT 1`,
+		leagueID,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, league.ErrSeasonNotFound
+		}
+		return nil, fmt.Errorf("error querying last season: %w", err)
+	}
+	return GetSeasonQuery(ctx, d, leagueID, id)
+}
+
+// SeasonsQuery lists the seasons of a league, newest first, without their
+// divisions.
+func SeasonsQuery(ctx context.Context, d *dbs.Service, leagueID int64) ([]model.Season, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT `+seasonColumns+` FROM league_seasons WHERE league_id = ? ORDER BY id DESC`,
+		leagueID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying seasons: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.Season{}
+	for rows.Next() {
+		s, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+// CreateSeasonQuery opens a new season of s.LeagueID once the last one has
+// finished. carried lists the members of each division carried over from
+// the last season, top first; any beyond the new season's lowest division
+// join that one.
+func CreateSeasonQuery(ctx context.Context, d *dbs.Service, s model.Season, carried [][]model.Member) (int64, error) {
+	rules, err := json.Marshal(s.Rules)
+	if err != nil {
+		return 0, fmt.Errorf("error encoding rules: %w", err)
+	}
+	plan, err := json.Marshal(s.Plan)
+	if err != nil {
+		return 0, fmt.Errorf("error encoding plan: %w", err)
+	}
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the league so that two seasons cannot be opened at once.
+	if _, err := scanLeague(tx.QueryRowContext(
+		ctx,
+		`SELECT `+leagueColumns+` FROM leagues WHERE id = ? FOR UPDATE`,
+		s.LeagueID,
+	)); err != nil {
+		return 0, err
+	}
+	var open bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM league_seasons WHERE league_id = ? AND status <> ?)`,
+		s.LeagueID, model.SeasonCompleted,
+	).Scan(&open)
+	if err != nil {
+		return 0, fmt.Errorf("error checking seasons: %w", err)
+	}
+	if open {
+		return 0, league.ErrSeasonOpen
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO league_seasons (league_id, name, status, rules, plan) VALUES (?, ?, ?, ?, ?)`,
+		s.LeagueID, s.Name, model.SeasonDraft, rules, plan,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting season: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	divisions := make([]int64, len(s.Divisions))
+	for i, dv := range s.Divisions {
+		res, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO league_divisions (season_id, name, level) VALUES (?, ?, ?)`,
+			id, dv.Name, dv.Level,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("error inserting division: %w", err)
+		}
+		if divisions[i], err = res.LastInsertId(); err != nil {
+			return 0, err
+		}
+	}
+	for level, members := range carried {
+		division := divisions[len(divisions)-1]
+		if level < len(divisions) {
+			division = divisions[level]
+		}
+		for _, m := range members {
+			_, err := tx.ExecContext(
+				ctx,
+				`INSERT INTO league_members (division_id, user_id, team_id, home_venue_id) VALUES (?, ?, ?, ?)`,
+				division, m.UserID, m.TeamID, m.HomeVenueID,
+			)
+			if err != nil {
+				return 0, fmt.Errorf("error carrying over league member: %w", err)
+			}
+		}
+	}
+	return id, tx.Commit()
+}
+
+func lockSeason(ctx context.Context, tx *sql.Tx, seasonID int64) (*model.Season, error) {
+	return scanSeason(tx.QueryRowContext(
+		ctx,
+		`SELECT `+seasonColumns+` FROM league_seasons WHERE id = ? FOR UPDATE`,
+		seasonID,
+	))
+}
+
+// AddMemberQuery puts a player or a team, whichever of m's UserID and
+// TeamID is set, in a division of a season that has not started.
+func AddMemberQuery(ctx context.Context, d *dbs.Service, seasonID int64, m model.Member) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	s, err := lockSeason(ctx, tx, seasonID)
+	if err != nil {
+		return 0, err
+	}
+	if s.Status != model.SeasonDraft {
+		return 0, league.ErrSeasonStarted
+	}
+	var count int
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT COUNT(m.id) FROM league_divisions d
+		LEFT JOIN league_members m ON m.division_id = d.id
+		WHERE d.id = ? AND d.season_id = ?
+		GROUP BY d.id`,
+		m.DivisionID, seasonID,
+	).Scan(&count)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, league.ErrDivisionNotFound
+		}
+		return 0, fmt.Errorf("error counting division members: %w", err)
+	}
+	if count >= model.MaxMembers {
+		return 0, league.ErrDivisionFull
+	}
+	var exists bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (
+			SELECT 1 FROM league_members m
+			JOIN league_divisions d ON d.id = m.division_id
+			WHERE d.season_id = ? AND (m.user_id = ? OR m.team_id = ?)
+		)`,
+		seasonID, m.UserID, m.TeamID,
+	).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("error checking league members: %w", err)
+	}
+	if exists {
+		return 0, league.ErrAlreadyMember
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO league_members (division_id, user_id, team_id, home_venue_id) VALUES (?, ?, ?, ?)`,
+		m.DivisionID, m.UserID, m.TeamID, m.HomeVenueID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting league member: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// RemoveMemberQuery takes a member out of a season that has not started.
+func RemoveMemberQuery(ctx context.Context, d *dbs.Service, seasonID, memberID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	s, err := lockSeason(ctx, tx, seasonID)
+	if err != nil {
+		return err
+	}
+	if s.Status != model.SeasonDraft {
+		return league.ErrSeasonStarted
+	}
+	res, err := tx.ExecContext(
+		ctx,
+		`DELETE m FROM league_members m
+		JOIN league_divisions d ON d.id = m.division_id
+		WHERE m.id = ? AND d.season_id = ?`,
+		memberID, seasonID,
+	)
+	if err != nil {
+		return fmt.Errorf("error removing league member: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return league.ErrMemberNotFound
+	}
+	return tx.Commit()
+}
+
+// venueCapacity counts the active courts for sport at each venue.
+func venueCapacity(ctx context.Context, q querier, sport string, venueIDs []int64) (map[int64]int, error) {
+	out := make(map[int64]int)
+	if len(venueIDs) == 0 {
+		return out, nil
+	}
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT venue_id, COUNT(*) FROM venue_courts
+		WHERE active AND sport = ? AND venue_id IN (`+placeholders(len(venueIDs))+`)
+		GROUP BY venue_id`,
+		append([]interface{}{sport}, int64Args(venueIDs)...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying venue courts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var courts int
+		if err := rows.Scan(&id, &courts); err != nil {
+			return nil, fmt.Errorf("error scanning venue courts: %w", err)
+		}
+		out[id] = courts
+	}
+	return out, rows.Err()
+}
+
+// StartSeasonQuery closes a season to new members and schedules its
+// fixtures around the courts each venue has for sport.
+func StartSeasonQuery(ctx context.Context, d *dbs.Service, seasonID int64, sport string) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	s, err := lockSeason(ctx, tx, seasonID)
+	if err != nil {
+		return err
+	}
+	if s.Status != model.SeasonDraft {
+		return league.ErrSeasonStarted
+	}
+	if err := seasonDivisions(ctx, tx, s); err != nil {
+		return err
+	}
+	var venues []int64
+	if s.Plan.VenueID != nil {
+		venues = append(venues, *s.Plan.VenueID)
+	}
+	for _, dv := range s.Divisions {
+		if len(dv.Members) < 2 {
+			return league.ErrNotEnoughMembers
+		}
+		for _, m := range dv.Members {
+			if m.HomeVenueID != nil {
+				venues = append(venues, *m.HomeVenueID)
+			}
+		}
+	}
+	capacity, err := venueCapacity(ctx, tx, sport, venues)
+	if err != nil {
+		return err
+	}
+	fixtures, err := league.Schedule(s.Divisions, s.Plan, capacity)
+	if err != nil {
+		return err
+	}
+
+	var values []string
+	var args []interface{}
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO league_fixtures
+				(season_id, division_id, round, home_id, away_id, venue_id, starts_at, ends_at, status)
+			VALUES `+strings.Join(values, ", "),
+			args...,
+		)
+		values, args = values[:0], args[:0]
+		if err != nil {
+			return fmt.Errorf("error inserting fixtures: %w", err)
+		}
+		return nil
+	}
+	for _, f := range fixtures {
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, seasonID, f.DivisionID, f.Round, f.HomeID, f.AwayID, f.VenueID, f.StartsAt, f.EndsAt, f.Status)
+		if len(values) == fixtureBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE league_seasons SET status = ? WHERE id = ?`, model.SeasonActive, seasonID)
+	if err != nil {
+		return fmt.Errorf("error starting season: %w", err)
+	}
+	return tx.Commit()
+}
+
+// CompleteSeasonQuery ends a season once every fixture has been played.
+func CompleteSeasonQuery(ctx context.Context, d *dbs.Service, seasonID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	s, err := lockSeason(ctx, tx, seasonID)
+	if err != nil {
+		return err
+	}
+	if s.Status != model.SeasonActive {
+		return league.ErrSeasonNotActive
+	}
+	var left bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM league_fixtures WHERE season_id = ? AND status = ?)`,
+		seasonID, model.FixtureScheduled,
+	).Scan(&left)
+	if err != nil {
+		return fmt.Errorf("error checking fixtures: %w", err)
+	}
+	if left {
+		return league.ErrFixturesLeft
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE league_seasons SET status = ? WHERE id = ?`, model.SeasonCompleted, seasonID)
+	if err != nil {
+		return fmt.Errorf("error completing season: %w", err)
+	}
+	return tx.Commit()
+}
+
+const fixtureColumns = `
+	f.id, f.season_id, f.division_id, f.round, f.home_id, f.away_id, f.venue_id, f.starts_at, f.ends_at,
+	f.status, f.home_score, f.away_score, f.game_id
+`
+
+func scanFixture(row rowScanner) (*model.Fixture, error) {
+	var f model.Fixture
+	var venueID, gameID sql.NullInt64
+	var home, away sql.NullInt32
+	err := row.Scan(
+		&f.ID, &f.SeasonID, &f.DivisionID, &f.Round, &f.HomeID, &f.AwayID, &venueID, &f.StartsAt, &f.EndsAt,
+		&f.Status, &home, &away, &gameID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, league.ErrFixtureNotFound
+		}
+		return nil, fmt.Errorf("error scanning fixture: %w", err)
+	}
+	if venueID.Valid {
+		f.VenueID = &venueID.Int64
+	}
+	if gameID.Valid {
+		f.GameID = &gameID.Int64
+	}
+	if home.Valid && away.Valid {
+		h, a := int(home.Int32), int(away.Int32)
+		f.HomeScore, f.AwayScore = &h, &a
+	}
+	return &f, nil
+}
+
+// FixturesQuery lists the fixtures of a season in date order, only those of
+// divisionID unless it is 0.
+func FixturesQuery(ctx context.Context, d *dbs.Service, seasonID, divisionID int64) ([]model.Fixture, error) {
+	queri := `SELECT ` + fixtureColumns + ` FROM league_fixtures f WHERE f.season_id = ?`
+	args := []interface{}{seasonID}
+	if divisionID != 0 {
+		queri += ` AND f.division_id = ?`
+		args = append(args, divisionID)
+	}
+	queri += ` ORDER BY f.starts_at, f.id`
+
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying fixtures: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.Fixture{}
+	for rows.Next() {
+		f, err := scanFixture(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *f)
+	}
+	return out, rows.Err()
+}
+
+// GetFixtureQuery loads a fixture of any season of leagueID.
+func GetFixtureQuery(ctx context.Context, d *dbs.Service, leagueID, fixtureID int64) (*model.Fixture, error) {
+	return scanFixture(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+fixtureColumns+` FROM league_fixtures f
+		JOIN league_seasons s ON s.id = f.season_id
+		WHERE s.league_id = ? AND f.id = ?`,
+		leagueID, fixtureID,
+	))
+}
+
+// FixtureByGameQuery finds the fixture played as gameID.
+func FixtureByGameQuery(ctx context.Context, d *dbs.Service, gameID int64) (*model.Fixture, error) {
+	return scanFixture(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+fixtureColumns+` FROM league_fixtures f WHERE f.game_id = ?`,
+		gameID,
+	))
+}
+
+// lockFixture locks a fixture and checks that its season is being played.
+func lockFixture(ctx context.Context, tx *sql.Tx, fixtureID int64) (*model.Fixture, error) {
+	f, err := scanFixture(tx.QueryRowContext(
+		ctx,
+		`SELECT `+fixtureColumns+` FROM league_fixtures f WHERE f.id = ? FOR UPDATE`,
+		fixtureID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	s, err := lockSeason(ctx, tx, f.SeasonID)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != model.SeasonActive {
+		return nil, league.ErrSeasonNotActive
+	}
+	return f, nil
+}
+
+// RecordFixtureQuery sets the score of a fixture, replacing any score it
+// already had.
+func RecordFixtureQuery(ctx context.Context, d *dbs.Service, fixtureID int64, home, away int) (*model.Fixture, error) {
+	if home < 0 || away < 0 {
+		return nil, league.ErrInvalidScore
+	}
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	f, err := lockFixture(ctx, tx, fixtureID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE league_fixtures SET home_score = ?, away_score = ?, status = ? WHERE id = ?`,
+		home, away, model.FixturePlayed, fixtureID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error recording fixture: %w", err)
+	}
+	f.HomeScore, f.AwayScore, f.Status = &home, &away, model.FixturePlayed
+	return f, tx.Commit()
+}
+
+// LinkFixtureGameQuery has a fixture played as gameID, so that the game's
+// confirmed result scores it. A game plays at most one fixture.
+func LinkFixtureGameQuery(ctx context.Context, d *dbs.Service, fixtureID, gameID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	f, err := lockFixture(ctx, tx, fixtureID)
+	if err != nil {
+		return err
+	}
+	if f.GameID != nil {
+		return league.ErrFixtureLinked
+	}
+	var taken bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM league_fixtures WHERE game_id = ?)`,
+		gameID,
+	).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("error checking linked games: %w", err)
+	}
+	if taken {
+		return league.ErrFixtureLinked
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE league_fixtures SET game_id = ? WHERE id = ?`, gameID, fixtureID)
+	if err != nil {
+		return fmt.Errorf("error linking game: %w", err)
+	}
+	return tx.Commit()
+}
+
+// MemberPlayersQuery returns the users who play for a league member: the
+// player themselves, or the members of the team.
+func MemberPlayersQuery(ctx context.Context, d *dbs.Service, memberID int64) (map[int64]bool, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT user_id FROM league_members WHERE id = ? AND user_id IS NOT NULL
+		UNION
+		SELECT tm.user_id FROM league_members m
+		JOIN team_members tm ON tm.team_id = m.team_id
+		WHERE m.id = ?`,
+		memberID, memberID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying member players: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning member player: %w", err)
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+// FixtureLeagueQuery returns the league a fixture is played in.
+func FixtureLeagueQuery(ctx context.Context, d *dbs.Service, fixtureID int64) (*model.League, error) {
+	return scanLeague(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+leagueColumns+` FROM leagues
+		WHERE id = (
+			SELECT s.league_id FROM league_fixtures f
+			JOIN league_seasons s ON s.id = f.season_id
+			WHERE f.id = ?
+		)`,
+		fixtureID,
+	))
+}
+
+// MembersQuery returns the league members with the given IDs.
+func MembersQuery(ctx context.Context, d *dbs.Service, ids ...int64) ([]model.Member, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return seasonMembers(ctx, d.DB, `m.id IN (`+placeholders(len(ids))+`)`, int64Args(ids)...)
+}
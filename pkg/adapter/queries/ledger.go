@@ -0,0 +1,269 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/ledger"
+	"github.com/dudeiebot/sportPeerGo/pkg/ledger/model"
+)
+
+// CreateExpenseQuery splits e between the game's players and records what
+// each of them owes the payer.
+func CreateExpenseQuery(ctx context.Context, d *dbs.Service, e model.Expense) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	g, r, err := lockGame(ctx, tx, e.GameID)
+	if err != nil {
+		return 0, err
+	}
+	if g.OrganizerID != e.CreatedBy {
+		return 0, game.ErrNotOrganizer
+	}
+	if g.Status == gamemodel.StatusCancelled {
+		return 0, game.ErrGameClosed
+	}
+	now := time.Now()
+	if err := ledger.Prepare(&e, r.Participants, now); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO expenses (game_id, paid_by, description, amount_cents, split, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.GameID, e.PaidBy, e.Description, e.AmountCents, e.Split, e.CreatedBy, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting expense: %w", err)
+	}
+	e.ID, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range e.Shares {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO expense_shares (expense_id, user_id, amount_cents, paid_at) VALUES (?, ?, ?, ?)`,
+			e.ID, s.UserID, s.AmountCents, s.PaidAt,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("error inserting share of user %d: %w", s.UserID, err)
+		}
+	}
+	for _, entry := range ledger.Charges(e) {
+		if err := insertEntry(ctx, tx, entry, now); err != nil {
+			return 0, err
+		}
+	}
+	return e.ID, tx.Commit()
+}
+
+func insertEntry(ctx context.Context, q querier, e model.Entry, now time.Time) error {
+	_, err := q.ExecContext(
+		ctx,
+		`INSERT INTO ledger_entries (expense_id, game_id, debtor_id, creditor_id, amount_cents, kind, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.ExpenseID, e.GameID, e.DebtorID, e.CreditorID, e.AmountCents, e.Kind, now,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting ledger entry: %w", err)
+	}
+	return nil
+}
+
+const expenseColumns = `id, game_id, paid_by, description, amount_cents, split, created_by, created_at`
+
+func scanExpense(row rowScanner) (*model.Expense, error) {
+	var e model.Expense
+	err := row.Scan(&e.ID, &e.GameID, &e.PaidBy, &e.Description, &e.AmountCents, &e.Split, &e.CreatedBy, &e.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ledger.ErrExpenseNotFound
+		}
+		return nil, fmt.Errorf("error scanning expense: %w", err)
+	}
+	return &e, nil
+}
+
+func expenseShares(ctx context.Context, q querier, expenseID int64, lock bool) ([]model.Share, error) {
+	queri := `SELECT user_id, amount_cents, paid_at FROM expense_shares WHERE expense_id = ? ORDER BY user_id`
+	if lock {
+		queri += ` FOR UPDATE`
+	}
+	rows, err := q.QueryContext(ctx, queri, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := []model.Share{}
+	for rows.Next() {
+		var s model.Share
+		var paidAt sql.NullTime
+		if err := rows.Scan(&s.UserID, &s.AmountCents, &paidAt); err != nil {
+			return nil, fmt.Errorf("error scanning share: %w", err)
+		}
+		if paidAt.Valid {
+			s.PaidAt = &paidAt.Time
+		}
+		shares = append(shares, s)
+	}
+	return shares, rows.Err()
+}
+
+func GetExpenseQuery(ctx context.Context, d *dbs.Service, gameID, expenseID int64) (*model.Expense, error) {
+	e, err := scanExpense(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+expenseColumns+` FROM expenses WHERE id = ? AND game_id = ?`,
+		expenseID, gameID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	e.Shares, err = expenseShares(ctx, d.DB, e.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ExpensesQuery lists a game's expenses, oldest first, with their shares.
+func ExpensesQuery(ctx context.Context, d *dbs.Service, gameID int64) ([]model.Expense, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT `+expenseColumns+` FROM expenses WHERE game_id = ? ORDER BY created_at, id`,
+		gameID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := []model.Expense{}
+	for rows.Next() {
+		e, err := scanExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range expenses {
+		expenses[i].Shares, err = expenseShares(ctx, d.DB, expenses[i].ID, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return expenses, nil
+}
+
+// MarkSharePaidQuery settles userID's share of an expense and records the
+// payment against the charge.
+func MarkSharePaidQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	gameID, expenseID, userID, actorID int64,
+) (*model.Expense, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	e, err := scanExpense(tx.QueryRowContext(
+		ctx,
+		`SELECT `+expenseColumns+` FROM expenses WHERE id = ? AND game_id = ? FOR UPDATE`,
+		expenseID, gameID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	e.Shares, err = expenseShares(ctx, tx, e.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ledger.CheckMarkPaid(*e, userID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE expense_shares SET paid_at = ? WHERE expense_id = ? AND user_id = ?`,
+		now, e.ID, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error marking share paid: %w", err)
+	}
+	if err := insertEntry(ctx, tx, ledger.Payment(*e, *s), now); err != nil {
+		return nil, err
+	}
+	s.PaidAt = &now
+	return e, tx.Commit()
+}
+
+func scanEntries(rows *sql.Rows) ([]model.Entry, error) {
+	defer rows.Close()
+
+	entries := []model.Entry{}
+	for rows.Next() {
+		var e model.Entry
+		err := rows.Scan(
+			&e.ID, &e.ExpenseID, &e.GameID, &e.DebtorID, &e.CreditorID, &e.AmountCents, &e.Kind, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning ledger entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+const entryColumns = `id, expense_id, game_id, debtor_id, creditor_id, amount_cents, kind, created_at`
+
+// UserEntriesQuery returns every ledger entry userID is on either side of.
+func UserEntriesQuery(ctx context.Context, d *dbs.Service, userID int64) ([]model.Entry, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT `+entryColumns+` FROM ledger_entries WHERE debtor_id = ? OR creditor_id = ? ORDER BY id`,
+		userID, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying ledger: %w", err)
+	}
+	return scanEntries(rows)
+}
+
+// GroupEntriesQuery returns the ledger entries between members of ids,
+// leaving out debts to anyone outside the group.
+func GroupEntriesQuery(ctx context.Context, d *dbs.Service, ids []int64) ([]model.Entry, error) {
+	if len(ids) == 0 {
+		return []model.Entry{}, nil
+	}
+	in := placeholders(len(ids))
+	args := append(int64Args(ids), int64Args(ids)...)
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT `+entryColumns+` FROM ledger_entries
+		WHERE debtor_id IN (`+in+`) AND creditor_id IN (`+in+`)
+		ORDER BY id`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying group ledger: %w", err)
+	}
+	return scanEntries(rows)
+}
@@ -0,0 +1,65 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/live"
+)
+
+// LogEventQuery appends an event to userID's event log, returning it with
+// its ID.
+func LogEventQuery(ctx context.Context, d *dbs.Service, userID int64, t string, data json.RawMessage) (live.Event, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`INSERT INTO user_events (user_id, type, data) VALUES (?, ?, ?)`,
+		userID, t, []byte(data),
+	)
+	if err != nil {
+		return live.Event{}, fmt.Errorf("error logging event: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return live.Event{}, err
+	}
+	return live.Event{ID: id, Type: t, Data: data}, nil
+}
+
+// EventsSinceQuery returns up to limit of userID's events after the given
+// ID, oldest first.
+func EventsSinceQuery(ctx context.Context, d *dbs.Service, userID, after int64, limit int) ([]live.Event, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT id, type, data FROM user_events WHERE user_id = ? AND id > ? ORDER BY id LIMIT ?`,
+		userID, after, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []live.Event
+	for rows.Next() {
+		var e live.Event
+		var data []byte
+		if err := rows.Scan(&e.ID, &e.Type, &data); err != nil {
+			return nil, fmt.Errorf("error scanning event: %w", err)
+		}
+		e.Data = data
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// PruneEventsQuery deletes logged events older than before. Clients away
+// for longer than that resume from the live stream only.
+func PruneEventsQuery(ctx context.Context, d *dbs.Service, before time.Time) (int64, error) {
+	res, err := d.DB.ExecContext(ctx, `DELETE FROM user_events WHERE created_at < ?`, before)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning events: %w", err)
+	}
+	return res.RowsAffected()
+}
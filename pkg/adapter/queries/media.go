@@ -0,0 +1,170 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/media"
+	"github.com/dudeiebot/sportPeerGo/pkg/media/model"
+)
+
+const imageColumns = `id, kind, subject_id, storage_key, extension, uploaded_by, created_at`
+
+func scanImage(row rowScanner) (*model.Image, error) {
+	var i model.Image
+	err := row.Scan(&i.ID, &i.Kind, &i.SubjectID, &i.Key, &i.Ext, &i.UploadedBy, &i.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, media.ErrImageNotFound
+		}
+		return nil, fmt.Errorf("error scanning image: %w", err)
+	}
+	return &i, nil
+}
+
+func lockImages(ctx context.Context, tx *sql.Tx, kind string, subjectID int64) ([]model.Image, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT `+imageColumns+` FROM images WHERE kind = ? AND subject_id = ? ORDER BY id FOR UPDATE`,
+		kind, subjectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying images: %w", err)
+	}
+	defer rows.Close()
+
+	images := []model.Image{}
+	for rows.Next() {
+		i, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, *i)
+	}
+	return images, rows.Err()
+}
+
+func insertImage(ctx context.Context, tx *sql.Tx, i model.Image) (int64, error) {
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO images (kind, subject_id, storage_key, extension, uploaded_by, created_at)
+		VALUES (?, ?, ?, ?, ?, NOW())`,
+		i.Kind, i.SubjectID, i.Key, i.Ext, i.UploadedBy,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting image: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ReplaceImageQuery makes i the only image of its subject, for avatars and
+// team photos. It returns the images it replaced so their files can be
+// deleted.
+func ReplaceImageQuery(ctx context.Context, d *dbs.Service, i model.Image) (int64, []model.Image, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	old, err := lockImages(ctx, tx, i.Kind, i.SubjectID)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM images WHERE kind = ? AND subject_id = ?`, i.Kind, i.SubjectID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error deleting images: %w", err)
+	}
+	id, err := insertImage(ctx, tx, i)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, old, tx.Commit()
+}
+
+// AddImageQuery adds i alongside its subject's other images, up to limit.
+func AddImageQuery(ctx context.Context, d *dbs.Service, i model.Image, limit int) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := lockImages(ctx, tx, i.Kind, i.SubjectID)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) >= limit {
+		return 0, media.ErrTooManyPhotos
+	}
+	id, err := insertImage(ctx, tx, i)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// DeleteImageQuery removes one image of a subject, or all of them when id
+// is 0, and returns what it removed.
+func DeleteImageQuery(ctx context.Context, d *dbs.Service, kind string, subjectID, id int64) ([]model.Image, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	images, err := lockImages(ctx, tx, kind, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	var removed []model.Image
+	for _, i := range images {
+		if id == 0 || i.ID == id {
+			removed = append(removed, i)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, media.ErrImageNotFound
+	}
+	queri := `DELETE FROM images WHERE kind = ? AND subject_id = ?`
+	args := []interface{}{kind, subjectID}
+	if id != 0 {
+		queri += ` AND id = ?`
+		args = append(args, id)
+	}
+	if _, err := tx.ExecContext(ctx, queri, args...); err != nil {
+		return nil, fmt.Errorf("error deleting image: %w", err)
+	}
+	return removed, tx.Commit()
+}
+
+// ImagesQuery returns the images of each subject of kind, oldest first.
+func ImagesQuery(ctx context.Context, d *dbs.Service, kind string, subjectIDs []int64) (map[int64][]model.Image, error) {
+	out := make(map[int64][]model.Image)
+	if len(subjectIDs) == 0 {
+		return out, nil
+	}
+	args := append([]interface{}{kind}, int64Args(subjectIDs)...)
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT `+imageColumns+` FROM images
+		WHERE kind = ? AND subject_id IN (`+placeholders(len(subjectIDs))+`)
+		ORDER BY id`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying images: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		i, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[i.SubjectID] = append(out[i.SubjectID], *i)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,453 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/moderation"
+	"github.com/dudeiebot/sportPeerGo/pkg/moderation/model"
+)
+
+// BlockUserQuery blocks blockedID for blockerID. Blocking also ends any
+// friendship or pending friend request between the two.
+func BlockUserQuery(ctx context.Context, d *dbs.Service, blockerID, blockedID int64) error {
+	if blockerID == blockedID {
+		return moderation.ErrBlockSelf
+	}
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT IGNORE INTO user_blocks (blocker_id, blocked_id) VALUES (?, ?)`,
+		blockerID, blockedID,
+	)
+	if err != nil {
+		return fmt.Errorf("error blocking user: %w", err)
+	}
+	for _, queri := range []string{
+		`DELETE FROM friendships WHERE (user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)`,
+		`DELETE FROM friend_requests
+		WHERE (requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)`,
+	} {
+		if _, err := tx.ExecContext(ctx, queri, blockerID, blockedID, blockedID, blockerID); err != nil {
+			return fmt.Errorf("error removing relationship: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func UnblockUserQuery(ctx context.Context, d *dbs.Service, blockerID, blockedID int64) error {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`DELETE FROM user_blocks WHERE blocker_id = ? AND blocked_id = ?`,
+		blockerID, blockedID,
+	)
+	if err != nil {
+		return fmt.Errorf("error unblocking user: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return moderation.ErrNotBlocked
+	}
+	return nil
+}
+
+// BlocksQuery lists the users blockerID has blocked.
+func BlocksQuery(ctx context.Context, d *dbs.Service, blockerID int64) ([]model.Block, error) {
+	queri := `
+		SELECT b.blocked_id, u.username, b.created_at
+		FROM user_blocks b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = ?
+		ORDER BY b.created_at DESC
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, blockerID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []model.Block{}
+	for rows.Next() {
+		var b model.Block
+		if err := rows.Scan(&b.UserID, &b.Username, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning block: %w", err)
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+// BlockedIDsQuery returns every user that userID has blocked or been blocked
+// by. Blocks hide users from each other both ways.
+func BlockedIDsQuery(ctx context.Context, d *dbs.Service, userID int64) (map[int64]bool, error) {
+	queri := `
+		SELECT blocked_id FROM user_blocks WHERE blocker_id = ?
+		UNION
+		SELECT blocker_id FROM user_blocks WHERE blocked_id = ?
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying blocks: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning block: %w", err)
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+func CreateReportQuery(ctx context.Context, d *dbs.Service, r model.Report) (int64, error) {
+	if r.TargetType == model.TargetUser && r.TargetID == r.ReporterID {
+		return 0, moderation.ErrReportSelf
+	}
+	table := "users"
+	if r.TargetType == model.TargetGame {
+		table = "games"
+	}
+	var exists bool
+	err := d.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = ?)`, r.TargetID).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("error checking report target: %w", err)
+	}
+	if !exists {
+		return 0, moderation.ErrTargetNotFound
+	}
+
+	err = d.DB.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (
+			SELECT 1 FROM reports
+			WHERE reporter_id = ? AND target_type = ? AND target_id = ? AND status IN (?, ?)
+		)`,
+		r.ReporterID, r.TargetType, r.TargetID, model.ReportOpen, model.ReportReviewing,
+	).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("error checking reports: %w", err)
+	}
+	if exists {
+		return 0, moderation.ErrDuplicateReport
+	}
+
+	res, err := d.DB.ExecContext(
+		ctx,
+		`INSERT INTO reports (reporter_id, target_type, target_id, category, details, status)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		r.ReporterID, r.TargetType, r.TargetID, r.Category, r.Details, model.ReportOpen,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting report: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+const reportColumns = `
+	r.id, r.reporter_id, r.target_type, r.target_id, r.category, r.details, r.status,
+	r.assigned_to, COALESCE(r.resolution, ''), r.created_at,
+	(SELECT COUNT(*) FROM reports o WHERE o.target_type = r.target_type AND o.target_id = r.target_id)
+`
+
+func scanReport(row rowScanner) (*model.Report, error) {
+	var r model.Report
+	var assigned sql.NullInt64
+	err := row.Scan(
+		&r.ID, &r.ReporterID, &r.TargetType, &r.TargetID, &r.Category, &r.Details, &r.Status,
+		&assigned, &r.Resolution, &r.CreatedAt, &r.TargetReports,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, moderation.ErrReportNotFound
+		}
+		return nil, fmt.Errorf("error scanning report: %w", err)
+	}
+	if assigned.Valid {
+		r.AssignedTo = &assigned.Int64
+	}
+	return &r, nil
+}
+
+func queryReports(ctx context.Context, d *dbs.Service, queri string, args ...interface{}) ([]model.Report, error) {
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []model.Report{}
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *r)
+	}
+	return reports, rows.Err()
+}
+
+func UserReportsQuery(ctx context.Context, d *dbs.Service, reporterID int64) ([]model.Report, error) {
+	queri := `SELECT ` + reportColumns + ` FROM reports r WHERE r.reporter_id = ? ORDER BY r.created_at DESC`
+	return queryReports(ctx, d, queri, reporterID)
+}
+
+// ReportQueueQuery returns reports with the given status, oldest first so
+// nothing is left waiting at the back of the queue.
+func ReportQueueQuery(ctx context.Context, d *dbs.Service, status string, limit, offset int) ([]model.Report, error) {
+	queri := `
+		SELECT ` + reportColumns + `
+		FROM reports r
+		WHERE r.status = ?
+		ORDER BY r.created_at, r.id
+		LIMIT ? OFFSET ?
+	`
+	return queryReports(ctx, d, queri, status, limit, offset)
+}
+
+func lockReport(ctx context.Context, tx *sql.Tx, id int64) (*model.Report, error) {
+	queri := `SELECT ` + reportColumns + ` FROM reports r WHERE r.id = ? FOR UPDATE`
+	return scanReport(tx.QueryRowContext(ctx, queri, id))
+}
+
+func saveReport(ctx context.Context, tx *sql.Tx, r *model.Report) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`UPDATE reports SET status = ?, assigned_to = ?, resolution = ? WHERE id = ?`,
+		r.Status, r.AssignedTo, r.Resolution, r.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating report: %w", err)
+	}
+	return nil
+}
+
+func ClaimReportQuery(ctx context.Context, d *dbs.Service, id, moderatorID int64) (*model.Report, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	r, err := lockReport(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := moderation.Claim(r, moderatorID); err != nil {
+		return nil, err
+	}
+	if err := saveReport(ctx, tx, r); err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, model.AuditEntry{
+		ModeratorID: moderatorID, Decision: "claim_report", ReportID: &r.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, tx.Commit()
+}
+
+// ResolveReportQuery closes the report and, when action is not nil, applies
+// the sanction in the same transaction so the two are never out of step.
+func ResolveReportQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	id, moderatorID int64,
+	status, resolution string,
+	action *model.Action,
+) (*model.Report, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	r, err := lockReport(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := moderation.Resolve(r, moderatorID, status, resolution); err != nil {
+		return nil, err
+	}
+	if err := saveReport(ctx, tx, r); err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, model.AuditEntry{
+		ModeratorID: moderatorID, Decision: status + "_report", ReportID: &r.ID, Note: resolution,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if action != nil {
+		if r.TargetType != model.TargetUser {
+			return nil, moderation.ErrActionTarget
+		}
+		action.UserID = r.TargetID
+		action.ModeratorID = moderatorID
+		action.ReportID = &r.ID
+		if err := applyAction(ctx, tx, action); err != nil {
+			return nil, err
+		}
+	}
+	return r, tx.Commit()
+}
+
+// ModerationActionQuery records a sanction against a user, or lifts one.
+func ModerationActionQuery(ctx context.Context, d *dbs.Service, a *model.Action) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := applyAction(ctx, tx, a); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// applyAction locks the user row so that concurrent moderators see each
+// other's actions when working out the user's standing.
+func applyAction(ctx context.Context, tx *sql.Tx, a *model.Action) error {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = ? FOR UPDATE`, a.UserID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("User Not Found")
+		}
+		return fmt.Errorf("error locking user: %w", err)
+	}
+	actions, err := userActions(ctx, tx, a.UserID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := moderation.Prepare(a, moderation.StandingAt(actions, now), now); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO moderation_actions (user_id, moderator_id, kind, reason, days, report_id, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.UserID, a.ModeratorID, a.Kind, a.Reason, a.Days, a.ReportID, a.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting moderation action: %w", err)
+	}
+	if a.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, model.AuditEntry{
+		ModeratorID: a.ModeratorID, Decision: a.Kind, ReportID: a.ReportID, UserID: &a.UserID, Note: a.Reason,
+	})
+}
+
+func UserActionsQuery(ctx context.Context, d *dbs.Service, userID int64) ([]model.Action, error) {
+	return userActions(ctx, d.DB, userID)
+}
+
+func userActions(ctx context.Context, q querier, userID int64) ([]model.Action, error) {
+	queri := `
+		SELECT id, user_id, moderator_id, kind, reason, days, report_id, expires_at, created_at
+		FROM moderation_actions
+		WHERE user_id = ?
+		ORDER BY created_at, id
+	`
+	rows, err := q.QueryContext(ctx, queri, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying moderation actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []model.Action{}
+	for rows.Next() {
+		var a model.Action
+		var reportID sql.NullInt64
+		var expires sql.NullTime
+		err := rows.Scan(
+			&a.ID, &a.UserID, &a.ModeratorID, &a.Kind, &a.Reason, &a.Days, &reportID, &expires, &a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning moderation action: %w", err)
+		}
+		if reportID.Valid {
+			a.ReportID = &reportID.Int64
+		}
+		if expires.Valid {
+			a.ExpiresAt = &expires.Time
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+// UserStandingQuery returns the sanctions currently in force for userID.
+func UserStandingQuery(ctx context.Context, d *dbs.Service, userID int64) (moderation.Standing, error) {
+	actions, err := userActions(ctx, d.DB, userID)
+	if err != nil {
+		return moderation.Standing{}, err
+	}
+	return moderation.StandingAt(actions, time.Now()), nil
+}
+
+func recordAudit(ctx context.Context, q querier, e model.AuditEntry) error {
+	_, err := q.ExecContext(
+		ctx,
+		`INSERT INTO moderation_audit (moderator_id, decision, report_id, user_id, note) VALUES (?, ?, ?, ?, ?)`,
+		e.ModeratorID, e.Decision, e.ReportID, e.UserID, e.Note,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording moderation audit: %w", err)
+	}
+	return nil
+}
+
+// AuditLogQuery pages through moderator decisions, newest first, optionally
+// only those about one user.
+func AuditLogQuery(ctx context.Context, d *dbs.Service, userID int64, limit, offset int) ([]model.AuditEntry, error) {
+	queri := `
+		SELECT id, moderator_id, decision, report_id, user_id, note, created_at
+		FROM moderation_audit
+		WHERE (? = 0 OR user_id = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, userID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying moderation audit: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []model.AuditEntry{}
+	for rows.Next() {
+		var e model.AuditEntry
+		var reportID, user sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.ModeratorID, &e.Decision, &reportID, &user, &e.Note, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning moderation audit: %w", err)
+		}
+		if reportID.Valid {
+			e.ReportID = &reportID.Int64
+		}
+		if user.Valid {
+			e.UserID = &user.Int64
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
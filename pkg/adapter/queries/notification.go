@@ -0,0 +1,334 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/notify"
+	"github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+)
+
+// NotificationSettingsQuery returns userID's digest setting and a preference
+// for every notification type, with defaults for any never saved.
+func NotificationSettingsQuery(ctx context.Context, d *dbs.Service, userID int64) (model.Settings, error) {
+	s := model.Settings{UserID: userID, EmailDigest: model.DigestInstant}
+	var last sql.NullTime
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT email_digest, last_digest_at FROM notification_settings WHERE user_id = ?`,
+		userID,
+	).Scan(&s.EmailDigest, &last)
+	if err != nil && err != sql.ErrNoRows {
+		return s, fmt.Errorf("error querying notification settings: %w", err)
+	}
+	if last.Valid {
+		s.LastDigestAt = &last.Time
+	}
+
+	prefs, err := preferencesFor(ctx, d, []int64{userID}, "")
+	if err != nil {
+		return s, err
+	}
+	s.Preferences = notify.Merge(prefs[userID])
+	return s, nil
+}
+
+func preferencesFor(ctx context.Context, d *dbs.Service, ids []int64, t string) (map[int64][]model.Preference, error) {
+	queri := `
+		SELECT user_id, type, in_app, email, push
+		FROM notification_preferences
+		WHERE user_id IN (` + placeholders(len(ids)) + `) AND (? = '' OR type = ?)
+	`
+	args := append(int64Args(ids), t, t)
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64][]model.Preference)
+	for rows.Next() {
+		var userID int64
+		var p model.Preference
+		if err := rows.Scan(&userID, &p.Type, &p.InApp, &p.Email, &p.Push); err != nil {
+			return nil, fmt.Errorf("error scanning notification preference: %w", err)
+		}
+		out[userID] = append(out[userID], p)
+	}
+	return out, rows.Err()
+}
+
+// DeliverySettingsQuery returns, for each of ids, their preference for
+// notifications of type t and their email digest setting.
+func DeliverySettingsQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	ids []int64,
+	t string,
+) (map[int64]model.Preference, map[int64]string, error) {
+	prefs := make(map[int64]model.Preference, len(ids))
+	digests := make(map[int64]string, len(ids))
+	if len(ids) == 0 {
+		return prefs, digests, nil
+	}
+	for _, id := range ids {
+		prefs[id] = notify.Default(t)
+		digests[id] = model.DigestInstant
+	}
+
+	saved, err := preferencesFor(ctx, d, ids, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	for id, p := range saved {
+		prefs[id] = p[0]
+	}
+
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT user_id, email_digest FROM notification_settings WHERE user_id IN (`+placeholders(len(ids))+`)`,
+		int64Args(ids)...,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error querying notification settings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var digest string
+		if err := rows.Scan(&id, &digest); err != nil {
+			return nil, nil, fmt.Errorf("error scanning notification settings: %w", err)
+		}
+		digests[id] = digest
+	}
+	return prefs, digests, rows.Err()
+}
+
+func UpdateNotificationSettingsQuery(ctx context.Context, d *dbs.Service, s model.Settings) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO notification_settings (user_id, email_digest) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE email_digest = VALUES(email_digest)`,
+		s.UserID, s.EmailDigest,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating notification settings: %w", err)
+	}
+	for _, p := range s.Preferences {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO notification_preferences (user_id, type, in_app, email, push) VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE in_app = VALUES(in_app), email = VALUES(email), push = VALUES(push)`,
+			s.UserID, p.Type, p.InApp, p.Email, p.Push,
+		)
+		if err != nil {
+			return fmt.Errorf("error updating notification preference: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// CreateNotificationQuery stores a notification. inApp makes it show in the
+// user's notification center and emailPending queues it for their next
+// digest.
+func CreateNotificationQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	n model.Notification,
+	inApp, emailPending bool,
+) (int64, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`INSERT INTO notifications
+			(user_id, type, title, body, actor_id, game_id, team_id, in_app, email_pending)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		n.UserID, n.Type, n.Title, n.Body, n.ActorID, n.GameID, n.TeamID, inApp, emailPending,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting notification: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+const notificationColumns = `id, user_id, type, title, body, actor_id, game_id, team_id, read_at, created_at`
+
+func queryNotifications(ctx context.Context, d *dbs.Service, queri string, args ...interface{}) ([]model.Notification, error) {
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []model.Notification{}
+	for rows.Next() {
+		var n model.Notification
+		var actorID, gameID, teamID sql.NullInt64
+		var readAt sql.NullTime
+		err := rows.Scan(
+			&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &actorID, &gameID, &teamID, &readAt, &n.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning notification: %w", err)
+		}
+		if actorID.Valid {
+			n.ActorID = &actorID.Int64
+		}
+		if gameID.Valid {
+			n.GameID = &gameID.Int64
+		}
+		if teamID.Valid {
+			n.TeamID = &teamID.Int64
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func NotificationsQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	userID int64,
+	unreadOnly bool,
+	limit, offset int,
+) ([]model.Notification, error) {
+	queri := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = ? AND in_app AND (NOT ? OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	return queryNotifications(ctx, d, queri, userID, unreadOnly, limit, offset)
+}
+
+func UnreadCountQuery(ctx context.Context, d *dbs.Service, userID int64) (int, error) {
+	var count int
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = ? AND in_app AND read_at IS NULL`,
+		userID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting notifications: %w", err)
+	}
+	return count, nil
+}
+
+func MarkNotificationReadQuery(ctx context.Context, d *dbs.Service, userID, id int64) error {
+	var exists bool
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM notifications WHERE id = ? AND user_id = ? AND in_app)`,
+		id, userID,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("error checking notification: %w", err)
+	}
+	if !exists {
+		return notify.ErrNotificationNotFound
+	}
+	_, err = d.DB.ExecContext(
+		ctx,
+		`UPDATE notifications SET read_at = NOW() WHERE id = ? AND read_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("error marking notification read: %w", err)
+	}
+	return nil
+}
+
+// MarkAllReadQuery marks every unread notification of userID read and
+// returns how many there were.
+func MarkAllReadQuery(ctx context.Context, d *dbs.Service, userID int64) (int64, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`UPDATE notifications SET read_at = NOW() WHERE user_id = ? AND in_app AND read_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error marking notifications read: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// DigestUsersQuery returns the settings of every user with notifications
+// waiting for an email digest.
+func DigestUsersQuery(ctx context.Context, d *dbs.Service) ([]model.Settings, error) {
+	queri := `
+		SELECT p.user_id, COALESCE(s.email_digest, ?), s.last_digest_at
+		FROM (SELECT DISTINCT user_id FROM notifications WHERE email_pending) p
+		LEFT JOIN notification_settings s ON s.user_id = p.user_id
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, model.DigestInstant)
+	if err != nil {
+		return nil, fmt.Errorf("error querying digest users: %w", err)
+	}
+	defer rows.Close()
+
+	var out []model.Settings
+	for rows.Next() {
+		var s model.Settings
+		var last sql.NullTime
+		if err := rows.Scan(&s.UserID, &s.EmailDigest, &last); err != nil {
+			return nil, fmt.Errorf("error scanning digest user: %w", err)
+		}
+		if last.Valid {
+			s.LastDigestAt = &last.Time
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func PendingDigestQuery(ctx context.Context, d *dbs.Service, userID int64) ([]model.Notification, error) {
+	queri := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = ? AND email_pending
+		ORDER BY created_at, id
+	`
+	return queryNotifications(ctx, d, queri, userID)
+}
+
+// MarkDigestSentQuery takes the given notifications out of the digest queue
+// and records when userID's digest went out.
+func MarkDigestSentQuery(ctx context.Context, d *dbs.Service, userID int64, ids []int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(ids) > 0 {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE notifications SET email_pending = FALSE WHERE id IN (`+placeholders(len(ids))+`)`,
+			int64Args(ids)...,
+		)
+		if err != nil {
+			return fmt.Errorf("error clearing digest queue: %w", err)
+		}
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO notification_settings (user_id, email_digest, last_digest_at) VALUES (?, ?, NOW())
+		ON DUPLICATE KEY UPDATE last_digest_at = NOW()`,
+		userID, model.DigestInstant,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording digest: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,230 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/payment"
+	"github.com/dudeiebot/sportPeerGo/pkg/payment/model"
+)
+
+const paymentColumns = `
+	p.id, p.game_id, p.user_id, p.amount_cents, p.currency, p.provider, p.intent_id, p.status,
+	p.created_at, p.updated_at
+`
+
+func scanPayment(row rowScanner) (*model.Payment, error) {
+	var p model.Payment
+	var intentID sql.NullString
+	err := row.Scan(
+		&p.ID, &p.GameID, &p.UserID, &p.AmountCents, &p.Currency, &p.Provider, &intentID, &p.Status,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, payment.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("error scanning payment: %w", err)
+	}
+	p.IntentID = intentID.String
+	return &p, nil
+}
+
+func queryPayments(ctx context.Context, d *dbs.Service, queri string, args ...interface{}) ([]model.Payment, error) {
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []model.Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, *p)
+	}
+	return payments, rows.Err()
+}
+
+// holdFees stores a pending payment for every player in joined who owes
+// the game's fee. It runs in the transaction that seats them, so no seat is
+// taken without a payment to charge or to reconcile, and the payment ID
+// serves as the provider's idempotency key.
+func holdFees(ctx context.Context, tx *sql.Tx, g *gamemodel.Game, joined []int64, provider string) error {
+	if g.FeeCents == 0 {
+		return nil
+	}
+	for _, id := range joined {
+		if id == g.OrganizerID {
+			continue
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO payments (game_id, user_id, amount_cents, currency, provider, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW())`,
+			g.ID, id, g.FeeCents, model.DefaultCurrency, provider, model.StatusPending,
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting payment: %w", err)
+		}
+	}
+	return nil
+}
+
+// UnchargedPaymentQuery returns the pending payment held for userID's seat
+// that the provider has not been asked to charge yet.
+func UnchargedPaymentQuery(ctx context.Context, d *dbs.Service, gameID, userID int64) (*model.Payment, error) {
+	return scanPayment(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+paymentColumns+` FROM payments p
+		WHERE p.game_id = ? AND p.user_id = ? AND p.status = ? AND p.intent_id IS NULL
+		ORDER BY p.id DESC LIMIT 1`,
+		gameID, userID, model.StatusPending,
+	))
+}
+
+// StalePaymentsQuery returns pending payments held before the given time
+// that were never charged, such as when the server stopped between seating
+// a player and asking the provider.
+func StalePaymentsQuery(ctx context.Context, d *dbs.Service, before time.Time, limit int) ([]model.Payment, error) {
+	return queryPayments(
+		ctx, d,
+		`SELECT `+paymentColumns+` FROM payments p
+		WHERE p.status = ? AND p.intent_id IS NULL AND p.created_at <= ?
+		ORDER BY p.id LIMIT ?`,
+		model.StatusPending, before, limit,
+	)
+}
+
+func SetPaymentIntentQuery(ctx context.Context, d *dbs.Service, paymentID int64, intentID string) error {
+	_, err := d.DB.ExecContext(
+		ctx,
+		`UPDATE payments SET intent_id = ?, updated_at = NOW() WHERE id = ?`,
+		intentID, paymentID,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving payment intent: %w", err)
+	}
+	return nil
+}
+
+// UpdatePaymentStatusQuery moves a payment from one status to another. It
+// reports false when the payment had already left from, for instance when
+// a webhook settled it first.
+func UpdatePaymentStatusQuery(ctx context.Context, d *dbs.Service, paymentID int64, from, to string) (bool, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`UPDATE payments SET status = ?, updated_at = NOW() WHERE id = ? AND status = ?`,
+		to, paymentID, from,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error updating payment: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ApplyPaymentEventQuery records a verified webhook and applies it to the
+// payment it is about. Providers deliver webhooks at least once, so an event
+// that was already recorded is skipped; it returns a nil payment when the
+// event changed nothing. An event for a payment that is not stored yet, such
+// as one arriving before its intent was saved, is not recorded and fails
+// with payment.ErrPaymentNotFound so the provider delivers it again.
+func ApplyPaymentEventQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	provider string,
+	e payment.Event,
+) (*model.Payment, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT IGNORE INTO payment_events (provider, event_id, type, intent_id, received_at)
+		VALUES (?, ?, ?, ?, NOW())`,
+		provider, e.ID, e.Type, e.IntentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error recording payment event: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return nil, err
+	}
+
+	p, err := scanPayment(tx.QueryRowContext(
+		ctx,
+		`SELECT `+paymentColumns+` FROM payments p WHERE p.provider = ? AND p.intent_id = ? FOR UPDATE`,
+		provider, e.IntentID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	status, changed := payment.Transition(p.Status, e.Type)
+	if !changed {
+		return nil, tx.Commit()
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE payments SET status = ?, updated_at = NOW() WHERE id = ?`, status, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating payment: %w", err)
+	}
+	p.Status = status
+	return p, tx.Commit()
+}
+
+func UserPaymentsQuery(ctx context.Context, d *dbs.Service, userID int64, limit, offset int) ([]model.Payment, error) {
+	return queryPayments(
+		ctx, d,
+		`SELECT `+paymentColumns+` FROM payments p WHERE p.user_id = ?
+		ORDER BY p.created_at DESC, p.id DESC LIMIT ? OFFSET ?`,
+		userID, limit, offset,
+	)
+}
+
+func GamePaymentsQuery(ctx context.Context, d *dbs.Service, gameID int64) ([]model.Payment, error) {
+	return queryPayments(
+		ctx, d,
+		`SELECT `+paymentColumns+` FROM payments p WHERE p.game_id = ? ORDER BY p.created_at, p.id`,
+		gameID,
+	)
+}
+
+// RefundablePaymentsQuery returns captured payments for cancelled games,
+// for one game when gameID is set or the oldest ones otherwise.
+func RefundablePaymentsQuery(ctx context.Context, d *dbs.Service, gameID *int64, limit int) ([]model.Payment, error) {
+	queri := `
+		SELECT ` + paymentColumns + `
+		FROM payments p
+		JOIN games g ON g.id = p.game_id
+		WHERE p.status = ? AND g.status = ?
+	`
+	args := []interface{}{model.StatusSucceeded, gamemodel.StatusCancelled}
+	if gameID != nil {
+		queri += ` AND g.id = ?`
+		args = append(args, *gameID)
+	}
+	queri += ` ORDER BY p.id LIMIT ?`
+	args = append(args, limit)
+	return queryPayments(ctx, d, queri, args...)
+}
+
+// DropUnpaidQuery takes userID out of a game whose fee they failed to pay,
+// freeing their seat for the waitlist.
+func DropUnpaidQuery(ctx context.Context, d *dbs.Service, gameID, userID int64) (game.Change, error) {
+	return updateRoster(ctx, d, gameID, func(g *gamemodel.Game, r *game.Roster, now time.Time) (game.Change, error) {
+		if !containsID(r.Participants, userID) || g.OrganizerID == userID {
+			return game.Change{}, nil
+		}
+		return r.Leave(userID, now, game.ConfirmWindow)
+	})
+}
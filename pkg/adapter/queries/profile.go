@@ -0,0 +1,239 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/matchmaking"
+	"github.com/dudeiebot/sportPeerGo/pkg/rating"
+	"github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+func GetProfileQuery(ctx context.Context, d *dbs.Service, userID int) (*model.Profile, error) {
+	queri := `SELECT id, username, COALESCE(bio, ''), COALESCE(city, ''), latitude, longitude FROM users WHERE id = ?`
+
+	var p model.Profile
+	var lat, lng sql.NullFloat64
+	err := d.DB.QueryRowContext(ctx, queri, userID).
+		Scan(&p.UserID, &p.Username, &p.Bio, &p.City, &lat, &lng)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("User Not Found")
+		}
+		return nil, fmt.Errorf("error querying profile: %w", err)
+	}
+	if lat.Valid && lng.Valid {
+		p.Location = &geo.Point{Lat: lat.Float64, Lng: lng.Float64}
+	}
+
+	sports, err := sportsForUsers(ctx, d, []int{userID})
+	if err != nil {
+		return nil, err
+	}
+	availability, err := availabilityForUsers(ctx, d, []int{userID})
+	if err != nil {
+		return nil, err
+	}
+	p.Sports = sports[userID]
+	p.Availability = availability[userID]
+
+	return &p, nil
+}
+
+func UpdateProfileQuery(ctx context.Context, d *dbs.Service, p model.Profile) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lat, lng sql.NullFloat64
+	if p.Location != nil {
+		lat = sql.NullFloat64{Float64: p.Location.Lat, Valid: true}
+		lng = sql.NullFloat64{Float64: p.Location.Lng, Valid: true}
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE users SET bio = ?, city = NULLIF(?, ''), latitude = ?, longitude = ? WHERE id = ?`,
+		p.Bio, p.City, lat, lng, p.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating profile: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_sports WHERE user_id = ?`, p.UserID); err != nil {
+		return fmt.Errorf("error clearing sports: %w", err)
+	}
+	for _, s := range p.Sports {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO user_sports (user_id, sport, skill) VALUES (?, ?, ?)`,
+			p.UserID, s.Sport, s.Skill,
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting sport: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_availability WHERE user_id = ?`, p.UserID); err != nil {
+		return fmt.Errorf("error clearing availability: %w", err)
+	}
+	for _, a := range p.Availability {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO user_availability (user_id, weekday, start_minute, end_minute) VALUES (?, ?, ?, ?)`,
+			p.UserID, a.Weekday, a.StartMinute, a.EndMinute,
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting availability: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// candidatePoolCap caps the candidates loaded for a user without a location,
+// whose pool has no bounding box to narrow it.
+const candidatePoolCap = 500
+
+// MatchCandidatesQuery loads every verified user who could be paired with
+// userID for sport (any sport when empty). When near is set the pool is
+// pre-filtered to a bounding box of radiusKm around it; users without a
+// location are kept so the engine can still rank them. Without near the
+// pool is capped at candidatePoolCap.
+func MatchCandidatesQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	userID int,
+	sport string,
+	near *geo.Point,
+	radiusKm float64,
+) ([]matchmaking.Candidate, error) {
+	queri := `
+		SELECT u.id, u.username, latitude, longitude
+		FROM users u
+		WHERE u.id <> ?
+		  AND u.is_verified = TRUE
+		  AND EXISTS (
+			SELECT 1 FROM user_sports us
+			WHERE us.user_id = u.id AND (? = '' OR us.sport = ?)
+		  )
+	`
+	args := []interface{}{userID, sport, sport}
+	if near != nil {
+		min, max := geo.BoundingBox(*near, radiusKm)
+		queri += ` AND (latitude IS NULL OR (latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?))`
+		args = append(args, min.Lat, max.Lat, min.Lng, max.Lng)
+	} else {
+		queri += ` ORDER BY u.id LIMIT ?`
+		args = append(args, candidatePoolCap)
+	}
+
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []matchmaking.Candidate
+	var ids []int
+	for rows.Next() {
+		var c matchmaking.Candidate
+		var lat, lng sql.NullFloat64
+		if err := rows.Scan(&c.Profile.UserID, &c.Profile.Username, &lat, &lng); err != nil {
+			return nil, fmt.Errorf("error scanning candidate: %w", err)
+		}
+		if lat.Valid && lng.Valid {
+			c.Profile.Location = &geo.Point{Lat: lat.Float64, Lng: lng.Float64}
+		}
+		candidates = append(candidates, c)
+		ids = append(ids, c.Profile.UserID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	sports, err := sportsForUsers(ctx, d, ids)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := availabilityForUsers(ctx, d, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		id := candidates[i].Profile.UserID
+		candidates[i].Profile.Sports = sports[id]
+		candidates[i].Profile.Availability = availability[id]
+	}
+	return candidates, nil
+}
+
+func sportsForUsers(ctx context.Context, d *dbs.Service, ids []int) (map[int][]model.SportSkill, error) {
+	queri := `
+		SELECT us.user_id, us.sport, us.skill, pr.rating, pr.rd
+		FROM user_sports us
+		LEFT JOIN player_ratings pr ON pr.user_id = us.user_id AND pr.sport = us.sport
+		WHERE us.user_id IN (` + placeholders(len(ids)) + `)
+	`
+
+	rows, err := d.DB.QueryContext(ctx, queri, intArgs(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sports: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int][]model.SportSkill)
+	for rows.Next() {
+		var id int
+		var s model.SportSkill
+		var r, rd sql.NullFloat64
+		if err := rows.Scan(&id, &s.Sport, &s.Skill, &r, &rd); err != nil {
+			return nil, fmt.Errorf("error scanning sport: %w", err)
+		}
+		if r.Valid {
+			s.Rating = int(math.Round(r.Float64))
+			s.Provisional = rating.Rating{RD: rd.Float64}.Provisional()
+		}
+		out[id] = append(out[id], s)
+	}
+	return out, rows.Err()
+}
+
+func availabilityForUsers(
+	ctx context.Context,
+	d *dbs.Service,
+	ids []int,
+) (map[int][]model.Availability, error) {
+	queri := `
+		SELECT user_id, weekday, start_minute, end_minute
+		FROM user_availability
+		WHERE user_id IN (` + placeholders(len(ids)) + `)
+	`
+
+	rows, err := d.DB.QueryContext(ctx, queri, intArgs(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying availability: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int][]model.Availability)
+	for rows.Next() {
+		var id int
+		var a model.Availability
+		if err := rows.Scan(&id, &a.Weekday, &a.StartMinute, &a.EndMinute); err != nil {
+			return nil, fmt.Errorf("error scanning availability: %w", err)
+		}
+		out[id] = append(out[id], a)
+	}
+	return out, rows.Err()
+}
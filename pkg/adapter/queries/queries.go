@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
@@ -64,9 +65,9 @@ func GetOtpQuery(
 		WHERE email = ?
 	`
 	var forgetPass model.ForgetPass
-	var expirationStr string
 
-	err := d.DB.QueryRowContext(ctx, queri, email).Scan(&forgetPass.Otp, &expirationStr)
+	err := d.DB.QueryRowContext(ctx, queri, email).
+		Scan(&forgetPass.Otp, &forgetPass.ExpirationTime)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("no OTP found for this email")
@@ -74,12 +75,6 @@ func GetOtpQuery(
 		return nil, fmt.Errorf("error querying database: %w", err)
 	}
 
-	expiration, err := time.Parse("2006-01-02 15:04:05", expirationStr)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing expiration time: %w", err)
-	}
-	forgetPass.ExpirationTime = expiration
-
 	return &forgetPass, nil
 }
 
@@ -162,3 +157,43 @@ func StoreOtpQuery(ctx context.Context, d *dbs.Service, f model.ForgetPass) erro
 
 	return nil
 }
+
+// querier is satisfied by both *sql.DB and *sql.Tx so helpers can run inside
+// or outside a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func placeholders(n int) string {
+	if n <= 0 {
+		return "NULL"
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func intArgs(ids []int) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+func int64Args(ids []int64) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+func IsAdminQuery(ctx context.Context, d *dbs.Service, userID int64) (bool, error) {
+	var isAdmin bool
+	err := d.DB.QueryRowContext(ctx, `SELECT is_admin FROM users WHERE id = ?`, userID).Scan(&isAdmin)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("error querying admin flag: %w", err)
+	}
+	return isAdmin, nil
+}
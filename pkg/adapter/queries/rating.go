@@ -0,0 +1,211 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/rating"
+	"github.com/dudeiebot/sportPeerGo/pkg/rating/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/result"
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+)
+
+// RateResultQuery applies a final result to the ratings of everyone who
+// played in it and returns whose ratings changed. Results are only ever
+// rated once, so calling it again, or on a result that is not final yet,
+// does nothing.
+func RateResultQuery(ctx context.Context, d *dbs.Service, resultID int64) ([]int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var r resultmodel.Result
+	var sport string
+	var ratedAt sql.NullTime
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT r.id, r.game_id, r.winner, r.status, r.rated_at, g.sport
+		FROM match_results r
+		JOIN games g ON g.id = r.game_id
+		WHERE r.id = ?
+		FOR UPDATE`,
+		resultID,
+	).Scan(&r.ID, &r.GameID, &r.Winner, &r.Status, &ratedAt, &sport)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, result.ErrResultNotFound
+		}
+		return nil, fmt.Errorf("error locking result: %w", err)
+	}
+	if ratedAt.Valid || !result.Final(r) {
+		return nil, nil
+	}
+	if err := loadSides(ctx, tx, &r); err != nil {
+		return nil, err
+	}
+
+	players := append(append([]int64{}, r.SideA...), r.SideB...)
+	before, err := lockRatings(ctx, tx, sport, players)
+	if err != nil {
+		return nil, err
+	}
+	ratingsOf := func(ids []int64) []rating.Rating {
+		out := make([]rating.Rating, len(ids))
+		for i, id := range ids {
+			out[i] = before[id]
+		}
+		return out
+	}
+	afterA, afterB := rating.Match(ratingsOf(r.SideA), ratingsOf(r.SideB), rating.ScoreA(r.Winner))
+	after := append(afterA, afterB...)
+
+	for i, userID := range players {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO player_ratings (user_id, sport, rating, rd, volatility, games) VALUES (?, ?, ?, ?, ?, 1)
+			ON DUPLICATE KEY UPDATE
+				rating = VALUES(rating), rd = VALUES(rd), volatility = VALUES(volatility), games = games + 1`,
+			userID, sport, after[i].Rating, after[i].RD, after[i].Volatility,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error saving rating: %w", err)
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO rating_history (user_id, sport, result_id, game_id, rating_before, rating, rd)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			userID, sport, r.ID, r.GameID, before[userID].Rating, after[i].Rating, after[i].RD,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error recording rating history: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE match_results SET rated_at = NOW() WHERE id = ?`, r.ID); err != nil {
+		return nil, fmt.Errorf("error marking result rated: %w", err)
+	}
+	return players, tx.Commit()
+}
+
+// lockRatings reads the current ratings of ids in sport with FOR UPDATE,
+// using the default rating for anyone not rated yet.
+func lockRatings(ctx context.Context, tx *sql.Tx, sport string, ids []int64) (map[int64]rating.Rating, error) {
+	out := make(map[int64]rating.Rating, len(ids))
+	for _, id := range ids {
+		out[id] = rating.Default()
+	}
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT user_id, rating, rd, volatility FROM player_ratings
+		WHERE sport = ? AND user_id IN (`+placeholders(len(ids))+`)
+		FOR UPDATE`,
+		append([]interface{}{sport}, int64Args(ids)...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error locking ratings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var r rating.Rating
+		if err := rows.Scan(&id, &r.Rating, &r.RD, &r.Volatility); err != nil {
+			return nil, fmt.Errorf("error scanning rating: %w", err)
+		}
+		out[id] = r
+	}
+	return out, rows.Err()
+}
+
+// UnratedResultsQuery returns the IDs of final results whose ratings have
+// not been applied, oldest first so ratings change in the order games were
+// settled.
+func UnratedResultsQuery(ctx context.Context, d *dbs.Service, limit int) ([]int64, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT id FROM match_results
+		WHERE status IN (?, ?) AND rated_at IS NULL
+		ORDER BY updated_at, id
+		LIMIT ?`,
+		resultmodel.StatusConfirmed, resultmodel.StatusResolved, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying unrated results: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning result: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func UserRatingsQuery(ctx context.Context, d *dbs.Service, userID int64) ([]model.PlayerRating, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT user_id, sport, rating, rd, volatility, games, updated_at
+		FROM player_ratings
+		WHERE user_id = ?
+		ORDER BY sport`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := []model.PlayerRating{}
+	for rows.Next() {
+		var r model.PlayerRating
+		err := rows.Scan(&r.UserID, &r.Sport, &r.Rating, &r.RD, &r.Volatility, &r.Games, &r.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning rating: %w", err)
+		}
+		r.Provisional = rating.Rating{RD: r.RD}.Provisional()
+		ratings = append(ratings, r)
+	}
+	return ratings, rows.Err()
+}
+
+// RatingHistoryQuery pages through the rating changes of userID, newest
+// first, in one sport or all of them when sport is empty.
+func RatingHistoryQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	userID int64,
+	sport string,
+	limit, offset int,
+) ([]model.HistoryEntry, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT result_id, game_id, sport, rating_before, rating, rd, created_at
+		FROM rating_history
+		WHERE user_id = ? AND (? = '' OR sport = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`,
+		userID, sport, sport, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying rating history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []model.HistoryEntry{}
+	for rows.Next() {
+		var h model.HistoryEntry
+		err := rows.Scan(&h.ResultID, &h.GameID, &h.Sport, &h.Before, &h.Rating, &h.RD, &h.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning rating history: %w", err)
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,455 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/checkin"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/reputation"
+	"github.com/dudeiebot/sportPeerGo/pkg/reputation/model"
+)
+
+// closeAttendanceBatch caps how many games one run of the attendance job
+// closes.
+const closeAttendanceBatch = 200
+
+func gameUserIDs(ctx context.Context, q querier, queri string, args ...interface{}) ([]int64, error) {
+	rows, err := q.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying game players: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning game player: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CreateReviewQuery stores r once the reviewer and reviewee are shown to
+// have played the game together.
+func CreateReviewQuery(ctx context.Context, d *dbs.Service, r model.Review) (int64, error) {
+	g, err := GetGameQuery(ctx, d, r.GameID)
+	if err != nil {
+		return 0, err
+	}
+	participants, err := gameUserIDs(
+		ctx, d.DB, `SELECT user_id FROM game_participants WHERE game_id = ?`, r.GameID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	noShows, err := gameUserIDs(
+		ctx, d.DB, `SELECT user_id FROM game_attendance WHERE game_id = ? AND status = ?`,
+		r.GameID, model.AttendanceNoShow,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := reputation.CheckReview(*g, participants, noShows, r, time.Now()); err != nil {
+		return 0, err
+	}
+
+	var exists bool
+	err = d.DB.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (
+			SELECT 1 FROM game_reviews WHERE game_id = ? AND reviewer_id = ? AND reviewee_id = ?
+		)`,
+		r.GameID, r.ReviewerID, r.RevieweeID,
+	).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("error querying review: %w", err)
+	}
+	if exists {
+		return 0, reputation.ErrAlreadyReviewed
+	}
+
+	res, err := d.DB.ExecContext(
+		ctx,
+		`INSERT INTO game_reviews
+			(game_id, reviewer_id, reviewee_id, sportsmanship, punctuality, skill_accuracy, comment)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.GameID, r.ReviewerID, r.RevieweeID, r.Sportsmanship, r.Punctuality, r.SkillAccuracy, r.Comment,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting review: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// GameReviewsByQuery lists the reviews reviewerID left for a game, so the
+// app can show who is still to be reviewed.
+func GameReviewsByQuery(ctx context.Context, d *dbs.Service, gameID, reviewerID int64) ([]model.Review, error) {
+	return queryReviews(
+		ctx, d,
+		`WHERE game_id = ? AND reviewer_id = ? ORDER BY created_at, id`,
+		gameID, reviewerID,
+	)
+}
+
+// UserReviewsQuery pages through the reviews userID received, newest first.
+func UserReviewsQuery(ctx context.Context, d *dbs.Service, userID int64, limit, offset int) ([]model.Review, error) {
+	return queryReviews(
+		ctx, d,
+		`WHERE reviewee_id = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`,
+		userID, limit, offset,
+	)
+}
+
+func queryReviews(ctx context.Context, d *dbs.Service, where string, args ...interface{}) ([]model.Review, error) {
+	queri := `
+		SELECT id, game_id, reviewer_id, reviewee_id, sportsmanship, punctuality, skill_accuracy,
+			comment, created_at
+		FROM game_reviews
+	` + where
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []model.Review{}
+	for rows.Next() {
+		var r model.Review
+		err := rows.Scan(
+			&r.ID, &r.GameID, &r.ReviewerID, &r.RevieweeID, &r.Sportsmanship, &r.Punctuality,
+			&r.SkillAccuracy, &r.Comment, &r.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning review: %w", err)
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+// attendanceRecord counts the games userID had attendance taken for since
+// the given time and how many of them they missed.
+func attendanceRecord(ctx context.Context, q querier, userID int64, since time.Time) (int, int, error) {
+	var games, noShows int
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*), COALESCE(SUM(a.status = ?), 0)
+		FROM game_attendance a
+		JOIN games g ON g.id = a.game_id
+		WHERE a.user_id = ? AND g.starts_at >= ? AND g.status <> ?`,
+		model.AttendanceNoShow, userID, since, gamemodel.StatusCancelled,
+	).Scan(&games, &noShows)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error querying attendance record: %w", err)
+	}
+	return games, noShows, nil
+}
+
+// noShowRestricted reports whether userID's recent no-shows keep them out
+// of public games.
+func noShowRestricted(ctx context.Context, q querier, userID int64, now time.Time) (bool, error) {
+	games, noShows, err := attendanceRecord(ctx, q, userID, now.Add(-reputation.NoShowWindow))
+	if err != nil {
+		return false, err
+	}
+	return reputation.Standing(games, noShows) == model.StandingRestricted, nil
+}
+
+func ReputationQuery(ctx context.Context, d *dbs.Service, userID int64) (*model.Reputation, error) {
+	rep := model.Reputation{UserID: userID}
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*),
+			COALESCE(AVG(sportsmanship), 0),
+			COALESCE(AVG(punctuality), 0),
+			COALESCE(AVG(skill_accuracy), 0),
+			COALESCE(AVG((sportsmanship + punctuality + skill_accuracy) / 3), 0)
+		FROM game_reviews
+		WHERE reviewee_id = ?`,
+		userID,
+	).Scan(&rep.Reviews, &rep.Sportsmanship, &rep.Punctuality, &rep.SkillAccuracy, &rep.Overall)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reviews: %w", err)
+	}
+	rep.Games, rep.NoShows, err = attendanceRecord(ctx, d.DB, userID, time.Now().Add(-reputation.NoShowWindow))
+	if err != nil {
+		return nil, err
+	}
+	rep.NoShowRate = reputation.NoShowRate(rep.Games, rep.NoShows)
+	rep.Standing = reputation.Standing(rep.Games, rep.NoShows)
+	return &rep, nil
+}
+
+// StandingsQuery returns the standing of each of the given users, leaving
+// out the ones in good standing.
+func StandingsQuery(ctx context.Context, d *dbs.Service, ids []int64) (map[int64]string, error) {
+	standings := make(map[int64]string)
+	if len(ids) == 0 {
+		return standings, nil
+	}
+	queri := `
+		SELECT a.user_id, COUNT(*), COALESCE(SUM(a.status = ?), 0)
+		FROM game_attendance a
+		JOIN games g ON g.id = a.game_id
+		WHERE a.user_id IN (` + placeholders(len(ids)) + `) AND g.starts_at >= ? AND g.status <> ?
+		GROUP BY a.user_id
+	`
+	args := append([]interface{}{model.AttendanceNoShow}, int64Args(ids)...)
+	args = append(args, time.Now().Add(-reputation.NoShowWindow), gamemodel.StatusCancelled)
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying attendance records: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var games, noShows int
+		if err := rows.Scan(&id, &games, &noShows); err != nil {
+			return nil, fmt.Errorf("error scanning attendance record: %w", err)
+		}
+		if s := reputation.Standing(games, noShows); s != model.StandingGood {
+			standings[id] = s
+		}
+	}
+	return standings, rows.Err()
+}
+
+// RecordAttendanceQuery saves the attendance the organizer took for a game,
+// overwriting earlier entries for the same players, including automatic
+// no-shows.
+func RecordAttendanceQuery(ctx context.Context, d *dbs.Service, gameID, organizerID int64, roll model.Roll) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	g, r, err := lockGame(ctx, tx, gameID)
+	if err != nil {
+		return err
+	}
+	if g.OrganizerID != organizerID {
+		return game.ErrNotOrganizer
+	}
+	if err := reputation.CheckRoll(*g, r.Participants, roll, time.Now()); err != nil {
+		return err
+	}
+	for _, e := range roll.Entries {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO game_attendance (game_id, user_id, status, source, recorded_by, recorded_at)
+			VALUES (?, ?, ?, ?, ?, NOW())
+			ON DUPLICATE KEY UPDATE
+				status = VALUES(status), source = VALUES(source),
+				recorded_by = VALUES(recorded_by), recorded_at = VALUES(recorded_at)`,
+			gameID, e.UserID, e.Status, model.SourceOrganizer, organizerID,
+		)
+		if err != nil {
+			return fmt.Errorf("error recording attendance of user %d: %w", e.UserID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func AttendanceQuery(ctx context.Context, d *dbs.Service, gameID int64) ([]model.Attendance, error) {
+	return gameAttendance(ctx, d.DB, gameID)
+}
+
+func gameAttendance(ctx context.Context, q querier, gameID int64) ([]model.Attendance, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT game_id, user_id, status, source, recorded_by, recorded_at
+		FROM game_attendance
+		WHERE game_id = ?
+		ORDER BY user_id`,
+		gameID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying attendance: %w", err)
+	}
+	defer rows.Close()
+
+	attendance := []model.Attendance{}
+	for rows.Next() {
+		var a model.Attendance
+		var recordedBy sql.NullInt64
+		if err := rows.Scan(&a.GameID, &a.UserID, &a.Status, &a.Source, &recordedBy, &a.RecordedAt); err != nil {
+			return nil, fmt.Errorf("error scanning attendance: %w", err)
+		}
+		if recordedBy.Valid {
+			a.RecordedBy = &recordedBy.Int64
+		}
+		attendance = append(attendance, a)
+	}
+	return attendance, rows.Err()
+}
+
+// CloseAttendanceQuery records every participant left unmarked as a no-show
+// in games that ended more than the attendance grace period ago and whose
+// organizer took attendance. Games with only check-ins stay open. It
+// returns who was marked, by game.
+func CloseAttendanceQuery(ctx context.Context, d *dbs.Service, now time.Time) (map[int64][]int64, error) {
+	games, err := gameUserIDs(
+		ctx, d.DB,
+		`SELECT g.id FROM games g
+		WHERE g.status = ? AND g.attendance_closed_at IS NULL
+		  AND g.ends_at <= ? AND g.ends_at > ?
+		  AND EXISTS (SELECT 1 FROM game_attendance a WHERE a.game_id = g.id AND a.source = ?)
+		ORDER BY g.ends_at, g.id
+		LIMIT ?`,
+		gamemodel.StatusOpen, now.Add(-reputation.AttendanceGrace), now.Add(-reputation.ReviewWindow),
+		model.SourceOrganizer,
+		closeAttendanceBatch,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	marked := make(map[int64][]int64)
+	for _, gameID := range games {
+		ids, err := closeAttendance(ctx, d, gameID, now)
+		if err != nil {
+			return marked, err
+		}
+		if len(ids) > 0 {
+			marked[gameID] = ids
+		}
+	}
+	return marked, nil
+}
+
+func closeAttendance(ctx context.Context, d *dbs.Service, gameID int64, now time.Time) ([]int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var closed sql.NullTime
+	err = tx.QueryRowContext(
+		ctx, `SELECT attendance_closed_at FROM games WHERE id = ? FOR UPDATE`, gameID,
+	).Scan(&closed)
+	if err != nil {
+		return nil, fmt.Errorf("error locking game attendance: %w", err)
+	}
+	if closed.Valid {
+		return nil, nil
+	}
+	attendance, err := gameAttendance(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if !reputation.RollTaken(attendance) {
+		return nil, nil
+	}
+
+	// The organizer took the attendance, so they are never a no-show.
+	ids, err := gameUserIDs(
+		ctx, tx,
+		`SELECT p.user_id
+		FROM game_participants p
+		JOIN games g ON g.id = p.game_id
+		WHERE p.game_id = ? AND p.user_id <> g.organizer_id
+		  AND NOT EXISTS (SELECT 1 FROM game_attendance a WHERE a.game_id = p.game_id AND a.user_id = p.user_id)
+		ORDER BY p.user_id`,
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO game_attendance (game_id, user_id, status, source, recorded_at) VALUES (?, ?, ?, ?, ?)`,
+			gameID, id, model.AttendanceNoShow, model.SourceAuto, now,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error recording no-show of user %d: %w", id, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE games SET attendance_closed_at = ? WHERE id = ?`, now, gameID); err != nil {
+		return nil, fmt.Errorf("error closing attendance: %w", err)
+	}
+	return ids, tx.Commit()
+}
+
+// CheckInQuery marks userID present at the game after they scanned its
+// code. It replaces a no-show the organizer recorded by mistake.
+func CheckInQuery(ctx context.Context, d *dbs.Service, gameID, userID int64, now time.Time) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	g, r, err := lockGame(ctx, tx, gameID)
+	if err != nil {
+		return err
+	}
+	if g.Status == gamemodel.StatusCancelled {
+		return reputation.ErrGameCancelled
+	}
+	if !containsID(r.Participants, userID) {
+		return game.ErrNotJoined
+	}
+	if !checkin.Open(*g, now) {
+		return checkin.ErrClosed
+	}
+
+	var status string
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT status FROM game_attendance WHERE game_id = ? AND user_id = ? FOR UPDATE`,
+		gameID, userID,
+	).Scan(&status)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error querying attendance: %w", err)
+	}
+	if status == model.AttendancePresent {
+		return checkin.ErrAlreadyCheckedIn
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO game_attendance (game_id, user_id, status, source, recorded_by, recorded_at)
+		VALUES (?, ?, ?, ?, NULL, ?)
+		ON DUPLICATE KEY UPDATE
+			status = VALUES(status), source = VALUES(source),
+			recorded_by = VALUES(recorded_by), recorded_at = VALUES(recorded_at)`,
+		gameID, userID, model.AttendancePresent, model.SourceCheckIn, now,
+	)
+	if err != nil {
+		return fmt.Errorf("error checking in: %w", err)
+	}
+	return tx.Commit()
+}
+
+func AttendanceStatsQuery(ctx context.Context, d *dbs.Service, userID int64) (*model.AttendanceStats, error) {
+	stats := model.AttendanceStats{UserID: userID}
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*),
+			COALESCE(SUM(a.status = ?), 0),
+			COALESCE(SUM(a.status = ?), 0),
+			COALESCE(SUM(a.status = ? AND a.source = ?), 0)
+		FROM game_attendance a
+		JOIN games g ON g.id = a.game_id
+		WHERE a.user_id = ? AND g.status <> ?`,
+		model.AttendancePresent, model.AttendanceNoShow, model.AttendancePresent, model.SourceCheckIn,
+		userID, gamemodel.StatusCancelled,
+	).Scan(&stats.Games, &stats.Attended, &stats.NoShows, &stats.CheckIns)
+	if err != nil {
+		return nil, fmt.Errorf("error querying attendance stats: %w", err)
+	}
+	if stats.Games > 0 {
+		stats.Rate = float64(stats.Attended) / float64(stats.Games)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,239 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/result"
+	"github.com/dudeiebot/sportPeerGo/pkg/result/model"
+)
+
+const resultColumns = `
+	id, game_id, reported_by, score, winner, status, confirmed_by, disputed_by,
+	dispute_reason, resolved_by, resolution_note, created_at, updated_at
+`
+
+func scanResult(row rowScanner) (*model.Result, error) {
+	var r model.Result
+	var score []byte
+	var confirmed, disputed, resolved sql.NullInt64
+	err := row.Scan(
+		&r.ID, &r.GameID, &r.ReportedBy, &score, &r.Winner, &r.Status, &confirmed, &disputed,
+		&r.DisputeReason, &resolved, &r.ResolutionNote, &r.CreatedAt, &r.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, result.ErrResultNotFound
+		}
+		return nil, fmt.Errorf("error scanning result: %w", err)
+	}
+	if err := json.Unmarshal(score, &r.Score); err != nil {
+		return nil, fmt.Errorf("error decoding result %d: %w", r.ID, err)
+	}
+	for _, field := range []struct {
+		v    sql.NullInt64
+		dest **int64
+	}{
+		{confirmed, &r.ConfirmedBy},
+		{disputed, &r.DisputedBy},
+		{resolved, &r.ResolvedBy},
+	} {
+		if field.v.Valid {
+			id := field.v.Int64
+			*field.dest = &id
+		}
+	}
+	return &r, nil
+}
+
+// loadSides fills in the players of each side of r.
+func loadSides(ctx context.Context, q querier, r *model.Result) error {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT user_id, side FROM match_result_players WHERE result_id = ? ORDER BY user_id`,
+		r.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error querying result players: %w", err)
+	}
+	defer rows.Close()
+
+	r.SideA, r.SideB = []int64{}, []int64{}
+	for rows.Next() {
+		var id int64
+		var side string
+		if err := rows.Scan(&id, &side); err != nil {
+			return fmt.Errorf("error scanning result player: %w", err)
+		}
+		if side == model.SideA {
+			r.SideA = append(r.SideA, id)
+		} else {
+			r.SideB = append(r.SideB, id)
+		}
+	}
+	return rows.Err()
+}
+
+// CreateResultQuery records the result of a game, pending confirmation. A
+// game has at most one result.
+func CreateResultQuery(ctx context.Context, d *dbs.Service, r model.Result) (int64, error) {
+	score, err := json.Marshal(r.Score)
+	if err != nil {
+		return 0, fmt.Errorf("error encoding score: %w", err)
+	}
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the game so two players reporting at once cannot both insert.
+	var gameID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM games WHERE id = ? FOR UPDATE`, r.GameID).Scan(&gameID)
+	if err != nil {
+		return 0, fmt.Errorf("error locking game: %w", err)
+	}
+	var exists bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM match_results WHERE game_id = ?)`,
+		r.GameID,
+	).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("error checking results: %w", err)
+	}
+	if exists {
+		return 0, result.ErrResultExists
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO match_results (game_id, reported_by, score, winner, status) VALUES (?, ?, ?, ?, ?)`,
+		r.GameID, r.ReportedBy, score, r.Winner, model.StatusPending,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting result: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	for _, side := range []struct {
+		name string
+		ids  []int64
+	}{
+		{model.SideA, r.SideA},
+		{model.SideB, r.SideB},
+	} {
+		for _, userID := range side.ids {
+			_, err := tx.ExecContext(
+				ctx,
+				`INSERT INTO match_result_players (result_id, user_id, side) VALUES (?, ?, ?)`,
+				id, userID, side.name,
+			)
+			if err != nil {
+				return 0, fmt.Errorf("error inserting result player: %w", err)
+			}
+		}
+	}
+	return id, tx.Commit()
+}
+
+func GameResultQuery(ctx context.Context, d *dbs.Service, gameID int64) (*model.Result, error) {
+	queri := `SELECT ` + resultColumns + ` FROM match_results WHERE game_id = ?`
+	r, err := scanResult(d.DB.QueryRowContext(ctx, queri, gameID))
+	if err != nil {
+		return nil, err
+	}
+	if err := loadSides(ctx, d.DB, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UpdateResultQuery locks the result of gameID, lets change decide what
+// happens to it, and saves it. The lock keeps a confirmation and a dispute
+// from both landing.
+func UpdateResultQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	gameID int64,
+	change func(r *model.Result) error,
+) (*model.Result, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queri := `SELECT ` + resultColumns + ` FROM match_results WHERE game_id = ? FOR UPDATE`
+	r, err := scanResult(tx.QueryRowContext(ctx, queri, gameID))
+	if err != nil {
+		return nil, err
+	}
+	if err := loadSides(ctx, tx, r); err != nil {
+		return nil, err
+	}
+	if err := change(r); err != nil {
+		return nil, err
+	}
+
+	score, err := json.Marshal(r.Score)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding score: %w", err)
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE match_results
+		SET score = ?, winner = ?, status = ?, confirmed_by = ?, disputed_by = ?,
+			dispute_reason = ?, resolved_by = ?, resolution_note = ?
+		WHERE id = ?`,
+		score, r.Winner, r.Status, r.ConfirmedBy, r.DisputedBy,
+		r.DisputeReason, r.ResolvedBy, r.ResolutionNote, r.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error updating result: %w", err)
+	}
+	return r, tx.Commit()
+}
+
+// DisputedResultsQuery lists disputed results, oldest first, for admins to
+// work through.
+func DisputedResultsQuery(ctx context.Context, d *dbs.Service, limit, offset int) ([]model.Result, error) {
+	queri := `
+		SELECT ` + resultColumns + `
+		FROM match_results
+		WHERE status = ?
+		ORDER BY updated_at, id
+		LIMIT ? OFFSET ?
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, model.StatusDisputed, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying disputed results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []model.Result{}
+	for rows.Next() {
+		r, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range results {
+		if err := loadSides(ctx, d.DB, &results[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
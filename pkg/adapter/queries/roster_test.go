@@ -0,0 +1,380 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+// rosterDB is an in-memory stand-in for the tables updateRoster touches. It
+// understands just the statements of lockGame and saveRosterChange, holds
+// the game row lock from SELECT ... FOR UPDATE until the transaction ends,
+// and applies writes on commit, so concurrent transactions interleave the
+// way they would in MySQL.
+type rosterDB struct {
+	lock sync.Mutex // the game row lock
+
+	mu           sync.Mutex
+	game         model.Game
+	participants []int64
+	waitlist     []rosterWait
+	violations   []string
+}
+
+type rosterWait struct {
+	userID  int64
+	expires *time.Time
+}
+
+func (db *rosterDB) Open(string) (driver.Conn, error) {
+	return &rosterConn{db: db}, nil
+}
+
+type rosterConn struct {
+	db     *rosterDB
+	locked bool
+	writes []func()
+}
+
+func (c *rosterConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *rosterConn) Close() error { return nil }
+
+func (c *rosterConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *rosterConn) Commit() error {
+	// Widen the window between reading the roster and saving it, so
+	// transactions that do not hold the lock would trip over each other.
+	time.Sleep(time.Millisecond)
+	db := c.db
+	db.mu.Lock()
+	for _, w := range c.writes {
+		w()
+	}
+	if len(db.participants) > db.game.Capacity {
+		db.violations = append(db.violations, fmt.Sprintf("%d players in a game of %d", len(db.participants), db.game.Capacity))
+	}
+	db.mu.Unlock()
+	return c.end()
+}
+
+func (c *rosterConn) Rollback() error {
+	return c.end()
+}
+
+func (c *rosterConn) end() error {
+	c.writes = nil
+	if c.locked {
+		c.locked = false
+		c.db.lock.Unlock()
+	}
+	return nil
+}
+
+func (c *rosterConn) QueryContext(ctx context.Context, q string, args []driver.NamedValue) (driver.Rows, error) {
+	db := c.db
+	switch {
+	case strings.Contains(q, "FROM games g") && strings.Contains(q, "FOR UPDATE"):
+		db.lock.Lock()
+		c.locked = true
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		g := db.game
+		return &rosterRows{
+			cols: make([]string, 17),
+			rows: [][]driver.Value{{
+				g.ID, g.OrganizerID, g.Sport, nil, g.Location, g.StartsAt, g.EndsAt,
+				int64(g.Capacity), int64(0), int64(0), g.Visibility, int64(0), g.Status, nil, nil,
+				int64(0), nil,
+			}},
+		}, nil
+	case strings.Contains(q, "FROM game_participants"):
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		rows := &rosterRows{cols: []string{"user_id"}}
+		for _, id := range db.participants {
+			rows.rows = append(rows.rows, []driver.Value{id})
+		}
+		return rows, nil
+	case strings.Contains(q, "FROM game_waitlist"):
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		rows := &rosterRows{cols: []string{"user_id", "offer_expires_at"}}
+		for _, e := range db.waitlist {
+			var expires driver.Value
+			if e.expires != nil {
+				expires = *e.expires
+			}
+			rows.rows = append(rows.rows, []driver.Value{e.userID, expires})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", q)
+}
+
+func (c *rosterConn) ExecContext(ctx context.Context, q string, args []driver.NamedValue) (driver.Result, error) {
+	db := c.db
+	if !c.locked {
+		return nil, fmt.Errorf("%q without the game row lock", q)
+	}
+	if strings.HasPrefix(strings.TrimSpace(q), "UPDATE games") {
+		capacity := args[5].Value.(int64)
+		c.writes = append(c.writes, func() {
+			db.game.Capacity = int(capacity)
+		})
+		return driver.RowsAffected(1), nil
+	}
+	userID := args[len(args)-1].Value.(int64)
+	switch {
+	case strings.HasPrefix(q, "DELETE FROM game_participants"):
+		c.writes = append(c.writes, func() {
+			db.participants = without(db.participants, userID)
+		})
+	case strings.HasPrefix(q, "DELETE FROM game_waitlist"):
+		c.writes = append(c.writes, func() {
+			kept := db.waitlist[:0]
+			for _, e := range db.waitlist {
+				if e.userID != userID {
+					kept = append(kept, e)
+				}
+			}
+			db.waitlist = kept
+		})
+	case strings.HasPrefix(q, "INSERT INTO game_participants"):
+		c.writes = append(c.writes, func() {
+			if containsID(db.participants, userID) {
+				db.violations = append(db.violations, fmt.Sprintf("user %d joined twice", userID))
+			}
+			db.participants = append(db.participants, userID)
+		})
+	case strings.HasPrefix(q, "INSERT INTO game_waitlist"):
+		c.writes = append(c.writes, func() {
+			db.waitlist = append(db.waitlist, rosterWait{userID: userID})
+		})
+	case strings.HasPrefix(q, "UPDATE game_waitlist SET offered_at"):
+		expires := args[1].Value.(time.Time)
+		c.writes = append(c.writes, func() {
+			for i := range db.waitlist {
+				if db.waitlist[i].userID == userID {
+					db.waitlist[i].expires = &expires
+				}
+			}
+		})
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", q)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func without(ids []int64, id int64) []int64 {
+	out := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+type rosterRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *rosterRows) Columns() []string { return r.cols }
+
+func (r *rosterRows) Close() error { return nil }
+
+func (r *rosterRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// TestUpdateGameOffersNewSeats raises the capacity of a full game and checks
+// the new seat is offered to the head of the waitlist, not to a newcomer.
+func TestUpdateGameOffersNewSeats(t *testing.T) {
+	fake := &rosterDB{
+		game: model.Game{
+			ID:          1,
+			OrganizerID: 100,
+			Sport:       "football",
+			StartsAt:    time.Now().Add(24 * time.Hour),
+			EndsAt:      time.Now().Add(26 * time.Hour),
+			Capacity:    2,
+			Visibility:  model.VisibilityPublic,
+			Status:      model.StatusOpen,
+		},
+		participants: []int64{100, 1},
+		waitlist:     []rosterWait{{userID: 11}, {userID: 12}},
+	}
+	name := fmt.Sprintf("roster-%p", fake)
+	sql.Register(name, fake)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	d := &dbs.Service{DB: db}
+	ctx := context.Background()
+
+	g := fake.game
+	g.Capacity = 3
+	c, err := UpdateGameQuery(ctx, d, g, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Offered) != 1 || c.Offered[0].UserID != 11 {
+		t.Fatalf("update offered %+v, want the new seat offered to user 11", c.Offered)
+	}
+
+	c, err = updateRoster(ctx, d, 1, func(g *model.Game, r *game.Roster, now time.Time) (game.Change, error) {
+		return r.Join(21, now, game.ConfirmWindow)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Joined) != 0 || len(c.Waitlisted) != 1 {
+		t.Errorf("newcomer got %+v, want a place on the waitlist", c)
+	}
+	if len(fake.participants) != 2 {
+		t.Errorf("got participants %v, want the new seat held for user 11", fake.participants)
+	}
+}
+
+// TestUpdateRosterConcurrent runs leaves, confirmations, joins and expiry
+// runs of one game in concurrent transactions and checks the game is never
+// overbooked and nobody is promoted twice.
+func TestUpdateRosterConcurrent(t *testing.T) {
+	const capacity = 4
+	fake := &rosterDB{
+		game: model.Game{
+			ID:          1,
+			OrganizerID: 100,
+			Sport:       "football",
+			StartsAt:    time.Now().Add(24 * time.Hour),
+			EndsAt:      time.Now().Add(26 * time.Hour),
+			Capacity:    capacity,
+			Visibility:  model.VisibilityPublic,
+			Status:      model.StatusOpen,
+		},
+		participants: []int64{100, 1, 2, 3},
+	}
+	for id := int64(11); id <= 20; id++ {
+		fake.waitlist = append(fake.waitlist, rosterWait{userID: id})
+	}
+	name := fmt.Sprintf("roster-%p", fake)
+	sql.Register(name, fake)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	d := &dbs.Service{DB: db}
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	joined := make(map[int64]int)
+	offered := make(map[int64]int)
+	record := func(c game.Change) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, id := range c.Joined {
+			joined[id]++
+		}
+		for _, o := range c.Offered {
+			offered[o.UserID]++
+		}
+	}
+
+	var wg sync.WaitGroup
+	run := func(fn func() (game.Change, error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := fn()
+			record(c)
+			if err != nil && err != game.ErrNoOffer {
+				t.Error(err)
+			}
+		}()
+	}
+	for _, id := range []int64{1, 2, 3} {
+		id := id
+		run(func() (game.Change, error) { return LeaveGameQuery(ctx, d, 1, id) })
+	}
+	for id := int64(11); id <= 20; id++ {
+		id := id
+		// Waiters keep trying to confirm until a seat is offered to them or
+		// every seat is taken.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				c, err := ConfirmWaitlistQuery(ctx, d, 1, id, "fake")
+				record(c)
+				if err != game.ErrNoOffer {
+					if err != nil {
+						t.Error(err)
+					}
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	for id := int64(21); id <= 25; id++ {
+		id := id
+		run(func() (game.Change, error) {
+			return updateRoster(ctx, d, 1, func(g *model.Game, r *game.Roster, now time.Time) (game.Change, error) {
+				return r.Join(id, now, game.ConfirmWindow)
+			})
+		})
+	}
+	for i := 0; i < 5; i++ {
+		run(func() (game.Change, error) {
+			return updateRoster(ctx, d, 1, func(g *model.Game, r *game.Roster, now time.Time) (game.Change, error) {
+				return r.Expire(now, game.ConfirmWindow), nil
+			})
+		})
+	}
+	wg.Wait()
+
+	for _, v := range fake.violations {
+		t.Error(v)
+	}
+	for id, n := range joined {
+		if n > 1 {
+			t.Errorf("user %d joined %d times", id, n)
+		}
+	}
+	for id, n := range offered {
+		if n > 1 {
+			t.Errorf("user %d was promoted %d times", id, n)
+		}
+	}
+	if len(fake.participants) != capacity {
+		t.Errorf("got participants %v, want the %d freed seats taken", fake.participants, capacity)
+	}
+	if len(joined) != 3 {
+		t.Errorf("got joins %v, want the 3 freed seats filled once each", joined)
+	}
+}
@@ -0,0 +1,475 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+func CreateSeriesQuery(ctx context.Context, d *dbs.Service, s model.Series) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := insertSeries(ctx, tx, s)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func insertSeries(ctx context.Context, q querier, s model.Series) (int64, error) {
+	queri := `
+		INSERT INTO game_series
+			(organizer_id, sport, venue_id, location, starts_at, duration_minutes,
+			 capacity, skill_min, skill_max, visibility, rrule, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := q.ExecContext(
+		ctx, queri,
+		s.OrganizerID, s.Sport, s.VenueID, s.Location, s.StartsAt, s.DurationMinutes,
+		s.Capacity, s.SkillMin, s.SkillMax, s.Visibility, s.RRule, model.StatusOpen,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting series: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, replaceExDates(ctx, q, id, s.ExDates)
+}
+
+func replaceExDates(ctx context.Context, q querier, seriesID int64, exdates []time.Time) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM game_series_exdates WHERE series_id = ?`, seriesID); err != nil {
+		return fmt.Errorf("error clearing exdates: %w", err)
+	}
+	for _, ex := range exdates {
+		_, err := q.ExecContext(
+			ctx,
+			`INSERT IGNORE INTO game_series_exdates (series_id, occurs_at) VALUES (?, ?)`,
+			seriesID, ex,
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting exdate: %w", err)
+		}
+	}
+	return nil
+}
+
+func GetSeriesQuery(ctx context.Context, d *dbs.Service, id int64) (*model.Series, error) {
+	return getSeries(ctx, d.DB, id, false)
+}
+
+func getSeries(ctx context.Context, q querier, id int64, lock bool) (*model.Series, error) {
+	queri := `
+		SELECT id, organizer_id, sport, venue_id, location, starts_at, duration_minutes,
+			capacity, skill_min, skill_max, visibility, rrule, status
+		FROM game_series
+		WHERE id = ?
+	`
+	if lock {
+		queri += ` FOR UPDATE`
+	}
+
+	var s model.Series
+	var venueID sql.NullInt64
+	err := q.QueryRowContext(ctx, queri, id).Scan(
+		&s.ID, &s.OrganizerID, &s.Sport, &venueID, &s.Location, &s.StartsAt, &s.DurationMinutes,
+		&s.Capacity, &s.SkillMin, &s.SkillMax, &s.Visibility, &s.RRule, &s.Status,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, game.ErrSeriesNotFound
+		}
+		return nil, fmt.Errorf("error querying series: %w", err)
+	}
+	if venueID.Valid {
+		s.VenueID = &venueID.Int64
+	}
+
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT occurs_at FROM game_series_exdates WHERE series_id = ? ORDER BY occurs_at`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying exdates: %w", err)
+	}
+	defer rows.Close()
+	s.ExDates = []time.Time{}
+	for rows.Next() {
+		var ex time.Time
+		if err := rows.Scan(&ex); err != nil {
+			return nil, fmt.Errorf("error scanning exdate: %w", err)
+		}
+		s.ExDates = append(s.ExDates, ex)
+	}
+	return &s, rows.Err()
+}
+
+// SeriesGamesQuery returns the materialized occurrences of a series whose
+// original date falls in [from, to).
+func SeriesGamesQuery(ctx context.Context, d *dbs.Service, seriesID int64, from, to time.Time) ([]model.Game, error) {
+	queri := `
+		SELECT ` + gameColumns + `
+		FROM games g
+		WHERE g.series_id = ? AND g.occurrence_at >= ? AND g.occurrence_at < ?
+		ORDER BY g.occurrence_at
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, seriesID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying series games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []model.Game
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, *g)
+	}
+	return games, rows.Err()
+}
+
+// MaterializeOccurrenceQuery returns the game backing the occurrence at at,
+// creating it from the series template on first use. Occurrences that have
+// already started are never created. The series row lock stops two requests
+// from creating the same occurrence twice.
+func MaterializeOccurrenceQuery(ctx context.Context, d *dbs.Service, seriesID int64, at time.Time) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	s, err := getSeries(ctx, tx, seriesID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var gameID int64
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT id FROM games WHERE series_id = ? AND occurrence_at = ?`,
+		seriesID, at,
+	).Scan(&gameID)
+	if err == nil {
+		return gameID, tx.Commit()
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("error querying occurrence: %w", err)
+	}
+
+	if err := game.CheckOccurrence(*s, at); err != nil {
+		return 0, err
+	}
+	if !at.After(time.Now()) {
+		return 0, game.ErrStartInPast
+	}
+	gameID, err = insertGame(ctx, tx, s.Template(at))
+	if err != nil {
+		return 0, err
+	}
+	return gameID, tx.Commit()
+}
+
+// CancelOccurrenceQuery excludes one date from the series and cancels its
+// game if it was already materialized, returning that game's id or 0.
+func CancelOccurrenceQuery(ctx context.Context, d *dbs.Service, seriesID, organizerID int64, at time.Time) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	s, err := getSeries(ctx, tx, seriesID, true)
+	if err != nil {
+		return 0, err
+	}
+	if s.OrganizerID != organizerID {
+		return 0, game.ErrNotOrganizer
+	}
+	if err := game.CheckOccurrence(*s, at); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT IGNORE INTO game_series_exdates (series_id, occurs_at) VALUES (?, ?)`,
+		seriesID, at,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error excluding occurrence: %w", err)
+	}
+	gameIDs, err := cancelSeriesGames(
+		ctx, tx,
+		`SELECT id FROM games WHERE series_id = ? AND occurrence_at = ? AND status = ? FOR UPDATE`,
+		seriesID, at, model.StatusOpen,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var gameID int64
+	if len(gameIDs) > 0 {
+		gameID = gameIDs[0]
+	}
+	return gameID, tx.Commit()
+}
+
+// cancelSeriesGames cancels the games selected by queri, releasing their
+// court bookings the way CancelGameQuery does, and returns their ids.
+func cancelSeriesGames(ctx context.Context, tx *sql.Tx, queri string, args ...interface{}) ([]int64, error) {
+	gameIDs, err := seriesGameIDs(ctx, tx, queri, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, id := range gameIDs {
+		_, err := tx.ExecContext(ctx, `UPDATE games SET status = ? WHERE id = ?`, model.StatusCancelled, id)
+		if err != nil {
+			return nil, fmt.Errorf("error cancelling series game: %w", err)
+		}
+		if err := cancelGameBookings(ctx, tx, id, now); err != nil {
+			return nil, err
+		}
+	}
+	return gameIDs, nil
+}
+
+// EditFutureOccurrencesQuery applies an "all future" edit starting at the
+// occurrence at at. Materialized games from then on follow the new series:
+// when the rule is unchanged they keep their slot, shifted by any change in
+// start time, and each is checked and edited like UpdateGameQuery would;
+// otherwise they are detached and kept as one-off games. It returns the id
+// of the series now covering at and the roster change of every edited game.
+func EditFutureOccurrencesQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	seriesID, organizerID int64,
+	at time.Time,
+	edit model.Series,
+) (int64, map[int64]game.Change, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	old, err := getSeries(ctx, tx, seriesID, true)
+	if err != nil {
+		return 0, nil, err
+	}
+	if old.OrganizerID != organizerID {
+		return 0, nil, game.ErrNotOrganizer
+	}
+	if err := game.CheckOccurrence(*old, at); err != nil {
+		return 0, nil, err
+	}
+
+	truncated, next, whole, err := game.SplitSeries(*old, at, edit)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := next.ValidateSeries(); err != nil {
+		return 0, nil, err
+	}
+
+	nextID := old.ID
+	if whole {
+		if err := updateSeries(ctx, tx, next); err != nil {
+			return 0, nil, err
+		}
+	} else {
+		if err := updateSeries(ctx, tx, truncated); err != nil {
+			return 0, nil, err
+		}
+		if nextID, err = insertSeries(ctx, tx, next); err != nil {
+			return 0, nil, err
+		}
+	}
+	next.ID = nextID
+
+	changes := make(map[int64]game.Change)
+	if !game.SameRule(edit.RRule, old.RRule) {
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE games SET series_id = NULL, occurrence_at = NULL WHERE series_id = ? AND occurrence_at >= ?`,
+			old.ID, at,
+		)
+		if err != nil {
+			return 0, nil, fmt.Errorf("error detaching future occurrences: %w", err)
+		}
+		return nextID, changes, tx.Commit()
+	}
+
+	gameIDs, err := seriesGameIDs(
+		ctx, tx,
+		`SELECT id FROM games WHERE series_id = ? AND occurrence_at >= ? AND status = ? ORDER BY occurrence_at`,
+		old.ID, at, model.StatusOpen,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	shift := next.StartsAt.Sub(at)
+	now := time.Now()
+	for _, id := range gameIDs {
+		current, r, err := lockGame(ctx, tx, id)
+		if err != nil {
+			return 0, nil, err
+		}
+		occursAt := *current.OccurrenceAt
+		if now.Before(current.StartsAt) {
+			occursAt = occursAt.Add(shift)
+			g := next.Template(occursAt)
+			g.FeeCents = current.FeeCents
+			// The series capacity never pushes out players already joined.
+			if g.Capacity < current.Participants {
+				g.Capacity = current.Participants
+			}
+			if changes[id], err = updateGame(ctx, tx, current, r, g); err != nil {
+				return 0, nil, err
+			}
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE games SET series_id = ?, occurrence_at = ? WHERE id = ?`,
+			nextID, occursAt, id,
+		)
+		if err != nil {
+			return 0, nil, fmt.Errorf("error updating future occurrences: %w", err)
+		}
+	}
+	return nextID, changes, tx.Commit()
+}
+
+func updateSeries(ctx context.Context, tx *sql.Tx, s model.Series) error {
+	queri := `
+		UPDATE game_series
+		SET sport = ?, venue_id = ?, location = ?, starts_at = ?, duration_minutes = ?,
+			capacity = ?, skill_min = ?, skill_max = ?, visibility = ?, rrule = ?
+		WHERE id = ?
+	`
+	_, err := tx.ExecContext(
+		ctx, queri,
+		s.Sport, s.VenueID, s.Location, s.StartsAt, s.DurationMinutes,
+		s.Capacity, s.SkillMin, s.SkillMax, s.Visibility, s.RRule, s.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating series: %w", err)
+	}
+	return replaceExDates(ctx, tx, s.ID, s.ExDates)
+}
+
+func seriesGameIDs(ctx context.Context, q querier, queri string, args ...interface{}) ([]int64, error) {
+	rows, err := q.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying series games: %w", err)
+	}
+	defer rows.Close()
+
+	var gameIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning series game: %w", err)
+		}
+		gameIDs = append(gameIDs, id)
+	}
+	return gameIDs, rows.Err()
+}
+
+// CancelSeriesQuery stops the series and cancels its upcoming games,
+// returning their ids.
+func CancelSeriesQuery(ctx context.Context, d *dbs.Service, seriesID, organizerID int64) ([]int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE game_series SET status = ? WHERE id = ? AND organizer_id = ? AND status = ?`,
+		model.StatusCancelled, seriesID, organizerID, model.StatusOpen,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling series: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, game.ErrNotOrganizer
+	}
+
+	gameIDs, err := cancelSeriesGames(
+		ctx, tx,
+		`SELECT id FROM games WHERE series_id = ? AND starts_at > ? AND status = ? FOR UPDATE`,
+		seriesID, time.Now(), model.StatusOpen,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return gameIDs, tx.Commit()
+}
+
+// SeriesViewerQuery describes how userID relates to a series through its
+// materialized games.
+func SeriesViewerQuery(ctx context.Context, d *dbs.Service, seriesID, userID int64) (game.Viewer, error) {
+	queri := `
+		SELECT
+			EXISTS (
+				SELECT 1 FROM game_participants p JOIN games g ON g.id = p.game_id
+				WHERE g.series_id = ? AND p.user_id = ?
+			),
+			EXISTS (
+				SELECT 1 FROM game_invites i JOIN games g ON g.id = i.game_id
+				WHERE g.series_id = ? AND i.user_id = ?
+			),
+			EXISTS (
+				SELECT 1 FROM friendships f JOIN game_series gs ON gs.organizer_id = f.friend_id
+				WHERE gs.id = ? AND f.user_id = ?
+			),
+			EXISTS (
+				SELECT 1 FROM user_blocks b JOIN game_series gs ON gs.id = ?
+				WHERE (b.blocker_id = gs.organizer_id AND b.blocked_id = ?)
+				   OR (b.blocker_id = ? AND b.blocked_id = gs.organizer_id)
+			)
+	`
+	v := game.Viewer{UserID: userID}
+	err := d.DB.QueryRowContext(
+		ctx, queri,
+		seriesID, userID, seriesID, userID, seriesID, userID, seriesID, userID, userID,
+	).Scan(&v.Participant, &v.Invited, &v.Friend, &v.Blocked)
+	if err != nil {
+		return v, fmt.Errorf("error querying series membership: %w", err)
+	}
+	return v, nil
+}
+
+// OccurrenceGameQuery returns the id of the materialized game for an
+// occurrence, or 0 if nobody has joined or edited it yet.
+func OccurrenceGameQuery(ctx context.Context, d *dbs.Service, seriesID int64, at time.Time) (int64, error) {
+	var id int64
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT id FROM games WHERE series_id = ? AND occurrence_at = ?`,
+		seriesID, at,
+	).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("error querying occurrence: %w", err)
+	}
+	return id, nil
+}
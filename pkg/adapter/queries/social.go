@@ -0,0 +1,386 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/social"
+	"github.com/dudeiebot/sportPeerGo/pkg/social/model"
+)
+
+const privacyColumns = `user_id, profile, games, location, location_fuzz_km, invites, friend_requests`
+
+func scanPrivacy(row rowScanner, p *model.Privacy) error {
+	return row.Scan(&p.UserID, &p.Profile, &p.Games, &p.Location, &p.LocationFuzzKm, &p.Invites, &p.FriendRequests)
+}
+
+// PrivacyQuery returns the user's privacy settings, or the defaults when they
+// never saved any.
+func PrivacyQuery(ctx context.Context, d *dbs.Service, userID int64) (model.Privacy, error) {
+	return privacy(ctx, d.DB, userID)
+}
+
+func privacy(ctx context.Context, q querier, userID int64) (model.Privacy, error) {
+	p := model.DefaultPrivacy(userID)
+	row := q.QueryRowContext(ctx, `SELECT `+privacyColumns+` FROM privacy_settings WHERE user_id = ?`, userID)
+	if err := scanPrivacy(row, &p); err != nil && err != sql.ErrNoRows {
+		return p, fmt.Errorf("error querying privacy settings: %w", err)
+	}
+	return p, nil
+}
+
+// PrivacyForUsersQuery returns the privacy settings of every user in ids,
+// filling in defaults for those without saved settings.
+func PrivacyForUsersQuery(ctx context.Context, d *dbs.Service, ids []int64) (map[int64]model.Privacy, error) {
+	out := make(map[int64]model.Privacy, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	for _, id := range ids {
+		out[id] = model.DefaultPrivacy(id)
+	}
+	queri := `SELECT ` + privacyColumns + ` FROM privacy_settings WHERE user_id IN (` + placeholders(len(ids)) + `)`
+	rows, err := d.DB.QueryContext(ctx, queri, int64Args(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying privacy settings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p model.Privacy
+		if err := scanPrivacy(rows, &p); err != nil {
+			return nil, fmt.Errorf("error scanning privacy settings: %w", err)
+		}
+		out[p.UserID] = p
+	}
+	return out, rows.Err()
+}
+
+func UpdatePrivacyQuery(ctx context.Context, d *dbs.Service, p model.Privacy) error {
+	queri := `
+		INSERT INTO privacy_settings (` + privacyColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			profile = VALUES(profile), games = VALUES(games), location = VALUES(location),
+			location_fuzz_km = VALUES(location_fuzz_km), invites = VALUES(invites),
+			friend_requests = VALUES(friend_requests)
+	`
+	_, err := d.DB.ExecContext(
+		ctx, queri,
+		p.UserID, p.Profile, p.Games, p.Location, p.LocationFuzzKm, p.Invites, p.FriendRequests,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving privacy settings: %w", err)
+	}
+	return nil
+}
+
+// RelationQuery describes how viewerID relates to userID. A viewerID of 0 is
+// an anonymous visitor.
+func RelationQuery(ctx context.Context, d *dbs.Service, viewerID, userID int64) (social.Relation, error) {
+	return relation(ctx, d.DB, viewerID, userID)
+}
+
+func relation(ctx context.Context, q querier, viewerID, userID int64) (social.Relation, error) {
+	r := social.Relation{Self: viewerID != 0 && viewerID == userID}
+	if viewerID == 0 || r.Self {
+		return r, nil
+	}
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT
+			EXISTS (SELECT 1 FROM friendships WHERE user_id = ? AND friend_id = ?),
+			EXISTS (
+				SELECT 1 FROM user_blocks
+				WHERE (blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)
+			)`,
+		viewerID, userID, viewerID, userID, userID, viewerID,
+	).Scan(&r.Friend, &r.Blocked)
+	if err != nil {
+		return r, fmt.Errorf("error querying friendship: %w", err)
+	}
+	return r, nil
+}
+
+// FriendIDsQuery returns the set of userID's friends.
+func FriendIDsQuery(ctx context.Context, d *dbs.Service, userID int64) (map[int64]bool, error) {
+	rows, err := d.DB.QueryContext(ctx, `SELECT friend_id FROM friendships WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying friends: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning friend: %w", err)
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+// SendFriendRequestQuery asks addresseeID to be requesterID's friend. When
+// addresseeID already asked requesterID, the two simply become friends and
+// accepted is true.
+func SendFriendRequestQuery(ctx context.Context, d *dbs.Service, requesterID, addresseeID int64) (bool, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	fromMe, fromThem, err := lockFriendRequests(ctx, tx, requesterID, addresseeID)
+	if err != nil {
+		return false, err
+	}
+	r, err := relation(ctx, tx, requesterID, addresseeID)
+	if err != nil {
+		return false, err
+	}
+
+	if fromThem && !r.Friend {
+		if err := acceptFriendRequest(ctx, tx, addresseeID, requesterID); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	target, err := privacy(ctx, tx, addresseeID)
+	if err != nil {
+		return false, err
+	}
+	if err := social.CheckRequest(r, fromMe, target); err != nil {
+		return false, err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO friend_requests (requester_id, addressee_id, status) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE status = VALUES(status), created_at = CURRENT_TIMESTAMP`,
+		requesterID, addresseeID, model.RequestPending,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error sending friend request: %w", err)
+	}
+	return false, tx.Commit()
+}
+
+// lockFriendRequests locks the requests between the two users in both
+// directions, always in the same order so concurrent requests cannot
+// deadlock, and reports which of them are pending.
+func lockFriendRequests(ctx context.Context, tx *sql.Tx, a, b int64) (bool, bool, error) {
+	lo, hi := a, b
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT requester_id, status FROM friend_requests
+		WHERE (requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)
+		ORDER BY requester_id
+		FOR UPDATE`,
+		lo, hi, hi, lo,
+	)
+	if err != nil {
+		return false, false, fmt.Errorf("error locking friend requests: %w", err)
+	}
+	defer rows.Close()
+
+	var fromA, fromB bool
+	for rows.Next() {
+		var requester int64
+		var status string
+		if err := rows.Scan(&requester, &status); err != nil {
+			return false, false, fmt.Errorf("error scanning friend request: %w", err)
+		}
+		if status != model.RequestPending {
+			continue
+		}
+		if requester == a {
+			fromA = true
+		} else {
+			fromB = true
+		}
+	}
+	return fromA, fromB, rows.Err()
+}
+
+func acceptFriendRequest(ctx context.Context, tx *sql.Tx, requesterID, addresseeID int64) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`UPDATE friend_requests SET status = ? WHERE requester_id = ? AND addressee_id = ?`,
+		model.RequestAccepted, requesterID, addresseeID,
+	)
+	if err != nil {
+		return fmt.Errorf("error accepting friend request: %w", err)
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT IGNORE INTO friendships (user_id, friend_id) VALUES (?, ?), (?, ?)`,
+		requesterID, addresseeID, addresseeID, requesterID,
+	)
+	if err != nil {
+		return fmt.Errorf("error adding friendship: %w", err)
+	}
+	return nil
+}
+
+// RespondFriendRequestQuery accepts or declines requesterID's pending request
+// to addresseeID.
+func RespondFriendRequestQuery(ctx context.Context, d *dbs.Service, requesterID, addresseeID int64, accept bool) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, fromRequester, err := lockFriendRequests(ctx, tx, addresseeID, requesterID)
+	if err != nil {
+		return err
+	}
+	if !fromRequester {
+		return social.ErrRequestNotFound
+	}
+
+	if accept {
+		err = acceptFriendRequest(ctx, tx, requesterID, addresseeID)
+	} else {
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE friend_requests SET status = ? WHERE requester_id = ? AND addressee_id = ?`,
+			model.RequestDeclined, requesterID, addresseeID,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UnfriendQuery removes the friendship in both directions.
+func UnfriendQuery(ctx context.Context, d *dbs.Service, userID, friendID int64) error {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`DELETE FROM friendships WHERE (user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)`,
+		userID, friendID, friendID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("error removing friendship: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return social.ErrNotFriends
+	}
+	return nil
+}
+
+// FriendsQuery pages through userID's friends by username, with how many
+// friends each of them shares with viewerID.
+func FriendsQuery(ctx context.Context, d *dbs.Service, userID, viewerID int64, limit, offset int) ([]model.Friend, error) {
+	queri := `
+		SELECT f.friend_id, u.username, f.created_at,
+			(SELECT COUNT(*)
+			 FROM friendships a
+			 JOIN friendships b ON b.friend_id = a.friend_id
+			 WHERE a.user_id = f.friend_id AND b.user_id = ?)
+		FROM friendships f
+		JOIN users u ON u.id = f.friend_id
+		WHERE f.user_id = ?
+		ORDER BY u.username, f.friend_id
+		LIMIT ? OFFSET ?
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, viewerID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying friends: %w", err)
+	}
+	defer rows.Close()
+
+	friends := []model.Friend{}
+	for rows.Next() {
+		var f model.Friend
+		if err := rows.Scan(&f.UserID, &f.Username, &f.Since, &f.MutualCount); err != nil {
+			return nil, fmt.Errorf("error scanning friend: %w", err)
+		}
+		friends = append(friends, f)
+	}
+	return friends, rows.Err()
+}
+
+func MutualFriendsCountQuery(ctx context.Context, d *dbs.Service, a, b int64) (int, error) {
+	var count int
+	err := d.DB.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM friendships x JOIN friendships y ON y.friend_id = x.friend_id
+		WHERE x.user_id = ? AND y.user_id = ?`,
+		a, b,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting mutual friends: %w", err)
+	}
+	return count, nil
+}
+
+// FriendRequestsQuery lists pending requests sent to userID, or sent by them
+// when outgoing is true. Username is the other user's.
+func FriendRequestsQuery(ctx context.Context, d *dbs.Service, userID int64, outgoing bool) ([]model.FriendRequest, error) {
+	queri := `
+		SELECT r.requester_id, r.addressee_id, u.username, r.status, r.created_at
+		FROM friend_requests r
+		JOIN users u ON u.id = r.requester_id
+		WHERE r.addressee_id = ? AND r.status = ?
+		ORDER BY r.created_at
+	`
+	if outgoing {
+		queri = `
+			SELECT r.requester_id, r.addressee_id, u.username, r.status, r.created_at
+			FROM friend_requests r
+			JOIN users u ON u.id = r.addressee_id
+			WHERE r.requester_id = ? AND r.status = ?
+			ORDER BY r.created_at
+		`
+	}
+	rows, err := d.DB.QueryContext(ctx, queri, userID, model.RequestPending)
+	if err != nil {
+		return nil, fmt.Errorf("error querying friend requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []model.FriendRequest{}
+	for rows.Next() {
+		var r model.FriendRequest
+		if err := rows.Scan(&r.RequesterID, &r.AddresseeID, &r.Username, &r.Status, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning friend request: %w", err)
+		}
+		requests = append(requests, r)
+	}
+	return requests, rows.Err()
+}
+
+// CheckInvitesQuery returns social.ErrInviteNotAllowed naming the first of
+// userIDs whose privacy settings do not accept invites from inviterID.
+func CheckInvitesQuery(ctx context.Context, d *dbs.Service, inviterID int64, userIDs []int64) error {
+	settings, err := PrivacyForUsersQuery(ctx, d, userIDs)
+	if err != nil {
+		return err
+	}
+	friends, err := FriendIDsQuery(ctx, d, inviterID)
+	if err != nil {
+		return err
+	}
+	blocked, err := BlockedIDsQuery(ctx, d, inviterID)
+	if err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		r := social.Relation{Self: id == inviterID, Friend: friends[id], Blocked: blocked[id]}
+		if !social.Allows(settings[id].Invites, r) {
+			return fmt.Errorf("user %d: %w", id, social.ErrInviteNotAllowed)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,408 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/team"
+	"github.com/dudeiebot/sportPeerGo/pkg/team/model"
+)
+
+const teamColumns = `
+	t.id, t.name, t.sport, t.kind, t.description, t.owner_id, t.created_at,
+	(SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id)
+`
+
+func scanTeam(row rowScanner) (*model.Team, error) {
+	var t model.Team
+	err := row.Scan(
+		&t.ID, &t.Name, &t.Sport, &t.Kind, &t.Description, &t.OwnerID, &t.CreatedAt,
+		&t.MemberCount,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, team.ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("error scanning team: %w", err)
+	}
+	return &t, nil
+}
+
+func CreateTeamQuery(ctx context.Context, d *dbs.Service, t model.Team) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO teams (name, sport, kind, description, owner_id) VALUES (?, ?, ?, ?, ?)`,
+		t.Name, t.Sport, t.Kind, t.Description, t.OwnerID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting team: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO team_members (team_id, user_id, role) VALUES (?, ?, ?)`,
+		id, t.OwnerID, model.RoleOwner,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error adding team owner: %w", err)
+	}
+	return id, tx.Commit()
+}
+
+// GetTeamQuery loads the team profile together with its roster.
+func GetTeamQuery(ctx context.Context, d *dbs.Service, id int64) (*model.Team, error) {
+	t, err := scanTeam(d.DB.QueryRowContext(ctx, `SELECT `+teamColumns+` FROM teams t WHERE t.id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	t.Members, err = TeamMembersQuery(ctx, d, id)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func ListTeamsQuery(ctx context.Context, d *dbs.Service, f model.Filter) ([]model.Team, error) {
+	queri := `SELECT ` + teamColumns + ` FROM teams t WHERE 1 = 1`
+	var args []interface{}
+	if f.Sport != "" {
+		queri += ` AND t.sport = ?`
+		args = append(args, f.Sport)
+	}
+	if f.Name != "" {
+		queri += ` AND t.name LIKE ?`
+		args = append(args, "%"+f.Name+"%")
+	}
+	queri += ` ORDER BY t.name, t.id LIMIT ? OFFSET ?`
+	args = append(args, f.Limit, f.Offset)
+	return queryTeams(ctx, d, queri, args...)
+}
+
+func UserTeamsQuery(ctx context.Context, d *dbs.Service, userID int64) ([]model.Team, error) {
+	queri := `
+		SELECT ` + teamColumns + `
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.id
+		WHERE m.user_id = ?
+		ORDER BY t.name, t.id
+	`
+	return queryTeams(ctx, d, queri, userID)
+}
+
+func queryTeams(ctx context.Context, d *dbs.Service, queri string, args ...interface{}) ([]model.Team, error) {
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []model.Team{}
+	for rows.Next() {
+		t, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, *t)
+	}
+	return teams, rows.Err()
+}
+
+func UpdateTeamQuery(ctx context.Context, d *dbs.Service, t model.Team) error {
+	_, err := d.DB.ExecContext(
+		ctx,
+		`UPDATE teams SET name = ?, sport = ?, kind = ?, description = ? WHERE id = ?`,
+		t.Name, t.Sport, t.Kind, t.Description, t.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating team: %w", err)
+	}
+	return nil
+}
+
+func TeamMembersQuery(ctx context.Context, d *dbs.Service, teamID int64) ([]model.Member, error) {
+	queri := `
+		SELECT m.team_id, m.user_id, u.username, m.role, m.joined_at
+		FROM team_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.team_id = ?
+		ORDER BY FIELD(m.role, 'owner', 'captain', 'member'), m.joined_at, m.user_id
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []model.Member{}
+	for rows.Next() {
+		var m model.Member
+		if err := rows.Scan(&m.TeamID, &m.UserID, &m.Username, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("error scanning team member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// TeamMemberQuery returns userID's membership of the team, or
+// team.ErrNotMember.
+func TeamMemberQuery(ctx context.Context, d *dbs.Service, teamID, userID int64) (*model.Member, error) {
+	return teamMember(ctx, d.DB, teamID, userID, false)
+}
+
+func teamMember(ctx context.Context, q querier, teamID, userID int64, lock bool) (*model.Member, error) {
+	queri := `SELECT team_id, user_id, role, joined_at FROM team_members WHERE team_id = ? AND user_id = ?`
+	if lock {
+		queri += ` FOR UPDATE`
+	}
+	var m model.Member
+	err := q.QueryRowContext(ctx, queri, teamID, userID).Scan(&m.TeamID, &m.UserID, &m.Role, &m.JoinedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, team.ErrNotMember
+		}
+		return nil, fmt.Errorf("error querying team member: %w", err)
+	}
+	return &m, nil
+}
+
+// lockTeam takes the team row lock that serializes every roster change, then
+// reads the two memberships involved.
+func lockTeam(ctx context.Context, tx *sql.Tx, teamID, actorID, targetID int64) (*model.Member, *model.Member, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM teams WHERE id = ? FOR UPDATE`, teamID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, team.ErrTeamNotFound
+		}
+		return nil, nil, fmt.Errorf("error locking team: %w", err)
+	}
+	actor, err := teamMember(ctx, tx, teamID, actorID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := teamMember(ctx, tx, teamID, targetID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return actor, target, nil
+}
+
+func setTeamRole(ctx context.Context, tx *sql.Tx, m *model.Member) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`UPDATE team_members SET role = ? WHERE team_id = ? AND user_id = ?`,
+		m.Role, m.TeamID, m.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating team role: %w", err)
+	}
+	return nil
+}
+
+// InviteToTeamQuery invites users who are not already on the team. Declined
+// invites are reopened.
+func InviteToTeamQuery(ctx context.Context, d *dbs.Service, teamID, invitedBy int64, userIDs []int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queri := `
+		INSERT INTO team_invites (team_id, user_id, invited_by, status)
+		SELECT ?, ?, ?, ?
+		FROM DUAL
+		WHERE NOT EXISTS (SELECT 1 FROM team_members WHERE team_id = ? AND user_id = ?)
+		ON DUPLICATE KEY UPDATE invited_by = VALUES(invited_by), status = VALUES(status)
+	`
+	for _, id := range userIDs {
+		_, err := tx.ExecContext(ctx, queri, teamID, id, invitedBy, model.InvitePending, teamID, id)
+		if err != nil {
+			return fmt.Errorf("error inviting user %d: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func PendingTeamInvitesQuery(ctx context.Context, d *dbs.Service, userID int64) ([]model.Invite, error) {
+	queri := `
+		SELECT i.team_id, t.name, i.user_id, i.invited_by, i.status, i.created_at
+		FROM team_invites i
+		JOIN teams t ON t.id = i.team_id
+		WHERE i.user_id = ? AND i.status = ?
+		ORDER BY i.created_at
+	`
+	rows, err := d.DB.QueryContext(ctx, queri, userID, model.InvitePending)
+	if err != nil {
+		return nil, fmt.Errorf("error querying team invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []model.Invite{}
+	for rows.Next() {
+		var i model.Invite
+		if err := rows.Scan(&i.TeamID, &i.TeamName, &i.UserID, &i.InvitedBy, &i.Status, &i.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning team invite: %w", err)
+		}
+		invites = append(invites, i)
+	}
+	return invites, rows.Err()
+}
+
+// RespondTeamInviteQuery accepts or declines userID's pending invite. An
+// accepted invite adds them to the team as a member.
+func RespondTeamInviteQuery(ctx context.Context, d *dbs.Service, teamID, userID int64, accept bool) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT status FROM team_invites WHERE team_id = ? AND user_id = ? FOR UPDATE`,
+		teamID, userID,
+	).Scan(&status)
+	if err == sql.ErrNoRows || (err == nil && status != model.InvitePending) {
+		return team.ErrInviteNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error querying team invite: %w", err)
+	}
+
+	status = model.InviteDeclined
+	if accept {
+		status = model.InviteAccepted
+		if _, err := teamMember(ctx, tx, teamID, userID, true); err == nil {
+			return team.ErrAlreadyMember
+		} else if err != team.ErrNotMember {
+			return err
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO team_members (team_id, user_id, role) VALUES (?, ?, ?)`,
+			teamID, userID, model.RoleMember,
+		)
+		if err != nil {
+			return fmt.Errorf("error adding team member: %w", err)
+		}
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE team_invites SET status = ? WHERE team_id = ? AND user_id = ?`,
+		status, teamID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating team invite: %w", err)
+	}
+	return tx.Commit()
+}
+
+func LeaveTeamQuery(ctx context.Context, d *dbs.Service, teamID, userID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	m, _, err := lockTeam(ctx, tx, teamID, userID, userID)
+	if err != nil {
+		return err
+	}
+	if err := team.CheckLeave(*m); err != nil {
+		return err
+	}
+	if err := removeTeamMember(ctx, tx, teamID, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// KickTeamMemberQuery removes targetID from the team. Roles are re-read under
+// the team lock, so a demotion racing with the kick is respected.
+func KickTeamMemberQuery(ctx context.Context, d *dbs.Service, teamID, actorID, targetID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	actor, target, err := lockTeam(ctx, tx, teamID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+	if err := team.CheckKick(*actor, *target); err != nil {
+		return err
+	}
+	if err := removeTeamMember(ctx, tx, teamID, targetID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func removeTeamMember(ctx context.Context, tx *sql.Tx, teamID, userID int64) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = ? AND user_id = ?`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("error removing team member: %w", err)
+	}
+	return nil
+}
+
+func SetTeamRoleQuery(ctx context.Context, d *dbs.Service, teamID, actorID, targetID int64, role string) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	actor, target, err := lockTeam(ctx, tx, teamID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+	if err := team.CheckSetRole(*actor, *target, role); err != nil {
+		return err
+	}
+	target.Role = role
+	if err := setTeamRole(ctx, tx, target); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func TransferTeamQuery(ctx context.Context, d *dbs.Service, teamID, ownerID, newOwnerID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	owner, target, err := lockTeam(ctx, tx, teamID, ownerID, newOwnerID)
+	if err != nil {
+		return err
+	}
+	if err := team.Transfer(owner, target); err != nil {
+		return err
+	}
+	for _, m := range []*model.Member{owner, target} {
+		if err := setTeamRole(ctx, tx, m); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE teams SET owner_id = ? WHERE id = ?`, newOwnerID, teamID); err != nil {
+		return fmt.Errorf("error updating team owner: %w", err)
+	}
+	return tx.Commit()
+}
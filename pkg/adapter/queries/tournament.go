@@ -0,0 +1,649 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/rating"
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/tournament"
+	"github.com/dudeiebot/sportPeerGo/pkg/tournament/model"
+)
+
+const tournamentColumns = `
+	id, organizer_id, name, sport, format, entrant_type, seeding, max_entrants, group_size,
+	status, starts_at, champion_id, created_at
+`
+
+func scanTournament(row rowScanner) (*model.Tournament, error) {
+	var t model.Tournament
+	var champion sql.NullInt64
+	err := row.Scan(
+		&t.ID, &t.OrganizerID, &t.Name, &t.Sport, &t.Format, &t.EntrantType, &t.Seeding,
+		&t.MaxEntrants, &t.GroupSize, &t.Status, &t.StartsAt, &champion, &t.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tournament.ErrTournamentNotFound
+		}
+		return nil, fmt.Errorf("error scanning tournament: %w", err)
+	}
+	if champion.Valid {
+		t.ChampionID = &champion.Int64
+	}
+	return &t, nil
+}
+
+func lockTournament(ctx context.Context, tx *sql.Tx, id int64) (*model.Tournament, error) {
+	return scanTournament(tx.QueryRowContext(
+		ctx,
+		`SELECT `+tournamentColumns+` FROM tournaments WHERE id = ? FOR UPDATE`,
+		id,
+	))
+}
+
+func CreateTournamentQuery(ctx context.Context, d *dbs.Service, t model.Tournament) (int64, error) {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`INSERT INTO tournaments
+			(organizer_id, name, sport, format, entrant_type, seeding, max_entrants, group_size, status, starts_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.OrganizerID, t.Name, t.Sport, t.Format, t.EntrantType, t.Seeding, t.MaxEntrants, t.GroupSize,
+		model.StatusRegistration, t.StartsAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting tournament: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func GetTournamentQuery(ctx context.Context, d *dbs.Service, id int64) (*model.Tournament, error) {
+	return scanTournament(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+tournamentColumns+` FROM tournaments WHERE id = ?`,
+		id,
+	))
+}
+
+func ListTournamentsQuery(ctx context.Context, d *dbs.Service, f model.Filter) ([]model.Tournament, error) {
+	queri := `SELECT ` + tournamentColumns + ` FROM tournaments WHERE 1 = 1`
+	var args []interface{}
+	if f.Sport != "" {
+		queri += ` AND sport = ?`
+		args = append(args, f.Sport)
+	}
+	if f.Status != "" {
+		queri += ` AND status = ?`
+		args = append(args, f.Status)
+	}
+	queri += ` ORDER BY starts_at, id LIMIT ? OFFSET ?`
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tournaments: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.Tournament{}
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, rows.Err()
+}
+
+const entrantColumns = `
+	e.id, e.tournament_id, e.user_id, e.team_id, COALESCE(u.username, tm.name, ''), e.seed, e.registered_at
+	FROM tournament_entrants e
+	LEFT JOIN users u ON u.id = e.user_id
+	LEFT JOIN teams tm ON tm.id = e.team_id
+`
+
+func scanEntrant(row rowScanner) (*model.Entrant, error) {
+	var e model.Entrant
+	var userID, teamID sql.NullInt64
+	err := row.Scan(&e.ID, &e.TournamentID, &userID, &teamID, &e.Name, &e.Seed, &e.RegisteredAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tournament.ErrNotRegistered
+		}
+		return nil, fmt.Errorf("error scanning entrant: %w", err)
+	}
+	if userID.Valid {
+		e.UserID = &userID.Int64
+	}
+	if teamID.Valid {
+		e.TeamID = &teamID.Int64
+	}
+	return &e, nil
+}
+
+// tournamentEntrants lists the entrants of a tournament in registration
+// order, which is the order Seed expects.
+func tournamentEntrants(ctx context.Context, q querier, tournamentID int64) ([]model.Entrant, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT `+entrantColumns+` WHERE e.tournament_id = ? ORDER BY e.registered_at, e.id`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying entrants: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.Entrant{}
+	for rows.Next() {
+		e, err := scanEntrant(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
+
+func EntrantsQuery(ctx context.Context, d *dbs.Service, tournamentID int64) ([]model.Entrant, error) {
+	return tournamentEntrants(ctx, d.DB, tournamentID)
+}
+
+func EntrantQuery(ctx context.Context, d *dbs.Service, tournamentID, entrantID int64) (*model.Entrant, error) {
+	return scanEntrant(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+entrantColumns+` WHERE e.tournament_id = ? AND e.id = ?`,
+		tournamentID, entrantID,
+	))
+}
+
+// RegisterEntrantQuery enters a player or a team, whichever of e's UserID
+// and TeamID is set, while the tournament is open and has room.
+func RegisterEntrantQuery(ctx context.Context, d *dbs.Service, e model.Entrant) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	t, err := lockTournament(ctx, tx, e.TournamentID)
+	if err != nil {
+		return 0, err
+	}
+	if (t.EntrantType == model.EntrantTeam) != (e.TeamID != nil) {
+		return 0, tournament.ErrWrongEntrantType
+	}
+	var count int
+	var exists bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*), COALESCE(SUM(user_id = ? OR team_id = ?), 0) > 0
+		FROM tournament_entrants WHERE tournament_id = ?`,
+		e.UserID, e.TeamID, e.TournamentID,
+	).Scan(&count, &exists)
+	if err != nil {
+		return 0, fmt.Errorf("error counting entrants: %w", err)
+	}
+	if exists {
+		return 0, tournament.ErrAlreadyRegistered
+	}
+	if err := tournament.CanRegister(*t, count, time.Now()); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO tournament_entrants (tournament_id, user_id, team_id) VALUES (?, ?, ?)`,
+		e.TournamentID, e.UserID, e.TeamID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting entrant: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// WithdrawEntrantQuery removes an entrant before the tournament starts.
+func WithdrawEntrantQuery(ctx context.Context, d *dbs.Service, tournamentID, entrantID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.Status != model.StatusRegistration {
+		return tournament.ErrAlreadyStarted
+	}
+	res, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM tournament_entrants WHERE tournament_id = ? AND id = ?`,
+		tournamentID, entrantID,
+	)
+	if err != nil {
+		return fmt.Errorf("error removing entrant: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return tournament.ErrNotRegistered
+	}
+	return tx.Commit()
+}
+
+// SetSeedsQuery applies the organizer's seeds, keyed by entrant ID, on top
+// of the seeds already set. A seed of 0 clears one.
+func SetSeedsQuery(ctx context.Context, d *dbs.Service, tournamentID int64, seeds map[int64]int) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.Status != model.StatusRegistration {
+		return tournament.ErrAlreadyStarted
+	}
+	entrants, err := tournamentEntrants(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	merged := make(map[int64]int, len(entrants))
+	for _, e := range entrants {
+		merged[e.ID] = e.Seed
+	}
+	for id, seed := range seeds {
+		if _, ok := merged[id]; !ok {
+			return tournament.ErrNotRegistered
+		}
+		merged[id] = seed
+	}
+	if err := tournament.ValidSeeds(merged, len(entrants)); err != nil {
+		return err
+	}
+
+	for id, seed := range seeds {
+		_, err := tx.ExecContext(ctx, `UPDATE tournament_entrants SET seed = ? WHERE id = ?`, seed, id)
+		if err != nil {
+			return fmt.Errorf("error seeding entrant: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// entrantRatings returns the rating of each entrant in sport: the player's
+// own, or the mean of a team's members, with unrated players counting as a
+// default rating.
+func entrantRatings(ctx context.Context, q querier, tournamentID int64, sport string) (map[int64]float64, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT e.id, COALESCE(
+			(SELECT pr.rating FROM player_ratings pr WHERE pr.user_id = e.user_id AND pr.sport = ?),
+			(SELECT AVG(COALESCE(pr.rating, ?))
+			 FROM team_members tm
+			 LEFT JOIN player_ratings pr ON pr.user_id = tm.user_id AND pr.sport = ?
+			 WHERE tm.team_id = e.team_id),
+			?)
+		FROM tournament_entrants e WHERE e.tournament_id = ?`,
+		sport, rating.DefaultRating, sport, rating.DefaultRating, tournamentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying entrant ratings: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64]float64)
+	for rows.Next() {
+		var id int64
+		var r float64
+		if err := rows.Scan(&id, &r); err != nil {
+			return nil, fmt.Errorf("error scanning entrant rating: %w", err)
+		}
+		out[id] = r
+	}
+	return out, rows.Err()
+}
+
+// StartTournamentQuery closes registration, seeds the entrants and stores
+// the draw.
+func StartTournamentQuery(ctx context.Context, d *dbs.Service, tournamentID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.Status != model.StatusRegistration {
+		return tournament.ErrAlreadyStarted
+	}
+	entrants, err := tournamentEntrants(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	var ratings map[int64]float64
+	if t.Seeding == model.SeedingRating {
+		ratings, err = entrantRatings(ctx, tx, tournamentID, t.Sport)
+		if err != nil {
+			return err
+		}
+	}
+	entrants = tournament.Seed(entrants, t.Seeding, ratings)
+	matches, err := tournament.Generate(*t, entrants)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entrants {
+		_, err := tx.ExecContext(ctx, `UPDATE tournament_entrants SET seed = ? WHERE id = ?`, e.Seed, e.ID)
+		if err != nil {
+			return fmt.Errorf("error seeding entrant: %w", err)
+		}
+	}
+	// Rosters are fixed at the start, so results and wins are credited to
+	// the players who entered even if a team changes later.
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO tournament_entrant_players (entrant_id, user_id)
+		SELECT id, user_id FROM tournament_entrants WHERE tournament_id = ? AND user_id IS NOT NULL
+		UNION
+		SELECT e.id, tm.user_id FROM tournament_entrants e
+		JOIN team_members tm ON tm.team_id = e.team_id
+		WHERE e.tournament_id = ?`,
+		tournamentID, tournamentID,
+	)
+	if err != nil {
+		return fmt.Errorf("error storing entrant players: %w", err)
+	}
+
+	// Matches link to each other, so insert them all first and then point
+	// the links at the stored IDs.
+	ids := make(map[int64]int64, len(matches))
+	for _, m := range matches {
+		res, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO tournament_matches
+				(tournament_id, bracket, round, position, group_no, entrant_a, entrant_b, winner_id, status)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			tournamentID, m.Bracket, m.Round, m.Position, m.Group, m.EntrantA, m.EntrantB, m.WinnerID, m.Status,
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting tournament match: %w", err)
+		}
+		if ids[m.ID], err = res.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	for _, m := range matches {
+		if m.NextMatchID == nil && m.LoserMatchID == nil {
+			continue
+		}
+		var next, loser *int64
+		if m.NextMatchID != nil {
+			id := ids[*m.NextMatchID]
+			next = &id
+		}
+		if m.LoserMatchID != nil {
+			id := ids[*m.LoserMatchID]
+			loser = &id
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE tournament_matches
+			SET next_match_id = ?, next_slot = ?, loser_match_id = ?, loser_slot = ?
+			WHERE id = ?`,
+			next, m.NextSlot, loser, m.LoserSlot, ids[m.ID],
+		)
+		if err != nil {
+			return fmt.Errorf("error linking tournament match: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE tournaments SET status = ? WHERE id = ?`,
+		model.StatusInProgress, tournamentID,
+	)
+	if err != nil {
+		return fmt.Errorf("error starting tournament: %w", err)
+	}
+	return tx.Commit()
+}
+
+const matchColumns = `
+	id, tournament_id, bracket, round, position, group_no, entrant_a, entrant_b, winner_id, status,
+	game_id, next_match_id, next_slot, loser_match_id, loser_slot
+`
+
+func scanMatch(row rowScanner) (*model.Match, error) {
+	var m model.Match
+	var a, b, winner, game, next, loser sql.NullInt64
+	err := row.Scan(
+		&m.ID, &m.TournamentID, &m.Bracket, &m.Round, &m.Position, &m.Group, &a, &b, &winner, &m.Status,
+		&game, &next, &m.NextSlot, &loser, &m.LoserSlot,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tournament.ErrMatchNotFound
+		}
+		return nil, fmt.Errorf("error scanning tournament match: %w", err)
+	}
+	for _, field := range []struct {
+		v    sql.NullInt64
+		dest **int64
+	}{
+		{a, &m.EntrantA},
+		{b, &m.EntrantB},
+		{winner, &m.WinnerID},
+		{game, &m.GameID},
+		{next, &m.NextMatchID},
+		{loser, &m.LoserMatchID},
+	} {
+		if field.v.Valid {
+			id := field.v.Int64
+			*field.dest = &id
+		}
+	}
+	return &m, nil
+}
+
+func tournamentMatches(ctx context.Context, q querier, tournamentID int64, lock bool) ([]model.Match, error) {
+	queri := `SELECT ` + matchColumns + ` FROM tournament_matches WHERE tournament_id = ? ORDER BY id`
+	if lock {
+		queri += ` FOR UPDATE`
+	}
+	rows, err := q.QueryContext(ctx, queri, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tournament matches: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.Match{}
+	for rows.Next() {
+		m, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *m)
+	}
+	return out, rows.Err()
+}
+
+func TournamentMatchesQuery(ctx context.Context, d *dbs.Service, tournamentID int64) ([]model.Match, error) {
+	return tournamentMatches(ctx, d.DB, tournamentID, false)
+}
+
+// MatchByGameQuery finds the tournament match played as gameID.
+func MatchByGameQuery(ctx context.Context, d *dbs.Service, gameID int64) (*model.Match, error) {
+	return scanMatch(d.DB.QueryRowContext(
+		ctx,
+		`SELECT `+matchColumns+` FROM tournament_matches WHERE game_id = ?`,
+		gameID,
+	))
+}
+
+// UndecidedMatchGamesQuery returns the games of ready tournament matches
+// whose result is already final, oldest result first. Their advance failed
+// when the result came in and is due another try.
+func UndecidedMatchGamesQuery(ctx context.Context, d *dbs.Service, limit int) ([]int64, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT m.game_id
+		FROM tournament_matches m
+		JOIN match_results r ON r.game_id = m.game_id
+		WHERE m.status = ? AND r.status IN (?, ?)
+		ORDER BY r.updated_at, r.id
+		LIMIT ?`,
+		model.MatchReady, resultmodel.StatusConfirmed, resultmodel.StatusResolved, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying undecided matches: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning undecided match: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// LinkMatchGameQuery has a ready match played as gameID, so that the game's
+// confirmed result decides it. A game plays at most one match.
+func LinkMatchGameQuery(ctx context.Context, d *dbs.Service, tournamentID, matchID, gameID int64) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	m, err := scanMatch(tx.QueryRowContext(
+		ctx,
+		`SELECT `+matchColumns+` FROM tournament_matches WHERE tournament_id = ? AND id = ? FOR UPDATE`,
+		tournamentID, matchID,
+	))
+	if err != nil {
+		return err
+	}
+	if m.GameID != nil {
+		return tournament.ErrMatchLinked
+	}
+	if m.Status != model.MatchReady {
+		return tournament.ErrMatchNotReady
+	}
+	var taken bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM tournament_matches WHERE game_id = ?)`,
+		gameID,
+	).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("error checking linked games: %w", err)
+	}
+	if taken {
+		return tournament.ErrMatchLinked
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE tournament_matches SET game_id = ? WHERE id = ?`, gameID, matchID)
+	if err != nil {
+		return fmt.Errorf("error linking game: %w", err)
+	}
+	return tx.Commit()
+}
+
+// CompleteMatchQuery records the winner of a match, or a draw when winnerID
+// is nil, and advances the draw. When the last match is decided the
+// tournament is completed and its champion recorded. It returns the
+// matches that changed.
+func CompleteMatchQuery(
+	ctx context.Context,
+	d *dbs.Service,
+	tournamentID, matchID int64,
+	winnerID *int64,
+) ([]model.Match, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != model.StatusInProgress {
+		return nil, tournament.ErrNotStarted
+	}
+	matches, err := tournamentMatches(ctx, tx, tournamentID, true)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := tournament.Complete(matches, matchID, winnerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range changed {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE tournament_matches SET entrant_a = ?, entrant_b = ?, winner_id = ?, status = ? WHERE id = ?`,
+			m.EntrantA, m.EntrantB, m.WinnerID, m.Status, m.ID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error updating tournament match: %w", err)
+		}
+	}
+
+	if done, champion := tournament.Finished(matches); done {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE tournaments SET status = ?, champion_id = ? WHERE id = ?`,
+			model.StatusCompleted, champion, tournamentID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error completing tournament: %w", err)
+		}
+	}
+	return changed, tx.Commit()
+}
+
+// EntrantPlayersQuery returns the users who play for an entrant: the player
+// themselves, or the members of the team when the tournament started.
+func EntrantPlayersQuery(ctx context.Context, d *dbs.Service, entrantID int64) (map[int64]bool, error) {
+	rows, err := d.DB.QueryContext(
+		ctx,
+		`SELECT user_id FROM tournament_entrant_players WHERE entrant_id = ?`,
+		entrantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying entrant players: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning entrant player: %w", err)
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
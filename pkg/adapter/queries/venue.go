@@ -0,0 +1,305 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+const venueColumns = `
+	v.id, v.name, v.address, v.latitude, v.longitude, v.timezone, v.opening_hours,
+	v.surfaces, v.amenities, v.pricing_notes, v.cancellation_policy, v.status, v.submitted_by, COALESCE(v.review_note, '')
+`
+
+func scanVenue(row rowScanner) (*model.Venue, error) {
+	var v model.Venue
+	var hours, surfaces, amenities []byte
+	err := row.Scan(
+		&v.ID, &v.Name, &v.Address, &v.Location.Lat, &v.Location.Lng, &v.Timezone, &hours,
+		&surfaces, &amenities, &v.PricingNotes, &v.CancellationPolicy, &v.Status, &v.SubmittedBy, &v.ReviewNote,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, venue.ErrVenueNotFound
+		}
+		return nil, fmt.Errorf("error scanning venue: %w", err)
+	}
+	for _, field := range []struct {
+		raw  []byte
+		dest interface{}
+	}{
+		{hours, &v.OpeningHours},
+		{surfaces, &v.Surfaces},
+		{amenities, &v.Amenities},
+	} {
+		if len(field.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(field.raw, field.dest); err != nil {
+			return nil, fmt.Errorf("error decoding venue %d: %w", v.ID, err)
+		}
+	}
+	return &v, nil
+}
+
+func CreateVenueQuery(ctx context.Context, d *dbs.Service, v model.Venue) (int64, error) {
+	hours, surfaces, amenities, err := encodeVenueLists(v)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queri := `
+		INSERT INTO venues
+			(name, address, latitude, longitude, timezone, opening_hours,
+			 surfaces, amenities, pricing_notes, cancellation_policy, status, submitted_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := tx.ExecContext(
+		ctx, queri,
+		v.Name, v.Address, v.Location.Lat, v.Location.Lng, v.Timezone, hours,
+		surfaces, amenities, v.PricingNotes, v.CancellationPolicy, v.Status, v.SubmittedBy,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting venue: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, c := range v.Courts {
+		if _, err := insertCourt(ctx, tx, id, c); err != nil {
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+func insertCourt(ctx context.Context, q querier, venueID int64, c model.Court) (int64, error) {
+	res, err := q.ExecContext(
+		ctx,
+		`INSERT INTO venue_courts (venue_id, name, sport, surface, active) VALUES (?, ?, ?, ?, TRUE)`,
+		venueID, c.Name, c.Sport, c.Surface,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting court: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func encodeVenueLists(v model.Venue) ([]byte, []byte, []byte, error) {
+	hours, err := json.Marshal(v.OpeningHours)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	surfaces, err := json.Marshal(v.Surfaces)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	amenities, err := json.Marshal(v.Amenities)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return hours, surfaces, amenities, nil
+}
+
+func GetVenueQuery(ctx context.Context, d *dbs.Service, id int64) (*model.Venue, error) {
+	v, err := scanVenue(d.DB.QueryRowContext(ctx, `SELECT `+venueColumns+` FROM venues v WHERE v.id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	courts, err := courtsForVenues(ctx, d.DB, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	v.Courts = courts[id]
+	return v, nil
+}
+
+// venueSearchCap caps a venue search without a location, which has no
+// bounding box to narrow it.
+const venueSearchCap = 100
+
+// SearchVenuesQuery loads approved venues, pre-filtered to the bounding box
+// of the search radius and to venues with a court for the sport. Exact
+// distances and ordering are left to venue.Search. Without a location the
+// first page by name is all venue.Search would return, so only that is
+// loaded.
+func SearchVenuesQuery(ctx context.Context, d *dbs.Service, f model.Filter) ([]model.Venue, error) {
+	queri := `SELECT ` + venueColumns + ` FROM venues v WHERE v.status = ?`
+	args := []interface{}{model.StatusApproved}
+	if f.Near != nil {
+		radius := f.RadiusKm
+		if radius <= 0 {
+			radius = venue.DefaultRadiusKm
+		}
+		min, max := geo.BoundingBox(*f.Near, radius)
+		queri += ` AND v.latitude BETWEEN ? AND ? AND v.longitude BETWEEN ? AND ?`
+		args = append(args, min.Lat, max.Lat, min.Lng, max.Lng)
+	}
+	if f.Sport != "" {
+		queri += ` AND EXISTS (SELECT 1 FROM venue_courts c WHERE c.venue_id = v.id AND c.active AND c.sport = ?)`
+		args = append(args, f.Sport)
+	}
+	if f.Near == nil {
+		limit := f.Limit
+		if limit <= 0 || limit > venueSearchCap {
+			limit = venueSearchCap
+		}
+		queri += ` ORDER BY v.name, v.id LIMIT ?`
+		args = append(args, limit)
+	}
+	return queryVenues(ctx, d, queri, args...)
+}
+
+func PendingVenuesQuery(ctx context.Context, d *dbs.Service) ([]model.Venue, error) {
+	queri := `SELECT ` + venueColumns + ` FROM venues v WHERE v.status = ? ORDER BY v.id`
+	return queryVenues(ctx, d, queri, model.StatusPending)
+}
+
+func queryVenues(ctx context.Context, d *dbs.Service, queri string, args ...interface{}) ([]model.Venue, error) {
+	rows, err := d.DB.QueryContext(ctx, queri, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying venues: %w", err)
+	}
+	defer rows.Close()
+
+	venues := []model.Venue{}
+	var ids []int64
+	for rows.Next() {
+		v, err := scanVenue(rows)
+		if err != nil {
+			return nil, err
+		}
+		venues = append(venues, *v)
+		ids = append(ids, v.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return venues, nil
+	}
+
+	courts, err := courtsForVenues(ctx, d.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range venues {
+		venues[i].Courts = courts[venues[i].ID]
+	}
+	return venues, nil
+}
+
+func courtsForVenues(ctx context.Context, q querier, ids []int64) (map[int64][]model.Court, error) {
+	queri := `
+		SELECT id, venue_id, name, sport, surface, active
+		FROM venue_courts
+		WHERE venue_id IN (` + placeholders(len(ids)) + `)
+		ORDER BY id
+	`
+	rows, err := q.QueryContext(ctx, queri, int64Args(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying courts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64][]model.Court)
+	for rows.Next() {
+		var c model.Court
+		if err := rows.Scan(&c.ID, &c.VenueID, &c.Name, &c.Sport, &c.Surface, &c.Active); err != nil {
+			return nil, fmt.Errorf("error scanning court: %w", err)
+		}
+		out[c.VenueID] = append(out[c.VenueID], c)
+	}
+	return out, rows.Err()
+}
+
+func UpdateVenueQuery(ctx context.Context, d *dbs.Service, v model.Venue) error {
+	hours, surfaces, amenities, err := encodeVenueLists(v)
+	if err != nil {
+		return err
+	}
+	queri := `
+		UPDATE venues
+		SET name = ?, address = ?, latitude = ?, longitude = ?, timezone = ?, opening_hours = ?,
+			surfaces = ?, amenities = ?, pricing_notes = ?, cancellation_policy = ?
+		WHERE id = ?
+	`
+	_, err = d.DB.ExecContext(
+		ctx, queri,
+		v.Name, v.Address, v.Location.Lat, v.Location.Lng, v.Timezone, hours,
+		surfaces, amenities, v.PricingNotes, v.CancellationPolicy, v.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating venue: %w", err)
+	}
+	return nil
+}
+
+func AddCourtQuery(ctx context.Context, d *dbs.Service, venueID int64, c model.Court) (int64, error) {
+	return insertCourt(ctx, d.DB, venueID, c)
+}
+
+// DeactivateCourtQuery hides a court from new bookings and searches while
+// keeping it for the history of past games.
+func DeactivateCourtQuery(ctx context.Context, d *dbs.Service, venueID, courtID int64) error {
+	res, err := d.DB.ExecContext(
+		ctx,
+		`UPDATE venue_courts SET active = FALSE WHERE id = ? AND venue_id = ?`,
+		courtID, venueID,
+	)
+	if err != nil {
+		return fmt.Errorf("error deactivating court: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return venue.ErrCourtNotFound
+	}
+	return nil
+}
+
+func ReviewVenueQuery(ctx context.Context, d *dbs.Service, id int64, approve bool, note string) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	v, err := scanVenue(tx.QueryRowContext(ctx, `SELECT `+venueColumns+` FROM venues v WHERE v.id = ? FOR UPDATE`, id))
+	if err != nil {
+		return err
+	}
+	if err := venue.Review(v, approve, note); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE venues SET status = ?, review_note = ? WHERE id = ?`,
+		v.Status, v.ReviewNote, id,
+	)
+	if err != nil {
+		return fmt.Errorf("error reviewing venue: %w", err)
+	}
+	return tx.Commit()
+}
+
+func VenuesBySubmitterQuery(ctx context.Context, d *dbs.Service, userID int64) ([]model.Venue, error) {
+	queri := `SELECT ` + venueColumns + ` FROM venues v WHERE v.submitted_by = ? ORDER BY v.id`
+	return queryVenues(ctx, d, queri, userID)
+}
@@ -0,0 +1,140 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+const (
+	prodID         = "-//sportPeer//Games//EN"
+	icalTimeFormat = "20060102T150405Z"
+	maxLineOctets  = 75
+
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event is a single VEVENT.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Status      string
+	URL         string
+}
+
+// GameEvent describes a game as a calendar event. host makes the UID globally
+// unique and baseURL is used for the link back to the game.
+func GameEvent(g model.Game, baseURL, host string) Event {
+	status := StatusConfirmed
+	if g.Status == model.StatusCancelled {
+		status = StatusCancelled
+	}
+	location := g.Location
+	if location == "" && g.VenueID != nil {
+		location = fmt.Sprintf("Venue #%d", *g.VenueID)
+	}
+	return Event{
+		UID:         fmt.Sprintf("game-%d@%s", g.ID, host),
+		Summary:     capitalize(g.Sport) + " game",
+		Description: fmt.Sprintf("%d/%d players", g.Participants, g.Capacity),
+		Location:    location,
+		Start:       g.StartsAt,
+		End:         g.EndsAt,
+		Status:      status,
+		URL:         fmt.Sprintf("%s/games/%d", baseURL, g.ID),
+	}
+}
+
+// Write renders a VCALENDAR containing events, following RFC 5545 escaping,
+// CRLF line endings and 75-octet line folding.
+func Write(w io.Writer, name string, events []Event, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) {
+		writeFolded(bw, s)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + prodID)
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if name != "" {
+		line("X-WR-CALNAME:" + escapeText(name))
+	}
+
+	stamp := now.UTC().Format(icalTimeFormat)
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + escapeText(e.UID))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART:" + e.Start.UTC().Format(icalTimeFormat))
+		line("DTEND:" + e.End.UTC().Format(icalTimeFormat))
+		line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION:" + escapeText(e.Location))
+		}
+		if e.URL != "" {
+			line("URL:" + e.URL)
+		}
+		if e.Status != "" {
+			line("STATUS:" + e.Status)
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// writeFolded writes s as a content line, folding it so that no physical
+// line exceeds 75 octets and no UTF-8 sequence is split.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,102 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+var stamp = time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+func TestWrite(t *testing.T) {
+	lagos := time.FixedZone("WAT", 3600)
+	events := []Event{{
+		UID:      "game-1@example.com",
+		Summary:  "Football game",
+		Location: "Pitch 2, Yaba; gate B",
+		Start:    time.Date(2026, 5, 5, 18, 0, 0, 0, lagos),
+		End:      time.Date(2026, 5, 5, 19, 0, 0, 0, lagos),
+		Status:   StatusConfirmed,
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "My games", events, stamp); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"VERSION:2.0\r\n",
+		"X-WR-CALNAME:My games\r\n",
+		"DTSTAMP:20260501T090000Z\r\n",
+		"DTSTART:20260505T170000Z\r\n",
+		"DTEND:20260505T180000Z\r\n",
+		"LOCATION:Pitch 2\\, Yaba\\; gate B\r\n",
+		"STATUS:CONFIRMED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Error("output contains bare LF line endings")
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	got := escapeText("a\\b;c,d\ne")
+	if want := `a\\b\;c\,d\ne`; got != want {
+		t.Errorf("escapeText = %q, want %q", got, want)
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	long := strings.Repeat("é", 100)
+	var buf bytes.Buffer
+	Write(&buf, "", []Event{{UID: "x", Summary: long, Start: stamp, End: stamp}}, stamp)
+
+	var summary strings.Builder
+	inSummary := false
+	for _, l := range strings.Split(buf.String(), "\r\n") {
+		if len(l) > maxLineOctets {
+			t.Errorf("line of %d octets exceeds the limit: %q", len(l), l)
+		}
+		switch {
+		case strings.HasPrefix(l, "SUMMARY:"):
+			inSummary = true
+			summary.WriteString(l)
+		case inSummary && strings.HasPrefix(l, " "):
+			summary.WriteString(l[1:])
+		default:
+			inSummary = false
+		}
+	}
+	if summary.String() != "SUMMARY:"+long {
+		t.Errorf("unfolded summary does not round-trip")
+	}
+}
+
+func TestGameEvent(t *testing.T) {
+	g := model.Game{
+		ID:           42,
+		Sport:        "tennis",
+		Location:     "Club",
+		StartsAt:     stamp,
+		EndsAt:       stamp.Add(time.Hour),
+		Capacity:     4,
+		Participants: 2,
+		Status:       model.StatusCancelled,
+	}
+	e := GameEvent(g, "https://sportpeer.example", "sportpeer.example")
+	if e.UID != "game-42@sportpeer.example" || e.Summary != "Tennis game" || e.Status != StatusCancelled {
+		t.Errorf("GameEvent = %+v", e)
+	}
+	if e.URL != "https://sportpeer.example/games/42" {
+		t.Errorf("URL = %q", e.URL)
+	}
+}
@@ -0,0 +1,25 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewFeedToken returns a random feed token and the hash to store for it.
+// Only the hash is persisted, so a leaked database cannot be used to read
+// anyone's calendar.
+func NewFeedToken() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	return token, HashFeedToken(token), nil
+}
+
+func HashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,21 @@
+package calendar
+
+import "testing"
+
+func TestNewFeedToken(t *testing.T) {
+	token, hash, err := NewFeedToken()
+	if err != nil {
+		t.Fatalf("NewFeedToken: %v", err)
+	}
+	if len(token) != 48 {
+		t.Errorf("token length = %d, want 48", len(token))
+	}
+	if hash != HashFeedToken(token) || hash == token {
+		t.Error("stored hash does not match the token")
+	}
+
+	other, _, _ := NewFeedToken()
+	if other == token {
+		t.Error("NewFeedToken returned the same token twice")
+	}
+}
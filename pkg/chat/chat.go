@@ -0,0 +1,49 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/chat/model"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrNotMember            = errors.New("you are not part of this conversation")
+	ErrMessageNotFound      = errors.New("message not found")
+	ErrSelf                 = errors.New("you cannot message yourself")
+	ErrUnknownFrame         = errors.New("unknown message type")
+)
+
+// DirectKey identifies the one direct conversation between two users,
+// whichever of them starts it.
+func DirectKey(a, b int64) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d:%d", a, b)
+}
+
+// PageSize clamps a requested page size to what the API serves.
+func PageSize(limit int) int {
+	if limit <= 0 {
+		return model.DefaultPageSize
+	}
+	if limit > model.MaxPageSize {
+		return model.MaxPageSize
+	}
+	return limit
+}
+
+// Paginate turns up to limit+1 messages, newest first, into a page. The
+// extra message only tells us there is an older page to point the cursor at.
+func Paginate(messages []model.Message, limit int) model.Page {
+	if messages == nil {
+		messages = []model.Message{}
+	}
+	if len(messages) <= limit {
+		return model.Page{Messages: messages}
+	}
+	messages = messages[:limit]
+	return model.Page{Messages: messages, NextCursor: messages[limit-1].ID}
+}
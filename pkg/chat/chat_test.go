@@ -0,0 +1,66 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/chat/model"
+)
+
+func TestDirectKey(t *testing.T) {
+	if DirectKey(7, 3) != DirectKey(3, 7) {
+		t.Errorf("DirectKey depends on argument order: %q vs %q", DirectKey(7, 3), DirectKey(3, 7))
+	}
+	if got := DirectKey(3, 7); got != "3:7" {
+		t.Errorf("DirectKey(3, 7) = %q, want %q", got, "3:7")
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	tests := []struct {
+		limit, want int
+	}{
+		{0, model.DefaultPageSize},
+		{-5, model.DefaultPageSize},
+		{10, 10},
+		{model.MaxPageSize + 1, model.MaxPageSize},
+	}
+	for _, tt := range tests {
+		if got := PageSize(tt.limit); got != tt.want {
+			t.Errorf("PageSize(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	messages := func(ids ...int64) []model.Message {
+		out := make([]model.Message, len(ids))
+		for i, id := range ids {
+			out[i] = model.Message{ID: id}
+		}
+		return out
+	}
+	tests := []struct {
+		name       string
+		in         []model.Message
+		limit      int
+		wantLen    int
+		wantCursor int64
+	}{
+		{"Empty", nil, 3, 0, 0},
+		{"Short page", messages(9, 8), 3, 2, 0},
+		{"Exact page", messages(9, 8, 7), 3, 3, 0},
+		{"More available", messages(9, 8, 7, 6), 3, 3, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Paginate(tt.in, tt.limit)
+			if p.Messages == nil {
+				t.Fatal("Paginate returned nil messages")
+			}
+			if len(p.Messages) != tt.wantLen || p.NextCursor != tt.wantCursor {
+				t.Errorf("Paginate = %d messages, cursor %d, want %d, %d",
+					len(p.Messages), p.NextCursor, tt.wantLen, tt.wantCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/chat/model"
+)
+
+const (
+	EventMessage = "message"
+	EventReceipt = "receipt"
+	EventError   = "error"
+
+	FrameMessage = "message"
+	FrameRead    = "read"
+
+	writeWait    = 10 * time.Second
+	pongWait     = 60 * time.Second
+	pingPeriod   = pongWait * 9 / 10
+	maxFrameSize = 32 << 10
+	sendBuffer   = 32
+)
+
+// Event is a frame pushed to connected clients.
+type Event struct {
+	Type    string         `json:"type"`
+	Message *model.Message `json:"message,omitempty"`
+	Receipt *model.Receipt `json:"receipt,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// Inbound is a frame sent by a client: a new message for a conversation, or
+// a read receipt up to MessageID.
+type Inbound struct {
+	Type           string `json:"type"`
+	ConversationID int64  `json:"conversationId"`
+	Body           string `json:"body,omitempty"`
+	MessageID      int64  `json:"messageId,omitempty"`
+}
+
+// Handler acts on one inbound frame from userID. An error is sent back to
+// the client that sent the frame.
+type Handler func(ctx context.Context, userID int64, in Inbound) error
+
+type client struct {
+	userID int64
+	send   chan Event
+}
+
+// Hub tracks the open connections of each user and fans events out to them.
+// A user may be connected from several devices at once.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[int64]map[*client]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{clients: make(map[int64]map[*client]struct{})}
+}
+
+// Send delivers e to every connection of the given users. It never blocks:
+// a client too slow to keep up is disconnected and can catch up over the
+// REST endpoints when it reconnects.
+func (h *Hub) Send(userIDs []int64, e Event) {
+	var slow []*client
+	h.mu.RLock()
+	for _, id := range userIDs {
+		for c := range h.clients[id] {
+			select {
+			case c.send <- e:
+			default:
+				slow = append(slow, c)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range slow {
+		h.unregister(c)
+	}
+}
+
+// Online reports whether userID has at least one open connection.
+func (h *Hub) Online(userID int64) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID]) > 0
+}
+
+func (h *Hub) register(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[c.userID] == nil {
+		h.clients[c.userID] = make(map[*client]struct{})
+	}
+	h.clients[c.userID][c] = struct{}{}
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conns := h.clients[c.userID]
+	if _, ok := conns[c]; !ok {
+		return
+	}
+	delete(conns, c)
+	if len(conns) == 0 {
+		delete(h.clients, c.userID)
+	}
+	close(c.send)
+}
+
+// Serve runs an upgraded connection for userID until either side closes it,
+// passing each inbound frame to handle.
+func (h *Hub) Serve(ctx context.Context, conn *websocket.Conn, userID int64, handle Handler) {
+	c := &client{userID: userID, send: make(chan Event, sendBuffer)}
+	h.register(c)
+	go writePump(conn, c)
+	defer h.unregister(c)
+
+	conn.SetReadLimit(maxFrameSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Websocket for user %d closed: %v", userID, err)
+			}
+			return
+		}
+		var in Inbound
+		if err := json.Unmarshal(data, &in); err != nil {
+			h.reply(c, Event{Type: EventError, Error: "invalid frame"})
+			continue
+		}
+		if err := handle(ctx, userID, in); err != nil {
+			h.reply(c, Event{Type: EventError, Error: err.Error()})
+		}
+	}
+}
+
+// reply sends e to the one connection c, unless it has been dropped.
+func (h *Hub) reply(c *client, e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if _, ok := h.clients[c.userID][c]; !ok {
+		return
+	}
+	select {
+	case c.send <- e:
+	default:
+	}
+}
+
+// writePump is the only writer to conn, as gorilla/websocket requires. It
+// closes the connection once the hub drops the client, which also ends the
+// read loop in Serve.
+func writePump(conn *websocket.Conn, c *client) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+	for {
+		select {
+		case e, ok := <-c.send:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/chat/model"
+)
+
+// newTestServer serves the hub over a real WebSocket, taking the user from
+// ?user= in place of a token. Message frames are broadcast to users 1 and 2.
+func newTestServer(t *testing.T, h *Hub) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	handle := func(ctx context.Context, userID int64, in Inbound) error {
+		if in.Type != FrameMessage {
+			return ErrUnknownFrame
+		}
+		msg := &model.Message{ConversationID: in.ConversationID, SenderID: userID, Body: in.Body}
+		h.Send([]int64{1, 2}, Event{Type: EventMessage, Message: msg})
+		return nil
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.ParseInt(r.URL.Query().Get("user"), 10, 64)
+		if err != nil {
+			http.Error(w, "bad user", http.StatusBadRequest)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		h.Serve(r.Context(), conn, userID, handle)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, h *Hub, userID int64) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?user=" + strconv.FormatInt(userID, 10)
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	waitFor(t, func() bool { return h.Online(userID) })
+	return conn
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) Event {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var e Event
+	if err := conn.ReadJSON(&e); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return e
+}
+
+func TestHubFansOutToEveryConnection(t *testing.T) {
+	h := NewHub()
+	srv := newTestServer(t, h)
+	alice := dial(t, srv, h, 1)
+	bob := dial(t, srv, h, 2)
+	bobPhone := dial(t, srv, h, 2)
+	carol := dial(t, srv, h, 3)
+
+	if err := alice.WriteJSON(Inbound{Type: FrameMessage, ConversationID: 9, Body: "hi"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for name, conn := range map[string]*websocket.Conn{"alice": alice, "bob": bob, "bob's phone": bobPhone} {
+		e := readEvent(t, conn)
+		if e.Type != EventMessage || e.Message == nil || e.Message.Body != "hi" || e.Message.SenderID != 1 {
+			t.Errorf("%s got %+v, want alice's message", name, e)
+		}
+	}
+
+	carol.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	var e Event
+	if err := carol.ReadJSON(&e); err == nil {
+		t.Errorf("carol got %+v, want nothing", e)
+	}
+}
+
+func TestHubRepliesWithErrorsToSenderOnly(t *testing.T) {
+	h := NewHub()
+	srv := newTestServer(t, h)
+	alice := dial(t, srv, h, 1)
+	bob := dial(t, srv, h, 2)
+
+	if err := alice.WriteJSON(Inbound{Type: "typing"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if e := readEvent(t, alice); e.Type != EventError || e.Error != ErrUnknownFrame.Error() {
+		t.Errorf("alice got %+v, want an unknown frame error", e)
+	}
+	if err := alice.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if e := readEvent(t, alice); e.Type != EventError {
+		t.Errorf("alice got %+v, want an invalid frame error", e)
+	}
+
+	// A later broadcast reaches bob first, so he was never sent the errors.
+	if err := alice.WriteJSON(Inbound{Type: FrameMessage, Body: "ok"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if e := readEvent(t, bob); e.Type != EventMessage {
+		t.Errorf("bob got %+v, want the message", e)
+	}
+}
+
+func TestHubUnregistersOnClose(t *testing.T) {
+	h := NewHub()
+	srv := newTestServer(t, h)
+	conn := dial(t, srv, h, 5)
+
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	conn.Close()
+	waitFor(t, func() bool { return !h.Online(5) })
+
+	// Sending to a user with no connections is a no-op.
+	h.Send([]int64{5}, Event{Type: EventMessage})
+}
+
+func TestHubDropsSlowClients(t *testing.T) {
+	h := NewHub()
+	c := &client{userID: 1, send: make(chan Event, 1)}
+	h.register(c)
+
+	h.Send([]int64{1}, Event{Type: EventMessage})
+	h.Send([]int64{1}, Event{Type: EventMessage})
+	if h.Online(1) {
+		t.Fatal("slow client is still registered")
+	}
+	if _, ok := <-c.send; !ok {
+		t.Fatal("queued event was lost")
+	}
+	if _, ok := <-c.send; ok {
+		t.Fatal("send channel was not closed")
+	}
+}
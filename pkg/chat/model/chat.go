@@ -0,0 +1,64 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	KindDirect = "direct"
+	KindGame   = "game"
+	KindTeam   = "team"
+
+	MaxMessageLength = 4000
+	DefaultPageSize  = 50
+	MaxPageSize      = 100
+)
+
+type Conversation struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	GameID      *int64    `json:"gameId,omitempty"`
+	TeamID      *int64    `json:"teamId,omitempty"`
+	Title       string    `json:"title"`
+	LastMessage *Message  `json:"lastMessage,omitempty"`
+	Unread      int       `json:"unread"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type Message struct {
+	ID             int64     `json:"id"`
+	ConversationID int64     `json:"conversationId"`
+	SenderID       int64     `json:"senderId"`
+	SenderName     string    `json:"senderName"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Receipt records the newest message a member has read in a conversation.
+type Receipt struct {
+	ConversationID int64     `json:"conversationId"`
+	UserID         int64     `json:"userId"`
+	LastReadID     int64     `json:"lastReadId"`
+	ReadAt         time.Time `json:"readAt"`
+}
+
+// Page is one page of a conversation, newest first. NextCursor is passed
+// back as ?before= to load older messages and is 0 on the last page.
+type Page struct {
+	Messages   []Message `json:"messages"`
+	NextCursor int64     `json:"nextCursor,omitempty"`
+}
+
+func (m *Message) ValidateMessage() error {
+	m.Body = strings.TrimSpace(m.Body)
+	if m.Body == "" {
+		return fmt.Errorf("Validation errors: Message cannot be empty")
+	}
+	if utf8.RuneCountInString(m.Body) > MaxMessageLength {
+		return fmt.Errorf("Validation errors: Message must be at most %d characters", MaxMessageLength)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"Valid", "  see you at 6  ", false},
+		{"Empty", "", true},
+		{"Whitespace", " \n\t ", true},
+		{"At limit", strings.Repeat("é", MaxMessageLength), false},
+		{"Too long", strings.Repeat("a", MaxMessageLength+1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Message{Body: tt.body}
+			err := m.ValidateMessage()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && m.Body != strings.TrimSpace(tt.body) {
+				t.Errorf("Body = %q, want it trimmed", m.Body)
+			}
+		})
+	}
+}
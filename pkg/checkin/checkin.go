@@ -0,0 +1,105 @@
+package checkin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+var (
+	ErrInvalidCode      = errors.New("invalid check-in code")
+	ErrWrongGame        = errors.New("this check-in code is for a different game")
+	ErrClosed           = errors.New("check-in opens 30 minutes before the game starts and closes 30 minutes after")
+	ErrAlreadyCheckedIn = errors.New("already checked in to this game")
+)
+
+const (
+	OpensBefore = 30 * time.Minute
+	ClosesAfter = 30 * time.Minute
+)
+
+// Claim is the payload of a check-in code. Codes are signed with a key
+// derived from the server secret, so players cannot make their own and a
+// code is never accepted as an access token.
+type Claim struct {
+	GameID    int64 `json:"gid"`
+	NotBefore int64 `json:"nbf"`
+	ExpiredAt int64 `json:"exp"`
+}
+
+// Window returns when check-in to g opens and closes: around the start,
+// and never past the end of the game.
+func Window(g gamemodel.Game) (time.Time, time.Time) {
+	closes := g.StartsAt.Add(ClosesAfter)
+	if g.EndsAt.Before(closes) {
+		closes = g.EndsAt
+	}
+	return g.StartsAt.Add(-OpensBefore), closes
+}
+
+// Open reports whether players can check in to g at now.
+func Open(g gamemodel.Game, now time.Time) bool {
+	opens, closes := Window(g)
+	return !now.Before(opens) && now.Before(closes)
+}
+
+// NewCode returns the code shown as a QR code at g. It is only accepted
+// during the check-in window.
+func NewCode(g gamemodel.Game) (string, error) {
+	opens, closes := Window(g)
+	claimJson, err := json.Marshal(Claim{GameID: g.ID, NotBefore: opens.Unix(), ExpiredAt: closes.Unix()})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode check-in code: %w", err)
+	}
+	payload := base64.URLEncoding.EncodeToString(claimJson)
+	return payload + "." + sign(payload), nil
+}
+
+// Verify checks that code was issued by this server for gameID and that
+// check-in is open at now.
+func Verify(code string, gameID int64, now time.Time) error {
+	parts := strings.Split(code, ".")
+	if len(parts) != 2 {
+		return ErrInvalidCode
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(sign(parts[0]))) {
+		return ErrInvalidCode
+	}
+	claimJson, err := base64.URLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrInvalidCode
+	}
+	var c Claim
+	if err := json.Unmarshal(claimJson, &c); err != nil {
+		return ErrInvalidCode
+	}
+	if c.GameID != gameID {
+		return ErrWrongGame
+	}
+	if now.Unix() < c.NotBefore || now.Unix() >= c.ExpiredAt {
+		return ErrClosed
+	}
+	return nil
+}
+
+// codeKey is the key check-in codes are signed with. It differs from the
+// access token key, which is the secret itself.
+func codeKey() []byte {
+	h := hmac.New(sha256.New, []byte(os.Getenv("SECRET")))
+	h.Write([]byte("checkin"))
+	return h.Sum(nil)
+}
+
+func sign(payload string) string {
+	h := hmac.New(sha256.New, codeKey())
+	h.Write([]byte(payload))
+	return base64.URLEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package checkin
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/user"
+)
+
+var starts = time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+
+func testGame() gamemodel.Game {
+	return gamemodel.Game{ID: 7, StartsAt: starts, EndsAt: starts.Add(2 * time.Hour)}
+}
+
+func TestWindow(t *testing.T) {
+	opens, closes := Window(testGame())
+	if !opens.Equal(starts.Add(-30*time.Minute)) || !closes.Equal(starts.Add(30*time.Minute)) {
+		t.Errorf("window = %v to %v", opens, closes)
+	}
+
+	short := testGame()
+	short.EndsAt = starts.Add(20 * time.Minute)
+	if _, closes := Window(short); !closes.Equal(short.EndsAt) {
+		t.Errorf("window of a short game closes at %v, want its end", closes)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	os.Setenv("SECRET", "test-secret")
+	defer os.Unsetenv("SECRET")
+
+	g := testGame()
+	code, err := NewCode(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := code[:strings.Index(code, ".")]
+
+	other := testGame()
+	other.ID = 8
+	otherCode, err := NewCode(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		code string
+		now  time.Time
+		want error
+	}{
+		{"At kickoff", code, starts, nil},
+		{"Just opened", code, starts.Add(-OpensBefore), nil},
+		{"Too early", code, starts.Add(-OpensBefore - time.Second), ErrClosed},
+		{"Too late", code, starts.Add(ClosesAfter), ErrClosed},
+		{"Other game", otherCode, starts, ErrWrongGame},
+		{"Forged signature", payload + ".c2lnbmF0dXJl", starts, ErrInvalidCode},
+		{"Tampered payload", "x" + code, starts, ErrInvalidCode},
+		{"Not a code", "hello", starts, ErrInvalidCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.code, g.ID, tt.now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	os.Setenv("SECRET", "another-secret")
+	if err := Verify(code, g.ID, starts); err != ErrInvalidCode {
+		t.Errorf("code signed with another secret = %v, want %v", err, ErrInvalidCode)
+	}
+}
+
+func TestCodeIsNotAToken(t *testing.T) {
+	os.Setenv("SECRET", "test-secret")
+	defer os.Unsetenv("SECRET")
+
+	// A game on now, so the code has not expired as a token either.
+	g := gamemodel.Game{ID: 7, StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour)}
+	code, err := NewCode(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err := user.ValidateToken(code); err == nil {
+		t.Errorf("check-in code accepted as a token for user %d", claims.Subject)
+	}
+
+	token, err := user.GenerateSecretToken(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(token, g.ID, time.Now()); err != ErrInvalidCode {
+		t.Errorf("access token as a check-in code = %v, want %v", err, ErrInvalidCode)
+	}
+}
@@ -0,0 +1,189 @@
+package feed
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/feed/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/social"
+)
+
+var ErrInvalidCursor = errors.New("invalid feed cursor")
+
+const (
+	// NearRadiusKm is how close a game must be to the viewer to be called
+	// near them.
+	NearRadiusKm = 25
+	// maxBatches bounds how many batches of activities one page reads when
+	// most of them are hidden from the viewer. The page is then cut short,
+	// and its cursor resumes where reading stopped.
+	maxBatches = 5
+)
+
+// Viewer is the user reading a feed.
+type Viewer struct {
+	UserID   int64
+	Friends  map[int64]bool
+	Teams    map[int64]bool
+	Blocked  map[int64]bool
+	Location *geo.Point
+}
+
+// Visible reports whether v may see a. Users see their own activities and
+// those of friends, subject to the actor's privacy settings and to the
+// visibility of the game involved; team activities are seen by members.
+func Visible(a model.Activity, v Viewer) bool {
+	if a.ActorID == nil {
+		return a.TeamID != nil && v.Teams[*a.TeamID]
+	}
+
+	actor := *a.ActorID
+	r := social.Relation{Self: actor == v.UserID, Friend: v.Friends[actor], Blocked: v.Blocked[actor]}
+	if !r.Self && !r.Friend {
+		return false
+	}
+	if !social.Allows(a.ActorPrivacy.Profile, r) {
+		return false
+	}
+	if a.GameID == nil {
+		return true
+	}
+	g := a.Game
+	if g == nil || g.Status == gamemodel.StatusCancelled || !social.Allows(a.ActorPrivacy.Games, r) {
+		return false
+	}
+	if a.Type == model.TypeJoinedGame && !g.ActorPlaying {
+		return false
+	}
+	return game.CanView(
+		gamemodel.Game{OrganizerID: g.OrganizerID, Visibility: g.Visibility},
+		game.Viewer{
+			UserID:      v.UserID,
+			Participant: g.ViewerPlaying,
+			Invited:     g.ViewerInvited,
+			Friend:      g.OrganizerFriend,
+			Blocked:     g.OrganizerBlocked,
+		},
+	)
+}
+
+// Describe fills in the activity's text for v, and whether its game is
+// near them.
+func Describe(a model.Activity, v Viewer) model.Activity {
+	name := a.ActorName
+	if a.ActorID != nil && *a.ActorID == v.UserID {
+		name = "You"
+	}
+	if a.ActorID == nil {
+		name = a.TeamName
+	}
+	if a.Game != nil && a.Game.Location != nil && v.Location != nil {
+		a.NearYou = geo.Distance(*v.Location, *a.Game.Location) <= NearRadiusKm
+	}
+	near := ""
+	if a.NearYou {
+		near = " near you"
+	}
+	sport := "a"
+	if a.Game != nil && a.Game.Sport != "" {
+		sport = article(a.Game.Sport) + " " + a.Game.Sport
+	}
+
+	switch a.Type {
+	case model.TypeOrganizedGame:
+		a.Text = fmt.Sprintf("%s organized %s game%s", name, sport, near)
+	case model.TypeJoinedGame:
+		a.Text = fmt.Sprintf("%s joined %s game%s", name, sport, near)
+	case model.TypeWon:
+		var w model.Win
+		json.Unmarshal(a.Data, &w)
+		switch {
+		case w.Opponent != "" && w.Score != "":
+			a.Text = fmt.Sprintf("%s beat %s %s in %s", name, w.Opponent, w.Score, w.Competition)
+		default:
+			a.Text = fmt.Sprintf("%s won %s", name, w.Competition)
+		}
+	case model.TypeBadgeEarned:
+		var b model.Badge
+		json.Unmarshal(a.Data, &b)
+		a.Text = fmt.Sprintf("%s earned the %s badge", name, b.Name)
+	}
+	return a
+}
+
+func article(word string) string {
+	switch word[0] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return "an"
+	}
+	return "a"
+}
+
+// Fetch returns up to limit activities of the viewer's friends and teams
+// with IDs below before, newest first. A before of 0 starts from the newest.
+type Fetch func(before int64, limit int) ([]model.Activity, error)
+
+// Collect reads activities through fetch until it has a page of limit
+// activities visible to v, starting after the cursor before. It returns the
+// page and the cursor of the next one, or 0 when there are no more.
+//
+// Feeds are assembled on read rather than copied to every friend when an
+// activity happens. Reading merges the newest activities of the viewer,
+// their friends and their teams, each taken from its own index, while
+// recording an activity stays a single insert. Privacy changes, blocks and
+// unfriending also apply at once, with no copied feeds to purge.
+func Collect(v Viewer, before int64, limit int, fetch Fetch) ([]model.Activity, int64, error) {
+	items := []model.Activity{}
+	batch := limit * 2
+	for i := 0; i < maxBatches; i++ {
+		candidates, err := fetch(before, batch)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, a := range candidates {
+			before = a.ID
+			if !Visible(a, v) {
+				continue
+			}
+			items = append(items, Describe(a, v))
+			if len(items) == limit {
+				return items, a.ID, nil
+			}
+		}
+		if len(candidates) < batch {
+			return items, 0, nil
+		}
+	}
+	return items, before, nil
+}
+
+// EncodeCursor makes an opaque cursor from the ID a page ended at.
+func EncodeCursor(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// DecodeCursor reverses EncodeCursor. The empty cursor starts from the
+// newest activity.
+func DecodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
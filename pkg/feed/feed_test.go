@@ -0,0 +1,296 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/feed/model"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	socialmodel "github.com/dudeiebot/sportPeerGo/pkg/social/model"
+)
+
+const (
+	viewerID   = int64(1)
+	friendID   = int64(2)
+	strangerID = int64(3)
+	blockedID  = int64(4)
+	teamID     = int64(10)
+	otherTeam  = int64(11)
+)
+
+var (
+	london = geo.Point{Lat: 51.5074, Lng: -0.1278}
+	paris  = geo.Point{Lat: 48.8566, Lng: 2.3522}
+)
+
+func testViewer() Viewer {
+	return Viewer{
+		UserID:   viewerID,
+		Friends:  map[int64]bool{friendID: true, blockedID: true},
+		Teams:    map[int64]bool{teamID: true},
+		Blocked:  map[int64]bool{blockedID: true},
+		Location: &london,
+	}
+}
+
+func ptr(id int64) *int64 {
+	return &id
+}
+
+var everyone = model.Privacy{Profile: socialmodel.AudienceEveryone, Games: socialmodel.AudienceEveryone}
+
+func joined(id, actor int64, g model.Game) model.Activity {
+	if g.Status == "" {
+		g.Status = gamemodel.StatusOpen
+	}
+	g.ActorPlaying = true
+	return model.Activity{
+		ID:           id,
+		Type:         model.TypeJoinedGame,
+		ActorID:      &actor,
+		ActorName:    "Sam",
+		GameID:       ptr(100 + id),
+		Game:         &g,
+		ActorPrivacy: everyone,
+	}
+}
+
+func publicGame() model.Game {
+	return model.Game{OrganizerID: strangerID, Sport: "football", Visibility: gamemodel.VisibilityPublic}
+}
+
+func TestVisible(t *testing.T) {
+	friendsOnly := model.Privacy{Profile: socialmodel.AudienceEveryone, Games: socialmodel.AudienceFriends}
+	hiddenGames := model.Privacy{Profile: socialmodel.AudienceEveryone, Games: socialmodel.AudienceNobody}
+	hiddenProfile := model.Privacy{Profile: socialmodel.AudienceNobody, Games: socialmodel.AudienceEveryone}
+
+	with := func(a model.Activity, change func(a *model.Activity)) model.Activity {
+		g := *a.Game
+		a.Game = &g
+		change(&a)
+		return a
+	}
+	base := joined(1, friendID, publicGame())
+
+	tests := []struct {
+		name     string
+		activity model.Activity
+		want     bool
+	}{
+		{"Friend joined a public game", base, true},
+		{"Own activity", joined(1, viewerID, publicGame()), true},
+		{"Stranger", joined(1, strangerID, publicGame()), false},
+		{"Blocked friend", joined(1, blockedID, publicGame()), false},
+		{"Games shared with friends", with(base, func(a *model.Activity) { a.ActorPrivacy = friendsOnly }), true},
+		{"Games hidden", with(base, func(a *model.Activity) { a.ActorPrivacy = hiddenGames }), false},
+		{"Profile hidden", with(base, func(a *model.Activity) { a.ActorPrivacy = hiddenProfile }), false},
+		{"Own activity with games hidden", with(joined(1, viewerID, publicGame()), func(a *model.Activity) {
+			a.ActorPrivacy = hiddenGames
+		}), true},
+		{"Cancelled game", with(base, func(a *model.Activity) { a.Game.Status = gamemodel.StatusCancelled }), false},
+		{"Friend left the game", with(base, func(a *model.Activity) { a.Game.ActorPlaying = false }), false},
+		{"Invite-only game", with(base, func(a *model.Activity) {
+			a.Game.Visibility = gamemodel.VisibilityInvite
+		}), false},
+		{"Invite-only game the viewer plays in", with(base, func(a *model.Activity) {
+			a.Game.Visibility = gamemodel.VisibilityInvite
+			a.Game.ViewerPlaying = true
+		}), true},
+		{"Invite-only game the viewer was invited to", with(base, func(a *model.Activity) {
+			a.Game.Visibility = gamemodel.VisibilityInvite
+			a.Game.ViewerInvited = true
+		}), true},
+		{"Friends game of a stranger", with(base, func(a *model.Activity) {
+			a.Game.Visibility = gamemodel.VisibilityFriends
+		}), false},
+		{"Friends game of a friend", with(base, func(a *model.Activity) {
+			a.Game.Visibility = gamemodel.VisibilityFriends
+			a.Game.OrganizerFriend = true
+		}), true},
+		{"Organizer blocked the viewer", with(base, func(a *model.Activity) { a.Game.OrganizerBlocked = true }), false},
+		{"Win without a game", model.Activity{
+			ID: 1, Type: model.TypeWon, ActorID: ptr(friendID), ActorPrivacy: everyone,
+		}, true},
+		{"Own team", model.Activity{ID: 1, Type: model.TypeWon, TeamID: ptr(teamID)}, true},
+		{"Other team", model.Activity{ID: 1, Type: model.TypeWon, TeamID: ptr(otherTeam)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Visible(tt.activity, testViewer()); got != tt.want {
+				t.Errorf("Visible = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	near := publicGame()
+	near.Location = &london
+	far := publicGame()
+	far.Sport = "ice hockey"
+	far.Location = &paris
+	win, _ := json.Marshal(model.Win{Competition: "Sunday League", Opponent: "Rovers", Score: "3-1"})
+	cup, _ := json.Marshal(model.Win{Competition: "Summer Cup"})
+	badge, _ := json.Marshal(model.Badge{Badge: "first_game", Name: "First Game"})
+
+	tests := []struct {
+		name     string
+		activity model.Activity
+		want     string
+		nearYou  bool
+	}{
+		{"Near", joined(1, friendID, near), "Sam joined a football game near you", true},
+		{"Far", joined(1, friendID, far), "Sam joined an ice hockey game", false},
+		{"Own", joined(1, viewerID, far), "You joined an ice hockey game", false},
+		{"Fixture win", model.Activity{Type: model.TypeWon, TeamID: ptr(teamID), TeamName: "United", Data: win},
+			"United beat Rovers 3-1 in Sunday League", false},
+		{"Tournament win", model.Activity{Type: model.TypeWon, ActorID: ptr(friendID), ActorName: "Sam", Data: cup},
+			"Sam won Summer Cup", false},
+		{"Badge", model.Activity{Type: model.TypeBadgeEarned, ActorID: ptr(friendID), ActorName: "Sam", Data: badge},
+			"Sam earned the First Game badge", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Describe(tt.activity, testViewer())
+			if got.Text != tt.want || got.NearYou != tt.nearYou {
+				t.Errorf("Describe = %q near %v, want %q near %v", got.Text, got.NearYou, tt.want, tt.nearYou)
+			}
+		})
+	}
+}
+
+// fetchFrom serves activities from a slice ordered newest first, the way
+// the database does.
+func fetchFrom(all []model.Activity, calls *int) Fetch {
+	return func(before int64, limit int) ([]model.Activity, error) {
+		*calls++
+		var out []model.Activity
+		for _, a := range all {
+			if (before == 0 || a.ID < before) && len(out) < limit {
+				out = append(out, a)
+			}
+		}
+		return out, nil
+	}
+}
+
+func ids(items []model.Activity) []int64 {
+	out := make([]int64, len(items))
+	for i, a := range items {
+		out[i] = a.ID
+	}
+	return out
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCollect(t *testing.T) {
+	// Activities 20 down to 1; every third one is by a stranger.
+	var all []model.Activity
+	for id := int64(20); id >= 1; id-- {
+		actor := friendID
+		if id%3 == 0 {
+			actor = strangerID
+		}
+		all = append(all, joined(id, actor, publicGame()))
+	}
+
+	var calls int
+	fetch := fetchFrom(all, &calls)
+	var pages [][]int64
+	var before int64
+	for {
+		items, next, err := Collect(testViewer(), before, 5, fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, ids(items))
+		if next == 0 {
+			break
+		}
+		before = next
+	}
+
+	want := [][]int64{
+		{20, 19, 17, 16, 14},
+		{13, 11, 10, 8, 7},
+		{5, 4, 2, 1},
+	}
+	if len(pages) != len(want) {
+		t.Fatalf("got pages %v, want %v", pages, want)
+	}
+	for i := range want {
+		if !equal(pages[i], want[i]) {
+			t.Errorf("page %d = %v, want %v", i+1, pages[i], want[i])
+		}
+	}
+}
+
+func TestCollectMostlyHidden(t *testing.T) {
+	// A long run of hidden activities ends a page early instead of reading
+	// the whole table; the cursor picks up where reading stopped.
+	var all []model.Activity
+	for id := int64(100); id >= 1; id-- {
+		actor := strangerID
+		if id == 1 {
+			actor = friendID
+		}
+		all = append(all, joined(id, actor, publicGame()))
+	}
+
+	var calls int
+	items, next, err := Collect(testViewer(), 0, 2, fetchFrom(all, &calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 || calls != maxBatches || next != 81 {
+		t.Fatalf("got %v, next %d after %d calls", ids(items), next, calls)
+	}
+
+	items, next, err = Collect(testViewer(), 17, 2, fetchFrom(all, &calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(ids(items), []int64{1}) || next != 0 {
+		t.Errorf("last page = %v, next %d", ids(items), next)
+	}
+}
+
+func TestCollectError(t *testing.T) {
+	failed := errors.New("database down")
+	_, _, err := Collect(testViewer(), 0, 5, func(int64, int) ([]model.Activity, error) {
+		return nil, failed
+	})
+	if err != failed {
+		t.Errorf("Collect error = %v, want %v", err, failed)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	for _, id := range []int64{1, 42, 1 << 40} {
+		got, err := DecodeCursor(EncodeCursor(id))
+		if err != nil || got != id {
+			t.Errorf("round trip of %d = %d, %v", id, got, err)
+		}
+	}
+	if EncodeCursor(0) != "" {
+		t.Error("the end of the feed should have no cursor")
+	}
+	for _, bad := range []string{"!!", "YWJj", "LTE"} {
+		if _, err := DecodeCursor(bad); err != ErrInvalidCursor {
+			t.Errorf("DecodeCursor(%q) = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
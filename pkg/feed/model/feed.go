@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+)
+
+const (
+	TypeOrganizedGame = "organized_game"
+	TypeJoinedGame    = "joined_game"
+	// TypeWon is a player or team winning a tournament or league fixture.
+	TypeWon         = "won"
+	TypeBadgeEarned = "badge_earned"
+
+	DefaultLimit = 20
+	MaxLimit     = 50
+)
+
+// Activity is something a user or team did that shows up in the feeds of
+// their friends and teammates. Exactly one of ActorID and TeamID is set.
+type Activity struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	ActorID   *int64 `json:"actorId,omitempty"`
+	ActorName string `json:"actorName,omitempty"`
+	TeamID    *int64 `json:"teamId,omitempty"`
+	TeamName  string `json:"teamName,omitempty"`
+	GameID    *int64 `json:"gameId,omitempty"`
+	// Data holds the details of each type, such as a Win.
+	Data      json.RawMessage `json:"data,omitempty"`
+	Text      string          `json:"text"`
+	NearYou   bool            `json:"nearYou,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+
+	// Key makes recording an activity idempotent: an activity with the same
+	// key replaces the earlier one.
+	Key string `json:"-"`
+	// Game and ActorPrivacy are loaded with each activity to decide who
+	// sees it.
+	Game         *Game   `json:"-"`
+	ActorPrivacy Privacy `json:"-"`
+}
+
+// Game is what the feed needs to know about an activity's game, including
+// how the viewer reading the feed relates to it.
+type Game struct {
+	OrganizerID int64
+	Sport       string
+	Visibility  string
+	Status      string
+	Location    *geo.Point
+	// ActorPlaying is false once the actor has left the game.
+	ActorPlaying bool
+
+	ViewerPlaying   bool
+	ViewerInvited   bool
+	OrganizerFriend bool
+	// OrganizerBlocked is true when the viewer and the organizer have
+	// blocked one another.
+	OrganizerBlocked bool
+}
+
+// Privacy is the part of the actor's privacy settings the feed respects.
+type Privacy struct {
+	Profile string
+	Games   string
+}
+
+// Win is the data of a won activity.
+type Win struct {
+	Competition  string `json:"competition"`
+	TournamentID *int64 `json:"tournamentId,omitempty"`
+	LeagueID     *int64 `json:"leagueId,omitempty"`
+	FixtureID    *int64 `json:"fixtureId,omitempty"`
+	Opponent     string `json:"opponent,omitempty"`
+	Score        string `json:"score,omitempty"`
+}
+
+// Badge is the data of a badge_earned activity.
+type Badge struct {
+	Badge string `json:"badge"`
+	Name  string `json:"name"`
+}
+
+// Page is one page of a feed, newest first. NextCursor is empty on the last
+// page.
+type Page struct {
+	Items      []Activity `json:"items"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+var (
+	ErrGameNotFound    = errors.New("game not found")
+	ErrGameClosed      = errors.New("game is no longer open")
+	ErrAlreadyJoined   = errors.New("already joined this game")
+	ErrNotJoined       = errors.New("not a participant of this game")
+	ErrNotInvited      = errors.New("this game is invite only")
+	ErrSkillOutOfRange = errors.New("your skill level is outside this game's range")
+	ErrNotOrganizer    = errors.New("only the organizer can do that")
+	ErrOrganizerLeave  = errors.New("the organizer cannot leave their own game, cancel it instead")
+	ErrFeeLocked       = errors.New("the fee cannot change once players have joined")
+	ErrRestricted      = errors.New("too many recent no-shows to join public games, ask the organizer for an invite")
+	ErrStartInPast     = errors.New("Game must start in the future")
+	ErrSkillExcludes   = errors.New("the skill range excludes players who already joined")
+)
+
+// Viewer describes how the requesting user relates to a game.
+type Viewer struct {
+	UserID      int64
+	Participant bool
+	Waitlisted  bool
+	Invited     bool
+	Friend      bool
+	// Blocked is true when the viewer and the organizer have blocked one
+	// another.
+	Blocked bool
+	// Restricted is true when the viewer's recent no-shows keep them out of
+	// public games they were not invited to.
+	Restricted bool
+}
+
+// CanView reports whether the viewer may see the game at all.
+func CanView(g model.Game, v Viewer) bool {
+	if g.OrganizerID == v.UserID {
+		return true
+	}
+	if v.Blocked {
+		return false
+	}
+	if v.Participant || v.Invited {
+		return true
+	}
+	switch g.Visibility {
+	case model.VisibilityPublic:
+		return true
+	case model.VisibilityFriends:
+		return v.Friend
+	}
+	return false
+}
+
+// CheckJoin validates a join attempt against a game whose row is already
+// locked by the caller. skill is the joiner's level in the game's sport, or 0
+// if they have not listed it. Capacity is left to Roster, which waitlists
+// joiners once the game is full.
+func CheckJoin(g model.Game, v Viewer, skill int, now time.Time) error {
+	if g.Status != model.StatusOpen || !now.Before(g.StartsAt) {
+		return ErrGameClosed
+	}
+	if v.Participant {
+		return ErrAlreadyJoined
+	}
+	if v.Blocked {
+		return ErrGameNotFound
+	}
+	if !CanView(g, v) {
+		return ErrNotInvited
+	}
+	if v.Waitlisted {
+		return ErrAlreadyWaitlisted
+	}
+	if v.Restricted && g.Visibility == model.VisibilityPublic && !v.Invited && g.OrganizerID != v.UserID {
+		return ErrRestricted
+	}
+	if g.HasSkillRange() && g.OrganizerID != v.UserID && (skill < g.SkillMin || skill > g.SkillMax) {
+		return ErrSkillOutOfRange
+	}
+	return nil
+}
+
+// CheckLeave validates leaving the game or its waitlist.
+func CheckLeave(g model.Game, v Viewer, now time.Time) error {
+	if !v.Participant && !v.Waitlisted {
+		return ErrNotJoined
+	}
+	if g.OrganizerID == v.UserID {
+		return ErrOrganizerLeave
+	}
+	if g.Status != model.StatusOpen || !now.Before(g.StartsAt) {
+		return ErrGameClosed
+	}
+	return nil
+}
+
+// CheckUpdate validates editing game current into next, with the same rules
+// as creating a game plus those protecting players already joined. The
+// caller holds current's row lock; skills maps each participant other than
+// the organizer to their level in next's sport.
+func CheckUpdate(current, next model.Game, skills map[int64]int, now time.Time) error {
+	if current.Status != model.StatusOpen || !now.Before(current.StartsAt) {
+		return ErrGameClosed
+	}
+	if !next.StartsAt.After(now) {
+		return ErrStartInPast
+	}
+	if next.Capacity < current.Participants {
+		return fmt.Errorf("capacity cannot be lower than the %d players already joined", current.Participants)
+	}
+	if next.FeeCents != current.FeeCents && current.Participants > 1 {
+		return ErrFeeLocked
+	}
+	if next.HasSkillRange() {
+		for _, skill := range skills {
+			if skill < next.SkillMin || skill > next.SkillMax {
+				return ErrSkillExcludes
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+var now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func openGame() model.Game {
+	return model.Game{
+		ID:           1,
+		OrganizerID:  10,
+		Sport:        "football",
+		StartsAt:     now.Add(24 * time.Hour),
+		EndsAt:       now.Add(26 * time.Hour),
+		Capacity:     4,
+		Participants: 1,
+		Visibility:   model.VisibilityPublic,
+		Status:       model.StatusOpen,
+	}
+}
+
+func TestCanView(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility string
+		viewer     Viewer
+		want       bool
+	}{
+		{"Public to stranger", model.VisibilityPublic, Viewer{UserID: 2}, true},
+		{"Friends to stranger", model.VisibilityFriends, Viewer{UserID: 2}, false},
+		{"Friends to friend", model.VisibilityFriends, Viewer{UserID: 2, Friend: true}, true},
+		{"Invite to stranger", model.VisibilityInvite, Viewer{UserID: 2}, false},
+		{"Invite to invitee", model.VisibilityInvite, Viewer{UserID: 2, Invited: true}, true},
+		{"Invite to organizer", model.VisibilityInvite, Viewer{UserID: 10}, true},
+		{"Public to blocked", model.VisibilityPublic, Viewer{UserID: 2, Blocked: true}, false},
+		{"Invited but blocked", model.VisibilityInvite, Viewer{UserID: 2, Invited: true, Blocked: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := openGame()
+			g.Visibility = tt.visibility
+			if got := CanView(g, tt.viewer); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckJoin(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(g *model.Game)
+		viewer Viewer
+		skill  int
+		want   error
+	}{
+		{"Open game", func(g *model.Game) {}, Viewer{UserID: 2}, 0, nil},
+		{"Full game", func(g *model.Game) { g.Participants = 4 }, Viewer{UserID: 2}, 0, nil},
+		{"Already waitlisted", func(g *model.Game) {}, Viewer{UserID: 2, Waitlisted: true}, 0, ErrAlreadyWaitlisted},
+		{"Cancelled", func(g *model.Game) { g.Status = model.StatusCancelled }, Viewer{UserID: 2}, 0, ErrGameClosed},
+		{"Started", func(g *model.Game) { g.StartsAt = now.Add(-time.Minute) }, Viewer{UserID: 2}, 0, ErrGameClosed},
+		{"Already joined", func(g *model.Game) {}, Viewer{UserID: 2, Participant: true}, 0, ErrAlreadyJoined},
+		{"Invite only", func(g *model.Game) { g.Visibility = model.VisibilityInvite }, Viewer{UserID: 2}, 0, ErrNotInvited},
+		{"Blocked by organizer", func(g *model.Game) {}, Viewer{UserID: 2, Blocked: true}, 0, ErrGameNotFound},
+		{"Below skill", func(g *model.Game) { g.SkillMin, g.SkillMax = 4, 6 }, Viewer{UserID: 2}, 3, ErrSkillOutOfRange},
+		{"Unlisted sport", func(g *model.Game) { g.SkillMin, g.SkillMax = 4, 6 }, Viewer{UserID: 2}, 0, ErrSkillOutOfRange},
+		{"Within skill", func(g *model.Game) { g.SkillMin, g.SkillMax = 4, 6 }, Viewer{UserID: 2}, 5, nil},
+		{"Restricted from public", func(g *model.Game) {}, Viewer{UserID: 2, Restricted: true}, 0, ErrRestricted},
+		{"Restricted but invited", func(g *model.Game) {}, Viewer{UserID: 2, Restricted: true, Invited: true}, 0, nil},
+		{"Restricted friend", func(g *model.Game) { g.Visibility = model.VisibilityFriends }, Viewer{UserID: 2, Restricted: true, Friend: true}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := openGame()
+			tt.mutate(&g)
+			if got := CheckJoin(g, tt.viewer, tt.skill, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLeave(t *testing.T) {
+	g := openGame()
+	if err := CheckLeave(g, Viewer{UserID: 2, Participant: true}, now); err != nil {
+		t.Errorf("participant leave: %v", err)
+	}
+	if err := CheckLeave(g, Viewer{UserID: 3, Waitlisted: true}, now); err != nil {
+		t.Errorf("waitlisted leave: %v", err)
+	}
+	if err := CheckLeave(g, Viewer{UserID: 2}, now); err != ErrNotJoined {
+		t.Errorf("non participant leave: got %v, want %v", err, ErrNotJoined)
+	}
+	if err := CheckLeave(g, Viewer{UserID: 10, Participant: true}, now); err != ErrOrganizerLeave {
+		t.Errorf("organizer leave: got %v, want %v", err, ErrOrganizerLeave)
+	}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	current := openGame()
+	current.Participants = 3
+	current.FeeCents = 500
+	skills := map[int64]int{2: 4, 3: 7}
+
+	tests := []struct {
+		name   string
+		mutate func(g *model.Game)
+		want   error
+	}{
+		{"Unchanged", func(g *model.Game) {}, nil},
+		{"Moved later", func(g *model.Game) { g.StartsAt = g.StartsAt.Add(time.Hour) }, nil},
+		{"Moved into the past", func(g *model.Game) { g.StartsAt = now.Add(-time.Hour) }, ErrStartInPast},
+		{"Fee changed", func(g *model.Game) { g.FeeCents = 1000 }, ErrFeeLocked},
+		{"Range keeps everyone", func(g *model.Game) { g.SkillMin, g.SkillMax = 3, 8 }, nil},
+		{"Range excludes a player", func(g *model.Game) { g.SkillMin, g.SkillMax = 5, 8 }, ErrSkillExcludes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := current
+			tt.mutate(&next)
+			if got := CheckUpdate(current, next, skills, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	next := current
+	next.Capacity = 2
+	if err := CheckUpdate(current, next, skills, now); err == nil {
+		t.Error("capacity below the roster should be rejected")
+	}
+	started := current
+	started.StartsAt = now.Add(-time.Hour)
+	if err := CheckUpdate(started, current, skills, now); err != ErrGameClosed {
+		t.Errorf("editing a started game: got %v, want %v", err, ErrGameClosed)
+	}
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+const (
+	VisibilityPublic  = "public"
+	VisibilityFriends = "friends"
+	VisibilityInvite  = "invite"
+
+	StatusOpen      = "open"
+	StatusCancelled = "cancelled"
+
+	MaxCapacity = 100
+	// MaxFeeCents caps what an organizer can charge to join a game.
+	MaxFeeCents = 100_000
+)
+
+type Game struct {
+	ID          int64     `json:"id"`
+	OrganizerID int64     `json:"organizerId"`
+	Sport       string    `json:"sport"`
+	VenueID     *int64    `json:"venueId,omitempty"`
+	CourtID     *int64    `json:"courtId,omitempty"`
+	Location    string    `json:"location"`
+	StartsAt    time.Time `json:"startsAt"`
+	EndsAt      time.Time `json:"endsAt"`
+	Capacity    int       `json:"capacity"`
+	SkillMin    int       `json:"skillMin"`
+	SkillMax    int       `json:"skillMax"`
+	Visibility  string    `json:"visibility"`
+	// FeeCents is what each player pays to join. Free games have none.
+	FeeCents     int64      `json:"feeCents,omitempty"`
+	Status       string     `json:"status"`
+	Participants int        `json:"participants"`
+	SeriesID     *int64     `json:"seriesId,omitempty"`
+	OccurrenceAt *time.Time `json:"occurrenceAt,omitempty"`
+}
+
+type Participant struct {
+	UserID   int64     `json:"userId"`
+	Username string    `json:"username"`
+	JoinedAt time.Time `json:"joinedAt"`
+	// Standing is shown to the organizer for players flagged or restricted
+	// for no-shows.
+	Standing string `json:"standing,omitempty"`
+}
+
+type Filter struct {
+	Sport string
+	From  time.Time
+	To    time.Time
+	// Skill, when set, leaves out games whose skill range excludes it.
+	Skill  int
+	Limit  int
+	Offset int
+}
+
+func (g *Game) ValidateGame() error {
+	var errors []string
+
+	g.Sport = usermodel.NormalizeSport(g.Sport)
+	g.Location = strings.TrimSpace(g.Location)
+	if g.Visibility == "" {
+		g.Visibility = VisibilityPublic
+	}
+
+	if g.Sport == "" {
+		errors = append(errors, "Sport is required")
+	}
+	if g.VenueID == nil && g.Location == "" {
+		errors = append(errors, "A venue or location is required")
+	}
+	if g.CourtID != nil && g.VenueID == nil {
+		errors = append(errors, "A court can only be booked at a venue")
+	}
+	if g.StartsAt.IsZero() || g.EndsAt.IsZero() {
+		errors = append(errors, "Start and end time are required")
+	} else if !g.EndsAt.After(g.StartsAt) {
+		errors = append(errors, "Game must end after it starts")
+	}
+	if g.Capacity < 2 || g.Capacity > MaxCapacity {
+		errors = append(errors, fmt.Sprintf("Capacity must be between 2 and %d", MaxCapacity))
+	}
+	if g.SkillMin != 0 || g.SkillMax != 0 {
+		if g.SkillMin < usermodel.MinSkill || g.SkillMax > usermodel.MaxSkill || g.SkillMin > g.SkillMax {
+			errors = append(
+				errors,
+				fmt.Sprintf("Skill range must be within %d-%d", usermodel.MinSkill, usermodel.MaxSkill),
+			)
+		}
+	}
+	if g.FeeCents < 0 || g.FeeCents > MaxFeeCents {
+		errors = append(errors, fmt.Sprintf("Fee must be between 0 and %d cents", MaxFeeCents))
+	}
+	switch g.Visibility {
+	case VisibilityPublic, VisibilityFriends, VisibilityInvite:
+	default:
+		errors = append(errors, "Visibility must be public, friends or invite")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+// HasSkillRange reports whether the organizer restricted who may join by skill.
+func (g *Game) HasSkillRange() bool {
+	return g.SkillMin != 0 || g.SkillMax != 0
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateGame(t *testing.T) {
+	start := time.Date(2026, 5, 5, 18, 0, 0, 0, time.UTC)
+	valid := func() Game {
+		return Game{
+			Sport:    "Football",
+			Location: "Campus pitch",
+			StartsAt: start,
+			EndsAt:   start.Add(time.Hour),
+			Capacity: 10,
+		}
+	}
+	venue := int64(3)
+
+	tests := []struct {
+		name   string
+		mutate func(g *Game)
+		want   bool
+	}{
+		{"Valid game", func(g *Game) {}, false},
+		{"Venue instead of location", func(g *Game) { g.Location = ""; g.VenueID = &venue }, false},
+		{"Court with venue", func(g *Game) { g.VenueID, g.CourtID = &venue, &venue }, false},
+		{"Court without venue", func(g *Game) { g.CourtID = &venue }, true},
+		{"Missing sport", func(g *Game) { g.Sport = " " }, true},
+		{"Missing location", func(g *Game) { g.Location = "" }, true},
+		{"Ends before start", func(g *Game) { g.EndsAt = start.Add(-time.Hour) }, true},
+		{"Capacity too small", func(g *Game) { g.Capacity = 1 }, true},
+		{"Inverted skill range", func(g *Game) { g.SkillMin, g.SkillMax = 7, 3 }, true},
+		{"Valid skill range", func(g *Game) { g.SkillMin, g.SkillMax = 3, 7 }, false},
+		{"Unknown visibility", func(g *Game) { g.Visibility = "secret" }, true},
+		{"Paid game", func(g *Game) { g.FeeCents = 500 }, false},
+		{"Negative fee", func(g *Game) { g.FeeCents = -1 }, true},
+		{"Fee too high", func(g *Game) { g.FeeCents = MaxFeeCents + 1 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := valid()
+			tt.mutate(&g)
+			err := g.ValidateGame()
+			if (err != nil) != tt.want {
+				t.Errorf("ValidateGame() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	g := valid()
+	g.ValidateGame()
+	if g.Sport != "football" || g.Visibility != VisibilityPublic {
+		t.Errorf("ValidateGame did not normalize: sport %q, visibility %q", g.Sport, g.Visibility)
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/recurrence"
+)
+
+// Series is a recurring game. Its occurrences are generated from RRule and
+// only stored as games once someone joins or edits one.
+type Series struct {
+	ID              int64       `json:"id"`
+	OrganizerID     int64       `json:"organizerId"`
+	Sport           string      `json:"sport"`
+	VenueID         *int64      `json:"venueId,omitempty"`
+	Location        string      `json:"location"`
+	StartsAt        time.Time   `json:"startsAt"`
+	DurationMinutes int         `json:"durationMinutes"`
+	Capacity        int         `json:"capacity"`
+	SkillMin        int         `json:"skillMin"`
+	SkillMax        int         `json:"skillMax"`
+	Visibility      string      `json:"visibility"`
+	RRule           string      `json:"rrule"`
+	ExDates         []time.Time `json:"exdates"`
+	Status          string      `json:"status"`
+}
+
+// Occurrence is one date of a series, backed by a stored game once
+// materialized.
+type Occurrence struct {
+	SeriesID int64     `json:"seriesId"`
+	OccursAt time.Time `json:"occursAt"`
+	GameID   *int64    `json:"gameId,omitempty"`
+	Game     Game      `json:"game"`
+}
+
+func (s *Series) ValidateSeries() error {
+	if s.DurationMinutes <= 0 || s.DurationMinutes > 24*60 {
+		return fmt.Errorf("Validation errors: Duration must be between 1 minute and 24 hours")
+	}
+	g := s.Template(s.StartsAt)
+	if err := g.ValidateGame(); err != nil {
+		return err
+	}
+	s.Sport, s.Location, s.Visibility = g.Sport, g.Location, g.Visibility
+
+	r, err := recurrence.Parse(s.RRule)
+	if err != nil {
+		return fmt.Errorf("Validation errors: invalid rrule: %w", err)
+	}
+	s.RRule = r.String()
+	return nil
+}
+
+// Template returns the game for the occurrence starting at at, as the series
+// describes it before any per-occurrence edits.
+func (s *Series) Template(at time.Time) Game {
+	seriesID := s.ID
+	occursAt := at
+	return Game{
+		OrganizerID:  s.OrganizerID,
+		Sport:        s.Sport,
+		VenueID:      s.VenueID,
+		Location:     s.Location,
+		StartsAt:     at,
+		EndsAt:       at.Add(time.Duration(s.DurationMinutes) * time.Minute),
+		Capacity:     s.Capacity,
+		SkillMin:     s.SkillMin,
+		SkillMax:     s.SkillMax,
+		Visibility:   s.Visibility,
+		Status:       StatusOpen,
+		SeriesID:     &seriesID,
+		OccurrenceAt: &occursAt,
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateSeries(t *testing.T) {
+	valid := func() Series {
+		return Series{
+			Sport:           "Football",
+			Location:        "Astro pitch",
+			StartsAt:        time.Date(2026, 5, 5, 17, 0, 0, 0, time.UTC),
+			DurationMinutes: 60,
+			Capacity:        10,
+			RRule:           "RRULE:FREQ=WEEKLY;BYDAY=tu",
+		}
+	}
+
+	s := valid()
+	if err := s.ValidateSeries(); err != nil {
+		t.Fatalf("ValidateSeries: %v", err)
+	}
+	if s.RRule != "FREQ=WEEKLY;BYDAY=TU" || s.Sport != "football" || s.Visibility != VisibilityPublic {
+		t.Errorf("ValidateSeries did not normalize: %+v", s)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(s *Series)
+	}{
+		{"Unsupported rule", func(s *Series) { s.RRule = "FREQ=HOURLY" }},
+		{"Missing rule", func(s *Series) { s.RRule = "" }},
+		{"Zero duration", func(s *Series) { s.DurationMinutes = 0 }},
+		{"Bad capacity", func(s *Series) { s.Capacity = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.mutate(&s)
+			if err := s.ValidateSeries(); err == nil {
+				t.Error("ValidateSeries accepted an invalid series")
+			}
+		})
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	s := Series{ID: 3, Sport: "tennis", DurationMinutes: 90, Capacity: 4}
+	at := time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC)
+
+	g := s.Template(at)
+	if !g.EndsAt.Equal(at.Add(90*time.Minute)) || *g.SeriesID != 3 || !g.OccurrenceAt.Equal(at) {
+		t.Errorf("Template = %+v", g)
+	}
+}
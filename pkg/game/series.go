@@ -0,0 +1,160 @@
+package game
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/recurrence"
+)
+
+const (
+	EditThis   = "this"
+	EditFuture = "future"
+
+	// MaxOccurrenceWindow caps how far a single listing may expand a series.
+	MaxOccurrenceWindow = 366 * 24 * time.Hour
+)
+
+var (
+	ErrSeriesNotFound   = errors.New("series not found")
+	ErrNoSuchOccurrence = errors.New("the series has no occurrence at that time")
+)
+
+func Schedule(s model.Series) (recurrence.Set, error) {
+	r, err := recurrence.Parse(s.RRule)
+	if err != nil {
+		return recurrence.Set{}, err
+	}
+	return recurrence.Set{Start: s.StartsAt, Rule: r, ExDates: s.ExDates}, nil
+}
+
+// CheckOccurrence makes sure at is a live occurrence of the series.
+func CheckOccurrence(s model.Series, at time.Time) error {
+	if s.Status != model.StatusOpen {
+		return ErrGameClosed
+	}
+	set, err := Schedule(s)
+	if err != nil {
+		return err
+	}
+	if !set.Includes(at) {
+		return ErrNoSuchOccurrence
+	}
+	return nil
+}
+
+// Occurrences expands the series over [from, to) and overlays the games that
+// have already been materialized. Materialized games are listed even when
+// their date has since been excluded, so their players see the cancellation.
+func Occurrences(s model.Series, from, to time.Time, materialized []model.Game) ([]model.Occurrence, error) {
+	set, err := Schedule(s)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[int64]model.Game, len(materialized))
+	for _, g := range materialized {
+		if g.OccurrenceAt != nil {
+			byDate[g.OccurrenceAt.Unix()] = g
+		}
+	}
+
+	out := []model.Occurrence{}
+	if s.Status == model.StatusOpen {
+		for _, at := range set.Between(from, to) {
+			occ := model.Occurrence{SeriesID: s.ID, OccursAt: at, Game: s.Template(at)}
+			if g, ok := byDate[at.Unix()]; ok {
+				id := g.ID
+				occ.GameID, occ.Game = &id, g
+				delete(byDate, at.Unix())
+			}
+			out = append(out, occ)
+		}
+	}
+	for _, g := range byDate {
+		at := *g.OccurrenceAt
+		if at.Before(from) || !at.Before(to) {
+			continue
+		}
+		id := g.ID
+		out = append(out, model.Occurrence{SeriesID: s.ID, OccursAt: at, GameID: &id, Game: g})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].OccursAt.Before(out[j].OccursAt) })
+	return out, nil
+}
+
+// SplitSeries applies an "all future" edit made at occurrence at. It returns
+// the old series ending just before at and the new series carrying the edit
+// from at onwards, with COUNT and exclusions carried over. When at is the
+// first occurrence there is nothing to keep, and whole is true: the caller
+// should update the old series in place with next.
+func SplitSeries(old model.Series, at time.Time, next model.Series) (model.Series, model.Series, bool, error) {
+	set, err := Schedule(old)
+	if err != nil {
+		return old, next, false, err
+	}
+	rule := set.Rule
+
+	if next.StartsAt.IsZero() {
+		next.StartsAt = at
+	}
+	if SameRule(next.RRule, old.RRule) {
+		next.RRule = old.RRule
+	}
+	next.OrganizerID = old.OrganizerID
+	next.Status = model.StatusOpen
+
+	shift := next.StartsAt.Sub(at)
+	sameRule := SameRule(next.RRule, old.RRule)
+	next.ExDates = nil
+	if sameRule {
+		for _, ex := range old.ExDates {
+			if !ex.Before(at) {
+				next.ExDates = append(next.ExDates, ex.Add(shift))
+			}
+		}
+	}
+
+	before := set.CountBefore(at)
+	if before == 0 {
+		next.ID = old.ID
+		return old, next, true, nil
+	}
+
+	if sameRule && rule.Count > 0 {
+		nextRule := rule
+		nextRule.Count = rule.Count - before
+		next.RRule = nextRule.String()
+	}
+
+	truncated := old
+	if rule.Count > 0 {
+		rule.Count = before
+	} else {
+		rule.Until = at.Add(-time.Second)
+	}
+	truncated.RRule = rule.String()
+	var kept []time.Time
+	for _, ex := range old.ExDates {
+		if ex.Before(at) {
+			kept = append(kept, ex)
+		}
+	}
+	truncated.ExDates = kept
+
+	return truncated, next, false, nil
+}
+
+// SameRule reports whether two RRULE strings describe the same pattern. An
+// empty edit means "unchanged".
+func SameRule(edit, current string) bool {
+	if edit == "" {
+		return true
+	}
+	a, errA := recurrence.Parse(edit)
+	b, errB := recurrence.Parse(current)
+	return errA == nil && errB == nil && a.String() == b.String()
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+// 2026-05-05 is a Tuesday.
+var firstTuesday = time.Date(2026, 5, 5, 17, 0, 0, 0, time.UTC)
+
+func weeklySeries() model.Series {
+	return model.Series{
+		ID:              7,
+		OrganizerID:     10,
+		Sport:           "football",
+		Location:        "Astro pitch",
+		StartsAt:        firstTuesday,
+		DurationMinutes: 60,
+		Capacity:        10,
+		Visibility:      model.VisibilityPublic,
+		RRule:           "FREQ=WEEKLY;BYDAY=TU",
+		Status:          model.StatusOpen,
+	}
+}
+
+func week(n int) time.Time {
+	return firstTuesday.AddDate(0, 0, 7*n)
+}
+
+func TestOccurrencesOverlayMaterialized(t *testing.T) {
+	s := weeklySeries()
+	s.ExDates = []time.Time{week(1), week(2)}
+
+	edited := s.Template(week(0))
+	edited.ID = 99
+	edited.Location = "Indoor hall"
+	cancelled := s.Template(week(2))
+	cancelled.ID = 100
+	cancelled.Status = model.StatusCancelled
+
+	got, err := Occurrences(s, week(0), week(4), []model.Game{edited, cancelled})
+	if err != nil {
+		t.Fatalf("Occurrences: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d occurrences, want 3: %+v", len(got), got)
+	}
+	if got[0].GameID == nil || got[0].Game.Location != "Indoor hall" {
+		t.Errorf("first occurrence not overlaid with the edited game: %+v", got[0])
+	}
+	if got[1].GameID == nil || got[1].Game.Status != model.StatusCancelled {
+		t.Errorf("cancelled materialized occurrence missing: %+v", got[1])
+	}
+	if !got[2].OccursAt.Equal(week(3)) || got[2].GameID != nil {
+		t.Errorf("third occurrence = %+v, want virtual week 3", got[2])
+	}
+	if !got[2].Game.EndsAt.Equal(week(3).Add(time.Hour)) {
+		t.Errorf("template end = %v, want one hour after start", got[2].Game.EndsAt)
+	}
+}
+
+func TestCheckOccurrence(t *testing.T) {
+	s := weeklySeries()
+	s.ExDates = []time.Time{week(1)}
+
+	if err := CheckOccurrence(s, week(2)); err != nil {
+		t.Errorf("CheckOccurrence(week 2): %v", err)
+	}
+	if err := CheckOccurrence(s, week(1)); err != ErrNoSuchOccurrence {
+		t.Errorf("CheckOccurrence(excluded) = %v, want %v", err, ErrNoSuchOccurrence)
+	}
+	if err := CheckOccurrence(s, week(2).Add(time.Hour)); err != ErrNoSuchOccurrence {
+		t.Errorf("CheckOccurrence(off schedule) = %v, want %v", err, ErrNoSuchOccurrence)
+	}
+	s.Status = model.StatusCancelled
+	if err := CheckOccurrence(s, week(2)); err != ErrGameClosed {
+		t.Errorf("CheckOccurrence(cancelled series) = %v, want %v", err, ErrGameClosed)
+	}
+}
+
+func TestSplitSeriesWithUntil(t *testing.T) {
+	old := weeklySeries()
+	old.ExDates = []time.Time{week(1), week(5)}
+
+	next := old
+	next.StartsAt = week(3).Add(time.Hour)
+	next.Location = "New pitch"
+
+	truncated, created, whole, err := SplitSeries(old, week(3), next)
+	if err != nil || whole {
+		t.Fatalf("SplitSeries = whole %v, err %v", whole, err)
+	}
+
+	oldSet, _ := Schedule(truncated)
+	got := oldSet.Between(week(0), week(10))
+	if len(got) != 2 || !got[1].Equal(week(2)) {
+		t.Errorf("old series occurrences = %v, want weeks 0 and 2", got)
+	}
+
+	newSet, _ := Schedule(created)
+	got = newSet.Between(week(0), week(7))
+	want := []time.Time{week(3).Add(time.Hour), week(4).Add(time.Hour), week(6).Add(time.Hour)}
+	if len(got) != len(want) {
+		t.Fatalf("new series occurrences = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("new occurrence %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if created.Location != "New pitch" {
+		t.Errorf("edit not carried to the new series: %q", created.Location)
+	}
+}
+
+func TestSplitSeriesCarriesCount(t *testing.T) {
+	old := weeklySeries()
+	old.RRule = "FREQ=WEEKLY;COUNT=6;BYDAY=TU"
+
+	truncated, created, _, err := SplitSeries(old, week(2), old)
+	if err != nil {
+		t.Fatalf("SplitSeries: %v", err)
+	}
+	if truncated.RRule != "FREQ=WEEKLY;COUNT=2;BYDAY=TU" {
+		t.Errorf("truncated rule = %q", truncated.RRule)
+	}
+	if created.RRule != "FREQ=WEEKLY;COUNT=4;BYDAY=TU" {
+		t.Errorf("new rule = %q", created.RRule)
+	}
+}
+
+func TestSplitSeriesAtFirstOccurrence(t *testing.T) {
+	old := weeklySeries()
+	next := old
+	next.Capacity = 12
+
+	_, created, whole, err := SplitSeries(old, week(0), next)
+	if err != nil || !whole {
+		t.Fatalf("SplitSeries at first occurrence = whole %v, err %v", whole, err)
+	}
+	if created.ID != old.ID || created.Capacity != 12 {
+		t.Errorf("whole-series edit = %+v", created)
+	}
+}
@@ -0,0 +1,230 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+)
+
+const defaultConfirmWindow = 2 * time.Hour
+
+var (
+	ErrAlreadyWaitlisted = errors.New("already on the waitlist for this game")
+	ErrNoOffer           = errors.New("you have no pending spot to confirm")
+	ErrOfferExpired      = errors.New("your offer has expired and passed to the next person")
+)
+
+// ConfirmWindow is how long a promoted waitlister has to claim the spot before
+// it is offered to the next person. Set WAITLIST_CONFIRM_WINDOW to override.
+var ConfirmWindow = confirmWindowFromEnv()
+
+func confirmWindowFromEnv() time.Duration {
+	v := os.Getenv("WAITLIST_CONFIRM_WINDOW")
+	if v == "" {
+		return defaultConfirmWindow
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid WAITLIST_CONFIRM_WINDOW %q, using %s", v, defaultConfirmWindow)
+		return defaultConfirmWindow
+	}
+	return d
+}
+
+type WaitEntry struct {
+	UserID         int64     `json:"userId"`
+	Offered        bool      `json:"offered"`
+	OfferExpiresAt time.Time `json:"offerExpiresAt,omitempty"`
+}
+
+type Offer struct {
+	UserID    int64
+	ExpiresAt time.Time
+}
+
+// Roster is the participants and waitlist of one game. Callers must hold the
+// game's row lock for the whole load-mutate-save cycle; Roster itself is not
+// safe for concurrent use.
+type Roster struct {
+	Capacity     int
+	StartsAt     time.Time
+	Participants []int64
+	Waitlist     []WaitEntry
+}
+
+// Change records what a roster operation did so it can be persisted and the
+// affected users notified.
+type Change struct {
+	Joined     []int64
+	Left       []int64
+	Waitlisted []int64
+	Offered    []Offer
+	// Dropped lists users removed from the waitlist without joining, whether
+	// they left, declined or let their offer lapse.
+	Dropped []int64
+	Expired []int64
+}
+
+func (r *Roster) Join(userID int64, now time.Time, window time.Duration) (Change, error) {
+	var c Change
+	r.expire(now, &c)
+
+	if r.isParticipant(userID) {
+		return c, ErrAlreadyJoined
+	}
+	if r.waitIndex(userID) >= 0 {
+		return c, ErrAlreadyWaitlisted
+	}
+
+	// Newcomers queue behind anyone still waiting for an offer, even if a
+	// seat is free, so fill hands seats out in waitlist order.
+	if r.heldSeats(now) < r.Capacity && !r.queueWaiting() {
+		r.Participants = append(r.Participants, userID)
+		c.Joined = append(c.Joined, userID)
+	} else {
+		r.Waitlist = append(r.Waitlist, WaitEntry{UserID: userID})
+		c.Waitlisted = append(c.Waitlisted, userID)
+	}
+
+	r.fill(now, window, &c)
+	return c, nil
+}
+
+// Leave removes the user from the participants or the waitlist and offers any
+// freed seat to the next person in line.
+func (r *Roster) Leave(userID int64, now time.Time, window time.Duration) (Change, error) {
+	var c Change
+	r.expire(now, &c)
+
+	if i := r.participantIndex(userID); i >= 0 {
+		r.Participants = append(r.Participants[:i], r.Participants[i+1:]...)
+		c.Left = append(c.Left, userID)
+	} else if i := r.waitIndex(userID); i >= 0 {
+		r.Waitlist = append(r.Waitlist[:i], r.Waitlist[i+1:]...)
+		c.Dropped = append(c.Dropped, userID)
+	} else {
+		return c, ErrNotJoined
+	}
+
+	r.fill(now, window, &c)
+	return c, nil
+}
+
+// Confirm turns a pending offer into a seat.
+func (r *Roster) Confirm(userID int64, now time.Time, window time.Duration) (Change, error) {
+	var c Change
+	r.expire(now, &c)
+
+	for _, id := range c.Expired {
+		if id == userID {
+			r.fill(now, window, &c)
+			return c, ErrOfferExpired
+		}
+	}
+	i := r.waitIndex(userID)
+	if i < 0 || !r.Waitlist[i].Offered {
+		r.fill(now, window, &c)
+		return c, ErrNoOffer
+	}
+
+	r.Waitlist = append(r.Waitlist[:i], r.Waitlist[i+1:]...)
+	r.Participants = append(r.Participants, userID)
+	c.Joined = append(c.Joined, userID)
+
+	r.fill(now, window, &c)
+	return c, nil
+}
+
+// Expire drops lapsed offers and passes their seats down the waitlist.
+func (r *Roster) Expire(now time.Time, window time.Duration) Change {
+	var c Change
+	r.expire(now, &c)
+	r.fill(now, window, &c)
+	return c
+}
+
+// Position returns the user's 1-based place on the waitlist, or 0.
+func (r *Roster) Position(userID int64) int {
+	return r.waitIndex(userID) + 1
+}
+
+func (r *Roster) expire(now time.Time, c *Change) {
+	kept := r.Waitlist[:0]
+	for _, e := range r.Waitlist {
+		if e.Offered && !now.Before(e.OfferExpiresAt) {
+			c.Dropped = append(c.Dropped, e.UserID)
+			c.Expired = append(c.Expired, e.UserID)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	r.Waitlist = kept
+}
+
+// fill offers free seats to the earliest waiters who do not have one yet.
+// Outstanding offers hold their seat until confirmed or expired.
+func (r *Roster) fill(now time.Time, window time.Duration, c *Change) {
+	if !now.Before(r.StartsAt) {
+		return
+	}
+	free := r.Capacity - r.heldSeats(now)
+	for i := range r.Waitlist {
+		if free <= 0 {
+			return
+		}
+		if r.Waitlist[i].Offered {
+			continue
+		}
+		expires := now.Add(window)
+		if expires.After(r.StartsAt) {
+			expires = r.StartsAt
+		}
+		r.Waitlist[i].Offered = true
+		r.Waitlist[i].OfferExpiresAt = expires
+		c.Offered = append(c.Offered, Offer{UserID: r.Waitlist[i].UserID, ExpiresAt: expires})
+		free--
+	}
+}
+
+func (r *Roster) heldSeats(now time.Time) int {
+	held := len(r.Participants)
+	for _, e := range r.Waitlist {
+		if e.Offered && now.Before(e.OfferExpiresAt) {
+			held++
+		}
+	}
+	return held
+}
+
+// queueWaiting reports whether anyone on the waitlist has no offer yet.
+func (r *Roster) queueWaiting() bool {
+	for _, e := range r.Waitlist {
+		if !e.Offered {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Roster) isParticipant(userID int64) bool {
+	return r.participantIndex(userID) >= 0
+}
+
+func (r *Roster) participantIndex(userID int64) int {
+	for i, id := range r.Participants {
+		if id == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *Roster) waitIndex(userID int64) int {
+	for i, e := range r.Waitlist {
+		if e.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
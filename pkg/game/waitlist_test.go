@@ -0,0 +1,210 @@
+package game
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+const window = 30 * time.Minute
+
+func newRoster(capacity int, participants ...int64) *Roster {
+	return &Roster{
+		Capacity:     capacity,
+		StartsAt:     now.Add(24 * time.Hour),
+		Participants: participants,
+	}
+}
+
+func TestRosterJoinWaitlistsWhenFull(t *testing.T) {
+	r := newRoster(2, 1)
+
+	c, err := r.Join(2, now, window)
+	if err != nil || len(c.Joined) != 1 {
+		t.Fatalf("Join(2) = %+v, %v; want a seat", c, err)
+	}
+	c, err = r.Join(3, now, window)
+	if err != nil || len(c.Waitlisted) != 1 {
+		t.Fatalf("Join(3) = %+v, %v; want waitlisted", c, err)
+	}
+	if pos := r.Position(3); pos != 1 {
+		t.Errorf("Position(3) = %d, want 1", pos)
+	}
+	if _, err := r.Join(3, now, window); err != ErrAlreadyWaitlisted {
+		t.Errorf("second Join(3) error = %v, want %v", err, ErrAlreadyWaitlisted)
+	}
+	if _, err := r.Join(2, now, window); err != ErrAlreadyJoined {
+		t.Errorf("second Join(2) error = %v, want %v", err, ErrAlreadyJoined)
+	}
+}
+
+func TestRosterLeavePromotesFirstWaiter(t *testing.T) {
+	r := newRoster(2, 1, 2)
+	r.Join(3, now, window)
+	r.Join(4, now, window)
+
+	c, err := r.Leave(2, now, window)
+	if err != nil {
+		t.Fatalf("Leave(2): %v", err)
+	}
+	if len(c.Offered) != 1 || c.Offered[0].UserID != 3 {
+		t.Fatalf("Leave offered %+v, want user 3", c.Offered)
+	}
+	if !c.Offered[0].ExpiresAt.Equal(now.Add(window)) {
+		t.Errorf("offer expires at %v, want %v", c.Offered[0].ExpiresAt, now.Add(window))
+	}
+
+	// The offered seat is held, so a newcomer is waitlisted behind user 4.
+	c, _ = r.Join(5, now, window)
+	if len(c.Waitlisted) != 1 || r.Position(5) != 3 {
+		t.Errorf("newcomer not waitlisted behind existing waiters: %+v, position %d", c, r.Position(5))
+	}
+
+	c, err = r.Confirm(3, now.Add(time.Minute), window)
+	if err != nil || len(c.Joined) != 1 || c.Joined[0] != 3 {
+		t.Fatalf("Confirm(3) = %+v, %v", c, err)
+	}
+	if _, err := r.Confirm(4, now, window); err != ErrNoOffer {
+		t.Errorf("Confirm(4) error = %v, want %v", err, ErrNoOffer)
+	}
+}
+
+func TestRosterNewSeatGoesToWaitlist(t *testing.T) {
+	r := newRoster(2, 1, 2)
+	r.Join(3, now, window)
+	r.Join(4, now, window)
+
+	// A seat added without offering it is still not the newcomer's.
+	r.Capacity = 3
+	c, err := r.Join(5, now, window)
+	if err != nil || len(c.Joined) != 0 || len(c.Waitlisted) != 1 || r.Position(5) != 3 {
+		t.Fatalf("Join(5) = %+v, %v; want waitlisted behind 3 and 4", c, err)
+	}
+	if len(c.Offered) != 1 || c.Offered[0].UserID != 3 {
+		t.Fatalf("Join(5) offered %+v, want the new seat offered to user 3", c.Offered)
+	}
+
+	r = newRoster(2, 1, 2)
+	r.Join(3, now, window)
+	r.Capacity = 3
+	c = r.Expire(now, window)
+	if len(c.Offered) != 1 || c.Offered[0].UserID != 3 {
+		t.Fatalf("Expire offered %+v, want the new seat offered to user 3", c.Offered)
+	}
+	if c, _ := r.Join(5, now, window); len(c.Waitlisted) != 1 {
+		t.Errorf("Join(5) = %+v; want waitlisted while the new seat is offered", c)
+	}
+}
+
+func TestRosterExpiredOfferPassesToNext(t *testing.T) {
+	r := newRoster(1, 1)
+	r.Join(2, now, window)
+	r.Join(3, now, window)
+	r.Leave(1, now, window)
+
+	later := now.Add(window)
+	if _, err := r.Confirm(2, later, window); err != ErrOfferExpired {
+		t.Fatalf("Confirm after window error = %v, want %v", err, ErrOfferExpired)
+	}
+	if r.Position(2) != 0 {
+		t.Error("expired waiter is still on the waitlist")
+	}
+	if len(r.Waitlist) != 1 || !r.Waitlist[0].Offered || r.Waitlist[0].UserID != 3 {
+		t.Errorf("seat did not pass to user 3: %+v", r.Waitlist)
+	}
+
+	c := r.Expire(later.Add(window), window)
+	if len(c.Expired) != 1 || c.Expired[0] != 3 || len(c.Offered) != 0 {
+		t.Errorf("Expire = %+v, want user 3 expired and nobody offered", c)
+	}
+}
+
+func TestRosterOfferNeverOutlivesStart(t *testing.T) {
+	r := newRoster(1, 1)
+	r.StartsAt = now.Add(10 * time.Minute)
+	r.Join(2, now, window)
+
+	c, _ := r.Leave(1, now, window)
+	if len(c.Offered) != 1 || !c.Offered[0].ExpiresAt.Equal(r.StartsAt) {
+		t.Errorf("offer = %+v, want expiry capped at start %v", c.Offered, r.StartsAt)
+	}
+
+	r = newRoster(1, 1)
+	r.StartsAt = now
+	r.Join(2, now.Add(-time.Hour), window)
+	if c, _ := r.Leave(1, now, window); len(c.Offered) != 0 {
+		t.Errorf("offered a seat after the game started: %+v", c.Offered)
+	}
+}
+
+// TestRosterConcurrentNoDoublePromotion drives one roster from many
+// goroutines, with a mutex standing in for the game row lock, and checks that
+// nobody is ever promoted twice or the game overbooked.
+func TestRosterConcurrentNoDoublePromotion(t *testing.T) {
+	const (
+		capacity = 5
+		players  = 60
+	)
+	r := newRoster(capacity)
+	var mu sync.Mutex
+	offers := make(map[int64]int)
+	var violations []string
+
+	check := func(c Change, at time.Time) {
+		for _, o := range c.Offered {
+			offers[o.UserID]++
+			if offers[o.UserID] > 1 {
+				violations = append(violations, "user promoted twice")
+			}
+		}
+		if held := r.heldSeats(at); held > capacity {
+			violations = append(violations, "roster overbooked")
+		}
+		seen := make(map[int64]bool)
+		for _, id := range r.Participants {
+			seen[id] = true
+		}
+		for _, e := range r.Waitlist {
+			if seen[e.UserID] {
+				violations = append(violations, "user both seated and waitlisted")
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := int64(1); i <= players; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(id))
+			at := now.Add(time.Duration(rng.Intn(60)) * time.Second)
+
+			mu.Lock()
+			c, _ := r.Join(id, at, window)
+			check(c, at)
+			mu.Unlock()
+
+			switch rng.Intn(3) {
+			case 0:
+				mu.Lock()
+				c, _ := r.Leave(id, at, window)
+				check(c, at)
+				mu.Unlock()
+			case 1:
+				mu.Lock()
+				c, _ := r.Confirm(id, at, window)
+				check(c, at)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(violations) > 0 {
+		t.Fatalf("invariants violated: %v", violations)
+	}
+	if len(r.Participants) > capacity {
+		t.Errorf("%d participants, capacity %d", len(r.Participants), capacity)
+	}
+}
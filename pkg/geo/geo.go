@@ -0,0 +1,54 @@
+package geo
+
+import "math"
+
+const earthRadiusKm = 6371.0
+
+type Point struct {
+	Lat float64 `json:"latitude"`
+	Lng float64 `json:"longitude"`
+}
+
+// Distance returns the great-circle distance between a and b in kilometres.
+func Distance(a, b Point) float64 {
+	lat1 := toRadians(a.Lat)
+	lat2 := toRadians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := toRadians(b.Lng - a.Lng)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// BoundingBox returns the min and max corners of a box containing every point
+// within radiusKm of p. It is meant for cheap SQL pre-filtering; callers still
+// need Distance for the exact cut.
+func BoundingBox(p Point, radiusKm float64) (Point, Point) {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	dLng := dLat / math.Max(math.Cos(toRadians(p.Lat)), 0.01)
+	return Point{Lat: p.Lat - dLat, Lng: p.Lng - dLng},
+		Point{Lat: p.Lat + dLat, Lng: p.Lng + dLng}
+}
+
+// Coarsen snaps p to the centre of a grid cell roughly cellKm wide, so that
+// every point in the cell is shown the same way and repeated lookups do not
+// narrow down the exact spot. A cellKm of zero or less returns p unchanged.
+func Coarsen(p Point, cellKm float64) Point {
+	if cellKm <= 0 {
+		return p
+	}
+	step := cellKm / earthRadiusKm * 180 / math.Pi
+	snap := func(v float64) float64 {
+		return (math.Floor(v/step) + 0.5) * step
+	}
+	return Point{Lat: snap(p.Lat), Lng: snap(p.Lng)}
+}
+
+func Valid(p Point) bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,70 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	lagos := Point{Lat: 6.5244, Lng: 3.3792}
+	abuja := Point{Lat: 9.0765, Lng: 7.3986}
+
+	got := Distance(lagos, abuja)
+	if math.Abs(got-524) > 5 {
+		t.Errorf("Distance(lagos, abuja) = %.1f, want ~524", got)
+	}
+	if d := Distance(lagos, lagos); d != 0 {
+		t.Errorf("Distance to self = %f, want 0", d)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	p := Point{Lat: 51.5, Lng: -0.12}
+	min, max := BoundingBox(p, 10)
+	edges := []Point{
+		{Lat: min.Lat, Lng: p.Lng},
+		{Lat: max.Lat, Lng: p.Lng},
+		{Lat: p.Lat, Lng: min.Lng},
+		{Lat: p.Lat, Lng: max.Lng},
+	}
+	for _, e := range edges {
+		if d := Distance(p, e); d < 9.9 {
+			t.Errorf("bounding box edge %v only %.2fkm away, want >= 10km", e, d)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Point
+		want bool
+	}{
+		{"Origin", Point{}, true},
+		{"Latitude too large", Point{Lat: 91}, false},
+		{"Longitude too small", Point{Lng: -181}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.p); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoarsen(t *testing.T) {
+	home := Point{Lat: 51.50722, Lng: -0.12750}
+	next := Point{Lat: 51.50731, Lng: -0.12741}
+
+	a, b := Coarsen(home, 2), Coarsen(next, 2)
+	if a != b {
+		t.Errorf("nearby points snapped to different cells: %v, %v", a, b)
+	}
+	if d := Distance(home, a); d > 2 {
+		t.Errorf("coarsened point moved %.2fkm, more than the cell size", d)
+	}
+	if Coarsen(home, 0) != home {
+		t.Error("Coarsen with no cell size changed the point")
+	}
+}
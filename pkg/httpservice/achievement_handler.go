@@ -0,0 +1,124 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/achievement"
+	"github.com/dudeiebot/sportPeerGo/pkg/achievement/model"
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	feedmodel "github.com/dudeiebot/sportPeerGo/pkg/feed/model"
+	notifymodel "github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+)
+
+// UserAchievements shows a user's progress toward every badge. It is part
+// of the profile and shares its privacy setting.
+func UserAchievements(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Achievement, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProfileAudience(ctx, s, userID); err != nil {
+			return nil, err
+		}
+		stats, err := query.AchievementStatsQuery(ctx, s.DBS, userID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		earned, err := query.EarnedBadgesQuery(ctx, s.DBS, userID)
+		if err != nil {
+			return nil, err
+		}
+		return achievement.Progress(stats, earned), nil
+	})
+}
+
+// achievementEvent awards the users any badges event has earned them.
+func achievementEvent(s *Server, event string, userIDs []int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, id := range userIDs {
+		if err := awardBadges(ctx, s, event, id); err != nil {
+			log.Printf("Failed to evaluate achievements of user %d: %v", id, err)
+		}
+	}
+}
+
+func awardBadges(ctx context.Context, s *Server, event string, userID int64) error {
+	earned, err := query.EarnedBadgesQuery(ctx, s.DBS, userID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	stats, err := query.AchievementStatsQuery(ctx, s.DBS, userID, now)
+	if err != nil {
+		return err
+	}
+	for _, r := range achievement.Earned(event, stats, earned) {
+		awarded, err := query.AwardBadgeQuery(ctx, s.DBS, userID, r.Badge, now)
+		if err != nil {
+			return err
+		}
+		if !awarded {
+			continue
+		}
+		a := feedmodel.Activity{
+			Type:    feedmodel.TypeBadgeEarned,
+			ActorID: &userID,
+			Key:     fmt.Sprintf("%s:%d:%s", feedmodel.TypeBadgeEarned, userID, r.Badge),
+		}
+		a.Data, _ = json.Marshal(feedmodel.Badge{Badge: r.Badge, Name: r.Name})
+		recordActivity(s, a)
+		notifyUsers(s, []int64{userID}, notifymodel.Notification{
+			Type:  notifymodel.TypeBadgeEarned,
+			Title: fmt.Sprintf("You earned the %s badge", r.Name),
+			Body:  r.Description + ".",
+		})
+	}
+	return nil
+}
+
+// evaluateEndedGames awards badges to the players of games that have
+// ended since the last run.
+func evaluateEndedGames(ctx context.Context, s *Server) {
+	now := time.Now()
+	games, err := query.EndedGamesQuery(ctx, s.DBS, now)
+	if err != nil {
+		log.Printf("Failed to load ended games: %v", err)
+		return
+	}
+	for gameID, ids := range games {
+		achievementEvent(s, model.EventGamePlayed, ids)
+		if err := query.MarkAchievementsEvaluatedQuery(ctx, s.DBS, gameID, now); err != nil {
+			log.Printf("Failed to mark achievements of game %d evaluated: %v", gameID, err)
+		}
+	}
+}
+
+// evaluateSettledMonths awards attendance badges for calendar months once
+// they are final, so a month in progress never counts toward one.
+func evaluateSettledMonths(ctx context.Context, s *Server) {
+	now := time.Now()
+	months, err := query.SettledMonthsQuery(ctx, s.DBS, now)
+	if err != nil {
+		log.Printf("Failed to load settled months: %v", err)
+		return
+	}
+	for _, month := range months {
+		ids, err := query.MonthPlayersQuery(ctx, s.DBS, month)
+		if err != nil {
+			log.Printf("Failed to load players of %s: %v", month, err)
+			continue
+		}
+		achievementEvent(s, model.EventMonthSettled, ids)
+		if err := query.MarkMonthSweptQuery(ctx, s.DBS, month, now); err != nil {
+			log.Printf("Failed to mark %s swept: %v", month, err)
+		}
+	}
+}
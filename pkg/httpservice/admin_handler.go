@@ -0,0 +1,36 @@
+package httpservice
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+)
+
+// AdminMiddleware only lets admins through. It must run after
+// user.AuthMiddleware, which puts the caller's userId in the context.
+func AdminMiddleware(s *Server, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, ok := r.Context().Value("userId").(int64)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		isAdmin, err := query.IsAdminQuery(r.Context(), s.DBS, userId)
+		if err != nil {
+			log.Printf("Error checking admin flag: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !isAdmin {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func isAdmin(ctx context.Context, s *Server) (bool, error) {
+	return query.IsAdminQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+}
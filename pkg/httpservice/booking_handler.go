@@ -0,0 +1,143 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+type BookingResponse struct {
+	Message string         `json:"message"`
+	Booking *model.Booking `json:"booking,omitempty"`
+}
+
+// HoldCourt reserves a court slot for the caller. The hold lapses after
+// venue.HoldWindow unless it is confirmed.
+func HoldCourt(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*BookingResponse, error) {
+		venueID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var b model.Booking
+		if err := json.NewDecoder(req.Body).Decode(&b); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := b.ValidateBooking(); err != nil {
+			return nil, err
+		}
+		b.VenueID = venueID
+		b.UserID = ctx.Value("userId").(int64)
+		b.GameID = nil
+
+		id, err := query.HoldCourtQuery(ctx, s.DBS, b)
+		if err != nil {
+			return nil, err
+		}
+		held, err := query.GetBookingQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		return &BookingResponse{
+			Message: fmt.Sprintf("Court held, confirm within %s to keep it", venue.HoldWindow),
+			Booking: held,
+		}, nil
+	})
+}
+
+func ConfirmBooking(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*BookingResponse, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		b, err := query.ConfirmBookingQuery(ctx, s.DBS, id, ctx.Value("userId").(int64))
+		if err != nil {
+			return nil, err
+		}
+		return &BookingResponse{Message: "Booking confirmed", Booking: b}, nil
+	})
+}
+
+func CancelBooking(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*BookingResponse, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		b, err := query.CancelBookingQuery(ctx, s.DBS, id, ctx.Value("userId").(int64))
+		if err != nil {
+			return nil, err
+		}
+		if b.LateCancel {
+			return &BookingResponse{
+				Message: "Booking cancelled late, the venue may still charge for the court",
+				Booking: b,
+			}, nil
+		}
+		return &BookingResponse{Message: "Booking cancelled", Booking: b}, nil
+	})
+}
+
+func MyBookings(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Booking, error) {
+		return query.UserBookingsQuery(ctx, s.DBS, ctx.Value("userId").(int64), time.Now())
+	})
+}
+
+// VenueAvailability returns the court grid for one day at the venue. The
+// date is read in the venue's timezone and slot is the cell length in minutes.
+func VenueAvailability(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]venue.CourtAvailability, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		v, err := query.GetVenueQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		if !venue.CanView(*v, 0, false) {
+			return nil, venue.ErrVenueNotFound
+		}
+
+		q := req.URL.Query()
+		now := time.Now()
+		day := now.In(v.Loc())
+		if d := q.Get("date"); d != "" {
+			day, err = time.Parse("2006-01-02", d)
+			if err != nil {
+				return nil, fmt.Errorf("invalid date, expected YYYY-MM-DD")
+			}
+		}
+		slot := 30 * time.Minute
+		if m := q.Get("slot"); m != "" {
+			minutes, err := strconv.Atoi(m)
+			if err != nil || minutes < 15 || minutes > 240 {
+				return nil, fmt.Errorf("slot must be between 15 and 240 minutes")
+			}
+			slot = time.Duration(minutes) * time.Minute
+		}
+
+		from, to := venue.DayBounds(*v, day)
+		bookings, err := query.VenueBookingsQuery(ctx, s.DBS, v.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return venue.Availability(*v, day, slot, bookings, now), nil
+	})
+}
+
+func expireCourtHolds(ctx context.Context, s *Server) {
+	if _, err := query.ExpireCourtHoldsQuery(ctx, s.DBS, time.Now()); err != nil {
+		log.Printf("Failed to expire court holds: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package httpservice
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/calendar"
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+)
+
+// How far back the feed reaches, so recent games stay on the calendar.
+const feedLookback = 30 * 24 * time.Hour
+
+type FeedResponse struct {
+	Message string `json:"message"`
+	URL     string `json:"url"`
+}
+
+func CreateCalendarFeed(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*FeedResponse, error) {
+		token, hash, err := calendar.NewFeedToken()
+		if err != nil {
+			return nil, err
+		}
+		if err := query.CreateFeedTokenQuery(ctx, s.DBS, ctx.Value("userId").(int64), hash); err != nil {
+			return nil, err
+		}
+		return &FeedResponse{
+			Message: "Calendar feed created, any previous feed URL has been revoked",
+			URL:     fmt.Sprintf("%s/calendar/feed/%s.ics", baseURL(req), token),
+		}, nil
+	})
+}
+
+func RevokeCalendarFeed(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		n, err := query.RevokeFeedTokensQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return &Response{Message: "No active calendar feed"}, nil
+		}
+		return &Response{Message: "Calendar feed revoked"}, nil
+	})
+}
+
+// CalendarFeed serves a user's games as an iCalendar feed. The token in the
+// URL is the only credential, so it never accepts an access token.
+func CalendarFeed(s *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := chi.URLParam(r, "token")
+		userID, err := query.FeedUserQuery(r.Context(), s.DBS, calendar.HashFeedToken(token))
+		if err != nil {
+			http.Error(w, "Calendar feed not found", http.StatusNotFound)
+			return
+		}
+
+		games, err := query.CalendarGamesQuery(r.Context(), s.DBS, userID, time.Now().Add(-feedLookback))
+		if err != nil {
+			log.Printf("Error loading calendar games: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		writeCalendar(w, r, "sportPeer games", "sportpeer.ics", games)
+	}
+}
+
+func GameCalendar(s *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g, _, err := visibleGame(r.Context(), s, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeCalendar(w, r, "", fmt.Sprintf("game-%d.ics", g.ID), []model.Game{*g})
+	}
+}
+
+func writeCalendar(w http.ResponseWriter, r *http.Request, name, filename string, games []model.Game) {
+	host := r.Host
+	if i := strings.IndexByte(host, ':'); i >= 0 {
+		host = host[:i]
+	}
+	events := make([]calendar.Event, len(games))
+	for i, g := range games {
+		events[i] = calendar.GameEvent(g, baseURL(r), host)
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if err := calendar.Write(w, name, events, time.Now()); err != nil {
+		log.Printf("Error writing calendar: %v", err)
+	}
+}
+
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,313 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/chat"
+	"github.com/dudeiebot/sportPeerGo/pkg/chat/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/moderation"
+	teammodel "github.com/dudeiebot/sportPeerGo/pkg/team/model"
+)
+
+// Clients authenticate with a bearer token rather than cookies, so a
+// cross-origin page gains nothing by opening a socket and any origin is
+// allowed.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+type DirectConversationRequest struct {
+	UserID int64 `json:"userId"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body"`
+}
+
+type ReadRequest struct {
+	MessageID int64 `json:"messageId"`
+}
+
+func MyConversations(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Conversation, error) {
+		return query.ConversationsQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+	})
+}
+
+// StartConversation opens the direct conversation with another user, or
+// returns the existing one.
+func StartConversation(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Conversation, error) {
+		var in DirectConversationRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil || in.UserID == 0 {
+			return nil, fmt.Errorf("userId is required")
+		}
+		userId := ctx.Value("userId").(int64)
+		if in.UserID == userId {
+			return nil, chat.ErrSelf
+		}
+		blocked, err := query.BlockedIDsQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		if blocked[in.UserID] {
+			return nil, moderation.ErrBlocked
+		}
+
+		id, err := query.DirectConversationQuery(ctx, s.DBS, userId, in.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return query.GetConversationQuery(ctx, s.DBS, id)
+	})
+}
+
+// GameConversation returns the group chat of a game for its participants.
+func GameConversation(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Conversation, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		if !v.Participant {
+			return nil, chat.ErrNotMember
+		}
+		id, err := query.GameConversationQuery(ctx, s.DBS, g.ID)
+		if err != nil {
+			return nil, err
+		}
+		return query.GetConversationQuery(ctx, s.DBS, id)
+	})
+}
+
+// TeamConversation returns the group chat of a team for its members.
+func TeamConversation(s *Server) http.HandlerFunc {
+	return NewTeamHandler(
+		s,
+		teammodel.RoleMember,
+		func(ctx context.Context, req *http.Request, m teammodel.Member) (*model.Conversation, error) {
+			id, err := query.TeamConversationQuery(ctx, s.DBS, m.TeamID)
+			if err != nil {
+				return nil, err
+			}
+			return query.GetConversationQuery(ctx, s.DBS, id)
+		},
+	)
+}
+
+// ConversationMessages pages backwards through a conversation. Pass the
+// nextCursor of a page as ?before= to load the page before it.
+func ConversationMessages(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Page, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		if _, _, err := conversationFor(ctx, s, id, userId); err != nil {
+			return nil, err
+		}
+
+		q := req.URL.Query()
+		var before int64
+		if v := q.Get("before"); v != "" {
+			if before, err = strconv.ParseInt(v, 10, 64); err != nil || before < 0 {
+				return nil, fmt.Errorf("invalid before cursor")
+			}
+		}
+		limit := 0
+		if v := q.Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("invalid limit")
+			}
+		}
+		limit = chat.PageSize(limit)
+
+		messages, err := query.MessagesQuery(ctx, s.DBS, id, userId, before, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		page := chat.Paginate(messages, limit)
+		return &page, nil
+	})
+}
+
+func SendMessage(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Message, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var in SendMessageRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		return sendMessage(ctx, s, ctx.Value("userId").(int64), id, in.Body)
+	})
+}
+
+func MarkConversationRead(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Receipt, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var in ReadRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil || in.MessageID == 0 {
+			return nil, fmt.Errorf("messageId is required")
+		}
+		return markRead(ctx, s, ctx.Value("userId").(int64), id, in.MessageID)
+	})
+}
+
+func ConversationReceipts(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Receipt, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		if _, _, err := conversationFor(ctx, s, id, userId); err != nil {
+			return nil, err
+		}
+		receipts, err := query.ReceiptsQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		blocked, err := query.BlockedIDsQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		visible := receipts[:0]
+		for _, r := range receipts {
+			if !blocked[r.UserID] {
+				visible = append(visible, r)
+			}
+		}
+		return visible, nil
+	})
+}
+
+// ChatSocket upgrades to a WebSocket that pushes new messages and read
+// receipts from all of the user's conversations, and accepts both from the
+// client too. It sits behind streamAuth since browsers cannot set headers on
+// the handshake.
+func ChatSocket(s *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already replied with an error.
+			return
+		}
+		userId := r.Context().Value("userId").(int64)
+		s.Hub.Serve(r.Context(), conn, userId, func(ctx context.Context, userID int64, in chat.Inbound) error {
+			var err error
+			switch in.Type {
+			case chat.FrameMessage:
+				_, err = sendMessage(ctx, s, userID, in.ConversationID, in.Body)
+			case chat.FrameRead:
+				_, err = markRead(ctx, s, userID, in.ConversationID, in.MessageID)
+			default:
+				err = chat.ErrUnknownFrame
+			}
+			return err
+		})
+	}
+}
+
+func sendMessage(
+	ctx context.Context,
+	s *Server,
+	userID, conversationID int64,
+	body string,
+) (*model.Message, error) {
+	c, members, err := conversationFor(ctx, s, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	m := model.Message{ConversationID: conversationID, SenderID: userID, Body: body}
+	if err := m.ValidateMessage(); err != nil {
+		return nil, err
+	}
+	blocked, err := query.BlockedIDsQuery(ctx, s.DBS, userID)
+	if err != nil {
+		return nil, err
+	}
+	if c.Kind == model.KindDirect {
+		for _, id := range members {
+			if blocked[id] {
+				return nil, moderation.ErrBlocked
+			}
+		}
+	}
+
+	sent, err := query.CreateMessageQuery(ctx, s.DBS, m)
+	if err != nil {
+		return nil, err
+	}
+	s.Hub.Send(unblocked(members, blocked), chat.Event{Type: chat.EventMessage, Message: sent})
+	return sent, nil
+}
+
+func markRead(
+	ctx context.Context,
+	s *Server,
+	userID, conversationID, messageID int64,
+) (*model.Receipt, error) {
+	_, members, err := conversationFor(ctx, s, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := query.MarkReadQuery(ctx, s.DBS, conversationID, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := query.BlockedIDsQuery(ctx, s.DBS, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.Hub.Send(unblocked(members, blocked), chat.Event{Type: chat.EventReceipt, Receipt: r})
+	return r, nil
+}
+
+// conversationFor loads a conversation and its members, failing unless
+// userID is one of them.
+func conversationFor(
+	ctx context.Context,
+	s *Server,
+	conversationID, userID int64,
+) (*model.Conversation, []int64, error) {
+	c, err := query.GetConversationQuery(ctx, s.DBS, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := query.ConversationMembersQuery(ctx, s.DBS, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, id := range members {
+		if id == userID {
+			return c, members, nil
+		}
+	}
+	return nil, nil, chat.ErrNotMember
+}
+
+func unblocked(ids []int64, blocked map[int64]bool) []int64 {
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !blocked[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
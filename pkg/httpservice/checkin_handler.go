@@ -0,0 +1,86 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	achievementmodel "github.com/dudeiebot/sportPeerGo/pkg/achievement/model"
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/checkin"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	"github.com/dudeiebot/sportPeerGo/pkg/reputation/model"
+)
+
+type CheckInRequest struct {
+	Code string `json:"code"`
+}
+
+// CheckInCodeResponse carries the code to show as a QR code at the game.
+type CheckInCodeResponse struct {
+	Code     string    `json:"code"`
+	OpensAt  time.Time `json:"opensAt"`
+	ClosesAt time.Time `json:"closesAt"`
+}
+
+// CheckInCode gives the organizer the game's check-in code. Players scan it
+// at the game to be marked present.
+func CheckInCode(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*CheckInCodeResponse, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		if g.OrganizerID != v.UserID {
+			return nil, game.ErrNotOrganizer
+		}
+		code, err := checkin.NewCode(*g)
+		if err != nil {
+			return nil, err
+		}
+		opens, closes := checkin.Window(*g)
+		return &CheckInCodeResponse{Code: code, OpensAt: opens, ClosesAt: closes}, nil
+	})
+}
+
+// CheckIn marks the caller present at a game with the code scanned there.
+// Players who cannot scan it are checked in by the organizer through the
+// attendance endpoint instead.
+func CheckIn(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var in CheckInRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		now := time.Now()
+		if err := checkin.Verify(in.Code, g.ID, now); err != nil {
+			return nil, err
+		}
+		if err := query.CheckInQuery(ctx, s.DBS, g.ID, v.UserID, now); err != nil {
+			return nil, err
+		}
+		go achievementEvent(s, achievementmodel.EventAttendance, []int64{v.UserID})
+		return &Response{Message: "Checked in"}, nil
+	})
+}
+
+// UserAttendance returns how reliably a user turns up to games. It is part
+// of the profile and shares its privacy setting.
+func UserAttendance(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.AttendanceStats, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProfileAudience(ctx, s, userID); err != nil {
+			return nil, err
+		}
+		return query.AttendanceStatsQuery(ctx, s.DBS, userID)
+	})
+}
@@ -0,0 +1,163 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	achievementmodel "github.com/dudeiebot/sportPeerGo/pkg/achievement/model"
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/feed"
+	"github.com/dudeiebot/sportPeerGo/pkg/feed/model"
+	leaguemodel "github.com/dudeiebot/sportPeerGo/pkg/league/model"
+	tournamentmodel "github.com/dudeiebot/sportPeerGo/pkg/tournament/model"
+)
+
+// MyFeed lists what the caller's friends and teams have been up to, newest
+// first. Pass the nextCursor of a page as ?cursor= to load the next one.
+func MyFeed(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Page, error) {
+		userId := ctx.Value("userId").(int64)
+		q := req.URL.Query()
+		before, err := feed.DecodeCursor(q.Get("cursor"))
+		if err != nil {
+			return nil, err
+		}
+		limit := model.DefaultLimit
+		if v := q.Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > model.MaxLimit {
+				return nil, fmt.Errorf("limit must be between 1 and %d", model.MaxLimit)
+			}
+		}
+
+		viewer, err := query.FeedViewerQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		items, next, err := feed.Collect(viewer, before, limit, func(before int64, limit int) ([]model.Activity, error) {
+			return query.FeedActivitiesQuery(ctx, s.DBS, userId, before, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &model.Page{Items: items, NextCursor: feed.EncodeCursor(next)}, nil
+	})
+}
+
+func recordActivity(s *Server, a model.Activity) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := query.RecordActivityQuery(ctx, s.DBS, a); err != nil {
+		log.Printf("Failed to record activity %s: %v", a.Key, err)
+	}
+}
+
+// gameActivity is userID organizing or joining a game. Leaving and joining
+// again brings back the same activity rather than adding another.
+func gameActivity(typ string, gameID, userID int64) model.Activity {
+	return model.Activity{
+		Type:    typ,
+		ActorID: &userID,
+		GameID:  &gameID,
+		Key:     fmt.Sprintf("%s:%d:%d", typ, gameID, userID),
+	}
+}
+
+// wonActivity is a tournament entrant or league member winning, credited
+// to their team when they play as one.
+func wonActivity(key string, userID, teamID *int64, w model.Win) model.Activity {
+	a := model.Activity{Type: model.TypeWon, Key: key}
+	if teamID != nil {
+		a.TeamID = teamID
+	} else {
+		a.ActorID = userID
+	}
+	a.Data, _ = json.Marshal(w)
+	return a
+}
+
+// tournamentFinished records the champion's win once a tournament is over
+// and awards its players their badges.
+func tournamentFinished(s *Server, tournamentID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t, err := query.GetTournamentQuery(ctx, s.DBS, tournamentID)
+	if err != nil {
+		log.Printf("Failed to load tournament %d: %v", tournamentID, err)
+		return
+	}
+	if t.Status != tournamentmodel.StatusCompleted || t.ChampionID == nil {
+		return
+	}
+	e, err := query.EntrantQuery(ctx, s.DBS, t.ID, *t.ChampionID)
+	if err != nil {
+		log.Printf("Failed to load champion of tournament %d: %v", t.ID, err)
+		return
+	}
+	recordActivity(s, wonActivity(
+		fmt.Sprintf("won:tournament:%d", t.ID),
+		e.UserID, e.TeamID,
+		model.Win{Competition: t.Name, TournamentID: &t.ID},
+	))
+
+	players, err := query.EntrantPlayersQuery(ctx, s.DBS, e.ID)
+	if err != nil {
+		log.Printf("Failed to load players of entrant %d: %v", e.ID, err)
+		return
+	}
+	ids := make([]int64, 0, len(players))
+	for id := range players {
+		ids = append(ids, id)
+	}
+	achievementEvent(s, achievementmodel.EventTournamentWon, ids)
+}
+
+// fixtureScored records the winner of a league fixture. A corrected score
+// moves the win to the new winner, or removes it for a draw.
+func fixtureScored(s *Server, f leaguemodel.Fixture) {
+	if f.HomeScore == nil || f.AwayScore == nil {
+		return
+	}
+	key := fmt.Sprintf("won:fixture:%d", f.ID)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if *f.HomeScore == *f.AwayScore {
+		if err := query.DeleteActivityQuery(ctx, s.DBS, key); err != nil {
+			log.Printf("Failed to remove activity %s: %v", key, err)
+		}
+		return
+	}
+	l, err := query.FixtureLeagueQuery(ctx, s.DBS, f.ID)
+	if err != nil {
+		log.Printf("Failed to load league of fixture %d: %v", f.ID, err)
+		return
+	}
+	members, err := query.MembersQuery(ctx, s.DBS, f.HomeID, f.AwayID)
+	if err != nil || len(members) != 2 {
+		log.Printf("Failed to load members of fixture %d: %v", f.ID, err)
+		return
+	}
+	winner, loser := members[0], members[1]
+	if winner.ID != f.HomeID {
+		winner, loser = loser, winner
+	}
+	winnerScore, loserScore := *f.HomeScore, *f.AwayScore
+	if winnerScore < loserScore {
+		winner, loser = loser, winner
+		winnerScore, loserScore = loserScore, winnerScore
+	}
+	recordActivity(s, wonActivity(key, winner.UserID, winner.TeamID, model.Win{
+		Competition: l.Name,
+		LeagueID:    &l.ID,
+		FixtureID:   &f.ID,
+		Opponent:    loser.Name,
+		Score:       fmt.Sprintf("%d-%d", winnerScore, loserScore),
+	}))
+}
@@ -0,0 +1,268 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	notifymodel "github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/social"
+	"github.com/dudeiebot/sportPeerGo/pkg/social/model"
+)
+
+type FriendRequestBody struct {
+	UserID int64 `json:"userId"`
+}
+
+type MutualFriendsResponse struct {
+	UserID int64 `json:"userId"`
+	Count  int   `json:"mutualFriends"`
+}
+
+func SendFriendRequest(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		var in FriendRequestBody
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil || in.UserID == 0 {
+			return nil, fmt.Errorf("userId is required")
+		}
+		userId := ctx.Value("userId").(int64)
+		accepted, err := query.SendFriendRequestQuery(ctx, s.DBS, userId, in.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if accepted {
+			friendsChanged(s, userId, in.UserID)
+			go notifyFriendAccepted(s, in.UserID, userId)
+			return &Response{Message: "You are now friends"}, nil
+		}
+		go notifyUsers(s, []int64{in.UserID}, notifymodel.Notification{
+			Type:    notifymodel.TypeFriendRequest,
+			Title:   "New friend request",
+			Body:    "Someone wants to be your friend.",
+			ActorID: &userId,
+		})
+		return &Response{Message: "Friend request sent"}, nil
+	})
+}
+
+func IncomingFriendRequests(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.FriendRequest, error) {
+		return query.FriendRequestsQuery(ctx, s.DBS, ctx.Value("userId").(int64), false)
+	})
+}
+
+func SentFriendRequests(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.FriendRequest, error) {
+		return query.FriendRequestsQuery(ctx, s.DBS, ctx.Value("userId").(int64), true)
+	})
+}
+
+func AcceptFriendRequest(s *Server) http.HandlerFunc {
+	return respondFriendRequest(s, true)
+}
+
+func DeclineFriendRequest(s *Server) http.HandlerFunc {
+	return respondFriendRequest(s, false)
+}
+
+func respondFriendRequest(s *Server, accept bool) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		requesterID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		err = query.RespondFriendRequestQuery(ctx, s.DBS, requesterID, userId, accept)
+		if err != nil {
+			return nil, err
+		}
+		if accept {
+			friendsChanged(s, requesterID, userId)
+			go notifyFriendAccepted(s, requesterID, userId)
+			return &Response{Message: "Friend request accepted"}, nil
+		}
+		return &Response{Message: "Friend request declined"}, nil
+	})
+}
+
+func Unfriend(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		friendID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		if err := query.UnfriendQuery(ctx, s.DBS, userId, friendID); err != nil {
+			return nil, err
+		}
+		friendsChanged(s, userId, friendID)
+		return &Response{Message: "Friend removed"}, nil
+	})
+}
+
+func MyFriends(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Friend, error) {
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		return query.FriendsQuery(ctx, s.DBS, userId, userId, limit, offset)
+	})
+}
+
+// UserFriends lists another user's friends, which are part of their profile
+// and follow its privacy setting.
+func UserFriends(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Friend, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		viewer := viewerID(ctx)
+		err = checkAudience(
+			ctx, s, viewer, userID,
+			func(p model.Privacy) string { return p.Profile }, social.ErrPrivate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return query.FriendsQuery(ctx, s.DBS, userID, viewer, limit, offset)
+	})
+}
+
+// MutualFriends counts the friends the caller shares with another user. The
+// count reveals part of that user's friend list, so it follows the same
+// privacy setting as UserFriends.
+func MutualFriends(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*MutualFriendsResponse, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProfileAudience(ctx, s, userID); err != nil {
+			return nil, err
+		}
+		count, err := query.MutualFriendsCountQuery(ctx, s.DBS, ctx.Value("userId").(int64), userID)
+		if err != nil {
+			return nil, err
+		}
+		return &MutualFriendsResponse{UserID: userID, Count: count}, nil
+	})
+}
+
+func GetPrivacy(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Privacy, error) {
+		userId, err := pathUserID(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		p, err := query.PrivacyQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		return &p, nil
+	})
+}
+
+func UpdatePrivacy(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		userId, err := pathUserID(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		p, err := query.PrivacyQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		// Fields left out of the body keep their current value.
+		if err := json.NewDecoder(req.Body).Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := p.ValidatePrivacy(); err != nil {
+			return nil, err
+		}
+		p.UserID = userId
+
+		if err := query.UpdatePrivacyQuery(ctx, s.DBS, p); err != nil {
+			return nil, err
+		}
+		s.Matches.Invalidate(int(userId))
+		return &Response{Message: "Privacy settings updated"}, nil
+	})
+}
+
+// checkAudience returns denied unless viewer is in the audience that pick
+// selects from userID's privacy settings.
+func checkAudience(
+	ctx context.Context,
+	s *Server,
+	viewer, userID int64,
+	pick func(model.Privacy) string,
+	denied error,
+) error {
+	settings, err := query.PrivacyQuery(ctx, s.DBS, userID)
+	if err != nil {
+		return err
+	}
+	r, err := query.RelationQuery(ctx, s.DBS, viewer, userID)
+	if err != nil {
+		return err
+	}
+	if !social.Allows(pick(settings), r) {
+		return denied
+	}
+	return nil
+}
+
+// friendsChanged drops cached matches that may show either user's location
+// at the wrong precision now that their friendship changed.
+func friendsChanged(s *Server, a, b int64) {
+	s.Matches.Invalidate(int(a))
+	s.Matches.Invalidate(int(b))
+}
+
+// notifyFriendAccepted tells requesterID that friendID accepted their
+// friend request.
+func notifyFriendAccepted(s *Server, requesterID, friendID int64) {
+	notifyUsers(s, []int64{requesterID}, notifymodel.Notification{
+		Type:    notifymodel.TypeFriendAccepted,
+		Title:   "Friend request accepted",
+		Body:    "Your friend request was accepted.",
+		ActorID: &friendID,
+	})
+}
+
+// viewerID returns the signed-in user on routes behind
+// OptionalAuthMiddleware, or 0 for anonymous visitors.
+func viewerID(ctx context.Context) int64 {
+	id, _ := ctx.Value("userId").(int64)
+	return id
+}
+
+func pageParams(req *http.Request) (int, int, error) {
+	q := req.URL.Query()
+	limit, offset := 20, 0
+	if v := q.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 || l > 100 {
+			return 0, 0, fmt.Errorf("limit must be between 1 and 100")
+		}
+		limit = l
+	}
+	if v := q.Get("offset"); v != "" {
+		o, err := strconv.Atoi(v)
+		if err != nil || o < 0 {
+			return 0, 0, fmt.Errorf("invalid offset")
+		}
+		offset = o
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,471 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	feedmodel "github.com/dudeiebot/sportPeerGo/pkg/feed/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/live"
+	notifymodel "github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+	paymentmodel "github.com/dudeiebot/sportPeerGo/pkg/payment/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/social"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+	venuemodel "github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+type GameResponse struct {
+	Message string      `json:"message"`
+	Game    *model.Game `json:"game,omitempty"`
+}
+
+type JoinResponse struct {
+	Message  string `json:"message"`
+	Position int    `json:"waitlistPosition,omitempty"`
+}
+
+type InviteRequest struct {
+	UserIDs []int64 `json:"userIds"`
+}
+
+func CreateGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*GameResponse, error) {
+		var g model.Game
+		if err := json.NewDecoder(req.Body).Decode(&g); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := g.ValidateGame(); err != nil {
+			return nil, err
+		}
+		if !g.StartsAt.After(time.Now()) {
+			return nil, game.ErrStartInPast
+		}
+		if err := checkGameVenue(ctx, s, g.VenueID); err != nil {
+			return nil, err
+		}
+		if g.CourtID != nil {
+			b := venuemodel.Booking{CourtID: *g.CourtID, StartsAt: g.StartsAt, EndsAt: g.EndsAt}
+			if err := b.ValidateBooking(); err != nil {
+				return nil, err
+			}
+		}
+		g.OrganizerID = ctx.Value("userId").(int64)
+
+		id, err := query.CreateGameQuery(ctx, s.DBS, g)
+		if err != nil {
+			return nil, fmt.Errorf("error creating game: %w", err)
+		}
+		go recordActivity(s, gameActivity(feedmodel.TypeOrganizedGame, id, g.OrganizerID))
+		created, err := query.GetGameQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		return &GameResponse{Message: "Game created successfully", Game: created}, nil
+	})
+}
+
+func ListGames(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Game, error) {
+		userId := ctx.Value("userId").(int64)
+		q := req.URL.Query()
+
+		f := model.Filter{
+			Sport: usermodel.NormalizeSport(q.Get("sport")),
+			From:  time.Now(),
+			Limit: 20,
+		}
+		if v := q.Get("from"); v != "" {
+			from, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid from time, expected RFC3339")
+			}
+			f.From = from
+		}
+		if v := q.Get("to"); v != "" {
+			to, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid to time, expected RFC3339")
+			}
+			f.To = to
+		}
+		if v := q.Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit <= 0 || limit > 100 {
+				return nil, fmt.Errorf("limit must be between 1 and 100")
+			}
+			f.Limit = limit
+		}
+		if v := q.Get("offset"); v != "" {
+			offset, err := strconv.Atoi(v)
+			if err != nil || offset < 0 {
+				return nil, fmt.Errorf("invalid offset")
+			}
+			f.Offset = offset
+		}
+
+		// ?forMe=true leaves out games the caller's skill, as rated from
+		// their results, would keep them out of.
+		if q.Get("forMe") == "true" {
+			if f.Sport == "" {
+				return nil, fmt.Errorf("sport is required to filter by your skill")
+			}
+			skill, err := query.UserSkillQuery(ctx, s.DBS, userId, f.Sport)
+			if err != nil {
+				return nil, err
+			}
+			f.Skill = skill
+		}
+
+		return query.ListGamesQuery(ctx, s.DBS, userId, f)
+	})
+}
+
+func GetGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Game, error) {
+		g, _, err := visibleGame(ctx, s, req)
+		return g, err
+	})
+}
+
+func UpdateGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*GameResponse, error) {
+		gameID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var g model.Game
+		if err := json.NewDecoder(req.Body).Decode(&g); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := g.ValidateGame(); err != nil {
+			return nil, err
+		}
+		if err := checkGameVenue(ctx, s, g.VenueID); err != nil {
+			return nil, err
+		}
+		g.ID = gameID
+
+		userId := ctx.Value("userId").(int64)
+		c, err := query.UpdateGameQuery(ctx, s.DBS, g, userId)
+		if err != nil {
+			return nil, err
+		}
+		go rosterChanged(s, gameID, c)
+		updated, err := query.GetGameQuery(ctx, s.DBS, gameID)
+		if err != nil {
+			return nil, err
+		}
+		participants, err := query.GameParticipantsQuery(ctx, s.DBS, gameID)
+		if err != nil {
+			return nil, err
+		}
+		go notifyParticipants(s, participants, notifymodel.Notification{
+			Type:    notifymodel.TypeGameUpdated,
+			Title:   "Game updated",
+			Body:    fmt.Sprintf("The organizer changed the details of the %s.", gameLabel(updated)),
+			ActorID: &userId,
+			GameID:  &gameID,
+		})
+		return &GameResponse{Message: "Game updated successfully", Game: updated}, nil
+	})
+}
+
+func CancelGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		gameID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		g, err := query.GetGameQuery(ctx, s.DBS, gameID)
+		if err != nil {
+			return nil, err
+		}
+		participants, err := query.GameParticipantsQuery(ctx, s.DBS, gameID)
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		if err := query.CancelGameQuery(ctx, s.DBS, gameID, userId); err != nil {
+			return nil, err
+		}
+		go gameCancelled(s, gameID, participants)
+		go notifyParticipants(s, participants, notifymodel.Notification{
+			Type:    notifymodel.TypeGameCancelled,
+			Title:   "Game cancelled",
+			Body:    fmt.Sprintf("The %s has been cancelled.", gameLabel(g)),
+			ActorID: &userId,
+			GameID:  &gameID,
+		})
+		return &Response{Message: "Game cancelled"}, nil
+	})
+}
+
+func JoinGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*JoinResponse, error) {
+		gameID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var in PaymentRequest
+		if req.ContentLength > 0 {
+			if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+				return nil, fmt.Errorf("invalid request body")
+			}
+		}
+		userId := ctx.Value("userId").(int64)
+		c, position, err := query.JoinGameQuery(ctx, s.DBS, gameID, userId, s.Payments.Name())
+		if err != nil {
+			return nil, err
+		}
+		go rosterChanged(s, gameID, c)
+
+		if position > 0 {
+			return &JoinResponse{
+				Message:  "Game is full, you have been added to the waitlist",
+				Position: position,
+			}, nil
+		}
+		status, err := joinFee(ctx, s, gameID, userId, in.PaymentMethod)
+		if err != nil {
+			return nil, err
+		}
+		if status == paymentmodel.StatusPending {
+			return &JoinResponse{Message: "Joined game, your payment is processing"}, nil
+		}
+		return &JoinResponse{Message: "Joined game"}, nil
+	})
+}
+
+func LeaveGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		gameID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		c, err := query.LeaveGameQuery(ctx, s.DBS, gameID, ctx.Value("userId").(int64))
+		if err != nil {
+			return nil, err
+		}
+		go rosterChanged(s, gameID, c)
+
+		if len(c.Left) == 0 {
+			return &Response{Message: "Left waitlist"}, nil
+		}
+		return &Response{Message: "Left game"}, nil
+	})
+}
+
+func ConfirmWaitlistSpot(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		gameID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var in PaymentRequest
+		if req.ContentLength > 0 {
+			if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+				return nil, fmt.Errorf("invalid request body")
+			}
+		}
+		userId := ctx.Value("userId").(int64)
+		c, err := query.ConfirmWaitlistQuery(ctx, s.DBS, gameID, userId, s.Payments.Name())
+		go rosterChanged(s, gameID, c)
+		if err != nil {
+			return nil, err
+		}
+		status, err := joinFee(ctx, s, gameID, userId, in.PaymentMethod)
+		if err != nil {
+			return nil, err
+		}
+		if status == paymentmodel.StatusPending {
+			return &Response{Message: "Spot confirmed, your payment is processing"}, nil
+		}
+		return &Response{Message: "Spot confirmed, you have joined the game"}, nil
+	})
+}
+
+func GameWaitlist(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]game.WaitEntry, error) {
+		g, _, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		return query.WaitlistQuery(ctx, s.DBS, g.ID)
+	})
+}
+
+// gameCancelled streams the cancellation to everyone who was in the game and
+// refunds their fees.
+func gameCancelled(s *Server, gameID int64, participants []model.Participant) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ids := make([]int64, len(participants))
+	for i, p := range participants {
+		ids[i] = p.UserID
+	}
+	publishEvent(ctx, s, ids, live.TypeGameCancelled, live.GameCancelled{GameID: gameID})
+	refundGame(ctx, s, gameID)
+}
+
+// notifyOffers tells every waitlister who has just been offered a seat.
+func notifyOffers(s *Server, gameID int64, offers []game.Offer) {
+	if len(offers) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	g, err := query.GetGameQuery(ctx, s.DBS, gameID)
+	if err != nil {
+		log.Printf("Failed to load game %d for waitlist offers: %v", gameID, err)
+		return
+	}
+	for _, o := range offers {
+		notifyUsers(s, []int64{o.UserID}, notifymodel.Notification{
+			Type:  notifymodel.TypeWaitlistOffer,
+			Title: "A spot opened up in your game",
+			Body: fmt.Sprintf(
+				"A spot opened up in the %s you were waiting for. "+
+					"Confirm it before %s or it will pass to the next person on the waitlist.",
+				gameLabel(g), o.ExpiresAt.UTC().Format(time.RFC1123),
+			),
+			GameID: &gameID,
+		})
+	}
+}
+
+func InviteToGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		gameID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var in InviteRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil || len(in.UserIDs) == 0 {
+			return nil, fmt.Errorf("userIds is required")
+		}
+		userId := ctx.Value("userId").(int64)
+		if err := query.CheckInvitesQuery(ctx, s.DBS, userId, in.UserIDs); err != nil {
+			return nil, err
+		}
+		err = query.InviteToGameQuery(ctx, s.DBS, gameID, userId, in.UserIDs)
+		if err != nil {
+			return nil, err
+		}
+		g, err := query.GetGameQuery(ctx, s.DBS, gameID)
+		if err != nil {
+			return nil, err
+		}
+		go notifyUsers(s, in.UserIDs, notifymodel.Notification{
+			Type:    notifymodel.TypeGameInvite,
+			Title:   "You're invited to a game",
+			Body:    fmt.Sprintf("You have been invited to a %s.", gameLabel(g)),
+			ActorID: &userId,
+			GameID:  &gameID,
+		})
+		return &Response{Message: "Invites sent"}, nil
+	})
+}
+
+func GameParticipants(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Participant, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		participants, err := query.GameParticipantsQuery(ctx, s.DBS, g.ID)
+		if err != nil {
+			return nil, err
+		}
+		if g.OrganizerID != v.UserID {
+			return visibleParticipants(ctx, s, v.UserID, participants)
+		}
+		ids := make([]int64, len(participants))
+		for i, p := range participants {
+			ids[i] = p.UserID
+		}
+		standings, err := query.StandingsQuery(ctx, s.DBS, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range participants {
+			participants[i].Standing = standings[participants[i].UserID]
+		}
+		return participants, nil
+	})
+}
+
+// visibleParticipants leaves out players whose games privacy setting hides
+// their games from the viewer, and players on either side of a block.
+// Organizers always see their full roster.
+func visibleParticipants(
+	ctx context.Context,
+	s *Server,
+	viewer int64,
+	participants []model.Participant,
+) ([]model.Participant, error) {
+	ids := make([]int64, len(participants))
+	for i, p := range participants {
+		ids[i] = p.UserID
+	}
+	settings, err := query.PrivacyForUsersQuery(ctx, s.DBS, ids)
+	if err != nil {
+		return nil, err
+	}
+	friends, err := query.FriendIDsQuery(ctx, s.DBS, viewer)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := query.BlockedIDsQuery(ctx, s.DBS, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := []model.Participant{}
+	for _, p := range participants {
+		r := social.Relation{Self: p.UserID == viewer, Friend: friends[p.UserID], Blocked: blocked[p.UserID]}
+		if social.Allows(settings[p.UserID].Games, r) {
+			visible = append(visible, p)
+		}
+	}
+	return visible, nil
+}
+
+// visibleGame loads the game named by {id}, hiding it from users who are not
+// allowed to see it.
+func visibleGame(ctx context.Context, s *Server, req *http.Request) (*model.Game, game.Viewer, error) {
+	gameID, err := pathID(req, "id")
+	if err != nil {
+		return nil, game.Viewer{}, err
+	}
+	g, err := query.GetGameQuery(ctx, s.DBS, gameID)
+	if err != nil {
+		return nil, game.Viewer{}, err
+	}
+	v, err := query.GameViewerQuery(ctx, s.DBS, gameID, ctx.Value("userId").(int64))
+	if err != nil {
+		return nil, v, err
+	}
+	if !game.CanView(*g, v) {
+		return nil, v, game.ErrGameNotFound
+	}
+	return g, v, nil
+}
+
+func pathID(req *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(req, name), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s format", name)
+	}
+	return id, nil
+}
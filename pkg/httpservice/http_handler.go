@@ -11,6 +11,9 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/team"
+	teammodel "github.com/dudeiebot/sportPeerGo/pkg/team/model"
 	"github.com/dudeiebot/sportPeerGo/pkg/user/model"
 )
 
@@ -91,6 +94,33 @@ func NewUpdateHandler(
 		if rowsAffected == 0 {
 			return &Response{Message: "No rows affected"}, nil
 		}
+		s.Matches.Invalidate(user.ID)
 		return &Response{Message: successMessage}, nil
 	})
 }
+
+// NewTeamHandler is the team equivalent of NewUpdateHandler: instead of
+// matching {id} against the token's userId it loads the caller's membership
+// of team {id} and only runs targetFunc when their role is at least minRole.
+func NewTeamHandler[OUT any](
+	s *Server,
+	minRole string,
+	targetFunc func(ctx context.Context, req *http.Request, m teammodel.Member) (OUT, error),
+) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (OUT, error) {
+		var zero OUT
+		teamID, err := pathID(req, "id")
+		if err != nil {
+			return zero, err
+		}
+		userId := ctx.Value("userId").(int64)
+		m, err := query.TeamMemberQuery(ctx, s.DBS, teamID, userId)
+		if err != nil {
+			return zero, err
+		}
+		if !team.AtLeast(m.Role, minRole) {
+			return zero, team.ErrForbidden
+		}
+		return targetFunc(ctx, req, *m)
+	})
+}
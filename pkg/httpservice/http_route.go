@@ -1,6 +1,8 @@
 package httpservice
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 
 	"github.com/dudeiebot/sportPeerGo/pkg/user"
@@ -21,5 +23,260 @@ func UserRoute(r chi.Router, s *Server) {
 		r.Put("/username/{id}", user.AuthMiddleware(UpdateUsername(s)))
 		r.Put("/email/{id}", user.AuthMiddleware(UpdateEmail(s)))
 		r.Post("/forgot-password/{email}", SendOtp(s))
+		r.Get("/profile/{id}", user.OptionalAuthMiddleware(GetProfile(s)))
+		r.Put("/profile/{id}", user.AuthMiddleware(UpdateProfile(s)))
+		r.Get("/privacy/{id}", user.AuthMiddleware(GetPrivacy(s)))
+		r.Put("/privacy/{id}", user.AuthMiddleware(UpdatePrivacy(s)))
+		r.Get("/friends/{id}", user.OptionalAuthMiddleware(UserFriends(s)))
+		r.Get("/games/{id}", user.AuthMiddleware(UserGames(s)))
+		r.Get("/ratings/{id}", user.OptionalAuthMiddleware(UserRatings(s)))
+		r.Get("/ratings/{id}/history", user.OptionalAuthMiddleware(RatingHistory(s)))
+		r.Get("/reputation/{id}", user.OptionalAuthMiddleware(UserReputation(s)))
+		r.Get("/reputation/{id}/reviews", user.OptionalAuthMiddleware(UserReviews(s)))
+		r.Get("/attendance/{id}", user.OptionalAuthMiddleware(UserAttendance(s)))
+		r.Get("/achievements/{id}", user.OptionalAuthMiddleware(UserAchievements(s)))
+		r.Put("/avatar/{id}", user.AuthMiddleware(UploadAvatar(s)))
+		r.Delete("/avatar/{id}", user.AuthMiddleware(DeleteAvatar(s)))
+	})
+}
+
+func FriendRoute(r chi.Router, s *Server) {
+	r.Route("/friends", func(r chi.Router) {
+		r.Get("/", user.AuthMiddleware(MyFriends(s)))
+		r.Delete("/{id}", user.AuthMiddleware(Unfriend(s)))
+		r.Get("/{id}/mutual", user.AuthMiddleware(MutualFriends(s)))
+		r.Post("/requests", user.AuthMiddleware(SendFriendRequest(s)))
+		r.Get("/requests", user.AuthMiddleware(IncomingFriendRequests(s)))
+		r.Get("/requests/sent", user.AuthMiddleware(SentFriendRequests(s)))
+		r.Post("/requests/{id}/accept", user.AuthMiddleware(AcceptFriendRequest(s)))
+		r.Post("/requests/{id}/decline", user.AuthMiddleware(DeclineFriendRequest(s)))
+	})
+}
+
+func MatchRoute(r chi.Router, s *Server) {
+	r.Route("/matches", func(r chi.Router) {
+		r.Get("/", user.AuthMiddleware(FindMatches(s)))
+	})
+}
+
+func GameRoute(r chi.Router, s *Server) {
+	r.Route("/games", func(r chi.Router) {
+		r.Post("/", user.AuthMiddleware(CreateGame(s)))
+		r.Get("/", user.AuthMiddleware(ListGames(s)))
+		r.Get("/{id}", user.AuthMiddleware(GetGame(s)))
+		r.Put("/{id}", user.AuthMiddleware(UpdateGame(s)))
+		r.Delete("/{id}", user.AuthMiddleware(CancelGame(s)))
+		r.Get("/{id}/participants", user.AuthMiddleware(GameParticipants(s)))
+		r.Post("/{id}/join", user.AuthMiddleware(JoinGame(s)))
+		r.Post("/{id}/leave", user.AuthMiddleware(LeaveGame(s)))
+		r.Get("/{id}/waitlist", user.AuthMiddleware(GameWaitlist(s)))
+		r.Post("/{id}/waitlist/confirm", user.AuthMiddleware(ConfirmWaitlistSpot(s)))
+		r.Post("/{id}/invites", user.AuthMiddleware(InviteToGame(s)))
+		r.Get("/{id}/calendar.ics", user.AuthMiddleware(GameCalendar(s)))
+		r.Get("/{id}/conversation", user.AuthMiddleware(GameConversation(s)))
+		r.Post("/{id}/result", user.AuthMiddleware(ReportResult(s)))
+		r.Get("/{id}/result", user.AuthMiddleware(GetResult(s)))
+		r.Post("/{id}/result/confirm", user.AuthMiddleware(ConfirmResult(s)))
+		r.Post("/{id}/result/dispute", user.AuthMiddleware(DisputeResult(s)))
+		r.Post("/{id}/result/resolve", user.AuthMiddleware(ResolveResult(s)))
+		r.Post("/{id}/reviews", user.AuthMiddleware(ReviewPlayer(s)))
+		r.Get("/{id}/reviews", user.AuthMiddleware(MyGameReviews(s)))
+		r.Put("/{id}/attendance", user.AuthMiddleware(RecordAttendance(s)))
+		r.Get("/{id}/attendance", user.AuthMiddleware(GameAttendance(s)))
+		r.Get("/{id}/checkin/code", user.AuthMiddleware(CheckInCode(s)))
+		r.Post("/{id}/checkin", user.AuthMiddleware(CheckIn(s)))
+		r.Post("/{id}/expenses", user.AuthMiddleware(AddExpense(s)))
+		r.Get("/{id}/expenses", user.AuthMiddleware(GameExpenses(s)))
+		r.Post("/{id}/expenses/{expenseId}/shares/{userId}/paid", user.AuthMiddleware(MarkSharePaid(s)))
+		r.Get("/{id}/payments", user.AuthMiddleware(GamePayments(s)))
+	})
+}
+
+func SeriesRoute(r chi.Router, s *Server) {
+	r.Route("/series", func(r chi.Router) {
+		r.Post("/", user.AuthMiddleware(CreateSeries(s)))
+		r.Get("/{id}", user.AuthMiddleware(GetSeries(s)))
+		r.Delete("/{id}", user.AuthMiddleware(CancelSeries(s)))
+		r.Get("/{id}/occurrences", user.AuthMiddleware(ListOccurrences(s)))
+		r.Put("/{id}/occurrences/{at}", user.AuthMiddleware(EditOccurrence(s)))
+		r.Delete("/{id}/occurrences/{at}", user.AuthMiddleware(CancelOccurrence(s)))
+		r.Post("/{id}/occurrences/{at}/join", user.AuthMiddleware(JoinOccurrence(s)))
+		r.Post("/{id}/occurrences/{at}/leave", user.AuthMiddleware(LeaveOccurrence(s)))
+	})
+}
+
+func CalendarRoute(r chi.Router, s *Server) {
+	r.Route("/calendar", func(r chi.Router) {
+		r.Post("/feed", user.AuthMiddleware(CreateCalendarFeed(s)))
+		r.Delete("/feed", user.AuthMiddleware(RevokeCalendarFeed(s)))
+		r.Get("/feed/{token}.ics", CalendarFeed(s))
+	})
+}
+
+func VenueRoute(r chi.Router, s *Server) {
+	r.Route("/venues", func(r chi.Router) {
+		r.Get("/", SearchVenues(s))
+		r.Post("/", user.AuthMiddleware(SubmitVenue(s)))
+		r.Get("/mine", user.AuthMiddleware(MyVenues(s)))
+		r.Get("/{id}", user.OptionalAuthMiddleware(GetVenue(s)))
+		r.Put("/{id}", user.AuthMiddleware(UpdateVenue(s)))
+		r.Post("/{id}/courts", user.AuthMiddleware(AddCourt(s)))
+		r.Delete("/{id}/courts/{courtId}", user.AuthMiddleware(RemoveCourt(s)))
+		r.Get("/{id}/availability", VenueAvailability(s))
+		r.Post("/{id}/bookings", user.AuthMiddleware(HoldCourt(s)))
+		r.Post("/{id}/photos", user.AuthMiddleware(AddVenuePhoto(s)))
+		r.Delete("/{id}/photos/{photoId}", user.AuthMiddleware(DeleteVenuePhoto(s)))
+	})
+}
+
+func TeamRoute(r chi.Router, s *Server) {
+	r.Route("/teams", func(r chi.Router) {
+		r.Post("/", user.AuthMiddleware(CreateTeam(s)))
+		r.Get("/", ListTeams(s))
+		r.Get("/mine", user.AuthMiddleware(MyTeams(s)))
+		r.Get("/invites", user.AuthMiddleware(MyTeamInvites(s)))
+		r.Get("/{id}", GetTeam(s))
+		r.Put("/{id}", user.AuthMiddleware(UpdateTeam(s)))
+		r.Post("/{id}/invites", user.AuthMiddleware(InviteToTeam(s)))
+		r.Post("/{id}/invites/accept", user.AuthMiddleware(AcceptTeamInvite(s)))
+		r.Post("/{id}/invites/decline", user.AuthMiddleware(DeclineTeamInvite(s)))
+		r.Post("/{id}/leave", user.AuthMiddleware(LeaveTeam(s)))
+		r.Post("/{id}/transfer", user.AuthMiddleware(TransferTeam(s)))
+		r.Delete("/{id}/members/{userId}", user.AuthMiddleware(KickTeamMember(s)))
+		r.Put("/{id}/members/{userId}/role", user.AuthMiddleware(SetTeamRole(s)))
+		r.Get("/{id}/conversation", user.AuthMiddleware(TeamConversation(s)))
+		r.Get("/{id}/ledger", user.AuthMiddleware(TeamSettlement(s)))
+		r.Put("/{id}/photo", user.AuthMiddleware(UploadTeamPhoto(s)))
+		r.Delete("/{id}/photo", user.AuthMiddleware(DeleteTeamPhoto(s)))
+	})
+}
+
+func TournamentRoute(r chi.Router, s *Server) {
+	r.Route("/tournaments", func(r chi.Router) {
+		r.Post("/", user.AuthMiddleware(CreateTournament(s)))
+		r.Get("/", ListTournaments(s))
+		r.Get("/{id}", GetTournament(s))
+		r.Get("/{id}/bracket", TournamentBracket(s))
+		r.Get("/{id}/entrants", TournamentEntrants(s))
+		r.Post("/{id}/entrants", user.AuthMiddleware(RegisterForTournament(s)))
+		r.Delete("/{id}/entrants/{entrantId}", user.AuthMiddleware(WithdrawFromTournament(s)))
+		r.Put("/{id}/seeds", user.AuthMiddleware(SeedTournament(s)))
+		r.Post("/{id}/start", user.AuthMiddleware(StartTournament(s)))
+		r.Post("/{id}/matches/{matchId}/game", user.AuthMiddleware(LinkMatchGame(s)))
+		r.Post("/{id}/matches/{matchId}/winner", user.AuthMiddleware(SetMatchWinner(s)))
+	})
+}
+
+func LeagueRoute(r chi.Router, s *Server) {
+	r.Route("/leagues", func(r chi.Router) {
+		r.Post("/", user.AuthMiddleware(CreateLeague(s)))
+		r.Get("/", ListLeagues(s))
+		r.Get("/{id}", GetLeague(s))
+		r.Get("/{id}/seasons", LeagueSeasons(s))
+		r.Post("/{id}/seasons", user.AuthMiddleware(CreateSeason(s)))
+		r.Get("/{id}/seasons/{seasonId}", GetSeason(s))
+		r.Post("/{id}/seasons/{seasonId}/members", user.AuthMiddleware(AddSeasonMember(s)))
+		r.Delete("/{id}/seasons/{seasonId}/members/{memberId}", user.AuthMiddleware(RemoveSeasonMember(s)))
+		r.Post("/{id}/seasons/{seasonId}/start", user.AuthMiddleware(StartSeason(s)))
+		r.Post("/{id}/seasons/{seasonId}/complete", user.AuthMiddleware(CompleteSeason(s)))
+		r.Get("/{id}/seasons/{seasonId}/fixtures", SeasonFixtures(s))
+		r.Get("/{id}/seasons/{seasonId}/standings", SeasonStandings(s))
+		r.Post("/{id}/fixtures/{fixtureId}/game", user.AuthMiddleware(LinkFixtureGame(s)))
+		r.Put("/{id}/fixtures/{fixtureId}/score", user.AuthMiddleware(RecordFixtureScore(s)))
+	})
+}
+
+func BookingRoute(r chi.Router, s *Server) {
+	r.Route("/bookings", func(r chi.Router) {
+		r.Get("/", user.AuthMiddleware(MyBookings(s)))
+		r.Post("/{id}/confirm", user.AuthMiddleware(ConfirmBooking(s)))
+		r.Delete("/{id}", user.AuthMiddleware(CancelBooking(s)))
+	})
+}
+
+func ConversationRoute(r chi.Router, s *Server) {
+	r.Route("/conversations", func(r chi.Router) {
+		r.Get("/", user.AuthMiddleware(MyConversations(s)))
+		r.Post("/", user.AuthMiddleware(StartConversation(s)))
+		r.Get("/ws", streamAuth(ChatSocket(s)))
+		r.Get("/{id}/messages", user.AuthMiddleware(ConversationMessages(s)))
+		r.Post("/{id}/messages", user.AuthMiddleware(SendMessage(s)))
+		r.Post("/{id}/read", user.AuthMiddleware(MarkConversationRead(s)))
+		r.Get("/{id}/receipts", user.AuthMiddleware(ConversationReceipts(s)))
+	})
+}
+
+func EventRoute(r chi.Router, s *Server) {
+	r.Get("/events", streamAuth(EventStream(s)))
+}
+
+func LeaderboardRoute(r chi.Router, s *Server) {
+	r.Get("/leaderboards", user.AuthMiddleware(Leaderboard(s)))
+}
+
+func LedgerRoute(r chi.Router, s *Server) {
+	r.Route("/ledger", func(r chi.Router) {
+		r.Get("/", user.AuthMiddleware(MyLedger(s)))
+		r.Get("/balance", user.AuthMiddleware(MyBalance(s)))
+	})
+}
+
+func PaymentRoute(r chi.Router, s *Server) {
+	r.Route("/payments", func(r chi.Router) {
+		r.Get("/", user.AuthMiddleware(MyPayments(s)))
+		r.Post("/webhook", PaymentWebhook(s))
+	})
+}
+
+// MediaRoute serves uploads when they are stored on the local disk.
+// Storage such as S3 serves its files itself.
+func MediaRoute(r chi.Router, s *Server) {
+	if h, ok := s.Media.(http.Handler); ok {
+		r.Handle("/media/*", http.StripPrefix("/media", h))
+	}
+}
+
+func FeedRoute(r chi.Router, s *Server) {
+	r.Get("/feed", user.AuthMiddleware(MyFeed(s)))
+}
+
+func NotificationRoute(r chi.Router, s *Server) {
+	r.Route("/notifications", func(r chi.Router) {
+		r.Get("/", user.AuthMiddleware(MyNotifications(s)))
+		r.Get("/unread-count", user.AuthMiddleware(UnreadNotificationCount(s)))
+		r.Post("/read-all", user.AuthMiddleware(MarkAllNotificationsRead(s)))
+		r.Post("/{id}/read", user.AuthMiddleware(MarkNotificationRead(s)))
+		r.Get("/settings", user.AuthMiddleware(GetNotificationSettings(s)))
+		r.Put("/settings", user.AuthMiddleware(UpdateNotificationSettings(s)))
+	})
+}
+
+func BlockRoute(r chi.Router, s *Server) {
+	r.Route("/blocks", func(r chi.Router) {
+		r.Post("/", user.AuthMiddleware(BlockUser(s)))
+		r.Get("/", user.AuthMiddleware(MyBlocks(s)))
+		r.Delete("/{id}", user.AuthMiddleware(UnblockUser(s)))
+	})
+}
+
+func ReportRoute(r chi.Router, s *Server) {
+	r.Route("/reports", func(r chi.Router) {
+		r.Post("/", user.AuthMiddleware(SubmitReport(s)))
+		r.Get("/", user.AuthMiddleware(MyReports(s)))
+	})
+}
+
+func AdminRoute(r chi.Router, s *Server) {
+	r.Route("/admin", func(r chi.Router) {
+		r.Get("/venues/pending", user.AuthMiddleware(AdminMiddleware(s, PendingVenues(s))))
+		r.Post("/venues/{id}/approve", user.AuthMiddleware(AdminMiddleware(s, ApproveVenue(s))))
+		r.Post("/venues/{id}/reject", user.AuthMiddleware(AdminMiddleware(s, RejectVenue(s))))
+		r.Get("/reports", user.AuthMiddleware(AdminMiddleware(s, ReportQueue(s))))
+		r.Post("/reports/{id}/claim", user.AuthMiddleware(AdminMiddleware(s, ClaimReport(s))))
+		r.Post("/reports/{id}/resolve", user.AuthMiddleware(AdminMiddleware(s, ResolveReport(s))))
+		r.Get("/users/{id}/actions", user.AuthMiddleware(AdminMiddleware(s, UserActions(s))))
+		r.Post("/users/{id}/actions", user.AuthMiddleware(AdminMiddleware(s, TakeAction(s))))
+		r.Get("/audit", user.AuthMiddleware(AdminMiddleware(s, AuditLog(s))))
+		r.Get("/results/disputed", user.AuthMiddleware(AdminMiddleware(s, DisputedResults(s))))
 	})
 }
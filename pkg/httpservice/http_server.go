@@ -13,11 +13,21 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 
 	"github.com/dudeiebot/sportPeerGo/pkg/adapter/dbs"
+	"github.com/dudeiebot/sportPeerGo/pkg/chat"
+	"github.com/dudeiebot/sportPeerGo/pkg/live"
+	"github.com/dudeiebot/sportPeerGo/pkg/matchmaking"
+	"github.com/dudeiebot/sportPeerGo/pkg/media/storage"
+	"github.com/dudeiebot/sportPeerGo/pkg/payment"
 )
 
 type Server struct {
-	port int
-	DBS  *dbs.Service
+	port     int
+	DBS      *dbs.Service
+	Matches  *matchmaking.Cache
+	Hub      *chat.Hub
+	Events   *live.Broker
+	Payments payment.PaymentProvider
+	Media    storage.Storage
 }
 
 type Response struct {
@@ -36,17 +46,55 @@ type LogoutResponse struct {
 func NewServer(ctx context.Context) (*http.Server, error) {
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
 
+	// The webhook route is public, so it must not accept webhooks signed
+	// with an empty key.
+	webhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if webhookSecret == "" {
+		return nil, fmt.Errorf("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+
 	dbService := dbs.New(ctx)
+	// No real provider is wired up yet, so fees go through the fake, which
+	// delivers its webhooks in-process.
+	payments := payment.NewFake([]byte(webhookSecret))
 	serverInstance := &Server{
-		port: port,
-		DBS:  dbService,
+		port:     port,
+		DBS:      dbService,
+		Matches:  matchmaking.NewCache(10 * time.Minute),
+		Hub:      chat.NewHub(),
+		Events:   live.NewBroker(),
+		Payments: payments,
+		Media:    newStorage(),
 	}
+	payments.Deliver = deliverWebhook(serverInstance)
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	AuthRoutes(r, serverInstance)
 	UserRoute(r, serverInstance)
+	MatchRoute(r, serverInstance)
+	GameRoute(r, serverInstance)
+	SeriesRoute(r, serverInstance)
+	CalendarRoute(r, serverInstance)
+	VenueRoute(r, serverInstance)
+	BookingRoute(r, serverInstance)
+	TeamRoute(r, serverInstance)
+	TournamentRoute(r, serverInstance)
+	LeagueRoute(r, serverInstance)
+	LedgerRoute(r, serverInstance)
+	PaymentRoute(r, serverInstance)
+	MediaRoute(r, serverInstance)
+	FriendRoute(r, serverInstance)
+	FeedRoute(r, serverInstance)
+	ConversationRoute(r, serverInstance)
+	NotificationRoute(r, serverInstance)
+	EventRoute(r, serverInstance)
+	BlockRoute(r, serverInstance)
+	ReportRoute(r, serverInstance)
+	LeaderboardRoute(r, serverInstance)
+	AdminRoute(r, serverInstance)
+	StartJobs(ctx, serverInstance)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", serverInstance.port),
@@ -0,0 +1,72 @@
+package httpservice
+
+import (
+	"context"
+	"log"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+)
+
+// StartJobs runs the server's periodic background work until ctx is done.
+func StartJobs(ctx context.Context, s *Server) {
+	go every(ctx, time.Minute, func(ctx context.Context) {
+		expireWaitlistOffers(ctx, s)
+	})
+	go every(ctx, time.Minute, func(ctx context.Context) {
+		expireCourtHolds(ctx, s)
+	})
+	go every(ctx, 10*time.Minute, func(ctx context.Context) {
+		sendEmailDigests(ctx, s)
+	})
+	go every(ctx, time.Hour, func(ctx context.Context) {
+		pruneEvents(ctx, s)
+	})
+	go every(ctx, 5*time.Minute, func(ctx context.Context) {
+		rateResults(ctx, s)
+	})
+	go every(ctx, 5*time.Minute, func(ctx context.Context) {
+		advanceTournaments(ctx, s)
+	})
+	go every(ctx, 15*time.Minute, func(ctx context.Context) {
+		refreshLeaderboards(ctx, s)
+	})
+	go every(ctx, 15*time.Minute, func(ctx context.Context) {
+		closeAttendance(ctx, s)
+	})
+	go every(ctx, 5*time.Minute, func(ctx context.Context) {
+		refundCancelledGames(ctx, s)
+	})
+	go every(ctx, 5*time.Minute, func(ctx context.Context) {
+		failStalePayments(ctx, s)
+	})
+	go every(ctx, 15*time.Minute, func(ctx context.Context) {
+		evaluateEndedGames(ctx, s)
+	})
+	go every(ctx, time.Hour, func(ctx context.Context) {
+		evaluateSettledMonths(ctx, s)
+	})
+}
+
+func every(ctx context.Context, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job(ctx)
+		}
+	}
+}
+
+func expireWaitlistOffers(ctx context.Context, s *Server) {
+	changes, err := query.ExpireWaitlistOffersQuery(ctx, s.DBS, time.Now())
+	if err != nil {
+		log.Printf("Failed to expire waitlist offers: %v", err)
+	}
+	for gameID, c := range changes {
+		rosterChanged(s, gameID, c)
+	}
+}
@@ -0,0 +1,55 @@
+package httpservice
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/leaderboard"
+	"github.com/dudeiebot/sportPeerGo/pkg/leaderboard/model"
+)
+
+// Leaderboard reads a page of a leaderboard, picked by ?sport=, ?city=,
+// ?metric= (rating or activity) and ?window= (all, season or month).
+// Boards are refreshed in the background, so they may lag by a few minutes.
+func Leaderboard(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Board, error) {
+		v := req.URL.Query()
+		q := model.Query{
+			Sport:  v.Get("sport"),
+			City:   v.Get("city"),
+			Metric: v.Get("metric"),
+			Window: v.Get("window"),
+		}
+		if err := q.ValidateQuery(); err != nil {
+			return nil, err
+		}
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		period, _ := leaderboard.Period(q.Window, time.Now())
+		return query.LeaderboardQuery(ctx, s.DBS, q, period, ctx.Value("userId").(int64), limit, offset)
+	})
+}
+
+// refreshLeaderboards recomputes every board from ratings and games.
+func refreshLeaderboards(ctx context.Context, s *Server) {
+	now := time.Now()
+	for _, metric := range model.Metrics {
+		for _, window := range model.Windows {
+			period, since := leaderboard.Period(window, now)
+			rows, err := query.LeaderboardRowsQuery(ctx, s.DBS, metric, since)
+			if err != nil {
+				log.Printf("Failed to compute %s %s leaderboards: %v", window, metric, err)
+				continue
+			}
+			boards := leaderboard.Build(rows)
+			if err := query.ReplaceLeaderboardQuery(ctx, s.DBS, metric, window, period, boards); err != nil {
+				log.Printf("Failed to save %s %s leaderboards: %v", window, metric, err)
+			}
+		}
+	}
+}
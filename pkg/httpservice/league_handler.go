@@ -0,0 +1,449 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/league"
+	"github.com/dudeiebot/sportPeerGo/pkg/league/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/result"
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+type LeagueResponse struct {
+	Message string        `json:"message"`
+	League  *model.League `json:"league,omitempty"`
+}
+
+type AddMemberRequest struct {
+	DivisionID  int64  `json:"divisionId"`
+	UserID      *int64 `json:"userId,omitempty"`
+	TeamID      *int64 `json:"teamId,omitempty"`
+	HomeVenueID *int64 `json:"homeVenueId,omitempty"`
+}
+
+type FixtureScoreRequest struct {
+	HomeScore int `json:"homeScore"`
+	AwayScore int `json:"awayScore"`
+}
+
+func CreateLeague(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*LeagueResponse, error) {
+		var l model.League
+		if err := json.NewDecoder(req.Body).Decode(&l); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := l.ValidateLeague(); err != nil {
+			return nil, err
+		}
+		l.OrganizerID = ctx.Value("userId").(int64)
+
+		id, err := query.CreateLeagueQuery(ctx, s.DBS, l)
+		if err != nil {
+			return nil, fmt.Errorf("error creating league: %w", err)
+		}
+		created, err := query.GetLeagueQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		return &LeagueResponse{Message: "League created successfully", League: created}, nil
+	})
+}
+
+func ListLeagues(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.League, error) {
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		return query.ListLeaguesQuery(ctx, s.DBS, model.Filter{
+			Sport:  usermodel.NormalizeSport(req.URL.Query().Get("sport")),
+			Limit:  limit,
+			Offset: offset,
+		})
+	})
+}
+
+func GetLeague(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.League, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		return query.GetLeagueQuery(ctx, s.DBS, id)
+	})
+}
+
+func LeagueSeasons(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Season, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		return query.SeasonsQuery(ctx, s.DBS, id)
+	})
+}
+
+// CreateSeason opens the next season of a league. When the last season
+// has finished, its members carry over with promotion and relegation
+// applied from its final standings.
+func CreateSeason(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Season, error) {
+		l, err := organizedLeague(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var season model.Season
+		if err := json.NewDecoder(req.Body).Decode(&season); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := season.ValidateSeason(); err != nil {
+			return nil, err
+		}
+		season.LeagueID = l.ID
+
+		var carried [][]model.Member
+		last, err := query.LastSeasonQuery(ctx, s.DBS, l.ID)
+		switch {
+		case err == nil && last.Status == model.SeasonCompleted:
+			tables, err := seasonTables(ctx, s, last)
+			if err != nil {
+				return nil, err
+			}
+			carried = league.Promote(tables, last.Rules)
+		case err != nil && err != league.ErrSeasonNotFound:
+			return nil, err
+		}
+
+		id, err := query.CreateSeasonQuery(ctx, s.DBS, season, carried)
+		if err != nil {
+			return nil, err
+		}
+		return query.GetSeasonQuery(ctx, s.DBS, l.ID, id)
+	})
+}
+
+func GetSeason(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Season, error) {
+		_, season, err := leagueSeason(ctx, s, req)
+		return season, err
+	})
+}
+
+// AddSeasonMember puts a player or a team in a division before the season
+// starts, optionally with the venue that hosts their home fixtures.
+func AddSeasonMember(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Season, error) {
+		l, err := organizedLeague(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		seasonID, err := pathID(req, "seasonId")
+		if err != nil {
+			return nil, err
+		}
+		var in AddMemberRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+
+		m := model.Member{DivisionID: in.DivisionID, HomeVenueID: in.HomeVenueID}
+		switch {
+		case l.EntrantType == model.EntrantTeam && in.TeamID != nil && in.UserID == nil:
+			t, err := query.GetTeamQuery(ctx, s.DBS, *in.TeamID)
+			if err != nil {
+				return nil, err
+			}
+			if t.Sport != l.Sport {
+				return nil, league.ErrTeamSport
+			}
+			m.TeamID = in.TeamID
+		case l.EntrantType == model.EntrantPlayer && in.UserID != nil && in.TeamID == nil:
+			m.UserID = in.UserID
+		default:
+			return nil, league.ErrWrongEntrantType
+		}
+		if in.HomeVenueID != nil {
+			if _, err := query.GetVenueQuery(ctx, s.DBS, *in.HomeVenueID); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := query.AddMemberQuery(ctx, s.DBS, seasonID, m); err != nil {
+			return nil, err
+		}
+		return query.GetSeasonQuery(ctx, s.DBS, l.ID, seasonID)
+	})
+}
+
+func RemoveSeasonMember(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		if _, err := organizedLeague(ctx, s, req); err != nil {
+			return nil, err
+		}
+		seasonID, err := pathID(req, "seasonId")
+		if err != nil {
+			return nil, err
+		}
+		memberID, err := pathID(req, "memberId")
+		if err != nil {
+			return nil, err
+		}
+		if err := query.RemoveMemberQuery(ctx, s.DBS, seasonID, memberID); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Member removed from the season"}, nil
+	})
+}
+
+// StartSeason schedules every fixture of the season.
+func StartSeason(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Fixture, error) {
+		l, err := organizedLeague(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		seasonID, err := pathID(req, "seasonId")
+		if err != nil {
+			return nil, err
+		}
+		season, err := query.GetSeasonQuery(ctx, s.DBS, l.ID, seasonID)
+		if err != nil {
+			return nil, err
+		}
+		if err := query.StartSeasonQuery(ctx, s.DBS, season.ID, l.Sport); err != nil {
+			return nil, err
+		}
+		return query.FixturesQuery(ctx, s.DBS, season.ID, 0)
+	})
+}
+
+// SeasonFixtures lists a season's fixtures, optionally of one division.
+func SeasonFixtures(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Fixture, error) {
+		_, season, err := leagueSeason(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var divisionID int64
+		if v := req.URL.Query().Get("divisionId"); v != "" {
+			if divisionID, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid divisionId format")
+			}
+		}
+		return query.FixturesQuery(ctx, s.DBS, season.ID, divisionID)
+	})
+}
+
+// SeasonStandings returns the table of every division, marking who would
+// be promoted or relegated if the season ended now.
+func SeasonStandings(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Table, error) {
+		_, season, err := leagueSeason(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		return seasonTables(ctx, s, season)
+	})
+}
+
+// CompleteSeason ends a season whose fixtures have all been played and
+// returns its final standings.
+func CompleteSeason(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Table, error) {
+		l, err := organizedLeague(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		seasonID, err := pathID(req, "seasonId")
+		if err != nil {
+			return nil, err
+		}
+		season, err := query.GetSeasonQuery(ctx, s.DBS, l.ID, seasonID)
+		if err != nil {
+			return nil, err
+		}
+		if err := query.CompleteSeasonQuery(ctx, s.DBS, season.ID); err != nil {
+			return nil, err
+		}
+		return seasonTables(ctx, s, season)
+	})
+}
+
+// LinkFixtureGame has a fixture played as one of the organizer's games, so
+// that the game's confirmed result scores it.
+func LinkFixtureGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		l, f, err := organizedFixture(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var in LinkGameRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		g, err := query.GetGameQuery(ctx, s.DBS, in.GameID)
+		if err != nil {
+			return nil, err
+		}
+		if g.OrganizerID != l.OrganizerID || g.Sport != l.Sport {
+			return nil, league.ErrGameMismatch
+		}
+		if err := query.LinkFixtureGameQuery(ctx, s.DBS, f.ID, g.ID); err != nil {
+			return nil, err
+		}
+
+		// The game may already have been played.
+		r, err := query.GameResultQuery(ctx, s.DBS, g.ID)
+		switch {
+		case err == nil && result.Final(*r):
+			go advanceLeague(s, *r)
+		case err != nil && err != result.ErrResultNotFound:
+			return nil, err
+		}
+		return &Response{Message: "Game linked to the fixture"}, nil
+	})
+}
+
+// RecordFixtureScore lets the organizer score a fixture by hand, or
+// correct its score.
+func RecordFixtureScore(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Fixture, error) {
+		_, f, err := organizedFixture(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var in FixtureScoreRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		scored, err := query.RecordFixtureQuery(ctx, s.DBS, f.ID, in.HomeScore, in.AwayScore)
+		if err != nil {
+			return nil, err
+		}
+		go fixtureScored(s, *scored)
+		return scored, nil
+	})
+}
+
+// organizedLeague loads the league in the path, which the caller must
+// organize.
+func organizedLeague(ctx context.Context, s *Server, req *http.Request) (*model.League, error) {
+	id, err := pathID(req, "id")
+	if err != nil {
+		return nil, err
+	}
+	l, err := query.GetLeagueQuery(ctx, s.DBS, id)
+	if err != nil {
+		return nil, err
+	}
+	if l.OrganizerID != ctx.Value("userId").(int64) {
+		return nil, league.ErrNotOrganizer
+	}
+	return l, nil
+}
+
+func organizedFixture(ctx context.Context, s *Server, req *http.Request) (*model.League, *model.Fixture, error) {
+	l, err := organizedLeague(ctx, s, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	fixtureID, err := pathID(req, "fixtureId")
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := query.GetFixtureQuery(ctx, s.DBS, l.ID, fixtureID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, f, nil
+}
+
+func leagueSeason(ctx context.Context, s *Server, req *http.Request) (*model.League, *model.Season, error) {
+	id, err := pathID(req, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	seasonID, err := pathID(req, "seasonId")
+	if err != nil {
+		return nil, nil, err
+	}
+	l, err := query.GetLeagueQuery(ctx, s.DBS, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	season, err := query.GetSeasonQuery(ctx, s.DBS, l.ID, seasonID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, season, nil
+}
+
+// seasonTables ranks every division of season, top first, and marks the
+// promotion and relegation places.
+func seasonTables(ctx context.Context, s *Server, season *model.Season) ([]model.Table, error) {
+	fixtures, err := query.FixturesQuery(ctx, s.DBS, season.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	byDivision := make(map[int64][]model.Fixture)
+	for _, f := range fixtures {
+		byDivision[f.DivisionID] = append(byDivision[f.DivisionID], f)
+	}
+
+	tables := make([]model.Table, len(season.Divisions))
+	for i, dv := range season.Divisions {
+		tables[i] = model.Table{
+			Division:  dv,
+			Standings: league.Standings(dv.Members, byDivision[dv.ID], season.Rules),
+		}
+	}
+	league.Promote(tables, season.Rules)
+	return tables, nil
+}
+
+// advanceLeague scores the league fixture played as r's game, if there is
+// one that has not been scored yet. Results that do not line up with the
+// fixture's members are left for the organizer to score by hand.
+func advanceLeague(s *Server, r resultmodel.Result) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	f, err := query.FixtureByGameQuery(ctx, s.DBS, r.GameID)
+	if err != nil {
+		if err != league.ErrFixtureNotFound {
+			log.Printf("Failed to load league fixture of game %d: %v", r.GameID, err)
+		}
+		return
+	}
+	if f.Status != model.FixtureScheduled {
+		return
+	}
+	playersHome, err := query.MemberPlayersQuery(ctx, s.DBS, f.HomeID)
+	if err != nil {
+		log.Printf("Failed to load players of league member %d: %v", f.HomeID, err)
+		return
+	}
+	playersAway, err := query.MemberPlayersQuery(ctx, s.DBS, f.AwayID)
+	if err != nil {
+		log.Printf("Failed to load players of league member %d: %v", f.AwayID, err)
+		return
+	}
+	home, away, err := league.FixtureScore(playersHome, playersAway, r)
+	var scored *model.Fixture
+	if err == nil {
+		scored, err = query.RecordFixtureQuery(ctx, s.DBS, f.ID, home, away)
+	}
+	if err != nil {
+		log.Printf("Failed to score fixture %d from result %d: %v", f.ID, r.ID, err)
+		return
+	}
+	fixtureScored(s, *scored)
+}
@@ -0,0 +1,167 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	"github.com/dudeiebot/sportPeerGo/pkg/ledger"
+	"github.com/dudeiebot/sportPeerGo/pkg/ledger/model"
+	teammodel "github.com/dudeiebot/sportPeerGo/pkg/team/model"
+)
+
+type ExpenseRequest struct {
+	Description string `json:"description"`
+	AmountCents int64  `json:"amountCents"`
+	// PaidBy defaults to the organizer.
+	PaidBy *int64 `json:"paidBy,omitempty"`
+	Split  string `json:"split"`
+	// Shares lists who an equal split is between, everyone when empty, or
+	// each player's amount for a custom split.
+	Shares []model.Share `json:"shares"`
+}
+
+type ExpenseResponse struct {
+	Message string         `json:"message"`
+	Expense *model.Expense `json:"expense,omitempty"`
+}
+
+// SettlementResponse is how a group can settle up in as few payments as
+// possible.
+type SettlementResponse struct {
+	Balances  []model.Counterparty `json:"balances"`
+	Transfers []model.Transfer     `json:"transfers"`
+}
+
+// AddExpense lets the organizer record money spent on a game, such as the
+// court fee, and split it between the players.
+func AddExpense(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*ExpenseResponse, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var in ExpenseRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		e := model.Expense{
+			GameID:      g.ID,
+			PaidBy:      g.OrganizerID,
+			Description: in.Description,
+			AmountCents: in.AmountCents,
+			Split:       in.Split,
+			Shares:      in.Shares,
+			CreatedBy:   v.UserID,
+		}
+		if in.PaidBy != nil {
+			e.PaidBy = *in.PaidBy
+		}
+		if err := e.ValidateExpense(); err != nil {
+			return nil, err
+		}
+		id, err := query.CreateExpenseQuery(ctx, s.DBS, e)
+		if err != nil {
+			return nil, err
+		}
+		saved, err := query.GetExpenseQuery(ctx, s.DBS, g.ID, id)
+		if err != nil {
+			return nil, err
+		}
+		return &ExpenseResponse{Message: "Expense added", Expense: saved}, nil
+	})
+}
+
+// GameExpenses lists what was spent on a game and who has paid their share.
+func GameExpenses(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Expense, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		if !v.Participant && g.OrganizerID != v.UserID {
+			return nil, game.ErrNotJoined
+		}
+		return query.ExpensesQuery(ctx, s.DBS, g.ID)
+	})
+}
+
+// MarkSharePaid settles a player's share of an expense. Either the player
+// or whoever paid the expense can mark it; no money moves through the app.
+func MarkSharePaid(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*ExpenseResponse, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		expenseID, err := pathID(req, "expenseId")
+		if err != nil {
+			return nil, err
+		}
+		userID, err := pathID(req, "userId")
+		if err != nil {
+			return nil, err
+		}
+		e, err := query.MarkSharePaidQuery(ctx, s.DBS, g.ID, expenseID, userID, v.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return &ExpenseResponse{Message: "Share marked paid", Expense: e}, nil
+	})
+}
+
+// MyBalance nets everything the caller owes and is owed across all games,
+// per player.
+func MyBalance(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Balance, error) {
+		userId := ctx.Value("userId").(int64)
+		entries, err := query.UserEntriesQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		b := ledger.BalanceOf(userId, entries)
+		return &b, nil
+	})
+}
+
+// MyLedger lists every charge and payment the caller is part of.
+func MyLedger(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Entry, error) {
+		return query.UserEntriesQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+	})
+}
+
+// TeamSettlement simplifies the debts between a team's members into the
+// fewest payments that settle them. Debts to players outside the team are
+// left out.
+func TeamSettlement(s *Server) http.HandlerFunc {
+	return NewTeamHandler(
+		s,
+		teammodel.RoleMember,
+		func(ctx context.Context, req *http.Request, m teammodel.Member) (*SettlementResponse, error) {
+			members, err := query.TeamMembersQuery(ctx, s.DBS, m.TeamID)
+			if err != nil {
+				return nil, err
+			}
+			ids := make([]int64, len(members))
+			for i, member := range members {
+				ids[i] = member.UserID
+			}
+			entries, err := query.GroupEntriesQuery(ctx, s.DBS, ids)
+			if err != nil {
+				return nil, err
+			}
+			balances := ledger.Balances(entries)
+			out := &SettlementResponse{Balances: []model.Counterparty{}, Transfers: ledger.Simplify(balances)}
+			for _, id := range ids {
+				if balances[id] != 0 {
+					out.Balances = append(out.Balances, model.Counterparty{UserID: id, NetCents: balances[id]})
+				}
+			}
+			return out, nil
+		},
+	)
+}
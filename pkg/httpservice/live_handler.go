@@ -0,0 +1,99 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	feedmodel "github.com/dudeiebot/sportPeerGo/pkg/feed/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	"github.com/dudeiebot/sportPeerGo/pkg/live"
+	"github.com/dudeiebot/sportPeerGo/pkg/user"
+)
+
+// EventRetention is how long events stay in the log for clients to resume.
+const EventRetention = 24 * time.Hour
+
+// EventStream streams the caller's notifications and roster changes of
+// their games as Server-Sent Events, resuming after Last-Event-ID.
+func EventStream(s *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId := r.Context().Value("userId").(int64)
+		events, unsubscribe := s.Events.Subscribe(userId)
+		defer unsubscribe()
+
+		replay := func(after int64, limit int) ([]live.Event, error) {
+			return query.EventsSinceQuery(r.Context(), s.DBS, userId, after, limit)
+		}
+		if err := live.Stream(w, r, events, replay); err != nil {
+			log.Printf("Event stream for user %d ended: %v", userId, err)
+		}
+	}
+}
+
+// streamAuth runs user.AuthMiddleware, also accepting the token as ?token=
+// since browsers cannot set headers on an EventSource or a WebSocket
+// handshake.
+func streamAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			if token := r.URL.Query().Get("token"); token != "" {
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		user.AuthMiddleware(next)(w, r)
+	}
+}
+
+// publishEvent logs an event for each of userIDs and pushes it to their open
+// streams. Like notifications, events follow a committed change, so
+// failures are only logged.
+func publishEvent(ctx context.Context, s *Server, userIDs []int64, t string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", t, err)
+		return
+	}
+	for _, id := range userIDs {
+		e, err := query.LogEventQuery(ctx, s.DBS, id, t, raw)
+		if err != nil {
+			log.Printf("Failed to log %s event for user %d: %v", t, id, err)
+			continue
+		}
+		s.Events.Publish(id, e)
+	}
+}
+
+// rosterChanged tells waitlisters about seats offered to them and streams
+// joins and leaves to everyone in the game, including those who just left.
+func rosterChanged(s *Server, gameID int64, c game.Change) {
+	notifyOffers(s, gameID, c.Offered)
+	for _, id := range c.Joined {
+		recordActivity(s, gameActivity(feedmodel.TypeJoinedGame, gameID, id))
+	}
+	if len(c.Joined) == 0 && len(c.Left) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	participants, err := query.GameParticipantsQuery(ctx, s.DBS, gameID)
+	if err != nil {
+		log.Printf("Failed to load participants of game %d: %v", gameID, err)
+		return
+	}
+	ids := append([]int64{}, c.Left...)
+	for _, p := range participants {
+		ids = append(ids, p.UserID)
+	}
+	publishEvent(ctx, s, ids, live.TypeRoster, live.RosterUpdate{GameID: gameID, Joined: c.Joined, Left: c.Left})
+}
+
+func pruneEvents(ctx context.Context, s *Server) {
+	if _, err := query.PruneEventsQuery(ctx, s.DBS, time.Now().Add(-EventRetention)); err != nil {
+		log.Printf("Failed to prune events: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package httpservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/matchmaking"
+	"github.com/dudeiebot/sportPeerGo/pkg/social"
+	"github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+type MatchResponse struct {
+	Matches []matchmaking.Match `json:"matches"`
+	Cached  bool                `json:"cached"`
+}
+
+func FindMatches(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*MatchResponse, error) {
+		userId := int(ctx.Value("userId").(int64))
+		q := req.URL.Query()
+
+		opts := matchmaking.Options{Sport: q.Get("sport")}
+		if v := q.Get("radius"); v != "" {
+			radius, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid radius")
+			}
+			opts.MaxDistanceKm = radius
+		}
+		if v := q.Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid limit")
+			}
+			opts.Limit = limit
+		}
+
+		key := opts.Key()
+		if matches, ok := s.Matches.Get(userId, key); ok {
+			return &MatchResponse{Matches: matches, Cached: true}, nil
+		}
+
+		self, err := query.GetProfileQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		radius := opts.MaxDistanceKm
+		if radius <= 0 {
+			radius = matchmaking.DefaultMaxDistance
+		}
+		candidates, err := query.MatchCandidatesQuery(
+			ctx, s.DBS, userId, model.NormalizeSport(opts.Sport), self.Location, radius,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error loading candidates: %w", err)
+		}
+		candidates, err = visibleCandidates(ctx, s, int64(userId), candidates)
+		if err != nil {
+			return nil, err
+		}
+		if err := withHistory(ctx, s, int64(userId), candidates); err != nil {
+			return nil, err
+		}
+
+		matches := matchmaking.Rank(*self, candidates, opts)
+		s.Matches.Set(userId, key, matches)
+		return &MatchResponse{Matches: matches}, nil
+	})
+}
+
+// visibleCandidates drops candidates whose profile the user may not see and
+// hides or coarsens their locations, so the distance factor never reveals
+// more than their profile page would.
+func visibleCandidates(
+	ctx context.Context,
+	s *Server,
+	userID int64,
+	candidates []matchmaking.Candidate,
+) ([]matchmaking.Candidate, error) {
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+	ids := make([]int64, len(candidates))
+	for i, c := range candidates {
+		ids[i] = int64(c.Profile.UserID)
+	}
+	settings, err := query.PrivacyForUsersQuery(ctx, s.DBS, ids)
+	if err != nil {
+		return nil, err
+	}
+	friends, err := query.FriendIDsQuery(ctx, s.DBS, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := candidates[:0]
+	for _, c := range candidates {
+		id := int64(c.Profile.UserID)
+		r := social.Relation{Friend: friends[id]}
+		if social.ApplyProfilePrivacy(&c.Profile, settings[id], r) != nil {
+			continue
+		}
+		visible = append(visible, c)
+	}
+	return visible, nil
+}
+
+// withHistory fills in how often the user has played with each candidate,
+// and who they have blocked or been blocked by.
+func withHistory(ctx context.Context, s *Server, userID int64, candidates []matchmaking.Candidate) error {
+	history, err := query.CoPlayersQuery(ctx, s.DBS, userID)
+	if err != nil {
+		return err
+	}
+	blocked, err := query.BlockedIDsQuery(ctx, s.DBS, userID)
+	if err != nil {
+		return err
+	}
+	for i := range candidates {
+		id := int64(candidates[i].Profile.UserID)
+		candidates[i].GamesTogether = history[id]
+		candidates[i].Blocked = blocked[id]
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package httpservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/media"
+	"github.com/dudeiebot/sportPeerGo/pkg/media/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/media/storage"
+	teammodel "github.com/dudeiebot/sportPeerGo/pkg/team/model"
+)
+
+type ImageResponse struct {
+	Message string       `json:"message"`
+	Image   *model.Image `json:"image"`
+}
+
+// newStorage picks where uploads are kept: an S3-compatible bucket when
+// S3_BUCKET is set, and the MEDIA_DIR directory otherwise.
+func newStorage() storage.Storage {
+	if bucket := os.Getenv("S3_BUCKET"); bucket != "" {
+		return &storage.S3{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    bucket,
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+			Client:    &http.Client{Timeout: 30 * time.Second},
+		}
+	}
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" {
+		dir = "media"
+	}
+	return storage.NewLocal(dir, "/media")
+}
+
+// readUpload returns the "file" field of a multipart upload.
+func readUpload(req *http.Request) ([]byte, error) {
+	// Leave room for the multipart headers around the file.
+	req.Body = http.MaxBytesReader(nil, req.Body, media.MaxUploadBytes+1<<20)
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("expected a multipart/form-data upload")
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, fmt.Errorf("the upload has no file field")
+		}
+		if err != nil {
+			return nil, uploadError(err)
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(part, media.MaxUploadBytes+1))
+		if err != nil {
+			return nil, uploadError(err)
+		}
+		if len(data) > media.MaxUploadBytes {
+			return nil, media.ErrTooLarge
+		}
+		return data, nil
+	}
+}
+
+func uploadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return media.ErrTooLarge
+	}
+	return fmt.Errorf("invalid upload")
+}
+
+// storeImage resizes an upload to kind's variants and stores them under a
+// fresh key, so replaced images never linger in caches under the same URL.
+func storeImage(ctx context.Context, s *Server, kind string, subjectID, uploader int64, data []byte) (*model.Image, error) {
+	renditions, err := media.Process(data, model.VariantsFor(kind))
+	if err != nil {
+		return nil, err
+	}
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	img := &model.Image{
+		Kind:       kind,
+		SubjectID:  subjectID,
+		Key:        fmt.Sprintf("%s/%d/%s", kind, subjectID, hex.EncodeToString(token)),
+		Ext:        renditions[0].Ext,
+		UploadedBy: uploader,
+	}
+	for i, r := range renditions {
+		if err := s.Media.Put(ctx, img.VariantKey(r.Name), r.ContentType, r.Data); err != nil {
+			for _, stored := range renditions[:i] {
+				if err := s.Media.Delete(ctx, img.VariantKey(stored.Name)); err != nil {
+					log.Printf("Failed to delete %s: %v", img.VariantKey(stored.Name), err)
+				}
+			}
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+// deleteImageFiles removes the stored variants of images that are gone.
+func deleteImageFiles(s *Server, images []model.Image) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, img := range images {
+		for _, v := range model.VariantsFor(img.Kind) {
+			if err := s.Media.Delete(ctx, img.VariantKey(v.Name)); err != nil {
+				log.Printf("Failed to delete %s: %v", img.VariantKey(v.Name), err)
+			}
+		}
+	}
+}
+
+func withURLs(s *Server, img *model.Image) *model.Image {
+	img.URLs = make(map[string]string)
+	for _, v := range model.VariantsFor(img.Kind) {
+		img.URLs[v.Name] = s.Media.URL(img.VariantKey(v.Name))
+	}
+	return img
+}
+
+// imagesOf returns the images of one subject with their URLs.
+func imagesOf(ctx context.Context, s *Server, kind string, subjectID int64) ([]model.Image, error) {
+	images, err := query.ImagesQuery(ctx, s.DBS, kind, []int64{subjectID})
+	if err != nil {
+		return nil, err
+	}
+	out := images[subjectID]
+	for i := range out {
+		withURLs(s, &out[i])
+	}
+	return out, nil
+}
+
+// replaceImage stores an upload as the only image of its subject.
+func replaceImage(
+	ctx context.Context,
+	s *Server,
+	req *http.Request,
+	kind string,
+	subjectID, uploader int64,
+) (*ImageResponse, error) {
+	data, err := readUpload(req)
+	if err != nil {
+		return nil, err
+	}
+	img, err := storeImage(ctx, s, kind, subjectID, uploader, data)
+	if err != nil {
+		return nil, err
+	}
+	id, old, err := query.ReplaceImageQuery(ctx, s.DBS, *img)
+	if err != nil {
+		go deleteImageFiles(s, []model.Image{*img})
+		return nil, err
+	}
+	go deleteImageFiles(s, old)
+
+	img.ID = id
+	img.CreatedAt = time.Now()
+	return &ImageResponse{Message: "Image uploaded", Image: withURLs(s, img)}, nil
+}
+
+func removeImages(ctx context.Context, s *Server, kind string, subjectID, id int64) (*Response, error) {
+	removed, err := query.DeleteImageQuery(ctx, s.DBS, kind, subjectID, id)
+	if err != nil {
+		return nil, err
+	}
+	go deleteImageFiles(s, removed)
+	return &Response{Message: "Image deleted"}, nil
+}
+
+// UploadAvatar sets the caller's profile picture from a multipart upload
+// with a "file" field.
+func UploadAvatar(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*ImageResponse, error) {
+		userId, err := pathUserID(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return replaceImage(ctx, s, req, model.KindAvatar, userId, userId)
+	})
+}
+
+func DeleteAvatar(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		userId, err := pathUserID(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return removeImages(ctx, s, model.KindAvatar, userId, 0)
+	})
+}
+
+// UploadTeamPhoto lets captains set the team's photo.
+func UploadTeamPhoto(s *Server) http.HandlerFunc {
+	return NewTeamHandler(s, teammodel.RoleCaptain, func(ctx context.Context, req *http.Request, m teammodel.Member) (*ImageResponse, error) {
+		return replaceImage(ctx, s, req, model.KindTeam, m.TeamID, m.UserID)
+	})
+}
+
+func DeleteTeamPhoto(s *Server) http.HandlerFunc {
+	return NewTeamHandler(s, teammodel.RoleCaptain, func(ctx context.Context, req *http.Request, m teammodel.Member) (*Response, error) {
+		return removeImages(ctx, s, model.KindTeam, m.TeamID, 0)
+	})
+}
+
+// AddVenuePhoto adds a photo to a venue. Like other venue edits, it is open
+// to whoever submitted the venue and to admins.
+func AddVenuePhoto(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*ImageResponse, error) {
+		v, err := editableVenue(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := readUpload(req)
+		if err != nil {
+			return nil, err
+		}
+		img, err := storeImage(ctx, s, model.KindVenue, v.ID, ctx.Value("userId").(int64), data)
+		if err != nil {
+			return nil, err
+		}
+		img.ID, err = query.AddImageQuery(ctx, s.DBS, *img, model.MaxVenuePhotos)
+		if err != nil {
+			go deleteImageFiles(s, []model.Image{*img})
+			return nil, err
+		}
+		img.CreatedAt = time.Now()
+		return &ImageResponse{Message: "Photo added", Image: withURLs(s, img)}, nil
+	})
+}
+
+func DeleteVenuePhoto(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		v, err := editableVenue(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		photoID, err := pathID(req, "photoId")
+		if err != nil {
+			return nil, err
+		}
+		if photoID <= 0 {
+			return nil, media.ErrImageNotFound
+		}
+		return removeImages(ctx, s, model.KindVenue, v.ID, photoID)
+	})
+}
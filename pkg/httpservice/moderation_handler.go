@@ -0,0 +1,212 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/moderation"
+	"github.com/dudeiebot/sportPeerGo/pkg/moderation/model"
+)
+
+type BlockRequest struct {
+	UserID int64 `json:"userId"`
+}
+
+type ResolveReportRequest struct {
+	Status     string `json:"status"`
+	Resolution string `json:"resolution"`
+	// Action optionally sanctions the reported user as part of the
+	// resolution. Only reports about users can carry one.
+	Action *model.Action `json:"action,omitempty"`
+}
+
+type ReportResponse struct {
+	Message string        `json:"message"`
+	Report  *model.Report `json:"report,omitempty"`
+}
+
+type UserActionsResponse struct {
+	Standing moderation.Standing `json:"standing"`
+	Actions  []model.Action      `json:"actions"`
+}
+
+func BlockUser(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		var in BlockRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil || in.UserID == 0 {
+			return nil, fmt.Errorf("userId is required")
+		}
+		userId := ctx.Value("userId").(int64)
+		if err := query.BlockUserQuery(ctx, s.DBS, userId, in.UserID); err != nil {
+			return nil, err
+		}
+		friendsChanged(s, userId, in.UserID)
+		return &Response{Message: "User blocked"}, nil
+	})
+}
+
+func UnblockUser(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		blockedID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		if err := query.UnblockUserQuery(ctx, s.DBS, userId, blockedID); err != nil {
+			return nil, err
+		}
+		friendsChanged(s, userId, blockedID)
+		return &Response{Message: "User unblocked"}, nil
+	})
+}
+
+func MyBlocks(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Block, error) {
+		return query.BlocksQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+	})
+}
+
+func SubmitReport(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*ReportResponse, error) {
+		var r model.Report
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := r.ValidateReport(); err != nil {
+			return nil, err
+		}
+		r.ReporterID = ctx.Value("userId").(int64)
+
+		id, err := query.CreateReportQuery(ctx, s.DBS, r)
+		if err != nil {
+			return nil, err
+		}
+		r.ID = id
+		r.Status = model.ReportOpen
+		return &ReportResponse{Message: "Report submitted, thank you", Report: &r}, nil
+	})
+}
+
+func MyReports(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Report, error) {
+		return query.UserReportsQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+	})
+}
+
+// ReportQueue lists reports for moderators, open ones by default. Pass
+// ?status=reviewing to see reports already claimed.
+func ReportQueue(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Report, error) {
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		status := req.URL.Query().Get("status")
+		switch status {
+		case "":
+			status = model.ReportOpen
+		case model.ReportOpen, model.ReportReviewing, model.ReportResolved, model.ReportDismissed:
+		default:
+			return nil, fmt.Errorf("unknown report status")
+		}
+		return query.ReportQueueQuery(ctx, s.DBS, status, limit, offset)
+	})
+}
+
+func ClaimReport(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Report, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		return query.ClaimReportQuery(ctx, s.DBS, id, ctx.Value("userId").(int64))
+	})
+}
+
+func ResolveReport(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Report, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var in ResolveReportRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if in.Action != nil {
+			if err := in.Action.ValidateAction(); err != nil {
+				return nil, err
+			}
+		}
+
+		return query.ResolveReportQuery(
+			ctx, s.DBS, id, ctx.Value("userId").(int64), in.Status, in.Resolution, in.Action,
+		)
+	})
+}
+
+func TakeAction(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Action, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var a model.Action
+		if err := json.NewDecoder(req.Body).Decode(&a); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := a.ValidateAction(); err != nil {
+			return nil, err
+		}
+		a.UserID = userID
+		a.ModeratorID = ctx.Value("userId").(int64)
+		a.ReportID = nil
+
+		if err := query.ModerationActionQuery(ctx, s.DBS, &a); err != nil {
+			return nil, err
+		}
+		return &a, nil
+	})
+}
+
+func UserActions(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*UserActionsResponse, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		actions, err := query.UserActionsQuery(ctx, s.DBS, userID)
+		if err != nil {
+			return nil, err
+		}
+		standing, err := query.UserStandingQuery(ctx, s.DBS, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &UserActionsResponse{Standing: standing, Actions: actions}, nil
+	})
+}
+
+// AuditLog pages through moderator decisions. Pass ?userId= to only see
+// decisions about one user.
+func AuditLog(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.AuditEntry, error) {
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		var userID int64
+		if v := req.URL.Query().Get("userId"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid userId")
+			}
+			userID = id
+		}
+		return query.AuditLogQuery(ctx, s.DBS, userID, limit, offset)
+	})
+}
@@ -0,0 +1,220 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/live"
+	"github.com/dudeiebot/sportPeerGo/pkg/notify"
+	"github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+	smtps "github.com/dudeiebot/sportPeerGo/pkg/user/email"
+)
+
+type UnreadCountResponse struct {
+	Unread int `json:"unread"`
+}
+
+type MarkAllReadResponse struct {
+	Message string `json:"message"`
+	Marked  int64  `json:"marked"`
+}
+
+// MyNotifications lists the caller's notifications, newest first. Pass
+// ?unread=true to only list unread ones.
+func MyNotifications(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Notification, error) {
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		unreadOnly := req.URL.Query().Get("unread") == "true"
+		return query.NotificationsQuery(ctx, s.DBS, ctx.Value("userId").(int64), unreadOnly, limit, offset)
+	})
+}
+
+func UnreadNotificationCount(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*UnreadCountResponse, error) {
+		count, err := query.UnreadCountQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+		if err != nil {
+			return nil, err
+		}
+		return &UnreadCountResponse{Unread: count}, nil
+	})
+}
+
+func MarkNotificationRead(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		if err := query.MarkNotificationReadQuery(ctx, s.DBS, ctx.Value("userId").(int64), id); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Notification marked as read"}, nil
+	})
+}
+
+func MarkAllNotificationsRead(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*MarkAllReadResponse, error) {
+		marked, err := query.MarkAllReadQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+		if err != nil {
+			return nil, err
+		}
+		return &MarkAllReadResponse{Message: "All notifications marked as read", Marked: marked}, nil
+	})
+}
+
+func GetNotificationSettings(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Settings, error) {
+		settings, err := query.NotificationSettingsQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+		if err != nil {
+			return nil, err
+		}
+		return &settings, nil
+	})
+}
+
+// UpdateNotificationSettings saves the digest setting and the preferences
+// listed in the body. Types left out keep their current preference.
+func UpdateNotificationSettings(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Settings, error) {
+		userId := ctx.Value("userId").(int64)
+		current, err := query.NotificationSettingsQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		in := model.Settings{EmailDigest: current.EmailDigest}
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := in.ValidateSettings(); err != nil {
+			return nil, err
+		}
+		in.UserID = userId
+
+		if err := query.UpdateNotificationSettingsQuery(ctx, s.DBS, in); err != nil {
+			return nil, err
+		}
+		updated, err := query.NotificationSettingsQuery(ctx, s.DBS, userId)
+		if err != nil {
+			return nil, err
+		}
+		return &updated, nil
+	})
+}
+
+// notifyUsers delivers n to each of userIDs on the channels they chose. It
+// runs after the change it reports is committed, so failures are logged
+// rather than returned.
+func notifyUsers(s *Server, userIDs []int64, n model.Notification) {
+	if len(userIDs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	prefs, digests, err := query.DeliverySettingsQuery(ctx, s.DBS, userIDs, n.Type)
+	if err != nil {
+		log.Printf("Failed to load notification preferences: %v", err)
+		return
+	}
+	var emailNow []int64
+	for _, id := range userIDs {
+		d := notify.Plan(n.Type, prefs[id], digests[id])
+		if d.Store {
+			n.UserID = id
+			nid, err := query.CreateNotificationQuery(ctx, s.DBS, n, d.InApp, d.EmailDigest)
+			if err != nil {
+				log.Printf("Failed to store %s notification for user %d: %v", n.Type, id, err)
+				continue
+			}
+			if d.InApp {
+				n.ID = nid
+				n.CreatedAt = time.Now()
+				publishEvent(ctx, s, []int64{id}, live.TypeNotification, n)
+			}
+		}
+		if d.EmailNow {
+			emailNow = append(emailNow, id)
+		}
+	}
+	if len(emailNow) == 0 {
+		return
+	}
+
+	emails, err := query.UserEmailsQuery(ctx, s.DBS, emailNow)
+	if err != nil {
+		log.Printf("Failed to load notification emails: %v", err)
+		return
+	}
+	for _, id := range emailNow {
+		if err := smtps.SendNotificationEmail(emails[id], n.Title, n.Body); err != nil {
+			log.Printf("Failed to send %s notification email: %v", n.Type, err)
+		}
+	}
+}
+
+// notifyParticipants notifies everyone in the game except the user who
+// caused the notification.
+func notifyParticipants(s *Server, participants []gamemodel.Participant, n model.Notification) {
+	var ids []int64
+	for _, p := range participants {
+		if n.ActorID == nil || p.UserID != *n.ActorID {
+			ids = append(ids, p.UserID)
+		}
+	}
+	notifyUsers(s, ids, n)
+}
+
+// gameLabel names a game in notification text.
+func gameLabel(g *gamemodel.Game) string {
+	return fmt.Sprintf("%s game on %s", g.Sport, g.StartsAt.UTC().Format("Mon 2 Jan 15:04 MST"))
+}
+
+// sendEmailDigests emails each user whose digest is due everything that has
+// queued up for it since their last one.
+func sendEmailDigests(ctx context.Context, s *Server) {
+	users, err := query.DigestUsersQuery(ctx, s.DBS)
+	if err != nil {
+		log.Printf("Failed to load digest users: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, u := range users {
+		if !notify.DigestDue(u.EmailDigest, u.LastDigestAt, now) {
+			continue
+		}
+		pending, err := query.PendingDigestQuery(ctx, s.DBS, u.UserID)
+		if err != nil {
+			log.Printf("Failed to load digest for user %d: %v", u.UserID, err)
+			continue
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		emails, err := query.UserEmailsQuery(ctx, s.DBS, []int64{u.UserID})
+		if err != nil {
+			log.Printf("Failed to load digest email for user %d: %v", u.UserID, err)
+			continue
+		}
+		subject, body := notify.Digest(pending)
+		if err := smtps.SendNotificationEmail(emails[u.UserID], subject, body); err != nil {
+			log.Printf("Failed to send digest to user %d: %v", u.UserID, err)
+			continue
+		}
+		ids := make([]int64, len(pending))
+		for i, n := range pending {
+			ids[i] = n.ID
+		}
+		if err := query.MarkDigestSentQuery(ctx, s.DBS, u.UserID, ids); err != nil {
+			log.Printf("Failed to record digest for user %d: %v", u.UserID, err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package httpservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	notifymodel "github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/payment"
+	"github.com/dudeiebot/sportPeerGo/pkg/payment/model"
+)
+
+// maxWebhookSize bounds the webhook payloads the server reads.
+const maxWebhookSize = 64 << 10
+
+// stalePaymentAge is how long a held payment may wait for its charge before
+// it is failed and the seat given back.
+const stalePaymentAge = 15 * time.Minute
+
+type PaymentRequest struct {
+	PaymentMethod string `json:"paymentMethod"`
+}
+
+// collectFee charges the payment held for a player's seat. A declined
+// payment gives the seat back. Payments the provider settles later keep the
+// seat until the webhook says otherwise, and it returns the status the
+// payment was left in.
+func collectFee(ctx context.Context, s *Server, p *model.Payment, method string) (string, error) {
+	intent, err := s.Payments.CreateIntent(ctx, payment.IntentRequest{
+		AmountCents:    p.AmountCents,
+		Currency:       p.Currency,
+		Method:         method,
+		IdempotencyKey: fmt.Sprintf("payment-%d", p.ID),
+	})
+	if err == nil {
+		if err = query.SetPaymentIntentQuery(ctx, s.DBS, p.ID, intent.ID); err == nil {
+			intent, err = s.Payments.Capture(ctx, intent.ID)
+		}
+	}
+	if err != nil || intent.Status == model.StatusFailed {
+		if _, err := query.UpdatePaymentStatusQuery(ctx, s.DBS, p.ID, model.StatusPending, model.StatusFailed); err != nil {
+			log.Printf("Failed to mark payment %d failed: %v", p.ID, err)
+		}
+		if err := dropUnpaid(ctx, s, p.GameID, p.UserID); err != nil {
+			log.Printf("Failed to drop unpaid player %d from game %d: %v", p.UserID, p.GameID, err)
+		}
+		return model.StatusFailed, payment.ErrDeclined
+	}
+	if intent.Status == model.StatusSucceeded {
+		if _, err := query.UpdatePaymentStatusQuery(ctx, s.DBS, p.ID, model.StatusPending, model.StatusSucceeded); err != nil {
+			return "", err
+		}
+	}
+	return intent.Status, nil
+}
+
+// joinFee charges the payment JoinGameQuery or ConfirmWaitlistQuery held
+// when they gave userID a seat. Free games and organizers have none.
+func joinFee(ctx context.Context, s *Server, gameID, userID int64, method string) (string, error) {
+	p, err := query.UnchargedPaymentQuery(ctx, s.DBS, gameID, userID)
+	if errors.Is(err, payment.ErrPaymentNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return collectFee(ctx, s, p, method)
+}
+
+func dropUnpaid(ctx context.Context, s *Server, gameID, userID int64) error {
+	c, err := query.DropUnpaidQuery(ctx, s.DBS, gameID, userID)
+	if err != nil {
+		return err
+	}
+	go rosterChanged(s, gameID, c)
+	return nil
+}
+
+// PaymentWebhook receives the provider's webhooks. Deliveries are verified
+// against their signature and may repeat, so events are applied once.
+func PaymentWebhook(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		payload, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookSize))
+		if err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := receiveWebhook(ctx, s, payload, req.Header.Get("Payment-Signature")); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Webhook received"}, nil
+	})
+}
+
+func receiveWebhook(ctx context.Context, s *Server, payload []byte, signature string) error {
+	e, err := s.Payments.VerifyWebhook(payload, signature, time.Now())
+	if err != nil {
+		return err
+	}
+	p, err := query.ApplyPaymentEventQuery(ctx, s.DBS, s.Payments.Name(), *e)
+	if err != nil || p == nil || p.Status != model.StatusFailed {
+		return err
+	}
+	return paymentFailed(ctx, s, p)
+}
+
+// paymentFailed gives up the seat of a player whose payment failed and
+// tells them why.
+func paymentFailed(ctx context.Context, s *Server, p *model.Payment) error {
+	if err := dropUnpaid(ctx, s, p.GameID, p.UserID); err != nil {
+		return err
+	}
+	g, err := query.GetGameQuery(ctx, s.DBS, p.GameID)
+	if err != nil {
+		return err
+	}
+	go notifyUsers(s, []int64{p.UserID}, notifymodel.Notification{
+		Type:   notifymodel.TypePaymentFailed,
+		Title:  "Payment failed",
+		Body:   fmt.Sprintf("Your payment for the %s failed, so your spot was given up.", gameLabel(g)),
+		GameID: &g.ID,
+	})
+	return nil
+}
+
+// failStalePayments fails payments that were held for a seat but never
+// charged, for instance because the server stopped mid-join, and gives
+// their seats back.
+func failStalePayments(ctx context.Context, s *Server) {
+	payments, err := query.StalePaymentsQuery(ctx, s.DBS, time.Now().Add(-stalePaymentAge), 200)
+	if err != nil {
+		log.Printf("Failed to load stale payments: %v", err)
+		return
+	}
+	for i := range payments {
+		p := &payments[i]
+		failed, err := query.UpdatePaymentStatusQuery(ctx, s.DBS, p.ID, model.StatusPending, model.StatusFailed)
+		if err != nil {
+			log.Printf("Failed to mark payment %d failed: %v", p.ID, err)
+			continue
+		}
+		if !failed {
+			continue
+		}
+		if err := paymentFailed(ctx, s, p); err != nil {
+			log.Printf("Failed to drop unpaid player %d from game %d: %v", p.UserID, p.GameID, err)
+		}
+	}
+}
+
+// deliverWebhook hands webhooks from the fake provider straight to the
+// server, as if they had been posted to PaymentWebhook.
+func deliverWebhook(s *Server) func(payload []byte, signature string) {
+	return func(payload []byte, signature string) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := receiveWebhook(ctx, s, payload, signature); err != nil {
+			log.Printf("Failed to handle payment webhook: %v", err)
+		}
+	}
+}
+
+// MyPayments pages through the caller's game fees, newest first.
+func MyPayments(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Payment, error) {
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		return query.UserPaymentsQuery(ctx, s.DBS, ctx.Value("userId").(int64), limit, offset)
+	})
+}
+
+// GamePayments shows the organizer who has paid for their game.
+func GamePayments(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Payment, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		if g.OrganizerID != v.UserID {
+			return nil, game.ErrNotOrganizer
+		}
+		return query.GamePaymentsQuery(ctx, s.DBS, g.ID)
+	})
+}
+
+// refundGame refunds every fee paid for a cancelled game. The payments
+// become refunded when the provider's webhook arrives; until then they are
+// refunded again on every run, and the idempotency key stops the provider
+// from paying out twice.
+func refundGame(ctx context.Context, s *Server, gameID int64) {
+	refundPayments(ctx, s, &gameID)
+}
+
+// refundCancelledGames retries refunds that did not go through when their
+// game was cancelled, and covers games cancelled with their series.
+func refundCancelledGames(ctx context.Context, s *Server) {
+	refundPayments(ctx, s, nil)
+}
+
+func refundPayments(ctx context.Context, s *Server, gameID *int64) {
+	payments, err := query.RefundablePaymentsQuery(ctx, s.DBS, gameID, 200)
+	if err != nil {
+		log.Printf("Failed to load refundable payments: %v", err)
+		return
+	}
+	for _, p := range payments {
+		_, err := s.Payments.Refund(ctx, payment.RefundRequest{
+			IntentID:       p.IntentID,
+			AmountCents:    p.AmountCents,
+			IdempotencyKey: fmt.Sprintf("refund-%d", p.ID),
+		})
+		if errors.Is(err, payment.ErrIntentNotFound) {
+			// The provider has no record of the charge, so there is
+			// nothing to pay back and retrying would never succeed.
+			log.Printf("Payment %d has no intent at the provider, marking it failed", p.ID)
+			if _, err := query.UpdatePaymentStatusQuery(ctx, s.DBS, p.ID, model.StatusSucceeded, model.StatusFailed); err != nil {
+				log.Printf("Failed to mark payment %d failed: %v", p.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to refund payment %d: %v", p.ID, err)
+		}
+	}
+}
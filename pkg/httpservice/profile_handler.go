@@ -0,0 +1,116 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/achievement"
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	mediamodel "github.com/dudeiebot/sportPeerGo/pkg/media/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/social"
+	socialmodel "github.com/dudeiebot/sportPeerGo/pkg/social/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+func GetProfile(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Profile, error) {
+		id, err := strconv.Atoi(chi.URLParam(req, "id"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID format")
+		}
+		p, err := query.GetProfileQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		settings, err := query.PrivacyQuery(ctx, s.DBS, int64(id))
+		if err != nil {
+			return nil, err
+		}
+		r, err := query.RelationQuery(ctx, s.DBS, viewerID(ctx), int64(id))
+		if err != nil {
+			return nil, err
+		}
+		if err := social.ApplyProfilePrivacy(p, settings, r); err != nil {
+			return nil, err
+		}
+		avatars, err := imagesOf(ctx, s, mediamodel.KindAvatar, int64(id))
+		if err != nil {
+			return nil, err
+		}
+		if len(avatars) > 0 {
+			p.Avatar = &avatars[0]
+		}
+		earned, err := query.EarnedBadgesQuery(ctx, s.DBS, int64(id))
+		if err != nil {
+			return nil, err
+		}
+		p.Badges = achievement.Badges(earned)
+		return p, nil
+	})
+}
+
+func UpdateProfile(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		userId, err := pathUserID(ctx, req)
+		if err != nil {
+			return &Response{Message: err.Error()}, nil
+		}
+
+		var p model.Profile
+		if err := json.NewDecoder(req.Body).Decode(&p); err != nil {
+			return &Response{Message: "invalid request body"}, nil
+		}
+		if err := p.ValidateProfile(); err != nil {
+			return nil, err
+		}
+		p.UserID = int(userId)
+
+		if err := query.UpdateProfileQuery(ctx, s.DBS, p); err != nil {
+			return nil, fmt.Errorf("error updating profile: %w", err)
+		}
+		s.Matches.Invalidate(p.UserID)
+
+		return &Response{Message: "Profile updated successfully"}, nil
+	})
+}
+
+// pathUserID checks that the {id} in the URL belongs to the authenticated
+// user and returns it.
+func pathUserID(ctx context.Context, req *http.Request) (int64, error) {
+	userId := ctx.Value("userId").(int64)
+	idInt, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID format")
+	}
+	if idInt != userId {
+		return 0, fmt.Errorf("Unauthorized")
+	}
+	return userId, nil
+}
+
+// UserGames lists the upcoming games a user has joined, subject to their games
+// privacy setting and to each game's own visibility.
+func UserGames(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]gamemodel.Game, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		viewer := ctx.Value("userId").(int64)
+		err = checkAudience(
+			ctx, s, viewer, userID,
+			func(p socialmodel.Privacy) string { return p.Games }, social.ErrGamesPrivate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return query.UserGamesQuery(ctx, s.DBS, userID, viewer, time.Now())
+	})
+}
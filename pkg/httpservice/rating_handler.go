@@ -0,0 +1,96 @@
+package httpservice
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/rating/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/social"
+	socialmodel "github.com/dudeiebot/sportPeerGo/pkg/social/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+// unratedBatch caps how many results one run of the rating job applies.
+const unratedBatch = 100
+
+// UserRatings lists a user's rating in every sport they have results in.
+// Ratings are part of the profile and share its privacy setting.
+func UserRatings(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.PlayerRating, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProfileAudience(ctx, s, userID); err != nil {
+			return nil, err
+		}
+		return query.UserRatingsQuery(ctx, s.DBS, userID)
+	})
+}
+
+// RatingHistory pages through how a user's ratings changed, newest first.
+// Pass ?sport= to follow a single sport.
+func RatingHistory(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.HistoryEntry, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProfileAudience(ctx, s, userID); err != nil {
+			return nil, err
+		}
+		sport := usermodel.NormalizeSport(req.URL.Query().Get("sport"))
+		return query.RatingHistoryQuery(ctx, s.DBS, userID, sport, limit, offset)
+	})
+}
+
+func checkProfileAudience(ctx context.Context, s *Server, userID int64) error {
+	return checkAudience(
+		ctx, s, viewerID(ctx), userID,
+		func(p socialmodel.Privacy) string { return p.Profile }, social.ErrPrivate,
+	)
+}
+
+// rateResult applies a result that has just become final. If it fails the
+// rating job picks the result up on its next run.
+func rateResult(s *Server, resultID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	players, err := query.RateResultQuery(ctx, s.DBS, resultID)
+	if err != nil {
+		log.Printf("Failed to rate result %d: %v", resultID, err)
+	}
+	ratingsChanged(s, players)
+}
+
+// rateResults applies every final result not rated yet, oldest first.
+func rateResults(ctx context.Context, s *Server) {
+	ids, err := query.UnratedResultsQuery(ctx, s.DBS, unratedBatch)
+	if err != nil {
+		log.Printf("Failed to load unrated results: %v", err)
+		return
+	}
+	for _, id := range ids {
+		players, err := query.RateResultQuery(ctx, s.DBS, id)
+		if err != nil {
+			log.Printf("Failed to rate result %d: %v", id, err)
+		}
+		ratingsChanged(s, players)
+	}
+}
+
+// ratingsChanged drops cached matches that ranked the players by their old
+// ratings.
+func ratingsChanged(s *Server, userIDs []int64) {
+	for _, id := range userIDs {
+		s.Matches.Invalidate(int(id))
+	}
+}
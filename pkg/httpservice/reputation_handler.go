@@ -0,0 +1,180 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	achievementmodel "github.com/dudeiebot/sportPeerGo/pkg/achievement/model"
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	notifymodel "github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/reputation/model"
+)
+
+type PlayerReviewRequest struct {
+	RevieweeID    int64  `json:"revieweeId"`
+	Sportsmanship int    `json:"sportsmanship"`
+	Punctuality   int    `json:"punctuality"`
+	SkillAccuracy int    `json:"skillAccuracy"`
+	Comment       string `json:"comment"`
+}
+
+type ReviewResponse struct {
+	Message string `json:"message"`
+	ID      int64  `json:"id"`
+}
+
+// ReviewPlayer rates another player after a game both of them played. Each
+// player can review each other player once per game.
+func ReviewPlayer(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*ReviewResponse, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var in PlayerReviewRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		r := model.Review{
+			GameID:        g.ID,
+			ReviewerID:    v.UserID,
+			RevieweeID:    in.RevieweeID,
+			Sportsmanship: in.Sportsmanship,
+			Punctuality:   in.Punctuality,
+			SkillAccuracy: in.SkillAccuracy,
+			Comment:       in.Comment,
+		}
+		if err := r.ValidateReview(); err != nil {
+			return nil, err
+		}
+		id, err := query.CreateReviewQuery(ctx, s.DBS, r)
+		if err != nil {
+			return nil, err
+		}
+		return &ReviewResponse{Message: "Review saved", ID: id}, nil
+	})
+}
+
+// MyGameReviews lists the reviews the caller has left for a game.
+func MyGameReviews(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Review, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		return query.GameReviewsByQuery(ctx, s.DBS, g.ID, v.UserID)
+	})
+}
+
+// UserReputation returns a user's review scores and attendance standing.
+// Like ratings, it is part of the profile and shares its privacy setting.
+func UserReputation(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Reputation, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProfileAudience(ctx, s, userID); err != nil {
+			return nil, err
+		}
+		return query.ReputationQuery(ctx, s.DBS, userID)
+	})
+}
+
+// UserReviews pages through the reviews a user received, newest first.
+// Reviewers are not shown, so nobody is put off leaving an honest one.
+func UserReviews(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Review, error) {
+		userID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProfileAudience(ctx, s, userID); err != nil {
+			return nil, err
+		}
+		reviews, err := query.UserReviewsQuery(ctx, s.DBS, userID, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		for i := range reviews {
+			reviews[i].ReviewerID = 0
+		}
+		return reviews, nil
+	})
+}
+
+// RecordAttendance lets the organizer mark who turned up, overriding
+// check-ins, from when check-in opens until the review window closes. Once
+// any attendance is taken, participants still unmarked after the grace
+// period become no-shows.
+func RecordAttendance(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Attendance, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var roll model.Roll
+		if err := json.NewDecoder(req.Body).Decode(&roll); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := roll.ValidateRoll(); err != nil {
+			return nil, err
+		}
+		if err := query.RecordAttendanceQuery(ctx, s.DBS, g.ID, v.UserID, roll); err != nil {
+			return nil, err
+		}
+		var present []int64
+		for _, e := range roll.Entries {
+			if e.Status == model.AttendancePresent {
+				present = append(present, e.UserID)
+			}
+		}
+		go achievementEvent(s, achievementmodel.EventAttendance, present)
+		return query.AttendanceQuery(ctx, s.DBS, g.ID)
+	})
+}
+
+// GameAttendance shows a game's attendance to its organizer and players.
+func GameAttendance(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Attendance, error) {
+		g, v, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		if !v.Participant && g.OrganizerID != v.UserID {
+			return nil, game.ErrNotJoined
+		}
+		return query.AttendanceQuery(ctx, s.DBS, g.ID)
+	})
+}
+
+// closeAttendance records the no-shows of games whose attendance grace
+// period has passed and lets each of them know.
+func closeAttendance(ctx context.Context, s *Server) {
+	marked, err := query.CloseAttendanceQuery(ctx, s.DBS, time.Now())
+	if err != nil {
+		log.Printf("Failed to close attendance: %v", err)
+	}
+	for gameID, ids := range marked {
+		g, err := query.GetGameQuery(ctx, s.DBS, gameID)
+		if err != nil {
+			log.Printf("Failed to load game %d for no-show notifications: %v", gameID, err)
+			continue
+		}
+		go notifyUsers(s, ids, notifymodel.Notification{
+			Type:   notifymodel.TypeNoShowRecorded,
+			Title:  "Marked as a no-show",
+			Body:   fmt.Sprintf("You were not marked present at the %s. Repeated no-shows restrict joining public games.", gameLabel(g)),
+			GameID: &g.ID,
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	notifymodel "github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/result"
+	"github.com/dudeiebot/sportPeerGo/pkg/result/model"
+)
+
+type ReportResultRequest struct {
+	SideA []int64     `json:"sideA"`
+	SideB []int64     `json:"sideB"`
+	Score model.Score `json:"score"`
+}
+
+type DisputeResultRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ResolveResultRequest struct {
+	Decision string `json:"decision"`
+	// Score replaces the reported score when the decision is correct.
+	Score *model.Score `json:"score,omitempty"`
+	Note  string       `json:"note"`
+}
+
+// ReportResult records the result of a finished game. It stays pending
+// until a player from the other side confirms or disputes it.
+func ReportResult(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Result, error) {
+		g, _, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var in ReportResultRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		userId := ctx.Value("userId").(int64)
+		r := model.Result{GameID: g.ID, ReportedBy: userId, SideA: in.SideA, SideB: in.SideB, Score: in.Score}
+		if err := r.ValidateResult(model.FormatFor(g.Sport)); err != nil {
+			return nil, err
+		}
+		participants, err := query.GameParticipantsQuery(ctx, s.DBS, g.ID)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int64, len(participants))
+		for i, p := range participants {
+			ids[i] = p.UserID
+		}
+		if err := result.CheckReport(*g, ids, r, time.Now()); err != nil {
+			return nil, err
+		}
+
+		if _, err := query.CreateResultQuery(ctx, s.DBS, r); err != nil {
+			return nil, err
+		}
+		saved, err := query.GameResultQuery(ctx, s.DBS, g.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		var answer []int64
+		for _, id := range append(append([]int64{}, saved.SideA...), saved.SideB...) {
+			if id != userId && saved.SideOf(id) != saved.SideOf(userId) {
+				answer = append(answer, id)
+			}
+		}
+		go notifyUsers(s, answer, notifymodel.Notification{
+			Type:    notifymodel.TypeResultReported,
+			Title:   "Confirm the result",
+			Body:    fmt.Sprintf("A result was recorded for the %s. Please confirm or dispute it.", gameLabel(g)),
+			ActorID: &userId,
+			GameID:  &g.ID,
+		})
+		return saved, nil
+	})
+}
+
+func GetResult(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Result, error) {
+		g, _, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		return query.GameResultQuery(ctx, s.DBS, g.ID)
+	})
+}
+
+func ConfirmResult(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Result, error) {
+		g, _, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		r, err := query.UpdateResultQuery(ctx, s.DBS, g.ID, func(r *model.Result) error {
+			return result.Confirm(r, userId)
+		})
+		if err != nil {
+			return nil, err
+		}
+		go resultFinal(s, *r)
+		return r, nil
+	})
+}
+
+// DisputeResult rejects a pending result and asks the organizer to settle
+// it. Organizers who played leave that to an admin.
+func DisputeResult(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Result, error) {
+		g, _, err := visibleGame(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var in DisputeResultRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if len(in.Reason) > model.MaxReasonLength {
+			return nil, fmt.Errorf("reason cannot exceed %d characters", model.MaxReasonLength)
+		}
+		userId := ctx.Value("userId").(int64)
+		r, err := query.UpdateResultQuery(ctx, s.DBS, g.ID, func(r *model.Result) error {
+			return result.Dispute(r, userId, in.Reason)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if result.CanResolve(*g, r, g.OrganizerID, false) {
+			go notifyUsers(s, []int64{g.OrganizerID}, notifymodel.Notification{
+				Type:    notifymodel.TypeResultDisputed,
+				Title:   "A result was disputed",
+				Body:    fmt.Sprintf("The result of the %s was disputed: %s", gameLabel(g), in.Reason),
+				ActorID: &userId,
+				GameID:  &g.ID,
+			})
+		}
+		return r, nil
+	})
+}
+
+func ResolveResult(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Result, error) {
+		gameID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		g, err := query.GetGameQuery(ctx, s.DBS, gameID)
+		if err != nil {
+			return nil, err
+		}
+		var in ResolveResultRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if len(in.Note) > model.MaxReasonLength {
+			return nil, fmt.Errorf("note cannot exceed %d characters", model.MaxReasonLength)
+		}
+		admin, err := isAdmin(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+
+		r, err := query.UpdateResultQuery(ctx, s.DBS, g.ID, func(r *model.Result) error {
+			if !result.CanResolve(*g, r, userId, admin) {
+				return result.ErrCannotResolve
+			}
+			corrected, err := correctedScore(g, r, in.Score)
+			if err != nil {
+				return err
+			}
+			return result.Resolve(r, userId, in.Decision, corrected, in.Note)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if result.Final(*r) {
+			go resultFinal(s, *r)
+		}
+		return r, nil
+	})
+}
+
+// DisputedResults lists disputed results for admins.
+func DisputedResults(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Result, error) {
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		return query.DisputedResultsQuery(ctx, s.DBS, limit, offset)
+	})
+}
+
+// resultFinal rates the players of a result that became final and records
+// it on the tournament match or league fixture it decides.
+func resultFinal(s *Server, r model.Result) {
+	rateResult(s, r.ID)
+	advanceTournament(s, r)
+	advanceLeague(s, r)
+}
+
+// correctedScore validates a corrected score against the sides of r.
+func correctedScore(g *gamemodel.Game, r *model.Result, score *model.Score) (*model.Score, error) {
+	if score == nil {
+		return nil, nil
+	}
+	check := *r
+	check.Score = *score
+	if err := check.ValidateResult(model.FormatFor(g.Sport)); err != nil {
+		return nil, err
+	}
+	return &check.Score, nil
+}
@@ -0,0 +1,362 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/game"
+	"github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	notifymodel "github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+	paymentmodel "github.com/dudeiebot/sportPeerGo/pkg/payment/model"
+)
+
+const defaultOccurrenceWindow = 8 * 7 * 24 * time.Hour
+
+type SeriesResponse struct {
+	Message string        `json:"message"`
+	Series  *model.Series `json:"series,omitempty"`
+	Game    *model.Game   `json:"game,omitempty"`
+}
+
+func CreateSeries(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*SeriesResponse, error) {
+		var series model.Series
+		if err := json.NewDecoder(req.Body).Decode(&series); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := series.ValidateSeries(); err != nil {
+			return nil, err
+		}
+		if !series.StartsAt.After(time.Now()) {
+			return nil, fmt.Errorf("Series must start in the future")
+		}
+		if err := checkGameVenue(ctx, s, series.VenueID); err != nil {
+			return nil, err
+		}
+		series.OrganizerID = ctx.Value("userId").(int64)
+
+		id, err := query.CreateSeriesQuery(ctx, s.DBS, series)
+		if err != nil {
+			return nil, fmt.Errorf("error creating series: %w", err)
+		}
+		created, err := query.GetSeriesQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		return &SeriesResponse{Message: "Series created successfully", Series: created}, nil
+	})
+}
+
+func GetSeries(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Series, error) {
+		return visibleSeries(ctx, s, req)
+	})
+}
+
+func ListOccurrences(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Occurrence, error) {
+		series, err := visibleSeries(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+
+		from, to := time.Now(), time.Time{}
+		if v := req.URL.Query().Get("from"); v != "" {
+			if from, err = time.Parse(time.RFC3339, v); err != nil {
+				return nil, fmt.Errorf("invalid from time, expected RFC3339")
+			}
+		}
+		if v := req.URL.Query().Get("to"); v != "" {
+			if to, err = time.Parse(time.RFC3339, v); err != nil {
+				return nil, fmt.Errorf("invalid to time, expected RFC3339")
+			}
+		} else {
+			to = from.Add(defaultOccurrenceWindow)
+		}
+		if !to.After(from) || to.Sub(from) > game.MaxOccurrenceWindow {
+			return nil, fmt.Errorf("to must be after from and at most a year later")
+		}
+
+		games, err := query.SeriesGamesQuery(ctx, s.DBS, series.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return game.Occurrences(*series, from, to, games)
+	})
+}
+
+func JoinOccurrence(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*JoinResponse, error) {
+		series, err := visibleSeries(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		at, err := pathTime(req, "at")
+		if err != nil {
+			return nil, err
+		}
+		var in PaymentRequest
+		if req.ContentLength > 0 {
+			if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+				return nil, fmt.Errorf("invalid request body")
+			}
+		}
+		gameID, err := query.MaterializeOccurrenceQuery(ctx, s.DBS, series.ID, at)
+		if err != nil {
+			return nil, err
+		}
+
+		userId := ctx.Value("userId").(int64)
+		c, position, err := query.JoinGameQuery(ctx, s.DBS, gameID, userId, s.Payments.Name())
+		if err != nil {
+			return nil, err
+		}
+		go rosterChanged(s, gameID, c)
+
+		if position > 0 {
+			return &JoinResponse{
+				Message:  "Game is full, you have been added to the waitlist",
+				Position: position,
+			}, nil
+		}
+		status, err := joinFee(ctx, s, gameID, userId, in.PaymentMethod)
+		if err != nil {
+			return nil, err
+		}
+		if status == paymentmodel.StatusPending {
+			return &JoinResponse{Message: "Joined game, your payment is processing"}, nil
+		}
+		return &JoinResponse{Message: "Joined game"}, nil
+	})
+}
+
+func LeaveOccurrence(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		seriesID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		at, err := pathTime(req, "at")
+		if err != nil {
+			return nil, err
+		}
+		gameID, err := query.OccurrenceGameQuery(ctx, s.DBS, seriesID, at)
+		if err != nil {
+			return nil, err
+		}
+		if gameID == 0 {
+			return nil, game.ErrNotJoined
+		}
+
+		c, err := query.LeaveGameQuery(ctx, s.DBS, gameID, ctx.Value("userId").(int64))
+		if err != nil {
+			return nil, err
+		}
+		go rosterChanged(s, gameID, c)
+
+		if len(c.Left) == 0 {
+			return &Response{Message: "Left waitlist"}, nil
+		}
+		return &Response{Message: "Left game"}, nil
+	})
+}
+
+// EditOccurrence changes one occurrence (scope=this, the default) or that
+// occurrence and every later one (scope=future).
+func EditOccurrence(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*SeriesResponse, error) {
+		seriesID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		at, err := pathTime(req, "at")
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+
+		switch scope := req.URL.Query().Get("scope"); scope {
+		case "", game.EditThis:
+			var g model.Game
+			if err := json.NewDecoder(req.Body).Decode(&g); err != nil {
+				return nil, fmt.Errorf("invalid request body")
+			}
+			if err := g.ValidateGame(); err != nil {
+				return nil, err
+			}
+			if err := checkGameVenue(ctx, s, g.VenueID); err != nil {
+				return nil, err
+			}
+			series, err := query.GetSeriesQuery(ctx, s.DBS, seriesID)
+			if err != nil {
+				return nil, err
+			}
+			if series.OrganizerID != userId {
+				return nil, game.ErrNotOrganizer
+			}
+			if g.ID, err = query.MaterializeOccurrenceQuery(ctx, s.DBS, seriesID, at); err != nil {
+				return nil, err
+			}
+			c, err := query.UpdateGameQuery(ctx, s.DBS, g, userId)
+			if err != nil {
+				return nil, err
+			}
+			go occurrencesUpdated(s, userId, map[int64]game.Change{g.ID: c})
+			updated, err := query.GetGameQuery(ctx, s.DBS, g.ID)
+			if err != nil {
+				return nil, err
+			}
+			return &SeriesResponse{Message: "Occurrence updated", Game: updated}, nil
+
+		case game.EditFuture:
+			var edit model.Series
+			if err := json.NewDecoder(req.Body).Decode(&edit); err != nil {
+				return nil, fmt.Errorf("invalid request body")
+			}
+			if err := checkGameVenue(ctx, s, edit.VenueID); err != nil {
+				return nil, err
+			}
+			nextID, changes, err := query.EditFutureOccurrencesQuery(ctx, s.DBS, seriesID, userId, at, edit)
+			if err != nil {
+				return nil, err
+			}
+			go occurrencesUpdated(s, userId, changes)
+			series, err := query.GetSeriesQuery(ctx, s.DBS, nextID)
+			if err != nil {
+				return nil, err
+			}
+			return &SeriesResponse{Message: "Future occurrences updated", Series: series}, nil
+
+		default:
+			return nil, fmt.Errorf("scope must be %s or %s", game.EditThis, game.EditFuture)
+		}
+	})
+}
+
+func CancelOccurrence(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		seriesID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		at, err := pathTime(req, "at")
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		gameID, err := query.CancelOccurrenceQuery(ctx, s.DBS, seriesID, userId, at)
+		if err != nil {
+			return nil, err
+		}
+		if gameID != 0 {
+			go occurrencesCancelled(s, userId, []int64{gameID})
+		}
+		return &Response{Message: "Occurrence cancelled"}, nil
+	})
+}
+
+func CancelSeries(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		seriesID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+		gameIDs, err := query.CancelSeriesQuery(ctx, s.DBS, seriesID, userId)
+		if err != nil {
+			return nil, err
+		}
+		go occurrencesCancelled(s, userId, gameIDs)
+		return &Response{Message: "Series cancelled"}, nil
+	})
+}
+
+// occurrencesUpdated passes on the roster change of each edited occurrence
+// and tells its players, as UpdateGame does for a one-off game.
+func occurrencesUpdated(s *Server, organizerID int64, changes map[int64]game.Change) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for id, c := range changes {
+		rosterChanged(s, id, c)
+		g, err := query.GetGameQuery(ctx, s.DBS, id)
+		if err != nil {
+			log.Printf("Failed to load updated game %d: %v", id, err)
+			continue
+		}
+		participants, err := query.GameParticipantsQuery(ctx, s.DBS, id)
+		if err != nil {
+			log.Printf("Failed to load participants of updated game %d: %v", id, err)
+			continue
+		}
+		notifyParticipants(s, participants, notifymodel.Notification{
+			Type:    notifymodel.TypeGameUpdated,
+			Title:   "Game updated",
+			Body:    fmt.Sprintf("The organizer changed the details of the %s.", gameLabel(g)),
+			ActorID: &organizerID,
+			GameID:  &g.ID,
+		})
+	}
+}
+
+// occurrencesCancelled tells the players of each cancelled occurrence and
+// refunds them, as CancelGame does for a one-off game.
+func occurrencesCancelled(s *Server, organizerID int64, gameIDs []int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, id := range gameIDs {
+		g, err := query.GetGameQuery(ctx, s.DBS, id)
+		if err != nil {
+			log.Printf("Failed to load cancelled game %d: %v", id, err)
+			continue
+		}
+		participants, err := query.GameParticipantsQuery(ctx, s.DBS, id)
+		if err != nil {
+			log.Printf("Failed to load participants of cancelled game %d: %v", id, err)
+			continue
+		}
+		gameCancelled(s, id, participants)
+		notifyParticipants(s, participants, notifymodel.Notification{
+			Type:    notifymodel.TypeGameCancelled,
+			Title:   "Game cancelled",
+			Body:    fmt.Sprintf("The %s has been cancelled.", gameLabel(g)),
+			ActorID: &organizerID,
+			GameID:  &g.ID,
+		})
+	}
+}
+
+func visibleSeries(ctx context.Context, s *Server, req *http.Request) (*model.Series, error) {
+	seriesID, err := pathID(req, "id")
+	if err != nil {
+		return nil, err
+	}
+	series, err := query.GetSeriesQuery(ctx, s.DBS, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	v, err := query.SeriesViewerQuery(ctx, s.DBS, seriesID, ctx.Value("userId").(int64))
+	if err != nil {
+		return nil, err
+	}
+	if !game.CanView(series.Template(series.StartsAt), v) {
+		return nil, game.ErrSeriesNotFound
+	}
+	return series, nil
+}
+
+func pathTime(req *http.Request, name string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, chi.URLParam(req, name))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s, expected RFC3339", name)
+	}
+	return t, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/moderation"
 	"github.com/dudeiebot/sportPeerGo/pkg/user"
 	smtps "github.com/dudeiebot/sportPeerGo/pkg/user/email"
 	"github.com/dudeiebot/sportPeerGo/pkg/user/model"
@@ -197,6 +198,14 @@ func LoginUser(s *Server) http.HandlerFunc {
 				return nil, fmt.Errorf("Please Verify your account before logging in")
 			}
 
+			standing, err := query.UserStandingQuery(ctx, s.DBS, int64(u.ID))
+			if err != nil {
+				return nil, err
+			}
+			if err := moderation.CheckLogin(standing); err != nil {
+				return nil, err
+			}
+
 			accessToken, err := user.GenerateSecretToken(int64(u.ID))
 			if err != nil {
 				return nil, err
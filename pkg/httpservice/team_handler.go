@@ -0,0 +1,236 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	mediamodel "github.com/dudeiebot/sportPeerGo/pkg/media/model"
+	notifymodel "github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/team/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+type TeamResponse struct {
+	Message string      `json:"message"`
+	Team    *model.Team `json:"team,omitempty"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+type TransferRequest struct {
+	UserID int64 `json:"userId"`
+}
+
+func CreateTeam(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*TeamResponse, error) {
+		var t model.Team
+		if err := json.NewDecoder(req.Body).Decode(&t); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := t.ValidateTeam(); err != nil {
+			return nil, err
+		}
+		t.OwnerID = ctx.Value("userId").(int64)
+
+		id, err := query.CreateTeamQuery(ctx, s.DBS, t)
+		if err != nil {
+			return nil, fmt.Errorf("error creating team: %w", err)
+		}
+		created, err := query.GetTeamQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		return &TeamResponse{Message: "Team created successfully", Team: created}, nil
+	})
+}
+
+func ListTeams(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Team, error) {
+		q := req.URL.Query()
+		f := model.Filter{
+			Sport: usermodel.NormalizeSport(q.Get("sport")),
+			Name:  q.Get("name"),
+			Limit: 20,
+		}
+		if v := q.Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit <= 0 || limit > 100 {
+				return nil, fmt.Errorf("limit must be between 1 and 100")
+			}
+			f.Limit = limit
+		}
+		if v := q.Get("offset"); v != "" {
+			offset, err := strconv.Atoi(v)
+			if err != nil || offset < 0 {
+				return nil, fmt.Errorf("invalid offset")
+			}
+			f.Offset = offset
+		}
+		return query.ListTeamsQuery(ctx, s.DBS, f)
+	})
+}
+
+func MyTeams(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Team, error) {
+		return query.UserTeamsQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+	})
+}
+
+func GetTeam(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Team, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		t, err := query.GetTeamQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		photos, err := imagesOf(ctx, s, mediamodel.KindTeam, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(photos) > 0 {
+			t.Photo = &photos[0]
+		}
+		return t, nil
+	})
+}
+
+func UpdateTeam(s *Server) http.HandlerFunc {
+	return NewTeamHandler(s, model.RoleCaptain, func(ctx context.Context, req *http.Request, m model.Member) (*TeamResponse, error) {
+		var t model.Team
+		if err := json.NewDecoder(req.Body).Decode(&t); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := t.ValidateTeam(); err != nil {
+			return nil, err
+		}
+		t.ID = m.TeamID
+
+		if err := query.UpdateTeamQuery(ctx, s.DBS, t); err != nil {
+			return nil, err
+		}
+		updated, err := query.GetTeamQuery(ctx, s.DBS, m.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		return &TeamResponse{Message: "Team updated successfully", Team: updated}, nil
+	})
+}
+
+func InviteToTeam(s *Server) http.HandlerFunc {
+	return NewTeamHandler(s, model.RoleCaptain, func(ctx context.Context, req *http.Request, m model.Member) (*Response, error) {
+		var in InviteRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil || len(in.UserIDs) == 0 {
+			return nil, fmt.Errorf("userIds is required")
+		}
+		if err := query.CheckInvitesQuery(ctx, s.DBS, m.UserID, in.UserIDs); err != nil {
+			return nil, err
+		}
+		if err := query.InviteToTeamQuery(ctx, s.DBS, m.TeamID, m.UserID, in.UserIDs); err != nil {
+			return nil, err
+		}
+		t, err := query.GetTeamQuery(ctx, s.DBS, m.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		go notifyUsers(s, in.UserIDs, notifymodel.Notification{
+			Type:    notifymodel.TypeTeamInvite,
+			Title:   "You're invited to a team",
+			Body:    fmt.Sprintf("You have been invited to join %s.", t.Name),
+			ActorID: &m.UserID,
+			TeamID:  &m.TeamID,
+		})
+		return &Response{Message: "Invites sent"}, nil
+	})
+}
+
+func MyTeamInvites(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Invite, error) {
+		return query.PendingTeamInvitesQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+	})
+}
+
+func AcceptTeamInvite(s *Server) http.HandlerFunc {
+	return respondTeamInvite(s, true)
+}
+
+func DeclineTeamInvite(s *Server) http.HandlerFunc {
+	return respondTeamInvite(s, false)
+}
+
+func respondTeamInvite(s *Server, accept bool) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		teamID, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		err = query.RespondTeamInviteQuery(ctx, s.DBS, teamID, ctx.Value("userId").(int64), accept)
+		if err != nil {
+			return nil, err
+		}
+		if accept {
+			return &Response{Message: "Joined team"}, nil
+		}
+		return &Response{Message: "Invite declined"}, nil
+	})
+}
+
+func LeaveTeam(s *Server) http.HandlerFunc {
+	return NewTeamHandler(s, model.RoleMember, func(ctx context.Context, req *http.Request, m model.Member) (*Response, error) {
+		if err := query.LeaveTeamQuery(ctx, s.DBS, m.TeamID, m.UserID); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Left team"}, nil
+	})
+}
+
+func KickTeamMember(s *Server) http.HandlerFunc {
+	return NewTeamHandler(s, model.RoleCaptain, func(ctx context.Context, req *http.Request, m model.Member) (*Response, error) {
+		targetID, err := pathID(req, "userId")
+		if err != nil {
+			return nil, err
+		}
+		if err := query.KickTeamMemberQuery(ctx, s.DBS, m.TeamID, m.UserID, targetID); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Member removed"}, nil
+	})
+}
+
+func SetTeamRole(s *Server) http.HandlerFunc {
+	return NewTeamHandler(s, model.RoleOwner, func(ctx context.Context, req *http.Request, m model.Member) (*Response, error) {
+		targetID, err := pathID(req, "userId")
+		if err != nil {
+			return nil, err
+		}
+		var in RoleRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := query.SetTeamRoleQuery(ctx, s.DBS, m.TeamID, m.UserID, targetID, in.Role); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Role updated"}, nil
+	})
+}
+
+func TransferTeam(s *Server) http.HandlerFunc {
+	return NewTeamHandler(s, model.RoleOwner, func(ctx context.Context, req *http.Request, m model.Member) (*Response, error) {
+		var in TransferRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil || in.UserID == 0 {
+			return nil, fmt.Errorf("userId is required")
+		}
+		if err := query.TransferTeamQuery(ctx, s.DBS, m.TeamID, m.UserID, in.UserID); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Ownership transferred"}, nil
+	})
+}
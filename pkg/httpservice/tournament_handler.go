@@ -0,0 +1,410 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/result"
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/team"
+	teammodel "github.com/dudeiebot/sportPeerGo/pkg/team/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/tournament"
+	"github.com/dudeiebot/sportPeerGo/pkg/tournament/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+// undecidedBatch caps how many matches one run of the advance job retries.
+const undecidedBatch = 100
+
+type TournamentResponse struct {
+	Message    string            `json:"message"`
+	Tournament *model.Tournament `json:"tournament,omitempty"`
+}
+
+type RegisterEntrantRequest struct {
+	// TeamID enters a team instead of the caller, in team tournaments.
+	TeamID *int64 `json:"teamId,omitempty"`
+}
+
+type SeedRequest struct {
+	EntrantID int64 `json:"entrantId"`
+	Seed      int   `json:"seed"`
+}
+
+type LinkGameRequest struct {
+	GameID int64 `json:"gameId"`
+}
+
+type MatchWinnerRequest struct {
+	// WinnerID is the winning entrant, or nil for a drawn group match.
+	WinnerID *int64 `json:"winnerId"`
+}
+
+func CreateTournament(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*TournamentResponse, error) {
+		var t model.Tournament
+		if err := json.NewDecoder(req.Body).Decode(&t); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := t.ValidateTournament(); err != nil {
+			return nil, err
+		}
+		t.OrganizerID = ctx.Value("userId").(int64)
+
+		id, err := query.CreateTournamentQuery(ctx, s.DBS, t)
+		if err != nil {
+			return nil, fmt.Errorf("error creating tournament: %w", err)
+		}
+		created, err := query.GetTournamentQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		return &TournamentResponse{Message: "Tournament created successfully", Tournament: created}, nil
+	})
+}
+
+func ListTournaments(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Tournament, error) {
+		limit, offset, err := pageParams(req)
+		if err != nil {
+			return nil, err
+		}
+		q := req.URL.Query()
+		return query.ListTournamentsQuery(ctx, s.DBS, model.Filter{
+			Sport:  usermodel.NormalizeSport(q.Get("sport")),
+			Status: q.Get("status"),
+			Limit:  limit,
+			Offset: offset,
+		})
+	})
+}
+
+func GetTournament(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Tournament, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		return query.GetTournamentQuery(ctx, s.DBS, id)
+	})
+}
+
+func TournamentEntrants(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Entrant, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		return query.EntrantsQuery(ctx, s.DBS, id)
+	})
+}
+
+// RegisterForTournament enters the caller, or in team tournaments a team
+// the caller captains.
+func RegisterForTournament(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Entrant, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var in RegisterEntrantRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		userId := ctx.Value("userId").(int64)
+		e := model.Entrant{TournamentID: id}
+
+		if in.TeamID == nil {
+			e.UserID = &userId
+		} else {
+			m, err := query.TeamMemberQuery(ctx, s.DBS, *in.TeamID, userId)
+			if err != nil {
+				return nil, err
+			}
+			if !team.AtLeast(m.Role, teammodel.RoleCaptain) {
+				return nil, team.ErrForbidden
+			}
+			t, err := query.GetTournamentQuery(ctx, s.DBS, id)
+			if err != nil {
+				return nil, err
+			}
+			tm, err := query.GetTeamQuery(ctx, s.DBS, *in.TeamID)
+			if err != nil {
+				return nil, err
+			}
+			if tm.Sport != t.Sport {
+				return nil, tournament.ErrTeamSport
+			}
+			e.TeamID = in.TeamID
+		}
+
+		entrantID, err := query.RegisterEntrantQuery(ctx, s.DBS, e)
+		if err != nil {
+			return nil, err
+		}
+		return query.EntrantQuery(ctx, s.DBS, id, entrantID)
+	})
+}
+
+// WithdrawFromTournament removes an entrant before the start. Players
+// withdraw themselves, captains their team, and the organizer anyone.
+func WithdrawFromTournament(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		entrantID, err := pathID(req, "entrantId")
+		if err != nil {
+			return nil, err
+		}
+		t, err := query.GetTournamentQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		e, err := query.EntrantQuery(ctx, s.DBS, id, entrantID)
+		if err != nil {
+			return nil, err
+		}
+		userId := ctx.Value("userId").(int64)
+
+		allowed := t.OrganizerID == userId || (e.UserID != nil && *e.UserID == userId)
+		if !allowed && e.TeamID != nil {
+			m, err := query.TeamMemberQuery(ctx, s.DBS, *e.TeamID, userId)
+			if err != nil && err != team.ErrNotMember {
+				return nil, err
+			}
+			allowed = m != nil && team.AtLeast(m.Role, teammodel.RoleCaptain)
+		}
+		if !allowed {
+			return nil, tournament.ErrNotOrganizer
+		}
+
+		if err := query.WithdrawEntrantQuery(ctx, s.DBS, id, entrantID); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Withdrawn from the tournament"}, nil
+	})
+}
+
+// SeedTournament sets seeds by hand. Entrants left unseeded are placed
+// after the seeded ones when the tournament starts.
+func SeedTournament(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Entrant, error) {
+		t, err := organizedTournament(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var in []SeedRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		seeds := make(map[int64]int, len(in))
+		for _, seed := range in {
+			seeds[seed.EntrantID] = seed.Seed
+		}
+		if err := query.SetSeedsQuery(ctx, s.DBS, t.ID, seeds); err != nil {
+			return nil, err
+		}
+		return query.EntrantsQuery(ctx, s.DBS, t.ID)
+	})
+}
+
+// StartTournament closes registration and draws the bracket.
+func StartTournament(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Bracket, error) {
+		t, err := organizedTournament(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		if err := query.StartTournamentQuery(ctx, s.DBS, t.ID); err != nil {
+			return nil, err
+		}
+		return bracket(ctx, s, t.ID)
+	})
+}
+
+// TournamentBracket returns the draw: a tree from the final for
+// elimination formats, or the groups and their standings for round robin.
+func TournamentBracket(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Bracket, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		return bracket(ctx, s, id)
+	})
+}
+
+// LinkMatchGame has a ready match played as one of the organizer's games,
+// so that the game's confirmed result advances the bracket.
+func LinkMatchGame(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		t, err := organizedTournament(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		matchID, err := pathID(req, "matchId")
+		if err != nil {
+			return nil, err
+		}
+		var in LinkGameRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		g, err := query.GetGameQuery(ctx, s.DBS, in.GameID)
+		if err != nil {
+			return nil, err
+		}
+		if g.OrganizerID != t.OrganizerID || g.Sport != t.Sport {
+			return nil, tournament.ErrGameMismatch
+		}
+		if err := query.LinkMatchGameQuery(ctx, s.DBS, t.ID, matchID, g.ID); err != nil {
+			return nil, err
+		}
+
+		// The game may already have been played.
+		r, err := query.GameResultQuery(ctx, s.DBS, g.ID)
+		switch {
+		case err == nil && result.Final(*r):
+			go advanceTournament(s, *r)
+		case err != nil && err != result.ErrResultNotFound:
+			return nil, err
+		}
+		return &Response{Message: "Game linked to the match"}, nil
+	})
+}
+
+// SetMatchWinner lets the organizer decide a match without a result, for
+// walkovers or games played elsewhere.
+func SetMatchWinner(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Match, error) {
+		t, err := organizedTournament(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		matchID, err := pathID(req, "matchId")
+		if err != nil {
+			return nil, err
+		}
+		var in MatchWinnerRequest
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		matches, err := query.CompleteMatchQuery(ctx, s.DBS, t.ID, matchID, in.WinnerID)
+		if err != nil {
+			return nil, err
+		}
+		go tournamentFinished(s, t.ID)
+		return matches, nil
+	})
+}
+
+// organizedTournament loads the tournament in the path, which the caller
+// must organize.
+func organizedTournament(ctx context.Context, s *Server, req *http.Request) (*model.Tournament, error) {
+	id, err := pathID(req, "id")
+	if err != nil {
+		return nil, err
+	}
+	t, err := query.GetTournamentQuery(ctx, s.DBS, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.OrganizerID != ctx.Value("userId").(int64) {
+		return nil, tournament.ErrNotOrganizer
+	}
+	return t, nil
+}
+
+func bracket(ctx context.Context, s *Server, id int64) (*model.Bracket, error) {
+	t, err := query.GetTournamentQuery(ctx, s.DBS, id)
+	if err != nil {
+		return nil, err
+	}
+	entrants, err := query.EntrantsQuery(ctx, s.DBS, id)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := query.TournamentMatchesQuery(ctx, s.DBS, id)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &model.Bracket{Tournament: *t, Entrants: entrants}
+	if t.Format == model.FormatRoundRobin {
+		b.Groups = tournament.Groups(matches)
+	} else {
+		b.Root = tournament.Tree(matches)
+	}
+	return b, nil
+}
+
+// advanceTournament decides the tournament match played as r's game, if
+// there is one. If it fails the advance job picks the match up on its next
+// run.
+func advanceTournament(s *Server, r resultmodel.Result) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	advanceMatch(ctx, s, r)
+}
+
+// advanceTournaments retries the ready matches whose final result has not
+// decided them yet.
+func advanceTournaments(ctx context.Context, s *Server) {
+	gameIDs, err := query.UndecidedMatchGamesQuery(ctx, s.DBS, undecidedBatch)
+	if err != nil {
+		log.Printf("Failed to load undecided tournament matches: %v", err)
+		return
+	}
+	for _, id := range gameIDs {
+		r, err := query.GameResultQuery(ctx, s.DBS, id)
+		if err != nil {
+			log.Printf("Failed to load result of game %d: %v", id, err)
+			continue
+		}
+		advanceMatch(ctx, s, *r)
+	}
+}
+
+// advanceMatch records r on the match it decides. Results that do not line
+// up with the match's entrants, and draws in elimination matches, are left
+// for the organizer to settle.
+func advanceMatch(ctx context.Context, s *Server, r resultmodel.Result) {
+	m, err := query.MatchByGameQuery(ctx, s.DBS, r.GameID)
+	if err != nil {
+		if err != tournament.ErrMatchNotFound {
+			log.Printf("Failed to load tournament match of game %d: %v", r.GameID, err)
+		}
+		return
+	}
+	if m.Status != model.MatchReady {
+		return
+	}
+	playersA, err := query.EntrantPlayersQuery(ctx, s.DBS, *m.EntrantA)
+	if err != nil {
+		log.Printf("Failed to load players of entrant %d: %v", *m.EntrantA, err)
+		return
+	}
+	playersB, err := query.EntrantPlayersQuery(ctx, s.DBS, *m.EntrantB)
+	if err != nil {
+		log.Printf("Failed to load players of entrant %d: %v", *m.EntrantB, err)
+		return
+	}
+	winner, err := tournament.WinningEntrant(*m, playersA, playersB, r)
+	if err != nil {
+		// Left for the organizer; retrying cannot change the outcome.
+		return
+	}
+	if _, err := query.CompleteMatchQuery(ctx, s.DBS, m.TournamentID, m.ID, winner); err != nil {
+		log.Printf("Failed to advance tournament match %d from result %d: %v", m.ID, r.ID, err)
+		return
+	}
+	tournamentFinished(s, m.TournamentID)
+}
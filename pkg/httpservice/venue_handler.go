@@ -0,0 +1,269 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	query "github.com/dudeiebot/sportPeerGo/pkg/adapter/queries"
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	mediamodel "github.com/dudeiebot/sportPeerGo/pkg/media/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+type VenueResponse struct {
+	Message string       `json:"message"`
+	Venue   *model.Venue `json:"venue,omitempty"`
+}
+
+type ReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// SubmitVenue adds a venue. Venues from regular users wait for an admin to
+// approve them; venues added by admins are approved straight away.
+func SubmitVenue(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*VenueResponse, error) {
+		var v model.Venue
+		if err := json.NewDecoder(req.Body).Decode(&v); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := v.ValidateVenue(); err != nil {
+			return nil, err
+		}
+		admin, err := isAdmin(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		v.SubmittedBy = ctx.Value("userId").(int64)
+		v.Status = model.StatusPending
+		message := "Venue submitted for review"
+		if admin {
+			v.Status = model.StatusApproved
+			message = "Venue created"
+		}
+
+		id, err := query.CreateVenueQuery(ctx, s.DBS, v)
+		if err != nil {
+			return nil, err
+		}
+		created, err := query.GetVenueQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		return &VenueResponse{Message: message, Venue: created}, nil
+	})
+}
+
+func SearchVenues(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Venue, error) {
+		q := req.URL.Query()
+		f := model.Filter{Sport: usermodel.NormalizeSport(q.Get("sport")), Limit: 20}
+
+		if q.Get("lat") != "" || q.Get("lng") != "" {
+			lat, errLat := strconv.ParseFloat(q.Get("lat"), 64)
+			lng, errLng := strconv.ParseFloat(q.Get("lng"), 64)
+			p := geo.Point{Lat: lat, Lng: lng}
+			if errLat != nil || errLng != nil || !geo.Valid(p) {
+				return nil, fmt.Errorf("lat and lng must be valid coordinates")
+			}
+			f.Near = &p
+		}
+		if v := q.Get("radius"); v != "" {
+			radius, err := strconv.ParseFloat(v, 64)
+			if err != nil || radius <= 0 {
+				return nil, fmt.Errorf("invalid radius")
+			}
+			f.RadiusKm = radius
+		}
+		if v := q.Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit <= 0 || limit > 100 {
+				return nil, fmt.Errorf("limit must be between 1 and 100")
+			}
+			f.Limit = limit
+		}
+
+		venues, err := query.SearchVenuesQuery(ctx, s.DBS, f)
+		if err != nil {
+			return nil, err
+		}
+		return venue.Search(venues, f), nil
+	})
+}
+
+func GetVenue(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*model.Venue, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		v, err := query.GetVenueQuery(ctx, s.DBS, id)
+		if err != nil {
+			return nil, err
+		}
+		viewer, admin := viewerID(ctx), false
+		if viewer != 0 && v.Status != model.StatusApproved {
+			if admin, err = isAdmin(ctx, s); err != nil {
+				return nil, err
+			}
+		}
+		if !venue.CanView(*v, viewer, admin) {
+			return nil, venue.ErrVenueNotFound
+		}
+		v.Photos, err = imagesOf(ctx, s, mediamodel.KindVenue, v.ID)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+func MyVenues(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Venue, error) {
+		return query.VenuesBySubmitterQuery(ctx, s.DBS, ctx.Value("userId").(int64))
+	})
+}
+
+func UpdateVenue(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*VenueResponse, error) {
+		current, err := editableVenue(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var v model.Venue
+		if err := json.NewDecoder(req.Body).Decode(&v); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		// Courts are managed through their own endpoints.
+		v.Courts = nil
+		if err := v.ValidateVenue(); err != nil {
+			return nil, err
+		}
+		v.ID = current.ID
+
+		if err := query.UpdateVenueQuery(ctx, s.DBS, v); err != nil {
+			return nil, err
+		}
+		updated, err := query.GetVenueQuery(ctx, s.DBS, v.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &VenueResponse{Message: "Venue updated", Venue: updated}, nil
+	})
+}
+
+func AddCourt(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*VenueResponse, error) {
+		v, err := editableVenue(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		var c model.Court
+		if err := json.NewDecoder(req.Body).Decode(&c); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		if err := c.ValidateCourt(); err != nil {
+			return nil, err
+		}
+		if _, err := query.AddCourtQuery(ctx, s.DBS, v.ID, c); err != nil {
+			return nil, err
+		}
+		updated, err := query.GetVenueQuery(ctx, s.DBS, v.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &VenueResponse{Message: "Court added", Venue: updated}, nil
+	})
+}
+
+func RemoveCourt(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		v, err := editableVenue(ctx, s, req)
+		if err != nil {
+			return nil, err
+		}
+		courtID, err := pathID(req, "courtId")
+		if err != nil {
+			return nil, err
+		}
+		if err := query.DeactivateCourtQuery(ctx, s.DBS, v.ID, courtID); err != nil {
+			return nil, err
+		}
+		return &Response{Message: "Court removed"}, nil
+	})
+}
+
+func PendingVenues(s *Server) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) ([]model.Venue, error) {
+		return query.PendingVenuesQuery(ctx, s.DBS)
+	})
+}
+
+func ApproveVenue(s *Server) http.HandlerFunc {
+	return reviewVenue(s, true)
+}
+
+func RejectVenue(s *Server) http.HandlerFunc {
+	return reviewVenue(s, false)
+}
+
+func reviewVenue(s *Server, approve bool) http.HandlerFunc {
+	return NewHandler(func(ctx context.Context, req *http.Request) (*Response, error) {
+		id, err := pathID(req, "id")
+		if err != nil {
+			return nil, err
+		}
+		var r ReviewRequest
+		if req.ContentLength > 0 {
+			if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+				return nil, fmt.Errorf("invalid request body")
+			}
+		}
+		if err := query.ReviewVenueQuery(ctx, s.DBS, id, approve, r.Note); err != nil {
+			return nil, err
+		}
+		if approve {
+			return &Response{Message: "Venue approved"}, nil
+		}
+		return &Response{Message: "Venue rejected"}, nil
+	})
+}
+
+func editableVenue(ctx context.Context, s *Server, req *http.Request) (*model.Venue, error) {
+	id, err := pathID(req, "id")
+	if err != nil {
+		return nil, err
+	}
+	v, err := query.GetVenueQuery(ctx, s.DBS, id)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := isAdmin(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if !venue.CanEdit(*v, ctx.Value("userId").(int64), admin) {
+		return nil, venue.ErrNotAllowed
+	}
+	return v, nil
+}
+
+// checkGameVenue makes sure a game is only placed at an approved venue.
+func checkGameVenue(ctx context.Context, s *Server, venueID *int64) error {
+	if venueID == nil {
+		return nil
+	}
+	v, err := query.GetVenueQuery(ctx, s.DBS, *venueID)
+	if err != nil {
+		return err
+	}
+	if v.Status != model.StatusApproved {
+		return venue.ErrVenueNotOpen
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package leaderboard
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/leaderboard/model"
+)
+
+// Row is one player's standing in one sport, before ranking.
+type Row struct {
+	UserID   int64
+	Username string
+	City     string
+	Sport    string
+	Score    float64
+	Games    int
+}
+
+// Key identifies a board within a metric and window. City is empty for the
+// global board.
+type Key struct {
+	Sport string
+	City  string
+}
+
+// Period returns the key of the period window covers at now, and when that
+// period began. Seasons are calendar quarters. The all-time window starts at
+// the zero time.
+func Period(window string, now time.Time) (string, time.Time) {
+	now = now.UTC()
+	switch window {
+	case model.WindowMonth:
+		return now.Format("2006-01"), time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case model.WindowSeason:
+		q := (int(now.Month()) - 1) / 3
+		start := time.Date(now.Year(), time.Month(q*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return fmt.Sprintf("%d-Q%d", now.Year(), q+1), start
+	}
+	return model.WindowAll, time.Time{}
+}
+
+// Build ranks rows onto every board they belong on: the global board of
+// their sport, and their city's board when they have set a city.
+func Build(rows []Row) map[Key][]model.Entry {
+	boards := make(map[Key][]model.Entry)
+	for _, r := range rows {
+		e := model.Entry{UserID: r.UserID, Username: r.Username, City: r.City, Score: r.Score, Games: r.Games}
+		global := Key{Sport: r.Sport}
+		boards[global] = append(boards[global], e)
+		if r.City != "" {
+			local := Key{Sport: r.Sport, City: r.City}
+			boards[local] = append(boards[local], e)
+		}
+	}
+	for _, entries := range boards {
+		Rank(entries)
+	}
+	return boards
+}
+
+// Rank sorts entries best first and numbers them. Equal scores share a rank
+// and the next rank is skipped, so ties read 1, 2, 2, 4. More games breaks
+// ties in the order, but not in the rank.
+func Rank(entries []model.Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Games != b.Games {
+			return a.Games > b.Games
+		}
+		return a.UserID < b.UserID
+	})
+	for i := range entries {
+		if i > 0 && entries[i].Score == entries[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package leaderboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/leaderboard/model"
+)
+
+func TestPeriod(t *testing.T) {
+	now := time.Date(2024, 8, 17, 22, 30, 0, 0, time.UTC)
+	tests := []struct {
+		window    string
+		wantKey   string
+		wantStart time.Time
+	}{
+		{model.WindowAll, "all", time.Time{}},
+		{model.WindowSeason, "2024-Q3", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{model.WindowMonth, "2024-08", time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			key, start := Period(tt.window, now)
+			if key != tt.wantKey || !start.Equal(tt.wantStart) {
+				t.Errorf("Period = %s, %v, want %s, %v", key, start, tt.wantKey, tt.wantStart)
+			}
+		})
+	}
+
+	// Periods are UTC whatever zone now is in.
+	lagos := time.FixedZone("WAT", 3600)
+	if key, _ := Period(model.WindowMonth, time.Date(2024, 9, 1, 0, 30, 0, 0, lagos)); key != "2024-08" {
+		t.Errorf("Period near midnight = %s, want 2024-08", key)
+	}
+}
+
+func TestRankSharesTies(t *testing.T) {
+	entries := []model.Entry{
+		{UserID: 1, Score: 10, Games: 1},
+		{UserID: 2, Score: 30, Games: 1},
+		{UserID: 3, Score: 20, Games: 2},
+		{UserID: 4, Score: 20, Games: 5},
+	}
+	Rank(entries)
+
+	want := []struct {
+		id   int64
+		rank int
+	}{{2, 1}, {4, 2}, {3, 2}, {1, 4}}
+	for i, w := range want {
+		if entries[i].UserID != w.id || entries[i].Rank != w.rank {
+			t.Errorf("position %d = user %d rank %d, want user %d rank %d",
+				i, entries[i].UserID, entries[i].Rank, w.id, w.rank)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	boards := Build([]Row{
+		{UserID: 1, Sport: "tennis", City: "lagos", Score: 1700},
+		{UserID: 2, Sport: "tennis", City: "abuja", Score: 1800},
+		{UserID: 3, Sport: "tennis", Score: 1600},
+		{UserID: 1, Sport: "squash", City: "lagos", Score: 1500},
+	})
+
+	if len(boards) != 5 {
+		t.Fatalf("Build made %d boards, want 5", len(boards))
+	}
+	global := boards[Key{Sport: "tennis"}]
+	if len(global) != 3 || global[0].UserID != 2 || global[2].UserID != 3 {
+		t.Errorf("global tennis board = %+v", global)
+	}
+	lagos := boards[Key{Sport: "tennis", City: "lagos"}]
+	if len(lagos) != 1 || lagos[0].UserID != 1 || lagos[0].Rank != 1 {
+		t.Errorf("lagos tennis board = %+v", lagos)
+	}
+	if squash := boards[Key{Sport: "squash", City: "lagos"}]; len(squash) != 1 {
+		t.Errorf("lagos squash board = %+v", squash)
+	}
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+const (
+	// MetricRating ranks by current rating, among players with settled
+	// ratings who played rated games in the window.
+	MetricRating = "rating"
+	// MetricActivity ranks by games played in the window.
+	MetricActivity = "activity"
+
+	WindowAll    = "all"
+	WindowSeason = "season"
+	WindowMonth  = "month"
+)
+
+var (
+	Metrics = []string{MetricRating, MetricActivity}
+	Windows = []string{WindowAll, WindowSeason, WindowMonth}
+)
+
+type Entry struct {
+	Rank     int     `json:"rank"`
+	UserID   int64   `json:"userId"`
+	Username string  `json:"username"`
+	City     string  `json:"city,omitempty"`
+	Score    float64 `json:"score"`
+	Games    int     `json:"games"`
+}
+
+// Board is a page of one leaderboard. Me is the requesting user's own entry,
+// wherever they rank, and is left out when they are not on the board.
+type Board struct {
+	Sport       string     `json:"sport"`
+	City        string     `json:"city,omitempty"`
+	Metric      string     `json:"metric"`
+	Window      string     `json:"window"`
+	Period      string     `json:"period"`
+	RefreshedAt *time.Time `json:"refreshedAt,omitempty"`
+	Entries     []Entry    `json:"entries"`
+	Me          *Entry     `json:"me,omitempty"`
+}
+
+// Query picks a leaderboard. City is empty for the global board.
+type Query struct {
+	Sport  string
+	City   string
+	Metric string
+	Window string
+}
+
+func (q *Query) ValidateQuery() error {
+	var errors []string
+
+	q.Sport = usermodel.NormalizeSport(q.Sport)
+	q.City = usermodel.NormalizeCity(q.City)
+	if q.Metric == "" {
+		q.Metric = MetricRating
+	}
+	if q.Window == "" {
+		q.Window = WindowAll
+	}
+
+	if q.Sport == "" {
+		errors = append(errors, "Sport is required")
+	}
+	if !contains(Metrics, q.Metric) {
+		errors = append(errors, fmt.Sprintf("Metric must be one of %s", strings.Join(Metrics, ", ")))
+	}
+	if !contains(Windows, q.Window) {
+		errors = append(errors, fmt.Sprintf("Window must be one of %s", strings.Join(Windows, ", ")))
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package model
+
+import "testing"
+
+func TestValidateQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		q       Query
+		wantErr bool
+	}{
+		{"Defaults", Query{Sport: "Tennis"}, false},
+		{"City board", Query{Sport: "tennis", City: " Lagos ", Metric: MetricActivity, Window: WindowMonth}, false},
+		{"Missing sport", Query{}, true},
+		{"Unknown metric", Query{Sport: "tennis", Metric: "wins"}, true},
+		{"Unknown window", Query{Sport: "tennis", Window: "week"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.q.ValidateQuery(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	q := Query{Sport: " Tennis", City: "LAGOS"}
+	if err := q.ValidateQuery(); err != nil {
+		t.Fatal(err)
+	}
+	if q.Sport != "tennis" || q.City != "lagos" || q.Metric != MetricRating || q.Window != WindowAll {
+		t.Errorf("ValidateQuery left %+v", q)
+	}
+}
@@ -0,0 +1,203 @@
+package league
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/league/model"
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+)
+
+var (
+	ErrLeagueNotFound   = errors.New("league not found")
+	ErrSeasonNotFound   = errors.New("season not found in this league")
+	ErrDivisionNotFound = errors.New("division not found in this season")
+	ErrFixtureNotFound  = errors.New("fixture not found in this league")
+	ErrMemberNotFound   = errors.New("member not found in this season")
+	ErrNotOrganizer     = errors.New("only the organizer can manage this league")
+	ErrSeasonOpen       = errors.New("the league's current season has not finished")
+	ErrSeasonStarted    = errors.New("the season has already started")
+	ErrSeasonNotActive  = errors.New("the season is not being played")
+	ErrAlreadyMember    = errors.New("already playing in this season")
+	ErrWrongEntrantType = errors.New("this league is not open to that kind of entrant")
+	ErrTeamSport        = errors.New("the team does not play this league's sport")
+	ErrNotEnoughMembers = errors.New("every division needs at least two members")
+	ErrDivisionFull     = errors.New("this division is full")
+	ErrFixturesLeft     = errors.New("fixtures are still to be played")
+	ErrFixtureLinked    = errors.New("this fixture already has a game")
+	ErrGameMismatch     = errors.New("the game must be a game of the league's sport that you organize")
+	ErrResultMismatch   = errors.New("the result's sides do not match the members of the fixture")
+	ErrInvalidScore     = errors.New("scores cannot be negative")
+	ErrNoDates          = errors.New("the season ends before every fixture can be scheduled")
+)
+
+// Standings ranks the members of a division from its played fixtures: by
+// points, then by each of rules' tiebreakers among the members still level,
+// then by name.
+func Standings(members []model.Member, fixtures []model.Fixture, rules model.Rules) []model.Standing {
+	rows := make(map[int64]*model.Standing, len(members))
+	group := make([]*model.Standing, len(members))
+	for i, m := range members {
+		group[i] = &model.Standing{MemberID: m.ID, Name: m.Name}
+		rows[m.ID] = group[i]
+	}
+
+	var played []model.Fixture
+	for _, f := range fixtures {
+		home, away := rows[f.HomeID], rows[f.AwayID]
+		if f.Status != model.FixturePlayed || home == nil || away == nil {
+			continue
+		}
+		played = append(played, f)
+		record(home, *f.HomeScore, *f.AwayScore, rules)
+		record(away, *f.AwayScore, *f.HomeScore, rules)
+	}
+
+	r := ranker{fixtures: played, rules: rules}
+	group = r.rank(group, append([]string{"points"}, rules.Tiebreakers...))
+	out := make([]model.Standing, len(group))
+	for i, s := range group {
+		s.Position = i + 1
+		out[i] = *s
+	}
+	return out
+}
+
+func record(s *model.Standing, scored, conceded int, rules model.Rules) {
+	s.Played++
+	s.For += scored
+	s.Against += conceded
+	s.Difference = s.For - s.Against
+	switch {
+	case scored > conceded:
+		s.Won++
+		s.Points += rules.Win
+	case scored < conceded:
+		s.Lost++
+		s.Points += rules.Loss
+	default:
+		s.Drawn++
+		s.Points += rules.Draw
+	}
+}
+
+type ranker struct {
+	fixtures []model.Fixture
+	rules    model.Rules
+}
+
+// rank orders group by the first criterion and ranks each run of members
+// still level by the rest. Head to head only counts fixtures between the
+// members of the run, so it is worked out afresh for every run.
+func (r ranker) rank(group []*model.Standing, criteria []string) []*model.Standing {
+	if len(group) < 2 {
+		return group
+	}
+	if len(criteria) == 0 {
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].Name != group[j].Name {
+				return group[i].Name < group[j].Name
+			}
+			return group[i].MemberID < group[j].MemberID
+		})
+		return group
+	}
+
+	key := r.key(group, criteria[0])
+	sort.SliceStable(group, func(i, j int) bool {
+		return key[group[i].MemberID] > key[group[j].MemberID]
+	})
+	out := make([]*model.Standing, 0, len(group))
+	for start := 0; start < len(group); {
+		end := start + 1
+		for end < len(group) && key[group[end].MemberID] == key[group[start].MemberID] {
+			end++
+		}
+		out = append(out, r.rank(group[start:end], criteria[1:])...)
+		start = end
+	}
+	return out
+}
+
+func (r ranker) key(group []*model.Standing, criterion string) map[int64]int {
+	key := make(map[int64]int, len(group))
+	switch criterion {
+	case model.TiebreakHeadToHead:
+		in := make(map[int64]bool, len(group))
+		for _, s := range group {
+			in[s.MemberID] = true
+		}
+		for _, f := range r.fixtures {
+			if !in[f.HomeID] || !in[f.AwayID] {
+				continue
+			}
+			home, away := r.rules.Draw, r.rules.Draw
+			switch {
+			case *f.HomeScore > *f.AwayScore:
+				home, away = r.rules.Win, r.rules.Loss
+			case *f.HomeScore < *f.AwayScore:
+				home, away = r.rules.Loss, r.rules.Win
+			}
+			key[f.HomeID] += home
+			key[f.AwayID] += away
+		}
+	default:
+		for _, s := range group {
+			switch criterion {
+			case "points":
+				key[s.MemberID] = s.Points
+			case model.TiebreakDifference:
+				key[s.MemberID] = s.Difference
+			case model.TiebreakScored:
+				key[s.MemberID] = s.For
+			case model.TiebreakWins:
+				key[s.MemberID] = s.Won
+			}
+		}
+	}
+	return key
+}
+
+// Promote marks who moves between divisions on tables, which are ordered
+// from the top division down, and returns the members of each division
+// for next season in the same order. The top Promotion of every division
+// but the first go up and the bottom Relegation of every division but the
+// last go down; a member is never both.
+func Promote(tables []model.Table, rules model.Rules) [][]model.Member {
+	next := make([][]model.Member, len(tables))
+	for level, t := range tables {
+		members := make(map[int64]model.Member, len(t.Division.Members))
+		for _, m := range t.Division.Members {
+			members[m.ID] = m
+		}
+		n := len(t.Standings)
+		for i := range t.Standings {
+			s := &t.Standings[i]
+			to := level
+			switch {
+			case level > 0 && i < rules.Promotion:
+				s.Movement, to = model.MovePromoted, level-1
+			case level < len(tables)-1 && i >= n-rules.Relegation:
+				s.Movement, to = model.MoveRelegated, level+1
+			}
+			if m, ok := members[s.MemberID]; ok {
+				next[to] = append(next[to], m)
+			}
+		}
+	}
+	return next
+}
+
+// FixtureScore reads a fixture's score, home side first, off the game
+// result r, given the players of the home and the away member.
+func FixtureScore(playersHome, playersAway map[int64]bool, r resultmodel.Result) (int, int, error) {
+	home := r.SideOfPlayers(playersHome)
+	away := r.SideOfPlayers(playersAway)
+	if home == "" || away == "" || home == away {
+		return 0, 0, ErrResultMismatch
+	}
+	if home == resultmodel.SideA {
+		return r.Score.A, r.Score.B, nil
+	}
+	return r.Score.B, r.Score.A, nil
+}
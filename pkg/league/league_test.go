@@ -0,0 +1,140 @@
+package league
+
+import (
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/league/model"
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+)
+
+func played(home, away int64, homeScore, awayScore int) model.Fixture {
+	return model.Fixture{
+		HomeID: home, AwayID: away, Status: model.FixturePlayed,
+		HomeScore: &homeScore, AwayScore: &awayScore,
+	}
+}
+
+func members(names ...string) []model.Member {
+	out := make([]model.Member, len(names))
+	for i, name := range names {
+		out[i] = model.Member{ID: int64(i + 1), Name: name}
+	}
+	return out
+}
+
+func order(standings []model.Standing) []int64 {
+	out := make([]int64, len(standings))
+	for i, s := range standings {
+		out[i] = s.MemberID
+	}
+	return out
+}
+
+func TestStandings(t *testing.T) {
+	// 1 and 2 both finish on 4 points; 2 won their meeting but 1 scored
+	// more.
+	fixtures := []model.Fixture{
+		played(1, 2, 0, 1),
+		played(1, 3, 5, 0),
+		played(2, 3, 1, 1),
+		played(3, 4, 0, 0),
+		played(4, 1, 2, 2),
+		{HomeID: 2, AwayID: 4, Status: model.FixtureScheduled},
+	}
+
+	tests := []struct {
+		name        string
+		tiebreakers []string
+		want        []int64
+	}{
+		{"Scored first", []string{model.TiebreakScored, model.TiebreakHeadToHead}, []int64{1, 2, 3, 4}},
+		{"Head to head first", []string{model.TiebreakHeadToHead, model.TiebreakScored}, []int64{2, 1, 3, 4}},
+		{"Only wins, then name", []string{model.TiebreakWins}, []int64{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := model.DefaultRules()
+			rules.Tiebreakers = tt.tiebreakers
+			got := Standings(members("a", "b", "c", "d"), fixtures, rules)
+			if o := order(got); len(o) != 4 || o[0] != tt.want[0] || o[1] != tt.want[1] {
+				t.Errorf("order = %v, want %v", o, tt.want)
+			}
+		})
+	}
+
+	got := Standings(members("a", "b", "c", "d"), fixtures, model.DefaultRules())
+	top := got[0]
+	if top.Position != 1 || top.Played != 3 || top.Won != 1 || top.Drawn != 1 || top.Lost != 1 ||
+		top.For != 7 || top.Against != 3 || top.Difference != 4 || top.Points != 4 {
+		t.Errorf("leader = %+v", top)
+	}
+	if got[1].Played != 2 {
+		t.Errorf("unplayed fixture counted: %+v", got[1])
+	}
+}
+
+func TestHeadToHeadMiniLeague(t *testing.T) {
+	// Three members level on points: head to head only counts the
+	// fixtures between them, so beating the fourth member does not help.
+	fixtures := []model.Fixture{
+		played(1, 2, 1, 0),
+		played(2, 3, 1, 0),
+		played(3, 1, 1, 0),
+		played(3, 4, 9, 0),
+		played(1, 4, 1, 0),
+		played(2, 4, 1, 0),
+	}
+	rules := model.DefaultRules()
+	rules.Tiebreakers = []string{model.TiebreakHeadToHead, model.TiebreakDifference}
+	if got := order(Standings(members("a", "b", "c", "d"), fixtures, rules)); got[0] != 3 || got[3] != 4 {
+		t.Errorf("order = %v, want 3 first on difference after a level mini-league", got)
+	}
+}
+
+func TestPromote(t *testing.T) {
+	table := func(level int, ids ...int64) model.Table {
+		var t model.Table
+		t.Division.Level = level
+		for i, id := range ids {
+			t.Division.Members = append(t.Division.Members, model.Member{ID: id})
+			t.Standings = append(t.Standings, model.Standing{Position: i + 1, MemberID: id})
+		}
+		return t
+	}
+	tables := []model.Table{table(1, 1, 2, 3), table(2, 4, 5, 6), table(3, 7, 8)}
+	next := Promote(tables, model.Rules{Promotion: 1, Relegation: 1})
+
+	want := [][]int64{{1, 2, 4}, {3, 5, 7}, {6, 8}}
+	for level, ms := range next {
+		got := make(map[int64]bool)
+		for _, m := range ms {
+			got[m.ID] = true
+		}
+		if len(ms) != len(want[level]) {
+			t.Errorf("division %d = %v, want %v", level+1, ms, want[level])
+			continue
+		}
+		for _, id := range want[level] {
+			if !got[id] {
+				t.Errorf("division %d is missing %d", level+1, id)
+			}
+		}
+	}
+	if tables[1].Standings[0].Movement != model.MovePromoted || tables[1].Standings[2].Movement != model.MoveRelegated {
+		t.Errorf("division 2 movements = %+v", tables[1].Standings)
+	}
+	if tables[0].Standings[0].Movement != "" || tables[2].Standings[1].Movement != "" {
+		t.Error("top of the first or bottom of the last division moved")
+	}
+}
+
+func TestFixtureScore(t *testing.T) {
+	r := resultmodel.Result{SideA: []int64{10, 11}, SideB: []int64{20}, Score: resultmodel.Score{A: 3, B: 1}}
+	home, away, err := FixtureScore(map[int64]bool{20: true, 21: true}, map[int64]bool{10: true}, r)
+	if err != nil || home != 1 || away != 3 {
+		t.Errorf("FixtureScore = %d, %d, %v, want 1, 3", home, away, err)
+	}
+	if _, _, err := FixtureScore(map[int64]bool{10: true}, map[int64]bool{11: true}, r); err != ErrResultMismatch {
+		t.Errorf("both members on one side = %v, want %v", err, ErrResultMismatch)
+	}
+}
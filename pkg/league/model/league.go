@@ -0,0 +1,299 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+const (
+	EntrantPlayer = "player"
+	EntrantTeam   = "team"
+
+	// SeasonDraft takes members; SeasonActive has its fixtures scheduled.
+	SeasonDraft     = "draft"
+	SeasonActive    = "active"
+	SeasonCompleted = "completed"
+
+	FixtureScheduled = "scheduled"
+	FixturePlayed    = "played"
+
+	// Tiebreakers, applied in order to members level on points.
+	TiebreakHeadToHead = "head_to_head"
+	TiebreakDifference = "difference"
+	TiebreakScored     = "scored"
+	TiebreakWins       = "wins"
+
+	MovePromoted  = "promoted"
+	MoveRelegated = "relegated"
+
+	MaxNameLength          = 100
+	MaxDivisions           = 20
+	MaxMembers             = 40
+	DefaultDurationMinutes = 90
+)
+
+var Tiebreakers = []string{TiebreakHeadToHead, TiebreakDifference, TiebreakScored, TiebreakWins}
+
+type League struct {
+	ID          int64     `json:"id"`
+	OrganizerID int64     `json:"organizerId"`
+	Name        string    `json:"name"`
+	Sport       string    `json:"sport"`
+	EntrantType string    `json:"entrantType"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Rules decide the standings: points for each outcome, the tiebreakers
+// for members level on points, and how many members of each division move
+// up and down at the end of the season.
+type Rules struct {
+	Win         int      `json:"win"`
+	Draw        int      `json:"draw"`
+	Loss        int      `json:"loss"`
+	Tiebreakers []string `json:"tiebreakers"`
+	Promotion   int      `json:"promotion"`
+	Relegation  int      `json:"relegation"`
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		Win:         3,
+		Draw:        1,
+		Tiebreakers: []string{TiebreakDifference, TiebreakScored, TiebreakHeadToHead},
+		Promotion:   1,
+		Relegation:  1,
+	}
+}
+
+// Plan is when and where fixtures may be played. Only the date of StartsAt
+// and EndsAt counts; their time zone is the league's. Kickoffs are in
+// minutes from midnight, like profile availability.
+type Plan struct {
+	StartsAt        time.Time  `json:"startsAt"`
+	EndsAt          *time.Time `json:"endsAt,omitempty"`
+	Weekdays        []int      `json:"weekdays"`
+	Kickoffs        []int      `json:"kickoffs"`
+	DurationMinutes int        `json:"durationMinutes"`
+	// Legs is 2 for a home and an away fixture between every pair of
+	// members, or 1 for a single fixture.
+	Legs      int         `json:"legs"`
+	Blackouts []time.Time `json:"blackouts,omitempty"`
+	// VenueID hosts the home fixtures of members without a home venue.
+	VenueID *int64 `json:"venueId,omitempty"`
+}
+
+type Season struct {
+	ID        int64      `json:"id"`
+	LeagueID  int64      `json:"leagueId"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	Rules     Rules      `json:"rules"`
+	Plan      Plan       `json:"plan"`
+	Divisions []Division `json:"divisions"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Division is one tier of a season. Level 1 is the top division.
+type Division struct {
+	ID       int64    `json:"id"`
+	SeasonID int64    `json:"seasonId"`
+	Name     string   `json:"name"`
+	Level    int      `json:"level"`
+	Members  []Member `json:"members,omitempty"`
+}
+
+// Member is a player or a team playing in a division.
+type Member struct {
+	ID          int64  `json:"id"`
+	DivisionID  int64  `json:"divisionId"`
+	UserID      *int64 `json:"userId,omitempty"`
+	TeamID      *int64 `json:"teamId,omitempty"`
+	Name        string `json:"name"`
+	HomeVenueID *int64 `json:"homeVenueId,omitempty"`
+}
+
+type Fixture struct {
+	ID         int64     `json:"id"`
+	SeasonID   int64     `json:"seasonId"`
+	DivisionID int64     `json:"divisionId"`
+	Round      int       `json:"round"`
+	HomeID     int64     `json:"homeId"`
+	AwayID     int64     `json:"awayId"`
+	VenueID    *int64    `json:"venueId,omitempty"`
+	StartsAt   time.Time `json:"startsAt"`
+	EndsAt     time.Time `json:"endsAt"`
+	Status     string    `json:"status"`
+	HomeScore  *int      `json:"homeScore,omitempty"`
+	AwayScore  *int      `json:"awayScore,omitempty"`
+	GameID     *int64    `json:"gameId,omitempty"`
+}
+
+type Standing struct {
+	Position   int    `json:"position"`
+	MemberID   int64  `json:"memberId"`
+	Name       string `json:"name"`
+	Played     int    `json:"played"`
+	Won        int    `json:"won"`
+	Drawn      int    `json:"drawn"`
+	Lost       int    `json:"lost"`
+	For        int    `json:"for"`
+	Against    int    `json:"against"`
+	Difference int    `json:"difference"`
+	Points     int    `json:"points"`
+	// Movement is where the member goes next season if the table stands.
+	Movement string `json:"movement,omitempty"`
+}
+
+// Table is the standings of a division.
+type Table struct {
+	Division  Division   `json:"division"`
+	Standings []Standing `json:"standings"`
+}
+
+type Filter struct {
+	Sport  string
+	Limit  int
+	Offset int
+}
+
+func (l *League) ValidateLeague() error {
+	var errors []string
+
+	l.Name = strings.TrimSpace(l.Name)
+	l.Sport = usermodel.NormalizeSport(l.Sport)
+	if l.EntrantType == "" {
+		l.EntrantType = EntrantTeam
+	}
+
+	if l.Name == "" || len(l.Name) > MaxNameLength {
+		errors = append(errors, fmt.Sprintf("Name is required and cannot exceed %d characters", MaxNameLength))
+	}
+	if l.Sport == "" {
+		errors = append(errors, "Sport is required")
+	}
+	if l.EntrantType != EntrantPlayer && l.EntrantType != EntrantTeam {
+		errors = append(errors, "Entrant type must be player or team")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+// ValidateSeason checks a new season and fills in defaults. Divisions are
+// listed top first and only need names.
+func (s *Season) ValidateSeason() error {
+	var errors []string
+
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" || len(s.Name) > MaxNameLength {
+		errors = append(errors, fmt.Sprintf("Name is required and cannot exceed %d characters", MaxNameLength))
+	}
+	if len(s.Divisions) == 0 {
+		s.Divisions = []Division{{Name: "Division 1"}}
+	}
+	if len(s.Divisions) > MaxDivisions {
+		errors = append(errors, fmt.Sprintf("A season cannot have more than %d divisions", MaxDivisions))
+	}
+	for i := range s.Divisions {
+		d := &s.Divisions[i]
+		d.Name = strings.TrimSpace(d.Name)
+		d.Level = i + 1
+		if d.Name == "" || len(d.Name) > MaxNameLength {
+			errors = append(errors, fmt.Sprintf("Division names are required and cannot exceed %d characters", MaxNameLength))
+			break
+		}
+	}
+	errors = append(errors, s.Rules.validate()...)
+	errors = append(errors, s.Plan.validate()...)
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+func (r *Rules) validate() []string {
+	var errors []string
+	defaults := DefaultRules()
+	if r.Win == 0 && r.Draw == 0 && r.Loss == 0 {
+		if r.Tiebreakers == nil && r.Promotion == 0 && r.Relegation == 0 {
+			*r = defaults
+		}
+		r.Win, r.Draw, r.Loss = defaults.Win, defaults.Draw, defaults.Loss
+	}
+	if r.Tiebreakers == nil {
+		r.Tiebreakers = defaults.Tiebreakers
+	}
+
+	if r.Loss < 0 || r.Draw < r.Loss || r.Win <= r.Draw {
+		errors = append(errors, "Points must be at least 0 for a loss, no fewer for a draw and more for a win")
+	}
+	seen := make(map[string]bool)
+	for _, t := range r.Tiebreakers {
+		if !known(Tiebreakers, t) || seen[t] {
+			errors = append(errors, fmt.Sprintf("Tiebreakers must be distinct and among %s", strings.Join(Tiebreakers, ", ")))
+			break
+		}
+		seen[t] = true
+	}
+	if r.Promotion < 0 || r.Relegation < 0 {
+		errors = append(errors, "Promotion and relegation cannot be negative")
+	}
+	return errors
+}
+
+func (p *Plan) validate() []string {
+	var errors []string
+	if p.Legs == 0 {
+		p.Legs = 2
+	}
+	if p.DurationMinutes == 0 {
+		p.DurationMinutes = DefaultDurationMinutes
+	}
+
+	if p.StartsAt.IsZero() {
+		errors = append(errors, "Start date is required")
+	}
+	if p.EndsAt != nil && p.EndsAt.Before(p.StartsAt) {
+		errors = append(errors, "End date cannot be before the start date")
+	}
+	if len(p.Weekdays) == 0 {
+		errors = append(errors, "At least one match weekday is required")
+	}
+	for _, d := range p.Weekdays {
+		if d < 0 || d > 6 {
+			errors = append(errors, "Weekdays must be between 0 (Sunday) and 6")
+			break
+		}
+	}
+	if len(p.Kickoffs) == 0 {
+		errors = append(errors, "At least one kickoff time is required")
+	}
+	for _, k := range p.Kickoffs {
+		if k < 0 || k >= 24*60 {
+			errors = append(errors, "Kickoff times must be within the day")
+			break
+		}
+	}
+	if p.DurationMinutes < 0 || p.DurationMinutes > 24*60 {
+		errors = append(errors, "Duration must be between 1 minute and a day")
+	}
+	if p.Legs != 1 && p.Legs != 2 {
+		errors = append(errors, "Legs must be 1 or 2")
+	}
+	return errors
+}
+
+func known(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
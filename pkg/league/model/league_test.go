@@ -0,0 +1,72 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateSeason(t *testing.T) {
+	starts := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	valid := func() Season {
+		return Season{
+			Name:      "2024/25",
+			Divisions: []Division{{Name: "Premier"}, {Name: "Championship"}},
+			Plan:      Plan{StartsAt: starts, Weekdays: []int{6}, Kickoffs: []int{600}},
+		}
+	}
+	customRules := valid()
+	customRules.Rules = Rules{Win: 2, Draw: 1, Tiebreakers: []string{TiebreakWins}}
+	badPoints := valid()
+	badPoints.Rules = Rules{Win: 1, Draw: 1}
+	unknownTiebreak := valid()
+	unknownTiebreak.Rules.Tiebreakers = []string{"coin_toss"}
+	repeatedTiebreak := valid()
+	repeatedTiebreak.Rules.Tiebreakers = []string{TiebreakWins, TiebreakWins}
+	noWeekdays := valid()
+	noWeekdays.Plan.Weekdays = nil
+	lateKickoff := valid()
+	lateKickoff.Plan.Kickoffs = []int{24 * 60}
+	threeLegs := valid()
+	threeLegs.Plan.Legs = 3
+	endsEarly := valid()
+	end := starts.AddDate(0, 0, -1)
+	endsEarly.Plan.EndsAt = &end
+	unnamedDivision := valid()
+	unnamedDivision.Divisions[1].Name = " "
+
+	tests := []struct {
+		name    string
+		s       Season
+		wantErr bool
+	}{
+		{"Valid", valid(), false},
+		{"Custom rules", customRules, false},
+		{"Draw worth a win", badPoints, true},
+		{"Unknown tiebreaker", unknownTiebreak, true},
+		{"Repeated tiebreaker", repeatedTiebreak, true},
+		{"No weekdays", noWeekdays, true},
+		{"Kickoff past midnight", lateKickoff, true},
+		{"Three legs", threeLegs, true},
+		{"Ends before it starts", endsEarly, true},
+		{"Unnamed division", unnamedDivision, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.s.ValidateSeason(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSeason() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	s := valid()
+	if err := s.ValidateSeason(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Rules, DefaultRules()) {
+		t.Errorf("rules = %+v, want the defaults", s.Rules)
+	}
+	if s.Plan.Legs != 2 || s.Plan.DurationMinutes != DefaultDurationMinutes || s.Divisions[1].Level != 2 {
+		t.Errorf("defaults not applied: %+v", s)
+	}
+}
@@ -0,0 +1,203 @@
+package league
+
+import (
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/league/model"
+)
+
+// maxDays bounds the search for match days when a season has no end date.
+const maxDays = 3 * 366
+
+// Pairings draws a round robin between members with the circle method.
+// Each round is a list of home, away pairs. With two legs the second half
+// of the season replays the first with home and away swapped. An odd
+// number of members gives one of them a rest every round.
+func Pairings(members []int64, legs int) [][][2]int64 {
+	ids := append([]int64{}, members...)
+	if len(ids)%2 == 1 {
+		ids = append(ids, 0)
+	}
+	n := len(ids)
+
+	var rounds [][][2]int64
+	for r := 0; r < n-1; r++ {
+		var round [][2]int64
+		for i := 0; i < n/2; i++ {
+			home, away := ids[i], ids[n-1-i]
+			// The fixed member alternates by round; everyone else is at
+			// home on odd tables and away on even ones as they turn, which
+			// keeps everyone close to as many home fixtures as away ones.
+			if (i == 0 && r%2 == 1) || (i > 0 && i%2 == 0) {
+				home, away = away, home
+			}
+			if home != 0 && away != 0 {
+				round = append(round, [2]int64{home, away})
+			}
+		}
+		rounds = append(rounds, round)
+		// Keep the first member in place and turn the rest one step.
+		last := ids[n-1]
+		copy(ids[2:], ids[1:n-1])
+		ids[1] = last
+	}
+
+	if legs == 2 {
+		first := rounds
+		for _, round := range first {
+			reversed := make([][2]int64, len(round))
+			for i, p := range round {
+				reversed[i] = [2]int64{p[1], p[0]}
+			}
+			rounds = append(rounds, reversed)
+		}
+	}
+	return rounds
+}
+
+// Schedule dates every fixture of a season. Round n of every division is
+// played on the n-th match day of the plan at the earliest, at the first
+// kickoff where the home venue has a free court; capacity is how many
+// fixtures each venue hosts at once, one if it is missing. A fixture that
+// does not fit slips to a later match day, and nobody plays twice on one
+// day or out of round order.
+func Schedule(divisions []model.Division, plan model.Plan, capacity map[int64]int) ([]model.Fixture, error) {
+	c := calendar{plan: plan}
+	venues := make(map[int64]*int64)
+	var rounds [][][][2]int64
+	for _, d := range divisions {
+		ids := make([]int64, len(d.Members))
+		for i, m := range d.Members {
+			ids[i] = m.ID
+			venues[m.ID] = plan.VenueID
+			if m.HomeVenueID != nil {
+				venues[m.ID] = m.HomeVenueID
+			}
+		}
+		rounds = append(rounds, Pairings(ids, plan.Legs))
+	}
+
+	type slot struct {
+		day, kickoff int
+		venue        int64
+	}
+	used := make(map[slot]int)
+	// take books the first kickoff of day with a free court at venue.
+	take := func(day int, venue *int64) int {
+		if venue == nil {
+			return 0
+		}
+		limit := capacity[*venue]
+		if limit < 1 {
+			limit = 1
+		}
+		for k := range plan.Kickoffs {
+			s := slot{day, k, *venue}
+			if used[s] < limit {
+				used[s]++
+				return k
+			}
+		}
+		return -1
+	}
+	lastDay := make(map[int64]int)
+	var fixtures []model.Fixture
+	for r := 0; ; r++ {
+		more := false
+		for di, division := range rounds {
+			if r >= len(division) {
+				continue
+			}
+			more = true
+			for _, p := range division[r] {
+				home, away := p[0], p[1]
+				venue := venues[home]
+				day := r
+				for _, id := range p {
+					if last, ok := lastDay[id]; ok && last >= day {
+						day = last + 1
+					}
+				}
+
+				var kickoff int
+				for {
+					if _, err := c.day(day); err != nil {
+						return nil, err
+					}
+					if kickoff = take(day, venue); kickoff >= 0 {
+						break
+					}
+					day++
+				}
+				lastDay[home], lastDay[away] = day, day
+
+				date, _ := c.day(day)
+				minutes := plan.Kickoffs[kickoff]
+				starts := time.Date(
+					date.Year(), date.Month(), date.Day(), minutes/60, minutes%60, 0, 0, date.Location(),
+				)
+				fixtures = append(fixtures, model.Fixture{
+					DivisionID: divisions[di].ID,
+					Round:      r + 1,
+					HomeID:     home,
+					AwayID:     away,
+					VenueID:    venue,
+					StartsAt:   starts,
+					EndsAt:     starts.Add(time.Duration(plan.DurationMinutes) * time.Minute),
+					Status:     model.FixtureScheduled,
+				})
+			}
+		}
+		if !more {
+			return fixtures, nil
+		}
+	}
+}
+
+// calendar lists the match days of a plan: its weekdays from the start
+// date on, leaving out blackouts.
+type calendar struct {
+	plan model.Plan
+	days []time.Time
+	next time.Time
+}
+
+// day returns the i-th match day, counting from 0.
+func (c *calendar) day(i int) (time.Time, error) {
+	if c.next.IsZero() {
+		s := c.plan.StartsAt
+		c.next = time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, s.Location())
+	}
+	for len(c.days) <= i {
+		d := c.next
+		if c.plan.EndsAt != nil && d.After(*c.plan.EndsAt) {
+			return time.Time{}, ErrNoDates
+		}
+		if d.Sub(c.plan.StartsAt) > maxDays*24*time.Hour {
+			return time.Time{}, ErrNoDates
+		}
+		c.next = d.AddDate(0, 0, 1)
+		if c.matchDay(d) {
+			c.days = append(c.days, d)
+		}
+	}
+	return c.days[i], nil
+}
+
+func (c *calendar) matchDay(d time.Time) bool {
+	weekday := false
+	for _, w := range c.plan.Weekdays {
+		if time.Weekday(w) == d.Weekday() {
+			weekday = true
+		}
+	}
+	if !weekday {
+		return false
+	}
+	for _, b := range c.plan.Blackouts {
+		if b.Year() == d.Year() && b.Month() == d.Month() && b.Day() == d.Day() {
+			return false
+		}
+	}
+	return true
+}
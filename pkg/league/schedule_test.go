@@ -0,0 +1,126 @@
+package league
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/league/model"
+)
+
+func ids(n int) []int64 {
+	out := make([]int64, n)
+	for i := range out {
+		out[i] = int64(i + 1)
+	}
+	return out
+}
+
+func TestPairings(t *testing.T) {
+	for _, n := range []int{2, 3, 4, 5, 6, 7, 8} {
+		rounds := Pairings(ids(n), 2)
+
+		meetings := make(map[[2]int64]int)
+		home := make(map[int64]int)
+		for _, round := range rounds {
+			playing := make(map[int64]bool)
+			for _, p := range round {
+				if playing[p[0]] || playing[p[1]] {
+					t.Errorf("%d members: %v plays twice in a round", n, p)
+				}
+				playing[p[0]], playing[p[1]] = true, true
+				meetings[p]++
+				home[p[0]]++
+			}
+		}
+		for _, a := range ids(n) {
+			for _, b := range ids(n) {
+				if a != b && meetings[[2]int64{a, b}] != 1 {
+					t.Errorf("%d members: %d hosted %d %d times, want once", n, a, b, meetings[[2]int64{a, b}])
+				}
+			}
+			if home[a] != n-1 {
+				t.Errorf("%d members: %d has %d home fixtures, want %d", n, a, home[a], n-1)
+			}
+		}
+
+		// In a single leg nobody hosts more than one fixture above half.
+		home = make(map[int64]int)
+		for _, round := range Pairings(ids(n), 1) {
+			for _, p := range round {
+				home[p[0]]++
+			}
+		}
+		for _, a := range ids(n) {
+			if diff := 2*home[a] - (n - 1); diff > 2 || diff < -2 {
+				t.Errorf("%d members: %d hosts %d of %d single-leg fixtures", n, a, home[a], n-1)
+			}
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	venue := int64(7)
+	start := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC) // a Monday
+	plan := model.Plan{
+		StartsAt:        start,
+		Weekdays:        []int{int(time.Saturday)},
+		Kickoffs:        []int{10 * 60, 12 * 60},
+		DurationMinutes: 90,
+		Legs:            1,
+		Blackouts:       []time.Time{time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC)},
+		VenueID:         &venue,
+	}
+	division := model.Division{ID: 1}
+	for _, id := range ids(6) {
+		division.Members = append(division.Members, model.Member{ID: id})
+	}
+
+	// Three fixtures a round but only two kickoffs at the one venue: the
+	// third slips to the next Saturday, and so does the next round of
+	// the members who played it.
+	fixtures, err := Schedule([]model.Division{division}, plan, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != 15 {
+		t.Fatalf("got %d fixtures, want 15", len(fixtures))
+	}
+	booked := make(map[time.Time]bool)
+	last := make(map[int64]time.Time)
+	for _, f := range fixtures {
+		if f.StartsAt.Weekday() != time.Saturday || f.StartsAt.Day() == 14 {
+			t.Errorf("fixture %+v is not on a match day", f)
+		}
+		if booked[f.StartsAt] {
+			t.Errorf("venue double booked at %v", f.StartsAt)
+		}
+		booked[f.StartsAt] = true
+		for _, id := range []int64{f.HomeID, f.AwayID} {
+			if !f.StartsAt.After(last[id].Add(24 * time.Hour)) {
+				t.Errorf("member %d plays on %v after playing on %v", id, f.StartsAt, last[id])
+			}
+			last[id] = f.StartsAt
+		}
+		if f.EndsAt.Sub(f.StartsAt) != 90*time.Minute {
+			t.Errorf("fixture lasts %v", f.EndsAt.Sub(f.StartsAt))
+		}
+	}
+	if first := fixtures[0].StartsAt; !first.Equal(time.Date(2024, 9, 7, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("first fixture at %v, want the first Saturday at 10:00", first)
+	}
+
+	// Two courts fit a whole round on each match day.
+	fixtures, err = Schedule([]model.Division{division}, plan, map[int64]int{venue: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fixtures[len(fixtures)-1].StartsAt; got.Month() != time.October || got.Day() != 12 {
+		t.Errorf("last fixture at %v, want the fifth match day", got)
+	}
+
+	end := time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC)
+	plan.EndsAt = &end
+	if _, err := Schedule([]model.Division{division}, plan, map[int64]int{venue: 2}); err != ErrNoDates {
+		t.Errorf("Schedule past the end date = %v, want %v", err, ErrNoDates)
+	}
+}
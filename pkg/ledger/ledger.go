@@ -0,0 +1,208 @@
+package ledger
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/ledger/model"
+)
+
+var (
+	ErrExpenseNotFound = errors.New("expense not found")
+	ErrShareNotFound   = errors.New("this player has no share of the expense")
+	ErrAlreadyPaid     = errors.New("this share has already been paid")
+	ErrNotParticipant  = errors.New("expenses can only be split between the game's participants")
+	ErrCannotMarkPaid  = errors.New("only the player who owes a share or the one who paid can mark it paid")
+)
+
+// Prepare fills in the shares of e before it is stored: an equal split is
+// divided between the listed players, or every participant when none are
+// listed, and the payer's own share is marked paid. Everyone involved must
+// have taken part in the game.
+func Prepare(e *model.Expense, participants []int64, now time.Time) error {
+	joined := make(map[int64]bool, len(participants))
+	for _, id := range participants {
+		joined[id] = true
+	}
+	if !joined[e.PaidBy] {
+		return ErrNotParticipant
+	}
+	if e.Split == model.SplitEqual {
+		users := participants
+		if len(e.Shares) > 0 {
+			users = make([]int64, len(e.Shares))
+			for i, s := range e.Shares {
+				users[i] = s.UserID
+			}
+		}
+		e.Shares = SplitEqually(e.AmountCents, users)
+	}
+	for i := range e.Shares {
+		if !joined[e.Shares[i].UserID] {
+			return ErrNotParticipant
+		}
+		if e.Shares[i].UserID == e.PaidBy {
+			e.Shares[i].PaidAt = &now
+		}
+	}
+	return nil
+}
+
+// SplitEqually divides total between users. Cents that do not divide
+// evenly go one each to the first users, so the shares always add up.
+func SplitEqually(total int64, users []int64) []model.Share {
+	if len(users) == 0 {
+		return nil
+	}
+	n := int64(len(users))
+	shares := make([]model.Share, len(users))
+	for i, id := range users {
+		shares[i] = model.Share{UserID: id, AmountCents: total / n}
+		if int64(i) < total%n {
+			shares[i].AmountCents++
+		}
+	}
+	return shares
+}
+
+// Charges returns the entries recording what each player owes the payer of
+// e. The payer's own share is not a debt and gets no entry.
+func Charges(e model.Expense) []model.Entry {
+	var entries []model.Entry
+	for _, s := range e.Shares {
+		if s.UserID == e.PaidBy || s.AmountCents == 0 {
+			continue
+		}
+		entries = append(entries, model.Entry{
+			ExpenseID:   e.ID,
+			GameID:      e.GameID,
+			DebtorID:    s.UserID,
+			CreditorID:  e.PaidBy,
+			AmountCents: s.AmountCents,
+			Kind:        model.EntryCharge,
+		})
+	}
+	return entries
+}
+
+// CheckMarkPaid validates actorID marking userID's share of e paid.
+func CheckMarkPaid(e model.Expense, userID, actorID int64) (*model.Share, error) {
+	if actorID != userID && actorID != e.PaidBy {
+		return nil, ErrCannotMarkPaid
+	}
+	for i := range e.Shares {
+		if e.Shares[i].UserID != userID {
+			continue
+		}
+		if e.Shares[i].PaidAt != nil {
+			return nil, ErrAlreadyPaid
+		}
+		return &e.Shares[i], nil
+	}
+	return nil, ErrShareNotFound
+}
+
+// Payment returns the entry that settles share s of e.
+func Payment(e model.Expense, s model.Share) model.Entry {
+	return model.Entry{
+		ExpenseID:   e.ID,
+		GameID:      e.GameID,
+		DebtorID:    s.UserID,
+		CreditorID:  e.PaidBy,
+		AmountCents: s.AmountCents,
+		Kind:        model.EntryPayment,
+	}
+}
+
+// Balances nets entries per user. A charge moves the amount from the
+// debtor to the creditor and a payment moves it back.
+func Balances(entries []model.Entry) map[int64]int64 {
+	balances := make(map[int64]int64)
+	for _, e := range entries {
+		amount := e.AmountCents
+		if e.Kind == model.EntryPayment {
+			amount = -amount
+		}
+		balances[e.CreditorID] += amount
+		balances[e.DebtorID] -= amount
+	}
+	return balances
+}
+
+// BalanceOf works out where userID stands with each player they share
+// entries with. Settled counterparties are left out.
+func BalanceOf(userID int64, entries []model.Entry) model.Balance {
+	nets := make(map[int64]int64)
+	for _, e := range entries {
+		amount := e.AmountCents
+		if e.Kind == model.EntryPayment {
+			amount = -amount
+		}
+		switch userID {
+		case e.CreditorID:
+			nets[e.DebtorID] += amount
+		case e.DebtorID:
+			nets[e.CreditorID] -= amount
+		}
+	}
+	b := model.Balance{UserID: userID, Counterparties: []model.Counterparty{}}
+	for id, net := range nets {
+		if net == 0 {
+			continue
+		}
+		b.NetCents += net
+		b.Counterparties = append(b.Counterparties, model.Counterparty{UserID: id, NetCents: net})
+	}
+	sort.Slice(b.Counterparties, func(i, j int) bool {
+		return b.Counterparties[i].UserID < b.Counterparties[j].UserID
+	})
+	return b
+}
+
+// Simplify returns transfers that settle balances, which must sum to zero.
+// Debtors pay creditors largest first, so every transfer settles at least
+// one of them and there is at most one fewer than the unsettled users.
+func Simplify(balances map[int64]int64) []model.Transfer {
+	type party struct {
+		id     int64
+		amount int64
+	}
+	var debtors, creditors []party
+	for id, b := range balances {
+		switch {
+		case b < 0:
+			debtors = append(debtors, party{id, -b})
+		case b > 0:
+			creditors = append(creditors, party{id, b})
+		}
+	}
+	byAmount := func(ps []party) func(i, j int) bool {
+		return func(i, j int) bool {
+			if ps[i].amount != ps[j].amount {
+				return ps[i].amount > ps[j].amount
+			}
+			return ps[i].id < ps[j].id
+		}
+	}
+	sort.Slice(debtors, byAmount(debtors))
+	sort.Slice(creditors, byAmount(creditors))
+
+	transfers := []model.Transfer{}
+	for i, j := 0, 0; i < len(debtors) && j < len(creditors); {
+		amount := debtors[i].amount
+		if creditors[j].amount < amount {
+			amount = creditors[j].amount
+		}
+		transfers = append(transfers, model.Transfer{FromID: debtors[i].id, ToID: creditors[j].id, AmountCents: amount})
+		debtors[i].amount -= amount
+		creditors[j].amount -= amount
+		if debtors[i].amount == 0 {
+			i++
+		}
+		if creditors[j].amount == 0 {
+			j++
+		}
+	}
+	return transfers
+}
@@ -0,0 +1,160 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/ledger/model"
+)
+
+var now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSplitEqually(t *testing.T) {
+	shares := SplitEqually(1000, []int64{1, 2, 3})
+	want := []int64{334, 333, 333}
+	var total int64
+	for i, s := range shares {
+		if s.AmountCents != want[i] {
+			t.Errorf("share %d = %d, want %d", i, s.AmountCents, want[i])
+		}
+		total += s.AmountCents
+	}
+	if total != 1000 {
+		t.Errorf("shares add up to %d", total)
+	}
+	if SplitEqually(1000, nil) != nil {
+		t.Error("split between nobody returned shares")
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	participants := []int64{1, 2, 3, 4}
+
+	e := model.Expense{PaidBy: 1, AmountCents: 1000, Split: model.SplitEqual}
+	if err := Prepare(&e, participants, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Shares) != 4 || e.Shares[0].AmountCents != 250 {
+		t.Errorf("shares = %+v, want 250 each for everyone", e.Shares)
+	}
+	if e.Shares[0].PaidAt == nil || e.Shares[1].PaidAt != nil {
+		t.Errorf("only the payer's share should be paid: %+v", e.Shares)
+	}
+
+	some := model.Expense{PaidBy: 1, AmountCents: 1000, Split: model.SplitEqual, Shares: []model.Share{{UserID: 2}, {UserID: 3}}}
+	if err := Prepare(&some, participants, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(some.Shares) != 2 || some.Shares[1].AmountCents != 500 {
+		t.Errorf("shares = %+v, want 500 each for 2 and 3", some.Shares)
+	}
+
+	outsider := model.Expense{PaidBy: 1, AmountCents: 1000, Split: model.SplitCustom, Shares: []model.Share{{UserID: 9, AmountCents: 1000}}}
+	if err := Prepare(&outsider, participants, now); err != ErrNotParticipant {
+		t.Errorf("share for an outsider = %v, want %v", err, ErrNotParticipant)
+	}
+	payer := model.Expense{PaidBy: 9, AmountCents: 1000, Split: model.SplitEqual}
+	if err := Prepare(&payer, participants, now); err != ErrNotParticipant {
+		t.Errorf("outsider paying = %v, want %v", err, ErrNotParticipant)
+	}
+}
+
+func TestCheckMarkPaid(t *testing.T) {
+	e := model.Expense{PaidBy: 1, Shares: []model.Share{{UserID: 1, PaidAt: &now}, {UserID: 2}, {UserID: 3}}}
+	tests := []struct {
+		name        string
+		user, actor int64
+		want        error
+	}{
+		{"Debtor", 2, 2, nil},
+		{"Payer", 3, 1, nil},
+		{"Someone else", 3, 2, ErrCannotMarkPaid},
+		{"Already paid", 1, 1, ErrAlreadyPaid},
+		{"No share", 4, 1, ErrShareNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CheckMarkPaid(e, tt.user, tt.actor); err != tt.want {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// ledger records two expenses: 1 paid 900 split three ways with 2 and 3,
+// then 2 paid 600 split with 4, and 3 has paid 1 back.
+func ledger() []model.Entry {
+	court := model.Expense{ID: 1, PaidBy: 1, Shares: SplitEqually(900, []int64{1, 2, 3})}
+	balls := model.Expense{ID: 2, PaidBy: 2, Shares: SplitEqually(600, []int64{2, 4})}
+	entries := append(Charges(court), Charges(balls)...)
+	return append(entries, Payment(court, court.Shares[2]))
+}
+
+func TestBalances(t *testing.T) {
+	balances := Balances(ledger())
+	want := map[int64]int64{1: 300, 2: 0, 3: 0, 4: -300}
+	var sum int64
+	for id, b := range balances {
+		if b != want[id] {
+			t.Errorf("balance of %d = %d, want %d", id, b, want[id])
+		}
+		sum += b
+	}
+	if sum != 0 {
+		t.Errorf("balances sum to %d", sum)
+	}
+
+	b := BalanceOf(2, ledger())
+	if b.NetCents != 0 || len(b.Counterparties) != 2 {
+		t.Fatalf("balance of 2 = %+v", b)
+	}
+	if c := b.Counterparties[0]; c.UserID != 1 || c.NetCents != -300 {
+		t.Errorf("2 against 1 = %+v, want -300", c)
+	}
+	if c := b.Counterparties[1]; c.UserID != 4 || c.NetCents != 300 {
+		t.Errorf("2 against 4 = %+v, want 300", c)
+	}
+	if b := BalanceOf(3, ledger()); len(b.Counterparties) != 0 {
+		t.Errorf("settled counterparties listed: %+v", b)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances map[int64]int64
+		max      int
+	}{
+		{"Chain", Balances(ledger()), 1},
+		{"Settled", map[int64]int64{1: 0, 2: 0}, 0},
+		{"Many to one", map[int64]int64{1: 900, 2: -300, 3: -300, 4: -300}, 3},
+		{"Mixed", map[int64]int64{1: 500, 2: 250, 3: -100, 4: -400, 5: -250}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfers := Simplify(tt.balances)
+			if len(transfers) > tt.max {
+				t.Errorf("%d transfers, want at most %d: %+v", len(transfers), tt.max, transfers)
+			}
+			left := make(map[int64]int64)
+			for id, b := range tt.balances {
+				left[id] = b
+			}
+			for _, tr := range transfers {
+				if tr.AmountCents <= 0 {
+					t.Errorf("transfer of %d", tr.AmountCents)
+				}
+				left[tr.FromID] += tr.AmountCents
+				left[tr.ToID] -= tr.AmountCents
+			}
+			for id, b := range left {
+				if b != 0 {
+					t.Errorf("%d is left at %d", id, b)
+				}
+			}
+		})
+	}
+	if got := Simplify(Balances(ledger())); got[0] != (model.Transfer{FromID: 4, ToID: 1, AmountCents: 300}) {
+		t.Errorf("chain = %+v, want 4 to pay 1 directly", got)
+	}
+}
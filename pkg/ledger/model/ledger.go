@@ -0,0 +1,128 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SplitEqual  = "equal"
+	SplitCustom = "custom"
+
+	// EntryCharge records that the debtor owes the creditor for a share of
+	// an expense; EntryPayment that the debtor paid it back.
+	EntryCharge  = "charge"
+	EntryPayment = "payment"
+
+	MaxDescriptionLength = 200
+	// MaxAmountCents keeps a mistyped amount from burying everyone in debt.
+	MaxAmountCents = 10_000_000
+)
+
+// Expense is money one player paid for a game that the others owe their
+// share of. Amounts are in cents of a single currency.
+type Expense struct {
+	ID          int64     `json:"id"`
+	GameID      int64     `json:"gameId"`
+	PaidBy      int64     `json:"paidBy"`
+	Description string    `json:"description"`
+	AmountCents int64     `json:"amountCents"`
+	Split       string    `json:"split"`
+	Shares      []Share   `json:"shares"`
+	CreatedBy   int64     `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Share is what one player owes of an expense. The payer's own share is
+// settled from the start.
+type Share struct {
+	UserID      int64      `json:"userId"`
+	AmountCents int64      `json:"amountCents"`
+	PaidAt      *time.Time `json:"paidAt,omitempty"`
+}
+
+// Entry is one line of the ledger. Every entry moves AmountCents between
+// exactly two users, so balances across the whole ledger always sum to zero.
+type Entry struct {
+	ID          int64     `json:"id"`
+	ExpenseID   int64     `json:"expenseId"`
+	GameID      int64     `json:"gameId"`
+	DebtorID    int64     `json:"debtorId"`
+	CreditorID  int64     `json:"creditorId"`
+	AmountCents int64     `json:"amountCents"`
+	Kind        string    `json:"kind"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Balance is where a user stands across every game. A positive NetCents is
+// owed to the user, a negative one is owed by them.
+type Balance struct {
+	UserID         int64          `json:"userId"`
+	NetCents       int64          `json:"netCents"`
+	Counterparties []Counterparty `json:"counterparties"`
+}
+
+// Counterparty is the net between the user and one other player, from the
+// user's side.
+type Counterparty struct {
+	UserID   int64 `json:"userId"`
+	NetCents int64 `json:"netCents"`
+}
+
+// Transfer is one payment that settles debts within a group.
+type Transfer struct {
+	FromID      int64 `json:"fromId"`
+	ToID        int64 `json:"toId"`
+	AmountCents int64 `json:"amountCents"`
+}
+
+func (e *Expense) ValidateExpense() error {
+	var errors []string
+
+	e.Description = strings.TrimSpace(e.Description)
+	if e.Split == "" {
+		e.Split = SplitEqual
+	}
+
+	if e.Description == "" {
+		errors = append(errors, "Description is required")
+	} else if len(e.Description) > MaxDescriptionLength {
+		errors = append(errors, fmt.Sprintf("Description must be at most %d characters", MaxDescriptionLength))
+	}
+	if e.AmountCents <= 0 || e.AmountCents > MaxAmountCents {
+		errors = append(errors, fmt.Sprintf("Amount must be between 1 and %d cents", MaxAmountCents))
+	}
+
+	seen := make(map[int64]bool, len(e.Shares))
+	var total int64
+	for _, s := range e.Shares {
+		if seen[s.UserID] {
+			errors = append(errors, fmt.Sprintf("User %d has more than one share", s.UserID))
+		}
+		seen[s.UserID] = true
+		if s.AmountCents < 0 {
+			errors = append(errors, "Shares cannot be negative")
+		}
+		total += s.AmountCents
+	}
+	switch e.Split {
+	case SplitEqual:
+		if total != 0 {
+			errors = append(errors, "Equal splits are computed, leave share amounts out")
+		}
+	case SplitCustom:
+		if len(e.Shares) == 0 {
+			errors = append(errors, "Custom splits need shares")
+		} else if total != e.AmountCents {
+			errors = append(errors, fmt.Sprintf("Shares add up to %d cents, not %d", total, e.AmountCents))
+		}
+	default:
+		errors = append(errors, "Split must be equal or custom")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
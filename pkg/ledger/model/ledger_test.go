@@ -0,0 +1,38 @@
+package model
+
+import "testing"
+
+func TestValidateExpense(t *testing.T) {
+	tests := []struct {
+		name    string
+		e       Expense
+		wantErr bool
+	}{
+		{"Equal among everyone", Expense{Description: "Court", AmountCents: 4000}, false},
+		{"Equal among some", Expense{Description: "Court", AmountCents: 4000, Shares: []Share{{UserID: 1}, {UserID: 2}}}, false},
+		{
+			"Custom",
+			Expense{Description: "Balls", AmountCents: 1000, Split: SplitCustom, Shares: []Share{{UserID: 1, AmountCents: 700}, {UserID: 2, AmountCents: 300}}},
+			false,
+		},
+		{
+			"Custom short",
+			Expense{Description: "Balls", AmountCents: 1000, Split: SplitCustom, Shares: []Share{{UserID: 1, AmountCents: 700}}},
+			true,
+		},
+		{"Custom without shares", Expense{Description: "Balls", AmountCents: 1000, Split: SplitCustom}, true},
+		{"Equal with amounts", Expense{Description: "Court", AmountCents: 1000, Shares: []Share{{UserID: 1, AmountCents: 1000}}}, true},
+		{"Twice the same user", Expense{Description: "Court", AmountCents: 1000, Shares: []Share{{UserID: 1}, {UserID: 1}}}, true},
+		{"No description", Expense{Description: "  ", AmountCents: 1000}, true},
+		{"Nothing spent", Expense{Description: "Court"}, true},
+		{"Too much", Expense{Description: "Court", AmountCents: MaxAmountCents + 1}, true},
+		{"Unknown split", Expense{Description: "Court", AmountCents: 1000, Split: "weighted"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.e.ValidateExpense(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateExpense() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	TypeNotification  = "notification"
+	TypeRoster        = "roster"
+	TypeGameCancelled = "game_cancelled"
+
+	subscriberBuffer = 64
+)
+
+// Event is one entry of a user's event log. IDs only ever grow, so a client
+// can resume from the last ID it saw.
+type Event struct {
+	ID   int64           `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// RosterUpdate is the data of a roster event.
+type RosterUpdate struct {
+	GameID int64   `json:"gameId"`
+	Joined []int64 `json:"joined,omitempty"`
+	Left   []int64 `json:"left,omitempty"`
+}
+
+// GameCancelled is the data of a game_cancelled event.
+type GameCancelled struct {
+	GameID int64 `json:"gameId"`
+}
+
+// Broker passes logged events to the streams each user has open.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[int64]map[chan Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[int64]map[chan Event]struct{})}
+}
+
+// Subscribe opens a stream of userID's events. The channel is closed by
+// unsubscribe, or by the broker if the subscriber falls behind, in which case
+// the client reconnects and resumes from the log.
+func (b *Broker) Subscribe(userID int64) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[chan Event]struct{})
+	}
+	b.subs[userID][ch] = struct{}{}
+	b.mu.Unlock()
+	return ch, func() { b.remove(userID, ch) }
+}
+
+func (b *Broker) Publish(userID int64, e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[userID] {
+		select {
+		case ch <- e:
+		default:
+			b.removeLocked(userID, ch)
+		}
+	}
+}
+
+func (b *Broker) remove(userID int64, ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(userID, ch)
+}
+
+func (b *Broker) removeLocked(userID int64, ch chan Event) {
+	subs := b.subs[userID]
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	if len(subs) == 0 {
+		delete(b.subs, userID)
+	}
+	close(ch)
+}
+
+// Write encodes e in the text/event-stream format.
+func Write(w io.Writer, e Event) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "id: %d\nevent: %s\n", e.ID, e.Type)
+	for _, line := range strings.Split(string(e.Data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ParseLastEventID reads a Last-Event-ID value, treating anything that is
+// not an event ID as a fresh start.
+func ParseLastEventID(v string) int64 {
+	id, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,96 @@
+package live
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Event
+		want string
+	}{
+		{
+			"Single line",
+			Event{ID: 7, Type: TypeRoster, Data: json.RawMessage(`{"gameId":1}`)},
+			"id: 7\nevent: roster\ndata: {\"gameId\":1}\n\n",
+		},
+		{
+			"Multi line",
+			Event{ID: 8, Type: TypeNotification, Data: json.RawMessage("{\n\"a\":1\n}")},
+			"id: 8\nevent: notification\ndata: {\ndata: \"a\":1\ndata: }\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.e); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLastEventID(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"42", 42},
+		{" 42 ", 42},
+		{"-1", 0},
+		{"abc", 0},
+	}
+	for _, tt := range tests {
+		if got := ParseLastEventID(tt.in); got != tt.want {
+			t.Errorf("ParseLastEventID(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+	a, unsubscribeA := b.Subscribe(1)
+	other, unsubscribeOther := b.Subscribe(2)
+	defer unsubscribeOther()
+
+	b.Publish(1, Event{ID: 1})
+	if e := <-a; e.ID != 1 {
+		t.Errorf("got event %d, want 1", e.ID)
+	}
+	select {
+	case e := <-other:
+		t.Errorf("user 2 got event %d meant for user 1", e.ID)
+	default:
+	}
+
+	unsubscribeA()
+	if _, ok := <-a; ok {
+		t.Error("channel still open after unsubscribe")
+	}
+	unsubscribeA()
+	b.Publish(1, Event{ID: 2})
+}
+
+func TestBrokerDropsSlowSubscribers(t *testing.T) {
+	b := NewBroker()
+	ch, unsubscribe := b.Subscribe(1)
+	defer unsubscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		b.Publish(1, Event{ID: int64(i + 1)})
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("received %d events before the drop, want %d", n, subscriberBuffer)
+	}
+}
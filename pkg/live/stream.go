@@ -0,0 +1,98 @@
+package live
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ReplayPage is how many logged events are loaded at a time on resume.
+const ReplayPage = 200
+
+var heartbeat = 25 * time.Second
+
+// Replayer loads up to limit logged events after the given ID, oldest first.
+type Replayer func(after int64, limit int) ([]Event, error)
+
+// Stream serves events as Server-Sent Events until the client goes away or
+// the subscription is closed. Events after the request's Last-Event-ID are
+// replayed from the log first; the caller must subscribe before calling so
+// that nothing published during the replay is missed.
+func Stream(w http.ResponseWriter, r *http.Request, events <-chan Event, replay Replayer) error {
+	rc := http.NewResponseController(w)
+	// The server's read and write timeouts are meant for ordinary requests
+	// and would otherwise cut the stream off.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("streaming unsupported: %w", err)
+	}
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("streaming unsupported: %w", err)
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, "retry: 3000\n\n"); err != nil {
+		return err
+	}
+
+	// EventSource sends Last-Event-ID itself on reconnect; the query
+	// parameter is for clients that cannot set headers.
+	last := ParseLastEventID(r.Header.Get("Last-Event-ID"))
+	if last == 0 {
+		last = ParseLastEventID(r.URL.Query().Get("lastEventId"))
+	}
+	// Events published during the replay arrive again on events. IDs are
+	// not published in order, so only those actually replayed are skipped.
+	replayed := make(map[int64]bool)
+	for last > 0 {
+		page, err := replay(last, ReplayPage)
+		if err != nil {
+			return err
+		}
+		for _, e := range page {
+			if err := Write(w, e); err != nil {
+				return err
+			}
+			replayed[e.ID] = true
+			last = e.ID
+		}
+		if len(page) < ReplayPage {
+			break
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if replayed[e.ID] {
+				delete(replayed, e.ID)
+				continue
+			}
+			if err := Write(w, e); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return err
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package live
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newStreamServer(t *testing.T, b *Broker, log []Event) *httptest.Server {
+	t.Helper()
+	replay := func(after int64, limit int) ([]Event, error) {
+		var out []Event
+		for _, e := range log {
+			if e.ID > after && len(out) < limit {
+				out = append(out, e)
+			}
+		}
+		return out, nil
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events, unsubscribe := b.Subscribe(1)
+		defer unsubscribe()
+		if err := Stream(w, r, events, replay); err != nil {
+			t.Errorf("Stream: %v", err)
+		}
+	}))
+	// Short timeouts stand in for the server's 30s WriteTimeout.
+	srv.Config.ReadTimeout = 100 * time.Millisecond
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// readIDs reads events off the stream until n have arrived.
+func readIDs(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+	var ids []string
+	for len(ids) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read after %v: %v", ids, err)
+		}
+		if strings.HasPrefix(line, "id: ") {
+			ids = append(ids, strings.TrimSpace(strings.TrimPrefix(line, "id: ")))
+		}
+	}
+	return ids
+}
+
+func open(t *testing.T, srv *httptest.Server, lastEventID string) *bufio.Reader {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+func TestStreamOutlivesServerTimeouts(t *testing.T) {
+	b := NewBroker()
+	srv := newStreamServer(t, b, nil)
+	r := open(t, srv, "")
+
+	time.Sleep(300 * time.Millisecond)
+	b.Publish(1, Event{ID: 5, Type: TypeRoster, Data: json.RawMessage(`{}`)})
+	if ids := readIDs(t, r, 1); ids[0] != "5" {
+		t.Errorf("got event %s, want 5", ids[0])
+	}
+}
+
+func TestStreamResumesFromLastEventID(t *testing.T) {
+	log := make([]Event, ReplayPage+5)
+	for i := range log {
+		log[i] = Event{ID: int64(i + 1), Type: TypeNotification, Data: json.RawMessage(`{}`)}
+	}
+	b := NewBroker()
+	srv := newStreamServer(t, b, log)
+	r := open(t, srv, "3")
+
+	ids := readIDs(t, r, len(log)-3)
+	if ids[0] != "4" || ids[len(ids)-1] != "205" {
+		t.Errorf("replayed %s..%s, want 4..205", ids[0], ids[len(ids)-1])
+	}
+
+	// A live copy of an event already replayed is skipped.
+	b.Publish(1, Event{ID: 200, Type: TypeNotification, Data: json.RawMessage(`{}`)})
+	b.Publish(1, Event{ID: 206, Type: TypeNotification, Data: json.RawMessage(`{}`)})
+	if ids := readIDs(t, r, 1); ids[0] != "206" {
+		t.Errorf("got live event %s, want 206", ids[0])
+	}
+}
+
+func TestStreamKeepsLateEvents(t *testing.T) {
+	log := []Event{
+		{ID: 1, Type: TypeNotification, Data: json.RawMessage(`{}`)},
+		{ID: 3, Type: TypeNotification, Data: json.RawMessage(`{}`)},
+	}
+	b := NewBroker()
+	srv := newStreamServer(t, b, log)
+	r := open(t, srv, "1")
+	if ids := readIDs(t, r, 1); ids[0] != "3" {
+		t.Fatalf("replayed %s, want 3", ids[0])
+	}
+
+	// Event 2 committed after event 3 and reaches the stream late.
+	b.Publish(1, Event{ID: 2, Type: TypeNotification, Data: json.RawMessage(`{}`)})
+	b.Publish(1, Event{ID: 3, Type: TypeNotification, Data: json.RawMessage(`{}`)})
+	b.Publish(1, Event{ID: 4, Type: TypeNotification, Data: json.RawMessage(`{}`)})
+	if ids := readIDs(t, r, 2); ids[0] != "2" || ids[1] != "4" {
+		t.Errorf("got live events %v, want [2 4]", ids)
+	}
+}
@@ -0,0 +1,76 @@
+package matchmaking
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	matches   []Match
+	expiresAt time.Time
+}
+
+// Cache keeps ranked results per user. An entry is dropped when its owner or
+// any user appearing in it changes their profile; the TTL bounds how long a
+// newly eligible candidate can be missing from an existing entry.
+type Cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[int]map[string]cacheEntry
+	now     func() time.Time
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		entries: make(map[int]map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (c *Cache) Get(userID int, key string) ([]Match, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userID][key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().After(entry.expiresAt) {
+		delete(c.entries[userID], key)
+		return nil, false
+	}
+	return entry.matches, true
+}
+
+func (c *Cache) Set(userID int, key string, matches []Match) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[userID] == nil {
+		c.entries[userID] = make(map[string]cacheEntry)
+	}
+	c.entries[userID][key] = cacheEntry{matches: matches, expiresAt: c.now().Add(c.ttl)}
+}
+
+// Invalidate drops the user's own results and every cached result that lists
+// them as a candidate.
+func (c *Cache) Invalidate(userID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, userID)
+	for owner, byKey := range c.entries {
+		for key, entry := range byKey {
+			for _, m := range entry.matches {
+				if m.UserID == userID {
+					delete(byKey, key)
+					break
+				}
+			}
+		}
+		if len(byKey) == 0 {
+			delete(c.entries, owner)
+		}
+	}
+}
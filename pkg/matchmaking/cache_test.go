@@ -0,0 +1,44 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheGetSet(t *testing.T) {
+	c := NewCache(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set(1, "tennis", []Match{{UserID: 2}})
+	if got, ok := c.Get(1, "tennis"); !ok || len(got) != 1 {
+		t.Fatalf("Get after Set = %v, %v", got, ok)
+	}
+	if _, ok := c.Get(1, "squash"); ok {
+		t.Error("Get returned an entry for an unknown key")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get(1, "tennis"); ok {
+		t.Error("Get returned an expired entry")
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c := NewCache(time.Minute)
+	c.Set(1, "a", []Match{{UserID: 2}, {UserID: 3}})
+	c.Set(4, "a", []Match{{UserID: 5}})
+	c.Set(2, "a", []Match{{UserID: 6}})
+
+	c.Invalidate(2)
+
+	if _, ok := c.Get(2, "a"); ok {
+		t.Error("Invalidate kept the user's own entry")
+	}
+	if _, ok := c.Get(1, "a"); ok {
+		t.Error("Invalidate kept an entry listing the user")
+	}
+	if _, ok := c.Get(4, "a"); !ok {
+		t.Error("Invalidate dropped an unrelated entry")
+	}
+}
@@ -0,0 +1,283 @@
+package matchmaking
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/rating"
+	"github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+const (
+	DefaultLimit       = 20
+	DefaultMaxDistance = 25.0
+	historySaturation  = 5
+	skillSpread        = float64(model.MaxSkill - model.MinSkill)
+	ratingSpread       = skillSpread * rating.SkillBand
+	FactorSkill        = "skill"
+	FactorDistance     = "distance"
+	FactorAvailability = "availability"
+	FactorHistory      = "history"
+)
+
+type Weights struct {
+	Skill        float64 `json:"skill"`
+	Distance     float64 `json:"distance"`
+	Availability float64 `json:"availability"`
+	History      float64 `json:"history"`
+}
+
+var DefaultWeights = Weights{Skill: 0.35, Distance: 0.25, Availability: 0.25, History: 0.15}
+
+type Options struct {
+	Sport         string
+	MaxDistanceKm float64
+	Limit         int
+	Weights       Weights
+}
+
+// Candidate is a potential partner together with everything the engine needs
+// to know about their relationship to the requesting user.
+type Candidate struct {
+	Profile       model.Profile
+	GamesTogether int
+	// Blocked is true when either user has blocked the other.
+	Blocked bool
+}
+
+type Factor struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+	Detail string  `json:"detail"`
+}
+
+type Match struct {
+	UserID   int      `json:"userId"`
+	Username string   `json:"username"`
+	Sport    string   `json:"sport"`
+	Score    float64  `json:"score"`
+	Factors  []Factor `json:"factors"`
+}
+
+// Key identifies a set of options for caching.
+func (o Options) Key() string {
+	return fmt.Sprintf("%s|%.1f|%d", model.NormalizeSport(o.Sport), o.MaxDistanceKm, o.Limit)
+}
+
+func (o Options) withDefaults() Options {
+	if o.Limit <= 0 {
+		o.Limit = DefaultLimit
+	}
+	if o.MaxDistanceKm <= 0 {
+		o.MaxDistanceKm = DefaultMaxDistance
+	}
+	if o.Weights == (Weights{}) {
+		o.Weights = DefaultWeights
+	}
+	o.Sport = model.NormalizeSport(o.Sport)
+	return o
+}
+
+// Rank scores every candidate against self and returns the best matches,
+// highest score first. Blocked candidates, candidates that share no sport with
+// self and candidates known to be further away than MaxDistanceKm are dropped.
+func Rank(self model.Profile, candidates []Candidate, opts Options) []Match {
+	opts = opts.withDefaults()
+
+	matches := make([]Match, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Blocked || c.Profile.UserID == self.UserID {
+			continue
+		}
+		m, ok := score(self, c, opts)
+		if !ok {
+			continue
+		}
+		matches = append(matches, m)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].UserID < matches[j].UserID
+	})
+
+	if len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+	return matches
+}
+
+func score(self model.Profile, c Candidate, opts Options) (Match, bool) {
+	sport, selfSkill, theirSkill, ok := pickSport(self, c.Profile, opts.Sport)
+	if !ok {
+		return Match{}, false
+	}
+
+	skill := skillFactor(selfSkill, theirSkill)
+	distance, ok := distanceFactor(self.Location, c.Profile.Location, opts.MaxDistanceKm)
+	if !ok {
+		return Match{}, false
+	}
+	availability := availabilityFactor(self.Availability, c.Profile.Availability)
+	history := historyFactor(c.GamesTogether)
+
+	skill.Weight = opts.Weights.Skill
+	distance.Weight = opts.Weights.Distance
+	availability.Weight = opts.Weights.Availability
+	history.Weight = opts.Weights.History
+
+	factors := []Factor{skill, distance, availability, history}
+	var total, weights float64
+	for _, f := range factors {
+		total += f.Score * f.Weight
+		weights += f.Weight
+	}
+	if weights > 0 {
+		total /= weights
+	}
+
+	return Match{
+		UserID:   c.Profile.UserID,
+		Username: c.Profile.Username,
+		Sport:    sport,
+		Score:    round(total),
+		Factors:  factors,
+	}, true
+}
+
+// pickSport chooses the sport to compare on: the requested one if given,
+// otherwise the shared sport where the two players are closest in skill.
+func pickSport(self, other model.Profile, sport string) (string, model.SportSkill, model.SportSkill, bool) {
+	if sport != "" {
+		a, okA := self.SportFor(sport)
+		b, okB := other.SportFor(sport)
+		return sport, a, b, okA && okB
+	}
+
+	best, bestGap := "", math.MaxInt
+	var bestA, bestB model.SportSkill
+	for _, s := range self.Sports {
+		b, ok := other.SportFor(s.Sport)
+		if !ok {
+			continue
+		}
+		gap := abs(level(s) - level(b))
+		if gap < bestGap || (gap == bestGap && s.Sport < best) {
+			best, bestGap, bestA, bestB = s.Sport, gap, s, b
+		}
+	}
+	return best, bestA, bestB, best != ""
+}
+
+// skillFactor compares ratings when both players have settled ones, and
+// skill levels otherwise.
+func skillFactor(a, b model.SportSkill) Factor {
+	if settled(a) && settled(b) {
+		gap := math.Abs(float64(a.Rating - b.Rating))
+		return Factor{
+			Name:   FactorSkill,
+			Score:  round(math.Max(0, 1-gap/ratingSpread)),
+			Detail: fmt.Sprintf("rating %d vs %d", a.Rating, b.Rating),
+		}
+	}
+	gap := abs(level(a) - level(b))
+	return Factor{
+		Name:   FactorSkill,
+		Score:  round(1 - float64(gap)/skillSpread),
+		Detail: fmt.Sprintf("skill %d vs %d", level(a), level(b)),
+	}
+}
+
+func settled(s model.SportSkill) bool {
+	return s.Rating != 0 && !s.Provisional
+}
+
+// level is the skill level a settled rating maps to, or the self-reported
+// one until the rating settles.
+func level(s model.SportSkill) int {
+	if !settled(s) {
+		return s.Skill
+	}
+	return rating.Rating{Rating: float64(s.Rating)}.Skill()
+}
+
+func distanceFactor(a, b *geo.Point, maxKm float64) (Factor, bool) {
+	if a == nil || b == nil {
+		return Factor{Name: FactorDistance, Detail: "location unknown"}, true
+	}
+	d := geo.Distance(*a, *b)
+	if d > maxKm {
+		return Factor{}, false
+	}
+	return Factor{
+		Name:   FactorDistance,
+		Score:  round(1 - d/maxKm),
+		Detail: fmt.Sprintf("%.1f km away", d),
+	}, true
+}
+
+func availabilityFactor(a, b []model.Availability) Factor {
+	overlap := OverlapMinutes(a, b)
+	shorter := math.Min(float64(totalMinutes(a)), float64(totalMinutes(b)))
+	if overlap == 0 || shorter == 0 {
+		return Factor{Name: FactorAvailability, Detail: "no shared availability"}
+	}
+	return Factor{
+		Name:   FactorAvailability,
+		Score:  round(math.Min(1, float64(overlap)/shorter)),
+		Detail: fmt.Sprintf("%d shared minutes per week", overlap),
+	}
+}
+
+func historyFactor(games int) Factor {
+	if games <= 0 {
+		return Factor{Name: FactorHistory, Detail: "never played together"}
+	}
+	return Factor{
+		Name:   FactorHistory,
+		Score:  round(math.Min(1, float64(games)/historySaturation)),
+		Detail: fmt.Sprintf("played together %d times", games),
+	}
+}
+
+// OverlapMinutes returns how many minutes per week both schedules share.
+func OverlapMinutes(a, b []model.Availability) int {
+	total := 0
+	for _, x := range a {
+		for _, y := range b {
+			if x.Weekday != y.Weekday {
+				continue
+			}
+			start := max(x.StartMinute, y.StartMinute)
+			end := min(x.EndMinute, y.EndMinute)
+			if end > start {
+				total += end - start
+			}
+		}
+	}
+	return total
+}
+
+func totalMinutes(windows []model.Availability) int {
+	total := 0
+	for _, w := range windows {
+		total += w.EndMinute - w.StartMinute
+	}
+	return total
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func round(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
@@ -0,0 +1,153 @@
+package matchmaking
+
+import (
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+var home = &geo.Point{Lat: 6.5244, Lng: 3.3792}
+
+func profile(id int, skill int, loc *geo.Point, avail ...model.Availability) model.Profile {
+	return model.Profile{
+		UserID:       id,
+		Location:     loc,
+		Sports:       []model.SportSkill{{Sport: "tennis", Skill: skill}},
+		Availability: avail,
+	}
+}
+
+func TestRankOrdersBySkillProximity(t *testing.T) {
+	self := profile(1, 5, home)
+	candidates := []Candidate{
+		{Profile: profile(2, 9, home)},
+		{Profile: profile(3, 5, home)},
+		{Profile: profile(4, 6, home)},
+	}
+
+	got := Rank(self, candidates, Options{Sport: "tennis"})
+	want := []int{3, 4, 2}
+	if len(got) != len(want) {
+		t.Fatalf("Rank returned %d matches, want %d", len(got), len(want))
+	}
+	for i, id := range want {
+		if got[i].UserID != id {
+			t.Errorf("position %d: got user %d, want %d", i, got[i].UserID, id)
+		}
+	}
+}
+
+func TestRankExcludesBlockedFarAndUnrelated(t *testing.T) {
+	self := profile(1, 5, home)
+	far := &geo.Point{Lat: 9.0765, Lng: 7.3986}
+	footballer := model.Profile{UserID: 5, Sports: []model.SportSkill{{Sport: "football", Skill: 5}}}
+
+	candidates := []Candidate{
+		{Profile: profile(2, 5, home), Blocked: true},
+		{Profile: profile(3, 5, far)},
+		{Profile: footballer},
+		{Profile: profile(1, 5, home)},
+		{Profile: profile(4, 5, nil)},
+	}
+
+	got := Rank(self, candidates, Options{})
+	if len(got) != 1 || got[0].UserID != 4 {
+		t.Fatalf("Rank = %+v, want only user 4", got)
+	}
+	if got[0].Factors[1].Detail != "location unknown" {
+		t.Errorf("distance detail = %q, want location unknown", got[0].Factors[1].Detail)
+	}
+}
+
+func TestRankRewardsAvailabilityAndHistory(t *testing.T) {
+	evening := model.Availability{Weekday: 2, StartMinute: 18 * 60, EndMinute: 20 * 60}
+	morning := model.Availability{Weekday: 2, StartMinute: 8 * 60, EndMinute: 10 * 60}
+	self := profile(1, 5, home, evening)
+
+	candidates := []Candidate{
+		{Profile: profile(2, 5, home, morning)},
+		{Profile: profile(3, 5, home, evening)},
+		{Profile: profile(4, 5, home, morning), GamesTogether: 3},
+	}
+
+	got := Rank(self, candidates, Options{Sport: "tennis"})
+	if got[0].UserID != 3 || got[1].UserID != 4 || got[2].UserID != 2 {
+		t.Errorf("unexpected order: %d, %d, %d", got[0].UserID, got[1].UserID, got[2].UserID)
+	}
+	for _, f := range got[0].Factors {
+		if f.Name == FactorAvailability && f.Score != 1 {
+			t.Errorf("availability score = %v, want 1 for identical windows", f.Score)
+		}
+	}
+}
+
+func TestRankPicksClosestSharedSport(t *testing.T) {
+	self := model.Profile{UserID: 1, Sports: []model.SportSkill{
+		{Sport: "tennis", Skill: 2},
+		{Sport: "squash", Skill: 7},
+	}}
+	other := model.Profile{UserID: 2, Sports: []model.SportSkill{
+		{Sport: "tennis", Skill: 9},
+		{Sport: "squash", Skill: 6},
+	}}
+
+	got := Rank(self, []Candidate{{Profile: other}}, Options{})
+	if len(got) != 1 || got[0].Sport != "squash" {
+		t.Fatalf("Rank = %+v, want a squash match", got)
+	}
+}
+
+func TestRankPrefersSettledRatings(t *testing.T) {
+	rated := func(id, skill, r int, provisional bool) model.Profile {
+		p := profile(id, skill, home)
+		p.Sports[0].Rating = r
+		p.Sports[0].Provisional = provisional
+		return p
+	}
+	// Everyone claims the same skill, but results tell them apart.
+	self := rated(1, 5, 1600, false)
+	candidates := []Candidate{
+		{Profile: rated(2, 5, 2000, false)},
+		{Profile: rated(3, 5, 1650, false)},
+		{Profile: rated(4, 5, 2000, true)},
+	}
+
+	got := Rank(self, candidates, Options{Sport: "tennis"})
+	want := []int{3, 4, 2}
+	for i, id := range want {
+		if got[i].UserID != id {
+			t.Fatalf("position %d: got user %d, want %d", i, got[i].UserID, id)
+		}
+	}
+	if d := got[0].Factors[0].Detail; d != "rating 1600 vs 1650" {
+		t.Errorf("skill detail = %q, want rating comparison", d)
+	}
+	// A provisional rating falls back to skill levels, with self's settled
+	// rating mapped onto the scale.
+	if d := got[1].Factors[0].Detail; d != "skill 6 vs 5" {
+		t.Errorf("skill detail = %q, want skill comparison", d)
+	}
+}
+
+func TestRankLimit(t *testing.T) {
+	self := profile(1, 5, home)
+	var candidates []Candidate
+	for id := 2; id < 10; id++ {
+		candidates = append(candidates, Candidate{Profile: profile(id, 5, home)})
+	}
+	if got := Rank(self, candidates, Options{Limit: 3}); len(got) != 3 {
+		t.Errorf("Rank returned %d matches, want 3", len(got))
+	}
+}
+
+func TestOverlapMinutes(t *testing.T) {
+	a := []model.Availability{{Weekday: 1, StartMinute: 60, EndMinute: 180}}
+	b := []model.Availability{
+		{Weekday: 1, StartMinute: 120, EndMinute: 240},
+		{Weekday: 2, StartMinute: 60, EndMinute: 180},
+	}
+	if got := OverlapMinutes(a, b); got != 60 {
+		t.Errorf("OverlapMinutes = %d, want 60", got)
+	}
+}
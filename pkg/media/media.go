@@ -0,0 +1,129 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/media/model"
+)
+
+var (
+	ErrTooLarge        = fmt.Errorf("images cannot be larger than %d MB", MaxUploadBytes>>20)
+	ErrUnsupportedType = errors.New("only JPEG, PNG and GIF images are supported")
+	ErrInvalidImage    = errors.New("the file is not a valid image")
+	ErrTooManyPixels   = fmt.Errorf("images cannot have more than %d megapixels", MaxPixels/1_000_000)
+	ErrImageNotFound   = errors.New("image not found")
+	ErrTooManyPhotos   = fmt.Errorf("a venue can have at most %d photos", model.MaxVenuePhotos)
+)
+
+const (
+	MaxUploadBytes = 8 << 20
+	// MaxPixels is checked before decoding, so that a small file claiming
+	// huge dimensions cannot exhaust memory.
+	MaxPixels = 40_000_000
+
+	jpegQuality = 85
+)
+
+// Rendition is an encoded variant of an upload, ready to store.
+type Rendition struct {
+	Name        string
+	ContentType string
+	Ext         string
+	Data        []byte
+}
+
+// Sniff returns the content type of an upload from its first bytes,
+// ignoring whatever type the client claimed.
+func Sniff(data []byte) (string, error) {
+	ctype := http.DetectContentType(data)
+	switch ctype {
+	case "image/jpeg", "image/png", "image/gif":
+		return ctype, nil
+	}
+	return "", ErrUnsupportedType
+}
+
+// Decode reads an upload into an upright image. JPEGs are rotated by their
+// EXIF orientation, as that metadata is lost when the image is re-encoded.
+func Decode(data []byte) (*image.RGBA, string, error) {
+	if len(data) > MaxUploadBytes {
+		return nil, "", ErrTooLarge
+	}
+	ctype, err := Sniff(data)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, "", ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", ErrTooManyPixels
+	}
+
+	var src image.Image
+	switch ctype {
+	case "image/jpeg":
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		src, err = png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		src, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, "", ErrInvalidImage
+	}
+	img := toRGBA(src)
+	if ctype == "image/jpeg" {
+		img = orient(img, Orientation(data))
+	}
+	return img, ctype, nil
+}
+
+// Process decodes an upload and encodes it at each variant's size. Only
+// pixels are carried over, so EXIF data such as where a photo was taken is
+// dropped. PNGs and GIFs become PNGs to keep their transparency; everything
+// else becomes a JPEG.
+func Process(data []byte, variants []model.Variant) ([]Rendition, error) {
+	img, ctype, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Rendition, 0, len(variants))
+	for _, v := range variants {
+		r := Rendition{Name: v.Name, ContentType: "image/jpeg", Ext: ".jpg"}
+		resized := Resize(img, v.Size, v.Square)
+
+		var buf bytes.Buffer
+		if ctype == "image/jpeg" {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			r.ContentType, r.Ext = "image/png", ".png"
+			err = png.Encode(&buf, resized)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s variant: %w", v.Name, err)
+		}
+		r.Data = buf.Bytes()
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,233 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/media/model"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withExif inserts an EXIF segment holding the orientation and a GPS tag
+// right after the JPEG's start of image marker.
+func withExif(jpg []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	for _, tag := range []uint16{orientationTag, 0x8825} {
+		tiff = binary.LittleEndian.AppendUint16(tiff, tag)
+		tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+		tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+		tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+		tiff = append(tiff, 0, 0)
+	}
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, []byte("GPS 51.5007N 0.1246W")...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+		err  error
+	}{
+		{"PNG", encodePNG(t, testImage(2, 2)), "image/png", nil},
+		{"JPEG", encodeJPEG(t, testImage(2, 2)), "image/jpeg", nil},
+		{"GIF", []byte("GIF89a\x01\x00\x01\x00"), "image/gif", nil},
+		{"Text", []byte("hello"), "", ErrUnsupportedType},
+		{"HTML posing as an image", []byte("<html><script>alert(1)</script>"), "", ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Sniff(tt.data)
+			if got != tt.want || err != tt.err {
+				t.Errorf("Sniff = %q, %v, want %q, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"Truncated PNG", encodePNG(t, testImage(8, 8))[:40], ErrInvalidImage},
+		{"Too large", append(encodePNG(t, testImage(1, 1)), make([]byte, MaxUploadBytes)...), ErrTooLarge},
+		{"Not an image", []byte("%PDF-1.4"), ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decode(tt.data); err != tt.want {
+				t.Errorf("Decode error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h, size   int
+		square       bool
+		wantW, wantH int
+	}{
+		{"Landscape", 400, 200, 100, false, 100, 50},
+		{"Portrait", 200, 400, 100, false, 50, 100},
+		{"Never scaled up", 40, 30, 100, false, 40, 30},
+		{"Square crop", 400, 200, 100, true, 100, 100},
+		{"Square crop of a small image", 60, 30, 100, true, 30, 30},
+		{"Thin strip keeps a pixel", 1000, 2, 100, false, 100, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Resize(testImage(tt.w, tt.h), tt.size, tt.square).Bounds()
+			if b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+				t.Errorf("Resize = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestResizeAverages(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		img.Set(0, y, color.RGBA{0, 0, 0, 255})
+		img.Set(1, y, color.RGBA{200, 200, 200, 255})
+		img.Set(2, y, color.RGBA{100, 0, 0, 255})
+		img.Set(3, y, color.RGBA{100, 0, 0, 255})
+	}
+	got := Resize(img, 2, false)
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{100, 100, 100, 255}) {
+		t.Errorf("left pixel = %v, want the average of black and light grey", c)
+	}
+	if c := got.RGBAAt(1, 0); c != (color.RGBA{100, 0, 0, 255}) {
+		t.Errorf("right pixel = %v, want dark red", c)
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	jpg := encodeJPEG(t, testImage(4, 2))
+	for _, o := range []uint16{1, 3, 6, 8} {
+		if got := Orientation(withExif(jpg, o)); got != int(o) {
+			t.Errorf("Orientation = %d, want %d", got, o)
+		}
+	}
+	if got := Orientation(jpg); got != 1 {
+		t.Errorf("Orientation without EXIF = %d, want 1", got)
+	}
+	if got := Orientation([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF}); got != 1 {
+		t.Errorf("Orientation of a truncated file = %d, want 1", got)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 2x1 image with a red pixel on the left.
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red := color.RGBA{255, 0, 0, 255}
+	img.Set(0, 0, red)
+
+	tests := []struct {
+		o            int
+		wantW, wantH int
+		redX, redY   int
+	}{
+		{1, 2, 1, 0, 0},
+		{2, 2, 1, 1, 0},
+		{3, 2, 1, 1, 0},
+		{6, 1, 2, 0, 0},
+		{8, 1, 2, 0, 1},
+	}
+	for _, tt := range tests {
+		got := orient(img, tt.o)
+		b := got.Bounds()
+		if b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Errorf("orient(%d) = %dx%d, want %dx%d", tt.o, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+			continue
+		}
+		if got.RGBAAt(tt.redX, tt.redY) != red {
+			t.Errorf("orient(%d) moved the red pixel away from (%d, %d)", tt.o, tt.redX, tt.redY)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	upload := withExif(encodeJPEG(t, testImage(600, 300)), 6)
+
+	renditions, err := Process(upload, model.PhotoVariants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(renditions) != len(model.PhotoVariants) {
+		t.Fatalf("got %d renditions, want %d", len(renditions), len(model.PhotoVariants))
+	}
+	thumb := renditions[0]
+	if thumb.Name != "thumb" || thumb.ContentType != "image/jpeg" || thumb.Ext != ".jpg" {
+		t.Errorf("thumb = %s %s %s", thumb.Name, thumb.ContentType, thumb.Ext)
+	}
+	for _, r := range renditions {
+		if bytes.Contains(r.Data, []byte("Exif")) || bytes.Contains(r.Data, []byte("GPS")) {
+			t.Errorf("%s variant kept the EXIF data", r.Name)
+		}
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sideways photo is turned upright before resizing.
+	if cfg.Width != 160 || cfg.Height != 320 {
+		t.Errorf("thumb = %dx%d, want 160x320", cfg.Width, cfg.Height)
+	}
+
+	avatars, err := Process(encodePNG(t, testImage(300, 200)), model.AvatarVariants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avatars[0].ContentType != "image/png" {
+		t.Errorf("PNG avatar stored as %s", avatars[0].ContentType)
+	}
+	cfg, err = png.DecodeConfig(bytes.NewReader(avatars[0].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 64 || cfg.Height != 64 {
+		t.Errorf("avatar thumb = %dx%d, want 64x64", cfg.Width, cfg.Height)
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	KindAvatar = "avatar"
+	KindTeam   = "team"
+	KindVenue  = "venue"
+
+	// MaxVenuePhotos caps how many photos a venue can have.
+	MaxVenuePhotos = 10
+)
+
+// Variant is one of the standard sizes an upload is stored in. Size is the
+// longest side in pixels; square variants are cropped to the centre first.
+type Variant struct {
+	Name   string
+	Size   int
+	Square bool
+}
+
+var (
+	AvatarVariants = []Variant{
+		{Name: "thumb", Size: 64, Square: true},
+		{Name: "small", Size: 256, Square: true},
+		{Name: "full", Size: 1024, Square: true},
+	}
+	PhotoVariants = []Variant{
+		{Name: "thumb", Size: 320},
+		{Name: "medium", Size: 1024},
+		{Name: "full", Size: 2048},
+	}
+)
+
+// VariantsFor returns the sizes images of kind are stored in.
+func VariantsFor(kind string) []Variant {
+	if kind == KindAvatar {
+		return AvatarVariants
+	}
+	return PhotoVariants
+}
+
+// Image is an uploaded picture of a user, team or venue. Only its resized
+// variants are kept, so nothing from the original file is ever served.
+type Image struct {
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	SubjectID int64  `json:"subjectId"`
+	// Key is the storage prefix the variants live under, and Ext their file
+	// extension.
+	Key        string            `json:"-"`
+	Ext        string            `json:"-"`
+	URLs       map[string]string `json:"urls"`
+	UploadedBy int64             `json:"uploadedBy"`
+	CreatedAt  time.Time         `json:"createdAt"`
+}
+
+// VariantKey is where the variant called name is stored.
+func (i Image) VariantKey(name string) string {
+	return i.Key + "/" + name + i.Ext
+}
@@ -0,0 +1,62 @@
+package media
+
+import "image"
+
+// Resize scales img so its longest side is at most size, keeping its aspect
+// ratio. Square resizes crop the centre of the image first. Images are never
+// scaled up.
+func Resize(img *image.RGBA, size int, square bool) *image.RGBA {
+	b := img.Bounds()
+	if square {
+		side := min(b.Dx(), b.Dy())
+		x := b.Min.X + (b.Dx()-side)/2
+		y := b.Min.Y + (b.Dy()-side)/2
+		img = img.SubImage(image.Rect(x, y, x+side, y+side)).(*image.RGBA)
+		b = img.Bounds()
+	}
+
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return toRGBA(img)
+	}
+	if w >= h {
+		w, h = size, max(1, h*size/w)
+	} else {
+		w, h = max(1, w*size/h), size
+	}
+	return scaleDown(img, w, h)
+}
+
+// scaleDown averages the source pixels each destination pixel covers. It
+// works on premultiplied colours, so transparent pixels don't darken their
+// neighbours.
+func scaleDown(src *image.RGBA, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(b.Min.X+x0, b.Min.Y+sy)
+				for sx := x0; sx < x1; sx++ {
+					sum[0] += int(src.Pix[i])
+					sum[1] += int(src.Pix[i+1])
+					sum[2] += int(src.Pix[i+2])
+					sum[3] += int(src.Pix[i+3])
+					i += 4
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			j := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[j+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps uploaded files. Keys are slash-separated paths such as
+// "avatar/12/3f9a/thumb.jpg"; URL returns where clients can fetch them.
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// ValidKey reports whether key is a relative path without empty, "." or
+// ".." segments, so it cannot escape the storage root.
+func ValidKey(key string) bool {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TargetUser = "user"
+	TargetGame = "game"
+
+	ReportOpen      = "open"
+	ReportReviewing = "reviewing"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+
+	ActionWarn    = "warn"
+	ActionSuspend = "suspend"
+	ActionBan     = "ban"
+	ActionLift    = "lift"
+
+	MaxDetailsLength = 2000
+	MaxSuspendDays   = 365
+)
+
+// Categories are the reasons a user can pick when reporting.
+var Categories = []string{
+	"harassment",
+	"hate",
+	"spam",
+	"cheating",
+	"no_show",
+	"unsafe_behaviour",
+	"inappropriate_content",
+	"other",
+}
+
+type Block struct {
+	UserID    int64     `json:"userId"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Report struct {
+	ID         int64     `json:"id"`
+	ReporterID int64     `json:"reporterId"`
+	TargetType string    `json:"targetType"`
+	TargetID   int64     `json:"targetId"`
+	Category   string    `json:"category"`
+	Details    string    `json:"details"`
+	Status     string    `json:"status"`
+	AssignedTo *int64    `json:"assignedTo,omitempty"`
+	Resolution string    `json:"resolution,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// TargetReports counts every report against the same target, to help
+	// moderators spot repeat offenders in the queue.
+	TargetReports int `json:"targetReports"`
+}
+
+// Action is a moderator's sanction against a user, or the lifting of one.
+type Action struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"userId"`
+	ModeratorID int64      `json:"moderatorId"`
+	Kind        string     `json:"kind"`
+	Reason      string     `json:"reason"`
+	Days        int        `json:"days,omitempty"`
+	ReportID    *int64     `json:"reportId,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// AuditEntry records one moderator decision.
+type AuditEntry struct {
+	ID          int64     `json:"id"`
+	ModeratorID int64     `json:"moderatorId"`
+	Decision    string    `json:"decision"`
+	ReportID    *int64    `json:"reportId,omitempty"`
+	UserID      *int64    `json:"userId,omitempty"`
+	Note        string    `json:"note"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (r *Report) ValidateReport() error {
+	var errors []string
+
+	r.Category = strings.ToLower(strings.TrimSpace(r.Category))
+	r.Details = strings.TrimSpace(r.Details)
+
+	if r.TargetType != TargetUser && r.TargetType != TargetGame {
+		errors = append(errors, "Target type must be user or game")
+	}
+	if r.TargetID <= 0 {
+		errors = append(errors, "Target is required")
+	}
+	if !IsCategory(r.Category) {
+		errors = append(errors, fmt.Sprintf("Category must be one of %s", strings.Join(Categories, ", ")))
+	}
+	if r.Category == "other" && r.Details == "" {
+		errors = append(errors, "Please describe the problem")
+	}
+	if len(r.Details) > MaxDetailsLength {
+		errors = append(errors, fmt.Sprintf("Details must be at most %d characters", MaxDetailsLength))
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+func (a *Action) ValidateAction() error {
+	var errors []string
+
+	a.Reason = strings.TrimSpace(a.Reason)
+	switch a.Kind {
+	case ActionWarn, ActionBan, ActionLift:
+		if a.Days != 0 {
+			errors = append(errors, "Only suspensions take a number of days")
+		}
+	case ActionSuspend:
+		if a.Days < 1 || a.Days > MaxSuspendDays {
+			errors = append(errors, fmt.Sprintf("Suspensions must last between 1 and %d days", MaxSuspendDays))
+		}
+	default:
+		errors = append(errors, "Action must be warn, suspend, ban or lift")
+	}
+	if a.Reason == "" {
+		errors = append(errors, "Reason is required")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+func IsCategory(c string) bool {
+	for _, category := range Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       Report
+		wantErr bool
+	}{
+		{"Valid user report", Report{TargetType: TargetUser, TargetID: 2, Category: "Harassment"}, false},
+		{"Valid game report", Report{TargetType: TargetGame, TargetID: 9, Category: "spam"}, false},
+		{"Unknown target type", Report{TargetType: "venue", TargetID: 2, Category: "spam"}, true},
+		{"Missing target", Report{TargetType: TargetUser, Category: "spam"}, true},
+		{"Unknown category", Report{TargetType: TargetUser, TargetID: 2, Category: "rude"}, true},
+		{"Other without details", Report{TargetType: TargetUser, TargetID: 2, Category: "other"}, true},
+		{"Other with details", Report{TargetType: TargetUser, TargetID: 2, Category: "other", Details: "x"}, false},
+		{
+			"Details too long",
+			Report{TargetType: TargetUser, TargetID: 2, Category: "spam", Details: strings.Repeat("a", MaxDetailsLength+1)},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.r.ValidateReport()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       Action
+		wantErr bool
+	}{
+		{"Warn", Action{Kind: ActionWarn, Reason: "abusive chat"}, false},
+		{"Suspend", Action{Kind: ActionSuspend, Days: 7, Reason: "no-shows"}, false},
+		{"Suspend without days", Action{Kind: ActionSuspend, Reason: "no-shows"}, true},
+		{"Suspend too long", Action{Kind: ActionSuspend, Days: MaxSuspendDays + 1, Reason: "x"}, true},
+		{"Ban with days", Action{Kind: ActionBan, Days: 3, Reason: "x"}, true},
+		{"Missing reason", Action{Kind: ActionBan, Reason: " "}, true},
+		{"Unknown kind", Action{Kind: "mute", Reason: "x"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.a.ValidateAction()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/moderation/model"
+)
+
+var (
+	ErrBlockSelf       = errors.New("you cannot block yourself")
+	ErrNotBlocked      = errors.New("that user is not blocked")
+	ErrBlocked         = errors.New("not available")
+	ErrReportSelf      = errors.New("you cannot report yourself")
+	ErrDuplicateReport = errors.New("you already have an open report about this")
+	ErrReportNotFound  = errors.New("report not found")
+	ErrTargetNotFound  = errors.New("the reported user or game does not exist")
+	ErrReportClosed    = errors.New("report has already been closed")
+	ErrReportAssigned  = errors.New("report is being reviewed by another moderator")
+	ErrBadResolution   = errors.New("reports can only be resolved or dismissed")
+	ErrBanned          = errors.New("this account has been banned")
+	ErrModerateSelf    = errors.New("moderators cannot act on their own account")
+	ErrNothingToLift   = errors.New("user has no active suspension or ban")
+	ErrSuspended       = errors.New("this account is suspended")
+	ErrActionTarget    = errors.New("only reports about users can carry an action")
+)
+
+// Standing is a user's current sanctions, derived from their action history.
+type Standing struct {
+	Banned         bool       `json:"banned"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
+	Warnings       int        `json:"warnings"`
+}
+
+// StandingAt replays actions, oldest first, to find the sanctions in force at
+// now. A lift clears any ban or suspension that came before it.
+func StandingAt(actions []model.Action, now time.Time) Standing {
+	var s Standing
+	for _, a := range actions {
+		switch a.Kind {
+		case model.ActionWarn:
+			s.Warnings++
+		case model.ActionBan:
+			s.Banned = true
+		case model.ActionSuspend:
+			if a.ExpiresAt != nil && a.ExpiresAt.After(now) &&
+				(s.SuspendedUntil == nil || a.ExpiresAt.After(*s.SuspendedUntil)) {
+				until := *a.ExpiresAt
+				s.SuspendedUntil = &until
+			}
+		case model.ActionLift:
+			s.Banned = false
+			s.SuspendedUntil = nil
+		}
+	}
+	return s
+}
+
+// CheckLogin returns why a user in standing s may not sign in, if anything.
+func CheckLogin(s Standing) error {
+	if s.Banned {
+		return ErrBanned
+	}
+	if s.SuspendedUntil != nil {
+		return fmt.Errorf("%w until %s", ErrSuspended, s.SuspendedUntil.UTC().Format(time.RFC1123))
+	}
+	return nil
+}
+
+// Prepare fills in the derived fields of a moderator action and checks it
+// makes sense against the user's current standing.
+func Prepare(a *model.Action, s Standing, now time.Time) error {
+	if a.ModeratorID == a.UserID {
+		return ErrModerateSelf
+	}
+	if a.Kind == model.ActionLift && !s.Banned && s.SuspendedUntil == nil {
+		return ErrNothingToLift
+	}
+	a.ExpiresAt = nil
+	if a.Kind == model.ActionSuspend {
+		until := now.AddDate(0, 0, a.Days)
+		a.ExpiresAt = &until
+	}
+	return nil
+}
+
+// Claim assigns an open report to moderatorID for review. A moderator may
+// re-claim a report they already hold.
+func Claim(r *model.Report, moderatorID int64) error {
+	switch r.Status {
+	case model.ReportOpen:
+	case model.ReportReviewing:
+		if r.AssignedTo != nil && *r.AssignedTo != moderatorID {
+			return ErrReportAssigned
+		}
+	default:
+		return ErrReportClosed
+	}
+	r.Status = model.ReportReviewing
+	r.AssignedTo = &moderatorID
+	return nil
+}
+
+// Resolve closes a report as resolved or dismissed. Reports under review by
+// another moderator cannot be closed from under them.
+func Resolve(r *model.Report, moderatorID int64, status, resolution string) error {
+	if status != model.ReportResolved && status != model.ReportDismissed {
+		return ErrBadResolution
+	}
+	if err := Claim(r, moderatorID); err != nil {
+		return err
+	}
+	r.Status = status
+	r.Resolution = resolution
+	return nil
+}
@@ -0,0 +1,126 @@
+package moderation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/moderation/model"
+)
+
+var now = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func at(d time.Duration) *time.Time {
+	t := now.Add(d)
+	return &t
+}
+
+func TestStandingAt(t *testing.T) {
+	tests := []struct {
+		name          string
+		actions       []model.Action
+		wantBanned    bool
+		wantSuspended *time.Time
+		wantWarnings  int
+	}{
+		{"Clean record", nil, false, nil, 0},
+		{"Warnings only", []model.Action{{Kind: model.ActionWarn}, {Kind: model.ActionWarn}}, false, nil, 2},
+		{"Active suspension", []model.Action{{Kind: model.ActionSuspend, ExpiresAt: at(time.Hour)}}, false, at(time.Hour), 0},
+		{"Expired suspension", []model.Action{{Kind: model.ActionSuspend, ExpiresAt: at(-time.Hour)}}, false, nil, 0},
+		{
+			"Longest suspension wins",
+			[]model.Action{
+				{Kind: model.ActionSuspend, ExpiresAt: at(48 * time.Hour)},
+				{Kind: model.ActionSuspend, ExpiresAt: at(time.Hour)},
+			},
+			false, at(48 * time.Hour), 0,
+		},
+		{"Ban", []model.Action{{Kind: model.ActionWarn}, {Kind: model.ActionBan}}, true, nil, 1},
+		{
+			"Lift clears earlier sanctions",
+			[]model.Action{
+				{Kind: model.ActionBan},
+				{Kind: model.ActionSuspend, ExpiresAt: at(time.Hour)},
+				{Kind: model.ActionLift},
+			},
+			false, nil, 0,
+		},
+		{"Ban after lift", []model.Action{{Kind: model.ActionLift}, {Kind: model.ActionBan}}, true, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := StandingAt(tt.actions, now)
+			if s.Banned != tt.wantBanned || s.Warnings != tt.wantWarnings {
+				t.Errorf("StandingAt = %+v", s)
+			}
+			if (s.SuspendedUntil == nil) != (tt.wantSuspended == nil) ||
+				(s.SuspendedUntil != nil && !s.SuspendedUntil.Equal(*tt.wantSuspended)) {
+				t.Errorf("SuspendedUntil = %v, want %v", s.SuspendedUntil, tt.wantSuspended)
+			}
+		})
+	}
+}
+
+func TestCheckLogin(t *testing.T) {
+	if err := CheckLogin(Standing{Warnings: 3}); err != nil {
+		t.Errorf("warned user cannot log in: %v", err)
+	}
+	if err := CheckLogin(Standing{Banned: true}); err != ErrBanned {
+		t.Errorf("banned user = %v, want %v", err, ErrBanned)
+	}
+	if err := CheckLogin(Standing{SuspendedUntil: at(time.Hour)}); !errors.Is(err, ErrSuspended) {
+		t.Errorf("suspended user = %v, want %v", err, ErrSuspended)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	suspend := model.Action{UserID: 2, ModeratorID: 1, Kind: model.ActionSuspend, Days: 7}
+	if err := Prepare(&suspend, Standing{}, now); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if suspend.ExpiresAt == nil || !suspend.ExpiresAt.Equal(now.AddDate(0, 0, 7)) {
+		t.Errorf("ExpiresAt = %v, want a week from now", suspend.ExpiresAt)
+	}
+
+	self := model.Action{UserID: 1, ModeratorID: 1, Kind: model.ActionWarn}
+	if err := Prepare(&self, Standing{}, now); err != ErrModerateSelf {
+		t.Errorf("self action = %v, want %v", err, ErrModerateSelf)
+	}
+	lift := model.Action{UserID: 2, ModeratorID: 1, Kind: model.ActionLift}
+	if err := Prepare(&lift, Standing{Warnings: 1}, now); err != ErrNothingToLift {
+		t.Errorf("lift without sanction = %v, want %v", err, ErrNothingToLift)
+	}
+	if err := Prepare(&lift, Standing{Banned: true}, now); err != nil {
+		t.Errorf("lift ban = %v", err)
+	}
+}
+
+func TestClaimAndResolve(t *testing.T) {
+	r := model.Report{Status: model.ReportOpen}
+	if err := Claim(&r, 1); err != nil || r.Status != model.ReportReviewing || *r.AssignedTo != 1 {
+		t.Fatalf("Claim = %v, report %+v", err, r)
+	}
+	if err := Claim(&r, 2); err != ErrReportAssigned {
+		t.Errorf("second moderator claim = %v, want %v", err, ErrReportAssigned)
+	}
+	if err := Resolve(&r, 2, model.ReportResolved, "warned"); err != ErrReportAssigned {
+		t.Errorf("resolve by other moderator = %v, want %v", err, ErrReportAssigned)
+	}
+	if err := Resolve(&r, 1, model.ReportOpen, ""); err != ErrBadResolution {
+		t.Errorf("resolve to open = %v, want %v", err, ErrBadResolution)
+	}
+	if err := Resolve(&r, 1, model.ReportDismissed, "not a violation"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if r.Status != model.ReportDismissed || r.Resolution != "not a violation" {
+		t.Errorf("report after Resolve = %+v", r)
+	}
+	if err := Claim(&r, 1); err != ErrReportClosed {
+		t.Errorf("claim closed report = %v, want %v", err, ErrReportClosed)
+	}
+
+	open := model.Report{Status: model.ReportOpen}
+	if err := Resolve(&open, 3, model.ReportResolved, ""); err != nil || *open.AssignedTo != 3 {
+		t.Errorf("resolving an unclaimed report = %v, %+v", err, open)
+	}
+}
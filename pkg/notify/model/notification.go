@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TypeGameUpdated    = "game_updated"
+	TypeGameCancelled  = "game_cancelled"
+	TypeGameInvite     = "game_invite"
+	TypeWaitlistOffer  = "waitlist_offer"
+	TypeFriendRequest  = "friend_request"
+	TypeFriendAccepted = "friend_accepted"
+	TypeTeamInvite     = "team_invite"
+	TypeResultReported = "result_reported"
+	TypeResultDisputed = "result_disputed"
+	TypeNoShowRecorded = "no_show_recorded"
+	TypePaymentFailed  = "payment_failed"
+	TypeBadgeEarned    = "badge_earned"
+
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+
+	DigestInstant = "instant"
+	DigestHourly  = "hourly"
+	DigestDaily   = "daily"
+)
+
+// Types lists every notification type, in the order preferences are shown.
+var Types = []string{
+	TypeGameUpdated,
+	TypeGameCancelled,
+	TypeGameInvite,
+	TypeWaitlistOffer,
+	TypeFriendRequest,
+	TypeFriendAccepted,
+	TypeTeamInvite,
+	TypeResultReported,
+	TypeResultDisputed,
+	TypeNoShowRecorded,
+	TypePaymentFailed,
+	TypeBadgeEarned,
+}
+
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"userId"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ActorID   *int64     `json:"actorId,omitempty"`
+	GameID    *int64     `json:"gameId,omitempty"`
+	TeamID    *int64     `json:"teamId,omitempty"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Preference is which channels a user wants one type of notification on.
+// Push is saved for when a push provider is added; nothing sends push
+// notifications yet.
+type Preference struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"inApp"`
+	Email bool   `json:"email"`
+	Push  bool   `json:"push"`
+}
+
+type Settings struct {
+	UserID int64 `json:"userId"`
+	// EmailDigest batches non-urgent emails hourly or daily instead of
+	// sending each one as it happens.
+	EmailDigest  string       `json:"emailDigest"`
+	LastDigestAt *time.Time   `json:"lastDigestAt,omitempty"`
+	Preferences  []Preference `json:"preferences"`
+}
+
+func IsType(t string) bool {
+	for _, known := range Types {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Settings) ValidateSettings() error {
+	var errors []string
+
+	switch s.EmailDigest {
+	case DigestInstant, DigestHourly, DigestDaily:
+	default:
+		errors = append(errors, "Email digest must be instant, hourly or daily")
+	}
+	seen := make(map[string]bool)
+	for _, p := range s.Preferences {
+		if !IsType(p.Type) {
+			errors = append(errors, fmt.Sprintf("Unknown notification type %q", p.Type))
+			continue
+		}
+		if seen[p.Type] {
+			errors = append(errors, fmt.Sprintf("Notification type %q is listed twice", p.Type))
+		}
+		seen[p.Type] = true
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
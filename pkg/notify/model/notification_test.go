@@ -0,0 +1,27 @@
+package model
+
+import "testing"
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Settings
+		wantErr bool
+	}{
+		{"Valid", Settings{EmailDigest: DigestDaily, Preferences: []Preference{{Type: TypeGameInvite}}}, false},
+		{"No preferences", Settings{EmailDigest: DigestInstant}, false},
+		{"Bad digest", Settings{EmailDigest: "weekly"}, true},
+		{"Unknown type", Settings{EmailDigest: DigestHourly, Preferences: []Preference{{Type: "news"}}}, true},
+		{"Duplicate type", Settings{
+			EmailDigest: DigestHourly,
+			Preferences: []Preference{{Type: TypeTeamInvite}, {Type: TypeTeamInvite, Email: true}},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.s.ValidateSettings(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// Default is the preference for a type the user has not configured. Every
+// type shows in the app; email is on for what needs a reply.
+func Default(t string) model.Preference {
+	p := model.Preference{Type: t, InApp: true}
+	switch t {
+	case model.TypeGameCancelled, model.TypeGameInvite, model.TypeWaitlistOffer, model.TypeTeamInvite:
+		p.Email = true
+	}
+	return p
+}
+
+// Merge fills in defaults for every type missing from saved, returning one
+// preference per type in model.Types order.
+func Merge(saved []model.Preference) []model.Preference {
+	byType := make(map[string]model.Preference, len(saved))
+	for _, p := range saved {
+		byType[p.Type] = p
+	}
+	out := make([]model.Preference, len(model.Types))
+	for i, t := range model.Types {
+		p, ok := byType[t]
+		if !ok {
+			p = Default(t)
+		}
+		out[i] = p
+	}
+	return out
+}
+
+// Urgent types carry a deadline, so their emails skip the digest.
+func Urgent(t string) bool {
+	return t == model.TypeWaitlistOffer
+}
+
+// Delivery is how one notification reaches one user.
+type Delivery struct {
+	// Store is false when the user wants the notification on no channel
+	// that needs it kept.
+	Store       bool
+	InApp       bool
+	EmailNow    bool
+	EmailDigest bool
+}
+
+// Plan decides the delivery of a notification of type t given the user's
+// preference for it and their digest setting.
+func Plan(t string, p model.Preference, digest string) Delivery {
+	d := Delivery{InApp: p.InApp}
+	if p.Email {
+		if digest == model.DigestInstant || digest == "" || Urgent(t) {
+			d.EmailNow = true
+		} else {
+			d.EmailDigest = true
+		}
+	}
+	d.Store = d.InApp || d.EmailDigest
+	return d
+}
+
+// DigestDue reports whether a user on the given digest schedule, last sent a
+// digest at last, should get the next one at now.
+func DigestDue(digest string, last *time.Time, now time.Time) bool {
+	var every time.Duration
+	switch digest {
+	case model.DigestHourly:
+		every = time.Hour
+	case model.DigestDaily:
+		every = 24 * time.Hour
+	default:
+		// Instant users have nothing batched, but flush anything left over
+		// from before they switched.
+		return true
+	}
+	return last == nil || !now.Before(last.Add(every))
+}
+
+// Digest composes one email covering notifications, oldest first.
+func Digest(notifications []model.Notification) (string, string) {
+	subject := fmt.Sprintf("You have %d new notifications", len(notifications))
+	if len(notifications) == 1 {
+		subject = "You have 1 new notification"
+	}
+	var b strings.Builder
+	for _, n := range notifications {
+		fmt.Fprintf(&b, "- %s: %s\n", n.Title, n.Body)
+	}
+	return subject, b.String()
+}
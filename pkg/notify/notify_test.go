@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/notify/model"
+)
+
+func TestMerge(t *testing.T) {
+	saved := []model.Preference{
+		{Type: model.TypeFriendRequest, InApp: false, Email: true},
+	}
+	got := Merge(saved)
+	if len(got) != len(model.Types) {
+		t.Fatalf("Merge returned %d preferences, want %d", len(got), len(model.Types))
+	}
+	for i, p := range got {
+		if p.Type != model.Types[i] {
+			t.Errorf("preference %d is %q, want %q", i, p.Type, model.Types[i])
+		}
+		want := Default(p.Type)
+		if p.Type == model.TypeFriendRequest {
+			want = saved[0]
+		}
+		if p != want {
+			t.Errorf("preference for %q = %+v, want %+v", p.Type, p, want)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	all := model.Preference{InApp: true, Email: true}
+	tests := []struct {
+		name   string
+		typ    string
+		pref   model.Preference
+		digest string
+		want   Delivery
+	}{
+		{"Instant email", model.TypeGameInvite, all, model.DigestInstant,
+			Delivery{Store: true, InApp: true, EmailNow: true}},
+		{"Digested email", model.TypeGameInvite, all, model.DigestDaily,
+			Delivery{Store: true, InApp: true, EmailDigest: true}},
+		{"Urgent skips digest", model.TypeWaitlistOffer, all, model.DigestDaily,
+			Delivery{Store: true, InApp: true, EmailNow: true}},
+		{"Email only, digested", model.TypeGameUpdated, model.Preference{Email: true}, model.DigestHourly,
+			Delivery{Store: true, EmailDigest: true}},
+		{"Email only, instant", model.TypeGameUpdated, model.Preference{Email: true}, model.DigestInstant,
+			Delivery{EmailNow: true}},
+		{"Nothing", model.TypeGameUpdated, model.Preference{Push: true}, model.DigestDaily,
+			Delivery{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Plan(tt.typ, tt.pref, tt.digest); got != tt.want {
+				t.Errorf("Plan = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+	tests := []struct {
+		name   string
+		digest string
+		last   *time.Time
+		want   bool
+	}{
+		{"Never sent", model.DigestDaily, nil, true},
+		{"Hourly, too soon", model.DigestHourly, ago(30 * time.Minute), false},
+		{"Hourly, due", model.DigestHourly, ago(time.Hour), true},
+		{"Daily, too soon", model.DigestDaily, ago(23 * time.Hour), false},
+		{"Daily, due", model.DigestDaily, ago(25 * time.Hour), true},
+		{"Instant flushes leftovers", model.DigestInstant, ago(time.Minute), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DigestDue(tt.digest, tt.last, now); got != tt.want {
+				t.Errorf("DigestDue = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	subject, body := Digest([]model.Notification{
+		{Title: "Game updated", Body: "Start time changed"},
+		{Title: "Friend request", Body: "sam wants to be friends"},
+	})
+	if subject != "You have 2 new notifications" {
+		t.Errorf("subject = %q", subject)
+	}
+	if !strings.Contains(body, "- Game updated: Start time changed\n") ||
+		!strings.Contains(body, "- Friend request: sam wants to be friends\n") {
+		t.Errorf("body = %q, want a line per notification", body)
+	}
+	if subject, _ := Digest([]model.Notification{{}}); subject != "You have 1 new notification" {
+		t.Errorf("single subject = %q", subject)
+	}
+}
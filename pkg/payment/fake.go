@@ -0,0 +1,187 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/payment/model"
+)
+
+// Payment methods understood by Fake. Any other method succeeds.
+const (
+	MethodSucceed      = "fake_succeed"
+	MethodDecline      = "fake_decline"
+	MethodAsync        = "fake_async"
+	MethodAsyncDecline = "fake_async_decline"
+)
+
+// Fake is a PaymentProvider for development and tests that never moves
+// money. The payment method picks the outcome: captures succeed or are
+// declined straight away, or stay pending until a webhook settles them.
+// Like a real provider, every webhook may be delivered more than once.
+type Fake struct {
+	Secret []byte
+	// Deliver is called with each signed webhook. Webhooks are dropped
+	// while it is nil.
+	Deliver func(payload []byte, signature string)
+	// Delay is how long async outcomes take.
+	Delay time.Duration
+	// Redeliver sends every webhook twice.
+	Redeliver bool
+
+	mu      sync.Mutex
+	seq     int
+	intents map[string]*fakeIntent
+	keys    map[string]string
+	refunds map[string]*Refund
+}
+
+type fakeIntent struct {
+	Intent
+	method   string
+	refunded bool
+}
+
+func NewFake(secret []byte) *Fake {
+	return &Fake{Secret: secret, Delay: 2 * time.Second, Redeliver: true}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) nextID(prefix string) string {
+	f.seq++
+	return fmt.Sprintf("%s_%d", prefix, f.seq)
+}
+
+func (f *Fake) CreateIntent(ctx context.Context, r IntentRequest) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.intents == nil {
+		f.intents = make(map[string]*fakeIntent)
+		f.keys = make(map[string]string)
+	}
+	if id, ok := f.keys[r.IdempotencyKey]; ok && r.IdempotencyKey != "" {
+		in := f.intents[id].Intent
+		return &in, nil
+	}
+	in := &fakeIntent{
+		Intent: Intent{
+			ID:          f.nextID("pi"),
+			AmountCents: r.AmountCents,
+			Currency:    r.Currency,
+			Status:      model.StatusPending,
+		},
+		method: r.Method,
+	}
+	f.intents[in.ID] = in
+	if r.IdempotencyKey != "" {
+		f.keys[r.IdempotencyKey] = in.ID
+	}
+	out := in.Intent
+	return &out, nil
+}
+
+func (f *Fake) Capture(ctx context.Context, intentID string) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	in, ok := f.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if in.Status != model.StatusPending {
+		out := in.Intent
+		return &out, nil
+	}
+	switch in.method {
+	case MethodDecline:
+		in.Status = model.StatusFailed
+	case MethodAsync, MethodAsyncDecline:
+		event := model.EventSucceeded
+		if in.method == MethodAsyncDecline {
+			event = model.EventFailed
+		}
+		id := in.ID
+		time.AfterFunc(f.Delay, func() { f.settle(id, event) })
+	default:
+		in.Status = model.StatusSucceeded
+	}
+	out := in.Intent
+	return &out, nil
+}
+
+// settle finishes an async capture and tells the server about it.
+func (f *Fake) settle(intentID, event string) {
+	f.mu.Lock()
+	in := f.intents[intentID]
+	in.Status, _ = Transition(in.Status, event)
+	eventID := f.nextID("evt")
+	f.mu.Unlock()
+
+	f.send(Event{ID: eventID, Type: event, IntentID: intentID})
+}
+
+func (f *Fake) Refund(ctx context.Context, r RefundRequest) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if refund, ok := f.refunds[r.IdempotencyKey]; ok && r.IdempotencyKey != "" {
+		out := *refund
+		return &out, nil
+	}
+	in, ok := f.intents[r.IntentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if in.Status != model.StatusSucceeded && !in.refunded {
+		return nil, ErrNotRefundable
+	}
+	refund := &Refund{ID: "re_" + in.ID, IntentID: in.ID, AmountCents: r.AmountCents}
+	if r.IdempotencyKey != "" {
+		if f.refunds == nil {
+			f.refunds = make(map[string]*Refund)
+		}
+		f.refunds[r.IdempotencyKey] = refund
+	}
+	if in.refunded {
+		return refund, nil
+	}
+	in.refunded = true
+	in.Status = model.StatusRefunded
+	event := Event{ID: f.nextID("evt"), Type: model.EventRefunded, IntentID: in.ID}
+	go f.send(event)
+	return refund, nil
+}
+
+func (f *Fake) send(e Event) {
+	if f.Deliver == nil {
+		return
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("Failed to encode fake webhook %s: %v", e.ID, err)
+		return
+	}
+	f.Deliver(payload, SignWebhook(f.Secret, payload, time.Now()))
+	if f.Redeliver {
+		f.Deliver(payload, SignWebhook(f.Secret, payload, time.Now()))
+	}
+}
+
+func (f *Fake) VerifyWebhook(payload []byte, signature string, now time.Time) (*Event, error) {
+	if err := VerifySignature(f.Secret, payload, signature, now); err != nil {
+		return nil, err
+	}
+	var e Event
+	if err := json.Unmarshal(payload, &e); err != nil || e.ID == "" || e.IntentID == "" {
+		return nil, fmt.Errorf("invalid webhook payload")
+	}
+	return &e, nil
+}
@@ -0,0 +1,136 @@
+package payment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/payment/model"
+)
+
+type delivery struct {
+	payload   []byte
+	signature string
+}
+
+func newTestFake() (*Fake, chan delivery) {
+	deliveries := make(chan delivery, 10)
+	f := NewFake([]byte("whsec"))
+	f.Delay = time.Millisecond
+	f.Deliver = func(payload []byte, signature string) {
+		deliveries <- delivery{payload, signature}
+	}
+	return f, deliveries
+}
+
+func receive(t *testing.T, f *Fake, deliveries chan delivery) *Event {
+	t.Helper()
+	select {
+	case d := <-deliveries:
+		e, err := f.VerifyWebhook(d.payload, d.signature, time.Now())
+		if err != nil {
+			t.Fatalf("webhook did not verify: %v", err)
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no webhook delivered")
+	}
+	return nil
+}
+
+func TestFakeCapture(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{MethodSucceed, model.StatusSucceeded},
+		{"", model.StatusSucceeded},
+		{MethodDecline, model.StatusFailed},
+		{MethodAsync, model.StatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			f, _ := newTestFake()
+			in, err := f.CreateIntent(ctx, IntentRequest{AmountCents: 500, Currency: "usd", Method: tt.method})
+			if err != nil {
+				t.Fatal(err)
+			}
+			captured, err := f.Capture(ctx, in.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if captured.Status != tt.want {
+				t.Errorf("status = %q, want %q", captured.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeAsyncWebhooks(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		method string
+		event  string
+	}{
+		{MethodAsync, model.EventSucceeded},
+		{MethodAsyncDecline, model.EventFailed},
+	} {
+		t.Run(tt.method, func(t *testing.T) {
+			f, deliveries := newTestFake()
+			in, _ := f.CreateIntent(ctx, IntentRequest{AmountCents: 500, Method: tt.method})
+			if _, err := f.Capture(ctx, in.ID); err != nil {
+				t.Fatal(err)
+			}
+			first := receive(t, f, deliveries)
+			if first.Type != tt.event || first.IntentID != in.ID {
+				t.Errorf("event = %+v, want %s for %s", first, tt.event, in.ID)
+			}
+			if again := receive(t, f, deliveries); again.ID != first.ID {
+				t.Errorf("redelivered event %s, want the same ID %s", again.ID, first.ID)
+			}
+		})
+	}
+}
+
+func TestFakeIdempotentIntent(t *testing.T) {
+	ctx := context.Background()
+	f, _ := newTestFake()
+	a, _ := f.CreateIntent(ctx, IntentRequest{AmountCents: 500, IdempotencyKey: "payment-1"})
+	b, _ := f.CreateIntent(ctx, IntentRequest{AmountCents: 500, IdempotencyKey: "payment-1"})
+	c, _ := f.CreateIntent(ctx, IntentRequest{AmountCents: 500, IdempotencyKey: "payment-2"})
+	if a.ID != b.ID || a.ID == c.ID {
+		t.Errorf("intents %s, %s, %s: want the first two to match", a.ID, b.ID, c.ID)
+	}
+}
+
+func TestFakeRefund(t *testing.T) {
+	ctx := context.Background()
+	f, deliveries := newTestFake()
+	f.Redeliver = false
+
+	declined, _ := f.CreateIntent(ctx, IntentRequest{AmountCents: 500, Method: MethodDecline})
+	f.Capture(ctx, declined.ID)
+	if _, err := f.Refund(ctx, RefundRequest{IntentID: declined.ID, AmountCents: 500}); err != ErrNotRefundable {
+		t.Errorf("refund of a declined payment = %v, want %v", err, ErrNotRefundable)
+	}
+
+	paid, _ := f.CreateIntent(ctx, IntentRequest{AmountCents: 500})
+	f.Capture(ctx, paid.ID)
+	first, err := f.Refund(ctx, RefundRequest{IntentID: paid.ID, AmountCents: 500, IdempotencyKey: "refund-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := receive(t, f, deliveries); e.Type != model.EventRefunded {
+		t.Errorf("event = %+v, want a refund", e)
+	}
+	second, err := f.Refund(ctx, RefundRequest{IntentID: paid.ID, AmountCents: 500, IdempotencyKey: "refund-1"})
+	if err != nil || second.ID != first.ID {
+		t.Errorf("second refund = %+v, %v, want the first one again", second, err)
+	}
+	select {
+	case d := <-deliveries:
+		t.Errorf("repeated refund sent another webhook: %s", d.payload)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
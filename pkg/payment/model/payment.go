@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+const (
+	// StatusPending payments are waiting on the provider, which settles them
+	// through a webhook.
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusRefunded  = "refunded"
+
+	EventSucceeded = "payment.succeeded"
+	EventFailed    = "payment.failed"
+	EventRefunded  = "refund.succeeded"
+
+	DefaultCurrency = "usd"
+)
+
+// Payment is a player's fee for joining a game.
+type Payment struct {
+	ID          int64  `json:"id"`
+	GameID      int64  `json:"gameId"`
+	UserID      int64  `json:"userId"`
+	AmountCents int64  `json:"amountCents"`
+	Currency    string `json:"currency"`
+	Provider    string `json:"provider"`
+	// IntentID is the provider's reference for the payment.
+	IntentID  string    `json:"intentId,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,140 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/payment/model"
+)
+
+var (
+	ErrDeclined         = errors.New("your payment was declined")
+	ErrIntentNotFound   = errors.New("payment intent not found")
+	ErrNotCapturable    = errors.New("this payment cannot be captured")
+	ErrNotRefundable    = errors.New("only captured payments can be refunded")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrPaymentNotFound  = errors.New("payment not found")
+)
+
+// SignatureTolerance is how old a signed webhook can be before it is
+// rejected as a replay.
+const SignatureTolerance = 5 * time.Minute
+
+// IntentRequest asks the provider to set up a payment.
+type IntentRequest struct {
+	AmountCents int64
+	Currency    string
+	// Method is the payment method the app collected from the player.
+	Method string
+	// IdempotencyKey makes retrying a request return the first intent
+	// instead of charging twice.
+	IdempotencyKey string
+}
+
+// Intent is the provider's view of a payment. Its Status is one of the
+// payment statuses.
+type Intent struct {
+	ID          string
+	AmountCents int64
+	Currency    string
+	Status      string
+}
+
+// RefundRequest asks the provider to give back a captured payment.
+type RefundRequest struct {
+	IntentID    string
+	AmountCents int64
+	// IdempotencyKey makes retrying a refund return the first one instead
+	// of refunding twice.
+	IdempotencyKey string
+}
+
+type Refund struct {
+	ID          string
+	IntentID    string
+	AmountCents int64
+}
+
+// Event is a verified webhook from the provider. ID is unique per event and
+// stays the same when the provider delivers it again.
+type Event struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	IntentID string `json:"intentId"`
+}
+
+// PaymentProvider takes payments for game fees. Capture may leave an intent
+// pending; the provider then reports the outcome through a webhook.
+type PaymentProvider interface {
+	Name() string
+	CreateIntent(ctx context.Context, r IntentRequest) (*Intent, error)
+	Capture(ctx context.Context, intentID string) (*Intent, error)
+	Refund(ctx context.Context, r RefundRequest) (*Refund, error)
+	VerifyWebhook(payload []byte, signature string, now time.Time) (*Event, error)
+}
+
+// Transition returns the status a payment moves to when an event arrives
+// and whether it changed. Events that arrive late or out of order, such as
+// a failure after a refund, are ignored.
+func Transition(status, event string) (string, bool) {
+	switch {
+	case status == model.StatusPending && event == model.EventSucceeded:
+		return model.StatusSucceeded, true
+	case status == model.StatusPending && event == model.EventFailed:
+		return model.StatusFailed, true
+	case status == model.StatusSucceeded && event == model.EventRefunded:
+		return model.StatusRefunded, true
+	}
+	return status, false
+}
+
+// SignWebhook signs payload the way providers sign their webhooks: an HMAC
+// of the timestamp and payload, sent with the timestamp so that old
+// deliveries can be refused.
+func SignWebhook(secret, payload []byte, at time.Time) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + t + ",v1=" + webhookMAC(secret, t, payload)
+}
+
+// VerifySignature checks a signature made by SignWebhook. Without a secret
+// anyone could sign a webhook, so every signature is refused.
+func VerifySignature(secret, payload []byte, signature string, now time.Time) error {
+	if len(secret) == 0 {
+		return fmt.Errorf("%w: no webhook secret configured", ErrInvalidSignature)
+	}
+	var t, mac string
+	for _, part := range strings.Split(signature, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			mac = v
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || mac == "" {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(mac), []byte(webhookMAC(secret, t, payload))) {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func webhookMAC(secret []byte, t string, payload []byte) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(t + "."))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,71 @@
+package payment
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/payment/model"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		status, event string
+		want          string
+		changed       bool
+	}{
+		{model.StatusPending, model.EventSucceeded, model.StatusSucceeded, true},
+		{model.StatusPending, model.EventFailed, model.StatusFailed, true},
+		{model.StatusSucceeded, model.EventRefunded, model.StatusRefunded, true},
+		{model.StatusSucceeded, model.EventSucceeded, model.StatusSucceeded, false},
+		{model.StatusSucceeded, model.EventFailed, model.StatusSucceeded, false},
+		{model.StatusRefunded, model.EventSucceeded, model.StatusRefunded, false},
+		{model.StatusFailed, model.EventSucceeded, model.StatusFailed, false},
+		{model.StatusPending, model.EventRefunded, model.StatusPending, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status+" "+tt.event, func(t *testing.T) {
+			got, changed := Transition(tt.status, tt.event)
+			if got != tt.want || changed != tt.changed {
+				t.Errorf("Transition = %q, %v, want %q, %v", got, changed, tt.want, tt.changed)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("whsec")
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	sig := SignWebhook(secret, payload, now)
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		payload []byte
+		sig     string
+		now     time.Time
+		wantErr bool
+	}{
+		{"Valid", secret, payload, sig, now, false},
+		{"Slightly late", secret, payload, sig, now.Add(time.Minute), false},
+		{"Replayed", secret, payload, sig, now.Add(SignatureTolerance + time.Second), true},
+		{"Other secret", []byte("other"), payload, sig, now, true},
+		{"Tampered payload", secret, []byte(`{"id":"evt_2"}`), sig, now, true},
+		{"No timestamp", secret, payload, sig[strings.Index(sig, ",")+1:], now, true},
+		{"Garbage", secret, payload, "nonsense", now, true},
+		{"No secret", nil, payload, SignWebhook(nil, payload, now), now, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.payload, tt.sig, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("error %v is not ErrInvalidSignature", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// PlayerRating is a user's rating in one sport.
+type PlayerRating struct {
+	UserID      int64     `json:"userId"`
+	Sport       string    `json:"sport"`
+	Rating      float64   `json:"rating"`
+	RD          float64   `json:"rd"`
+	Volatility  float64   `json:"volatility"`
+	Games       int       `json:"games"`
+	Provisional bool      `json:"provisional"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// HistoryEntry is the change one rated result made to a rating.
+type HistoryEntry struct {
+	ResultID  int64     `json:"resultId"`
+	GameID    int64     `json:"gameId"`
+	Sport     string    `json:"sport"`
+	Before    float64   `json:"before"`
+	Rating    float64   `json:"rating"`
+	RD        float64   `json:"rd"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,187 @@
+package rating
+
+import (
+	"math"
+
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+// Glicko-2, as described by Glickman in "Example of the Glicko-2 system".
+// Every rated match is its own rating period.
+const (
+	DefaultRating     = 1500.0
+	DefaultRD         = 350.0
+	DefaultVolatility = 0.06
+	// Tau limits how fast volatility changes. Glickman suggests 0.3 to 1.2.
+	Tau = 0.5
+	// ProvisionalRD is the deviation above which a rating is too uncertain
+	// to be shown as settled or used in place of self-reported skill.
+	ProvisionalRD = 110.0
+	// SkillBand is how many rating points make up one self-reported skill
+	// level, with 1500 at the bottom of level 6.
+	SkillBand = 150.0
+
+	scale     = 173.7178
+	tolerance = 0.000001
+)
+
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	RD         float64 `json:"rd"`
+	Volatility float64 `json:"volatility"`
+}
+
+// Outcome is one game against Opponent, with Score 1 for a win, 0.5 for a
+// draw and 0 for a loss.
+type Outcome struct {
+	Opponent Rating
+	Score    float64
+}
+
+func Default() Rating {
+	return Rating{Rating: DefaultRating, RD: DefaultRD, Volatility: DefaultVolatility}
+}
+
+// Provisional reports whether r is still too uncertain to rely on.
+func (r Rating) Provisional() bool {
+	return r.RD > ProvisionalRD
+}
+
+// Skill maps r onto the 1-10 scale players use to describe themselves.
+func (r Rating) Skill() int {
+	skill := int(math.Floor((r.Rating-DefaultRating)/SkillBand)) + 6
+	return max(usermodel.MinSkill, min(usermodel.MaxSkill, skill))
+}
+
+// EffectiveSkill is the skill level to go by for a player who reported
+// reported: the level their rating maps to once it has settled, otherwise
+// the one they reported. r is nil for players who have never been rated.
+func EffectiveSkill(reported int, r *Rating) int {
+	if r == nil || r.Provisional() {
+		return reported
+	}
+	return r.Skill()
+}
+
+// Update returns r after a rating period with the given outcomes. With no
+// outcomes only the deviation grows.
+func Update(r Rating, outcomes []Outcome) Rating {
+	mu := (r.Rating - DefaultRating) / scale
+	phi := r.RD / scale
+	sigma := r.Volatility
+
+	if len(outcomes) == 0 {
+		return Rating{Rating: r.Rating, RD: capRD(math.Sqrt(phi*phi+sigma*sigma) * scale), Volatility: sigma}
+	}
+
+	var vInv, sum float64
+	for _, o := range outcomes {
+		muJ := (o.Opponent.Rating - DefaultRating) / scale
+		gJ := g(o.Opponent.RD / scale)
+		e := expected(mu, muJ, gJ)
+		vInv += gJ * gJ * e * (1 - e)
+		sum += gJ * (o.Score - e)
+	}
+	v := 1 / vInv
+	delta := v * sum
+
+	sigma = volatility(phi, sigma, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * sum
+
+	return Rating{Rating: mu*scale + DefaultRating, RD: capRD(phi * scale), Volatility: sigma}
+}
+
+// Match rates a game between two sides, returning the new ratings of each
+// player in the same order. scoreA is side A's score as in Outcome. Each
+// player is rated against the composite of the other side, so how much a
+// player moves depends on their own rating and deviation against the
+// opposition rather than on the side as a whole.
+func Match(sideA, sideB []Rating, scoreA float64) ([]Rating, []Rating) {
+	compA, compB := Composite(sideA), Composite(sideB)
+	rate := func(side []Rating, opponent Rating, score float64) []Rating {
+		out := make([]Rating, len(side))
+		for i, r := range side {
+			out[i] = Update(r, []Outcome{{Opponent: opponent, Score: score}})
+		}
+		return out
+	}
+	return rate(sideA, compB, scoreA), rate(sideB, compA, 1-scoreA)
+}
+
+// Composite combines a side into one rating: the mean rating, and the root
+// mean square of the deviations.
+func Composite(side []Rating) Rating {
+	if len(side) == 0 {
+		return Default()
+	}
+	var c Rating
+	for _, r := range side {
+		c.Rating += r.Rating
+		c.RD += r.RD * r.RD
+		c.Volatility += r.Volatility
+	}
+	n := float64(len(side))
+	return Rating{Rating: c.Rating / n, RD: math.Sqrt(c.RD / n), Volatility: c.Volatility / n}
+}
+
+// ScoreA turns the winner of a result into side A's score.
+func ScoreA(winner string) float64 {
+	switch winner {
+	case resultmodel.SideA:
+		return 1
+	case resultmodel.SideB:
+		return 0
+	}
+	return 0.5
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, gJ float64) float64 {
+	return 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+}
+
+// volatility finds the new volatility with the Illinois algorithm, step 5
+// of the paper.
+func volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(Tau*Tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*Tau) < 0 {
+			k++
+		}
+		B = a - k*Tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > tolerance {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
+
+func capRD(rd float64) float64 {
+	return math.Min(rd, DefaultRD)
+}
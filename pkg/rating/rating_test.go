@@ -0,0 +1,120 @@
+package rating
+
+import (
+	"math"
+	"testing"
+
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+// The worked example from Glickman's paper.
+func TestUpdateMatchesPaper(t *testing.T) {
+	player := Rating{Rating: 1500, RD: 200, Volatility: 0.06}
+	got := Update(player, []Outcome{
+		{Opponent: Rating{Rating: 1400, RD: 30}, Score: 1},
+		{Opponent: Rating{Rating: 1550, RD: 100}, Score: 0},
+		{Opponent: Rating{Rating: 1700, RD: 300}, Score: 0},
+	})
+	if !near(got.Rating, 1464.06, 0.01) {
+		t.Errorf("rating = %.2f, want 1464.06", got.Rating)
+	}
+	if !near(got.RD, 151.52, 0.01) {
+		t.Errorf("RD = %.2f, want 151.52", got.RD)
+	}
+	if !near(got.Volatility, 0.05999, 0.00001) {
+		t.Errorf("volatility = %.5f, want 0.05999", got.Volatility)
+	}
+}
+
+func TestUpdateWithoutGames(t *testing.T) {
+	r := Rating{Rating: 1600, RD: 50, Volatility: 0.06}
+	got := Update(r, nil)
+	if got.Rating != r.Rating || got.Volatility != r.Volatility {
+		t.Errorf("Update without games changed %+v to %+v", r, got)
+	}
+	if !near(got.RD, 51.07, 0.01) {
+		t.Errorf("RD = %.2f, want 51.07", got.RD)
+	}
+	if got := Update(Default(), nil); got.RD != DefaultRD {
+		t.Errorf("RD grew past %v to %v", DefaultRD, got.RD)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	strong := Rating{Rating: 1800, RD: 60, Volatility: 0.06}
+	newcomer := Default()
+	opponent := Rating{Rating: 1600, RD: 60, Volatility: 0.06}
+
+	a, b := Match([]Rating{strong, newcomer}, []Rating{opponent, opponent}, ScoreA(resultmodel.SideA))
+	if len(a) != 2 || len(b) != 2 {
+		t.Fatalf("Match returned %d and %d ratings, want 2 and 2", len(a), len(b))
+	}
+	if a[0].Rating <= strong.Rating || a[1].Rating <= newcomer.Rating {
+		t.Errorf("winners did not gain: %+v", a)
+	}
+	if b[0].Rating >= opponent.Rating {
+		t.Errorf("loser did not drop: %+v", b[0])
+	}
+	// The newcomer is uncertain and expected to lose, so moves much more.
+	if a[1].Rating-newcomer.Rating <= a[0].Rating-strong.Rating {
+		t.Errorf("newcomer gained %.1f, strong player %.1f",
+			a[1].Rating-newcomer.Rating, a[0].Rating-strong.Rating)
+	}
+
+	a, b = Match([]Rating{opponent}, []Rating{opponent}, ScoreA(resultmodel.Draw))
+	if !near(a[0].Rating, opponent.Rating, 0.001) || !near(b[0].Rating, opponent.Rating, 0.001) {
+		t.Errorf("draw between equals moved ratings: %+v %+v", a[0], b[0])
+	}
+}
+
+func TestSkillAndProvisional(t *testing.T) {
+	tests := []struct {
+		rating float64
+		skill  int
+	}{
+		{0, 1},
+		{1350, 5},
+		{1499, 5},
+		{1500, 6},
+		{1649, 6},
+		{2100, 10},
+		{3000, 10},
+	}
+	for _, tt := range tests {
+		if got := (Rating{Rating: tt.rating}).Skill(); got != tt.skill {
+			t.Errorf("Skill(%v) = %d, want %d", tt.rating, got, tt.skill)
+		}
+	}
+	if !Default().Provisional() {
+		t.Error("a new rating is not provisional")
+	}
+	if (Rating{Rating: 1500, RD: 80}).Provisional() {
+		t.Error("a settled rating is provisional")
+	}
+}
+
+func TestEffectiveSkill(t *testing.T) {
+	settled := &Rating{Rating: 1820, RD: 70}
+	tests := []struct {
+		name     string
+		reported int
+		r        *Rating
+		want     int
+	}{
+		{"Never rated", 3, nil, 3},
+		{"Provisional", 3, &Rating{Rating: 1820, RD: 200}, 3},
+		{"Settled", 3, settled, 8},
+		{"Settled without a reported level", 0, settled, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EffectiveSkill(tt.reported, tt.r); got != tt.want {
+				t.Errorf("EffectiveSkill = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
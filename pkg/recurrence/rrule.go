@@ -0,0 +1,411 @@
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The subset of RFC 5545 recurrence rules supported: FREQ (DAILY, WEEKLY,
+// MONTHLY), INTERVAL, COUNT, UNTIL, BYDAY (with ordinals for MONTHLY) and
+// BYMONTHDAY. Weeks start on Monday.
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+
+	dateTimeFormat    = "20060102T150405Z"
+	dateTimeFloating  = "20060102T150405"
+	dateFormat        = "20060102"
+	maxIterations     = 10000
+	maxOrdinalWeekday = 5
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY entry. N is the ordinal within the month (1 for the
+// first, -1 for the last) or 0 for every such weekday.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+// Parse reads an RRULE value, with or without the "RRULE:" prefix.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	r := Rule{Interval: 1}
+	if s == "" {
+		return r, fmt.Errorf("empty recurrence rule")
+	}
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("invalid rule part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("INTERVAL must be a positive integer")
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("COUNT must be a positive integer")
+			}
+			r.Count = n
+		case "UNTIL":
+			t, err := ParseDateTime(value)
+			if err != nil {
+				return r, fmt.Errorf("invalid UNTIL: %w", err)
+			}
+			r.Until = t
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(d)
+				if err != nil {
+					return r, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return r, fmt.Errorf("invalid BYMONTHDAY %q", d)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return r, fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			return r, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+
+	return r, r.Validate()
+}
+
+func (r Rule) Validate() error {
+	switch r.Freq {
+	case Daily, Weekly, Monthly:
+	case "":
+		return fmt.Errorf("FREQ is required")
+	default:
+		return fmt.Errorf("unsupported FREQ %s", r.Freq)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("COUNT and UNTIL cannot both be set")
+	}
+	if r.Freq == Daily && (len(r.ByDay) > 0 || len(r.ByMonthDay) > 0) {
+		return fmt.Errorf("BYDAY and BYMONTHDAY are not supported with FREQ=DAILY")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return fmt.Errorf("BYMONTHDAY is not supported with FREQ=WEEKLY")
+	}
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != Monthly {
+			return fmt.Errorf("ordinal BYDAY values need FREQ=MONTHLY")
+		}
+	}
+	if len(r.ByDay) > 0 && len(r.ByMonthDay) > 0 {
+		return fmt.Errorf("BYDAY and BYMONTHDAY cannot be combined")
+	}
+	return nil
+}
+
+// String formats the rule as an RRULE value without the prefix.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+FormatDateTime(r.Until))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = d.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (w WeekdayNum) String() string {
+	code := ""
+	for c, wd := range weekdayCodes {
+		if wd == w.Weekday {
+			code = c
+		}
+	}
+	if w.N == 0 {
+		return code
+	}
+	return strconv.Itoa(w.N) + code
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	wd, ok := weekdayCodes[s[len(s)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	n := 0
+	if prefix := s[:len(s)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -maxOrdinalWeekday || n > maxOrdinalWeekday {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY ordinal %q", s)
+		}
+	}
+	return WeekdayNum{Weekday: wd, N: n}, nil
+}
+
+// ParseDateTime accepts the UTC, floating and date-only forms used by UNTIL
+// and EXDATE. Floating and date-only values are read as UTC.
+func ParseDateTime(s string) (time.Time, error) {
+	for _, layout := range []string{dateTimeFormat, dateTimeFloating, dateFormat} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date-time %q", s)
+}
+
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// Set is a recurring schedule: a first occurrence, the rule that repeats it
+// and the occurrences excluded from it.
+type Set struct {
+	Start   time.Time
+	Rule    Rule
+	ExDates []time.Time
+}
+
+// Between returns the occurrences starting in [from, to), in order. COUNT is
+// applied before exclusions, as RFC 5545 requires.
+func (s Set) Between(from, to time.Time) []time.Time {
+	var out []time.Time
+	s.each(func(t time.Time) bool {
+		if !t.Before(to) {
+			return false
+		}
+		if !t.Before(from) && !s.excluded(t) {
+			out = append(out, t)
+		}
+		return true
+	})
+	return out
+}
+
+// Includes reports whether t is one of the set's occurrences.
+func (s Set) Includes(t time.Time) bool {
+	if s.excluded(t) {
+		return false
+	}
+	found := false
+	s.each(func(o time.Time) bool {
+		if o.Equal(t) {
+			found = true
+		}
+		return o.Before(t)
+	})
+	return found
+}
+
+// CountBefore returns how many occurrences, excluded ones included, start
+// before t. It is used to carry COUNT over when a series is split.
+func (s Set) CountBefore(t time.Time) int {
+	n := 0
+	s.each(func(o time.Time) bool {
+		if !o.Before(t) {
+			return false
+		}
+		n++
+		return true
+	})
+	return n
+}
+
+func (s Set) excluded(t time.Time) bool {
+	for _, ex := range s.ExDates {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// each calls fn for every occurrence in order until fn returns false or the
+// rule is exhausted.
+func (s Set) each(fn func(time.Time) bool) {
+	r := s.Rule
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	emitted := 0
+	for period := 0; period < maxIterations; period++ {
+		candidates := s.period(period * interval)
+		for _, t := range candidates {
+			if t.Before(s.Start) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			if r.Count > 0 && emitted >= r.Count {
+				return
+			}
+			emitted++
+			if !fn(t) {
+				return
+			}
+		}
+	}
+}
+
+// period returns the candidate occurrences in the n-th day, week or month
+// after the start, in chronological order.
+func (s Set) period(n int) []time.Time {
+	start := s.Start
+	loc := start.Location()
+	hour, min, sec := start.Clock()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, min, sec, start.Nanosecond(), loc)
+	}
+
+	switch s.Rule.Freq {
+	case Daily:
+		return []time.Time{at(start.Year(), start.Month(), start.Day()+n)}
+
+	case Weekly:
+		offset := (int(start.Weekday()) + 6) % 7
+		monday := at(start.Year(), start.Month(), start.Day()-offset+7*n)
+		days := s.Rule.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Weekday: start.Weekday()}}
+		}
+		var out []time.Time
+		for _, d := range days {
+			shift := (int(d.Weekday) + 6) % 7
+			out = append(out, at(monday.Year(), monday.Month(), monday.Day()+shift))
+		}
+		sortTimes(out)
+		return dedupe(out)
+
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, loc)
+		year, month := first.Year(), first.Month()
+		days := daysIn(year, month)
+		var out []time.Time
+
+		switch {
+		case len(s.Rule.ByDay) > 0:
+			for _, d := range s.Rule.ByDay {
+				for _, day := range weekdaysInMonth(year, month, d, loc) {
+					out = append(out, at(year, month, day))
+				}
+			}
+		default:
+			monthDays := s.Rule.ByMonthDay
+			if len(monthDays) == 0 {
+				monthDays = []int{start.Day()}
+			}
+			for _, md := range monthDays {
+				day := md
+				if md < 0 {
+					day = days + md + 1
+				}
+				if day >= 1 && day <= days {
+					out = append(out, at(year, month, day))
+				}
+			}
+		}
+		sortTimes(out)
+		return dedupe(out)
+	}
+	return nil
+}
+
+func weekdaysInMonth(year int, month time.Month, d WeekdayNum, loc *time.Location) []int {
+	var days []int
+	for day := 1; day <= daysIn(year, month); day++ {
+		if time.Date(year, month, day, 0, 0, 0, 0, loc).Weekday() == d.Weekday {
+			days = append(days, day)
+		}
+	}
+	switch {
+	case d.N > 0 && d.N <= len(days):
+		return []int{days[d.N-1]}
+	case d.N < 0 && -d.N <= len(days):
+		return []int{days[len(days)+d.N]}
+	case d.N == 0:
+		return days
+	}
+	return nil
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func sortTimes(ts []time.Time) {
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Before(ts[j]) })
+}
+
+func dedupe(ts []time.Time) []time.Time {
+	out := ts[:0]
+	for i, t := range ts {
+		if i == 0 || !t.Equal(ts[i-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, s string) Rule {
+	t.Helper()
+	r, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return r
+}
+
+func dates(ts []time.Time) []string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = t.Format("2006-01-02 15:04")
+	}
+	return out
+}
+
+func assertDates(t *testing.T, got []time.Time, want ...string) {
+	t.Helper()
+	g := dates(got)
+	if len(g) != len(want) {
+		t.Fatalf("got %v, want %v", g, want)
+	}
+	for i := range want {
+		if g[i] != want[i] {
+			t.Fatalf("got %v, want %v", g, want)
+		}
+	}
+}
+
+// 2026-05-05 is a Tuesday.
+var tuesday = time.Date(2026, 5, 5, 18, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{"RRULE:FREQ=WEEKLY;BYDAY=TU", false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=10", false},
+		{"FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231T235959Z", false},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1", false},
+		{"FREQ=DAILY;UNTIL=20260601", false},
+		{"", true},
+		{"BYDAY=TU", true},
+		{"FREQ=YEARLY", true},
+		{"FREQ=WEEKLY;BYDAY=2TU", true},
+		{"FREQ=WEEKLY;BYDAY=XX", true},
+		{"FREQ=MONTHLY;BYDAY=6TU", true},
+		{"FREQ=WEEKLY;COUNT=2;UNTIL=20260601", true},
+		{"FREQ=WEEKLY;INTERVAL=0", true},
+		{"FREQ=WEEKLY;BYSETPOS=1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleStringRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"FREQ=WEEKLY;INTERVAL=2;COUNT=10;BYDAY=TU,TH",
+		"FREQ=MONTHLY;UNTIL=20261231T235959Z;BYDAY=-1FR",
+		"FREQ=MONTHLY;BYMONTHDAY=15",
+	} {
+		if got := mustParse(t, s).String(); got != s {
+			t.Errorf("String() = %q, want %q", got, s)
+		}
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	s := Set{Start: tuesday, Rule: mustParse(t, "FREQ=WEEKLY;BYDAY=TU")}
+	got := s.Between(tuesday, tuesday.AddDate(0, 0, 22))
+	assertDates(t, got, "2026-05-05 18:00", "2026-05-12 18:00", "2026-05-19 18:00", "2026-05-26 18:00")
+}
+
+func TestBiweeklyMultipleDays(t *testing.T) {
+	s := Set{Start: tuesday, Rule: mustParse(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=TH,TU;COUNT=5")}
+	got := s.Between(tuesday, tuesday.AddDate(1, 0, 0))
+	assertDates(t, got,
+		"2026-05-05 18:00", "2026-05-07 18:00",
+		"2026-05-19 18:00", "2026-05-21 18:00",
+		"2026-06-02 18:00",
+	)
+}
+
+func TestWeeklySkipsDaysBeforeStart(t *testing.T) {
+	// Start on a Tuesday with Monday in BYDAY: the first Monday is next week.
+	s := Set{Start: tuesday, Rule: mustParse(t, "FREQ=WEEKLY;BYDAY=MO,TU;COUNT=3")}
+	got := s.Between(tuesday.AddDate(0, 0, -7), tuesday.AddDate(0, 1, 0))
+	assertDates(t, got, "2026-05-05 18:00", "2026-05-11 18:00", "2026-05-12 18:00")
+}
+
+func TestWeeklyRepeatedDay(t *testing.T) {
+	s := Set{Start: tuesday, Rule: mustParse(t, "FREQ=WEEKLY;BYDAY=TU,TU;COUNT=3")}
+	got := s.Between(tuesday, tuesday.AddDate(0, 1, 0))
+	assertDates(t, got, "2026-05-05 18:00", "2026-05-12 18:00", "2026-05-19 18:00")
+}
+
+func TestMonthlyByWeekday(t *testing.T) {
+	s := Set{Start: time.Date(2026, 5, 12, 19, 0, 0, 0, time.UTC), Rule: mustParse(t, "FREQ=MONTHLY;BYDAY=2TU;COUNT=3")}
+	got := s.Between(s.Start, s.Start.AddDate(1, 0, 0))
+	assertDates(t, got, "2026-05-12 19:00", "2026-06-09 19:00", "2026-07-14 19:00")
+
+	s = Set{Start: time.Date(2026, 1, 30, 7, 0, 0, 0, time.UTC), Rule: mustParse(t, "FREQ=MONTHLY;BYDAY=-1FR")}
+	got = s.Between(s.Start, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	assertDates(t, got, "2026-01-30 07:00", "2026-02-27 07:00", "2026-03-27 07:00")
+}
+
+func TestMonthlySkipsShortMonths(t *testing.T) {
+	s := Set{Start: time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC), Rule: mustParse(t, "FREQ=MONTHLY;COUNT=3")}
+	got := s.Between(s.Start, s.Start.AddDate(1, 0, 0))
+	assertDates(t, got, "2026-01-31 09:00", "2026-03-31 09:00", "2026-05-31 09:00")
+
+	s = Set{Start: time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC), Rule: mustParse(t, "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=2")}
+	assertDates(t, s.Between(s.Start, s.Start.AddDate(1, 0, 0)), "2026-01-31 09:00", "2026-02-28 09:00")
+}
+
+func TestUntilAndExDates(t *testing.T) {
+	s := Set{
+		Start:   tuesday,
+		Rule:    mustParse(t, "FREQ=WEEKLY;UNTIL=20260526T180000Z"),
+		ExDates: []time.Time{tuesday.AddDate(0, 0, 7)},
+	}
+	got := s.Between(tuesday, tuesday.AddDate(1, 0, 0))
+	assertDates(t, got, "2026-05-05 18:00", "2026-05-19 18:00", "2026-05-26 18:00")
+
+	if s.Includes(tuesday.AddDate(0, 0, 7)) {
+		t.Error("Includes returned true for an excluded date")
+	}
+	if !s.Includes(tuesday.AddDate(0, 0, 14)) {
+		t.Error("Includes returned false for a real occurrence")
+	}
+	if s.Includes(tuesday.Add(time.Hour)) {
+		t.Error("Includes returned true for a time off the schedule")
+	}
+}
+
+func TestCountAppliesBeforeExDates(t *testing.T) {
+	s := Set{
+		Start:   tuesday,
+		Rule:    mustParse(t, "FREQ=DAILY;COUNT=3"),
+		ExDates: []time.Time{tuesday.AddDate(0, 0, 1)},
+	}
+	assertDates(t, s.Between(tuesday, tuesday.AddDate(0, 1, 0)), "2026-05-05 18:00", "2026-05-07 18:00")
+	if n := s.CountBefore(tuesday.AddDate(0, 0, 2)); n != 2 {
+		t.Errorf("CountBefore = %d, want 2", n)
+	}
+}
+
+func TestKeepsWallClockAcrossDST(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	start := time.Date(2026, 3, 24, 18, 0, 0, 0, london)
+	s := Set{Start: start, Rule: mustParse(t, "FREQ=WEEKLY;COUNT=2")}
+	got := s.Between(start, start.AddDate(0, 1, 0))
+	if got[1].Hour() != 18 {
+		t.Errorf("occurrence after DST change at %v, want 18:00 local", got[1])
+	}
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	MinScore = 1
+	MaxScore = 5
+
+	MaxCommentLength = 500
+
+	AttendancePresent = "present"
+	AttendanceNoShow  = "no_show"
+
+	// SourceOrganizer marks attendance taken by the organizer, SourceCheckIn
+	// players who scanned the game's check-in code and SourceAuto the
+	// no-shows filled in once attendance closes.
+	SourceOrganizer = "organizer"
+	SourceCheckIn   = "check_in"
+	SourceAuto      = "auto"
+
+	StandingGood       = "good"
+	StandingFlagged    = "flagged"
+	StandingRestricted = "restricted"
+)
+
+// Review is one player's rating of another after a game they both played.
+type Review struct {
+	ID         int64 `json:"id"`
+	GameID     int64 `json:"gameId"`
+	ReviewerID int64 `json:"reviewerId,omitempty"`
+	RevieweeID int64 `json:"revieweeId"`
+	// Each score is from MinScore to MaxScore.
+	Sportsmanship int       `json:"sportsmanship"`
+	Punctuality   int       `json:"punctuality"`
+	SkillAccuracy int       `json:"skillAccuracy"`
+	Comment       string    `json:"comment,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Reputation aggregates the reviews a user received and their attendance
+// over the no-show window.
+type Reputation struct {
+	UserID        int64   `json:"userId"`
+	Reviews       int     `json:"reviews"`
+	Sportsmanship float64 `json:"sportsmanship"`
+	Punctuality   float64 `json:"punctuality"`
+	SkillAccuracy float64 `json:"skillAccuracy"`
+	Overall       float64 `json:"overall"`
+	Games         int     `json:"games"`
+	NoShows       int     `json:"noShows"`
+	NoShowRate    float64 `json:"noShowRate"`
+	Standing      string  `json:"standing"`
+}
+
+// AttendanceStats counts every game a user had attendance taken for.
+type AttendanceStats struct {
+	UserID   int64 `json:"userId"`
+	Games    int   `json:"games"`
+	Attended int   `json:"attended"`
+	NoShows  int   `json:"noShows"`
+	// CheckIns is how many of the attended games the user checked in to
+	// with the game's code.
+	CheckIns int     `json:"checkIns"`
+	Rate     float64 `json:"attendanceRate"`
+}
+
+// Attendance records whether a participant turned up to a game.
+type Attendance struct {
+	GameID     int64     `json:"gameId"`
+	UserID     int64     `json:"userId"`
+	Status     string    `json:"status"`
+	Source     string    `json:"source"`
+	RecordedBy *int64    `json:"recordedBy,omitempty"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// Roll is the attendance an organizer takes for a game.
+type Roll struct {
+	Entries []Attendance `json:"attendance"`
+}
+
+func (r *Review) ValidateReview() error {
+	var errors []string
+
+	r.Comment = strings.TrimSpace(r.Comment)
+
+	if r.RevieweeID == 0 {
+		errors = append(errors, "Reviewee is required")
+	}
+	for _, s := range []struct {
+		name  string
+		score int
+	}{
+		{"Sportsmanship", r.Sportsmanship},
+		{"Punctuality", r.Punctuality},
+		{"Skill accuracy", r.SkillAccuracy},
+	} {
+		if s.score < MinScore || s.score > MaxScore {
+			errors = append(errors, fmt.Sprintf("%s must be between %d and %d", s.name, MinScore, MaxScore))
+		}
+	}
+	if len(r.Comment) > MaxCommentLength {
+		errors = append(errors, fmt.Sprintf("Comment must be at most %d characters", MaxCommentLength))
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+func (r *Roll) ValidateRoll() error {
+	var errors []string
+
+	if len(r.Entries) == 0 {
+		errors = append(errors, "Attendance is required")
+	}
+	seen := make(map[int64]bool, len(r.Entries))
+	for _, e := range r.Entries {
+		if e.UserID == 0 {
+			errors = append(errors, "Every entry needs a user")
+			continue
+		}
+		if seen[e.UserID] {
+			errors = append(errors, fmt.Sprintf("User %d is listed more than once", e.UserID))
+		}
+		seen[e.UserID] = true
+		switch e.Status {
+		case AttendancePresent, AttendanceNoShow:
+		default:
+			errors = append(errors, fmt.Sprintf("Status of user %d must be present or no_show", e.UserID))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
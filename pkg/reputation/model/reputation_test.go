@@ -0,0 +1,53 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReview(t *testing.T) {
+	valid := Review{RevieweeID: 2, Sportsmanship: 5, Punctuality: 3, SkillAccuracy: 1}
+	tests := []struct {
+		name    string
+		mutate  func(r *Review)
+		wantErr bool
+	}{
+		{"Valid", func(r *Review) {}, false},
+		{"No reviewee", func(r *Review) { r.RevieweeID = 0 }, true},
+		{"Score too low", func(r *Review) { r.Punctuality = 0 }, true},
+		{"Score too high", func(r *Review) { r.SkillAccuracy = 6 }, true},
+		{"Long comment", func(r *Review) { r.Comment = strings.Repeat("a", MaxCommentLength+1) }, true},
+		{"Padded comment", func(r *Review) { r.Comment = "  " + strings.Repeat("a", MaxCommentLength) + "  " }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.mutate(&r)
+			if err := r.ValidateReview(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateReview() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRoll(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Attendance
+		wantErr bool
+	}{
+		{"Valid", []Attendance{{UserID: 1, Status: AttendancePresent}, {UserID: 2, Status: AttendanceNoShow}}, false},
+		{"Empty", nil, true},
+		{"Unknown status", []Attendance{{UserID: 1, Status: "late"}}, true},
+		{"Missing user", []Attendance{{Status: AttendancePresent}}, true},
+		{"Listed twice", []Attendance{{UserID: 1, Status: AttendancePresent}, {UserID: 1, Status: AttendanceNoShow}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Roll{Entries: tt.entries}
+			if err := r.ValidateRoll(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRoll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
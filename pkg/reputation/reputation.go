@@ -0,0 +1,123 @@
+package reputation
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/checkin"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/reputation/model"
+)
+
+var (
+	ErrReviewSelf        = errors.New("you cannot review yourself")
+	ErrNotPlayedTogether = errors.New("you can only review players you shared a game with")
+	ErrGameNotOver       = errors.New("players can be reviewed once the game has ended")
+	ErrGameCancelled     = errors.New("cancelled games cannot be reviewed")
+	ErrReviewClosed      = errors.New("reviews close a week after the game")
+	ErrAlreadyReviewed   = errors.New("you have already reviewed this player for this game")
+	ErrAttendanceClosed  = errors.New("attendance can be taken from the start of the game until a week after it ends")
+	ErrNotParticipant    = errors.New("attendance can only be taken for the game's participants")
+)
+
+const (
+	// ReviewWindow is how long after a game ends its players can review one
+	// another and the organizer can correct attendance.
+	ReviewWindow = 7 * 24 * time.Hour
+	// AttendanceGrace is how long after a game ends the organizer has to
+	// take attendance before every participant left unmarked is recorded as
+	// a no-show. Games nobody took attendance for are left alone.
+	AttendanceGrace = 24 * time.Hour
+	// NoShowWindow is how far back attendance counts toward a standing.
+	NoShowWindow = 90 * 24 * time.Hour
+
+	flagNoShows     = 2
+	flagRate        = 0.2
+	restrictNoShows = 3
+	restrictRate    = 0.3
+)
+
+// CheckReview validates r for game g, whose participants and recorded
+// no-shows are given. Reviewer and reviewee must both have played.
+func CheckReview(g gamemodel.Game, participants, noShows []int64, r model.Review, now time.Time) error {
+	if r.ReviewerID == r.RevieweeID {
+		return ErrReviewSelf
+	}
+	if g.Status == gamemodel.StatusCancelled {
+		return ErrGameCancelled
+	}
+	played := make(map[int64]bool, len(participants))
+	for _, id := range participants {
+		played[id] = true
+	}
+	for _, id := range noShows {
+		delete(played, id)
+	}
+	if !played[r.ReviewerID] || !played[r.RevieweeID] {
+		return ErrNotPlayedTogether
+	}
+	if now.Before(g.EndsAt) {
+		return ErrGameNotOver
+	}
+	if !now.Before(g.EndsAt.Add(ReviewWindow)) {
+		return ErrReviewClosed
+	}
+	return nil
+}
+
+// CheckRoll validates attendance taken for game g, whose participants are
+// given. Organizers can take it from when check-in opens, so they can check
+// in players who cannot scan the code.
+func CheckRoll(g gamemodel.Game, participants []int64, roll model.Roll, now time.Time) error {
+	if g.Status == gamemodel.StatusCancelled {
+		return ErrGameCancelled
+	}
+	if opens, _ := checkin.Window(g); now.Before(opens) || !now.Before(g.EndsAt.Add(ReviewWindow)) {
+		return ErrAttendanceClosed
+	}
+	joined := make(map[int64]bool, len(participants))
+	for _, id := range participants {
+		joined[id] = true
+	}
+	for _, e := range roll.Entries {
+		if !joined[e.UserID] {
+			return ErrNotParticipant
+		}
+	}
+	return nil
+}
+
+// RollTaken reports whether the organizer took attendance for a game, given
+// what was recorded for it. Players checking themselves in do not count:
+// closing a game on their word alone would mark everyone else a no-show.
+func RollTaken(attendance []model.Attendance) bool {
+	for _, a := range attendance {
+		if a.Source == model.SourceOrganizer {
+			return true
+		}
+	}
+	return false
+}
+
+// NoShowRate is the share of games with attendance taken that the user
+// missed.
+func NoShowRate(games, noShows int) float64 {
+	if games == 0 {
+		return 0
+	}
+	return float64(noShows) / float64(games)
+}
+
+// Standing classifies a user by their attendance over the no-show window.
+// Flagged players are shown as unreliable to organizers; restricted players
+// cannot join public games until older no-shows fall out of the window.
+func Standing(games, noShows int) string {
+	rate := NoShowRate(games, noShows)
+	switch {
+	case noShows >= restrictNoShows && rate >= restrictRate:
+		return model.StandingRestricted
+	case noShows >= flagNoShows && rate >= flagRate:
+		return model.StandingFlagged
+	}
+	return model.StandingGood
+}
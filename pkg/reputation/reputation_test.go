@@ -0,0 +1,134 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/checkin"
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/reputation/model"
+)
+
+var now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func playedGame() gamemodel.Game {
+	return gamemodel.Game{
+		ID:          1,
+		OrganizerID: 10,
+		StartsAt:    now.Add(-3 * time.Hour),
+		EndsAt:      now.Add(-time.Hour),
+		Status:      gamemodel.StatusOpen,
+	}
+}
+
+func TestCheckReview(t *testing.T) {
+	participants := []int64{10, 2, 3, 4}
+	review := model.Review{GameID: 1, ReviewerID: 2, RevieweeID: 3}
+	tests := []struct {
+		name    string
+		mutate  func(g *gamemodel.Game, r *model.Review)
+		noShows []int64
+		want    error
+	}{
+		{"Teammates", func(g *gamemodel.Game, r *model.Review) {}, nil, nil},
+		{"Organizer", func(g *gamemodel.Game, r *model.Review) { r.RevieweeID = 10 }, nil, nil},
+		{"Self", func(g *gamemodel.Game, r *model.Review) { r.RevieweeID = 2 }, nil, ErrReviewSelf},
+		{"Reviewee did not play", func(g *gamemodel.Game, r *model.Review) { r.RevieweeID = 5 }, nil, ErrNotPlayedTogether},
+		{"Reviewer did not play", func(g *gamemodel.Game, r *model.Review) { r.ReviewerID = 5 }, nil, ErrNotPlayedTogether},
+		{"Reviewee no-show", func(g *gamemodel.Game, r *model.Review) {}, []int64{3}, ErrNotPlayedTogether},
+		{"Reviewer no-show", func(g *gamemodel.Game, r *model.Review) {}, []int64{2}, ErrNotPlayedTogether},
+		{"Still playing", func(g *gamemodel.Game, r *model.Review) { g.EndsAt = now.Add(time.Minute) }, nil, ErrGameNotOver},
+		{"Window closed", func(g *gamemodel.Game, r *model.Review) { g.EndsAt = now.Add(-ReviewWindow) }, nil, ErrReviewClosed},
+		{"Cancelled", func(g *gamemodel.Game, r *model.Review) { g.Status = gamemodel.StatusCancelled }, nil, ErrGameCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, r := playedGame(), review
+			tt.mutate(&g, &r)
+			if got := CheckReview(g, participants, tt.noShows, r, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRoll(t *testing.T) {
+	participants := []int64{10, 2, 3}
+	roll := model.Roll{Entries: []model.Attendance{
+		{UserID: 2, Status: model.AttendancePresent},
+		{UserID: 3, Status: model.AttendanceNoShow},
+	}}
+	tests := []struct {
+		name   string
+		mutate func(g *gamemodel.Game)
+		roll   model.Roll
+		want   error
+	}{
+		{"After the game", func(g *gamemodel.Game) {}, roll, nil},
+		{"During the game", func(g *gamemodel.Game) { g.EndsAt = now.Add(time.Hour) }, roll, nil},
+		{"Check-in open", func(g *gamemodel.Game) { g.StartsAt = now.Add(time.Minute) }, roll, nil},
+		{"Before check-in", func(g *gamemodel.Game) { g.StartsAt = now.Add(checkin.OpensBefore + time.Minute) }, roll, ErrAttendanceClosed},
+		{"A week later", func(g *gamemodel.Game) { g.EndsAt = now.Add(-ReviewWindow) }, roll, ErrAttendanceClosed},
+		{"Cancelled", func(g *gamemodel.Game) { g.Status = gamemodel.StatusCancelled }, roll, ErrGameCancelled},
+		{
+			"Outsider",
+			func(g *gamemodel.Game) {},
+			model.Roll{Entries: []model.Attendance{{UserID: 5, Status: model.AttendancePresent}}},
+			ErrNotParticipant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := playedGame()
+			tt.mutate(&g)
+			if got := CheckRoll(g, participants, tt.roll, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollTaken(t *testing.T) {
+	checkedIn := model.Attendance{GameID: 1, UserID: 2, Status: model.AttendancePresent, Source: model.SourceCheckIn}
+	marked := model.Attendance{GameID: 1, UserID: 3, Status: model.AttendancePresent, Source: model.SourceOrganizer}
+	tests := []struct {
+		name       string
+		attendance []model.Attendance
+		want       bool
+	}{
+		{"Nothing recorded", nil, false},
+		{"One check-in", []model.Attendance{checkedIn}, false},
+		{"Organizer roll", []model.Attendance{marked}, true},
+		{"Check-ins and roll", []model.Attendance{checkedIn, marked}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RollTaken(tt.attendance); got != tt.want {
+				t.Errorf("RollTaken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStanding(t *testing.T) {
+	tests := []struct {
+		name           string
+		games, noShows int
+		want           string
+	}{
+		{"No games", 0, 0, model.StandingGood},
+		{"One slip", 3, 1, model.StandingGood},
+		{"Two in many games", 20, 2, model.StandingGood},
+		{"Two in ten", 10, 2, model.StandingFlagged},
+		{"Three in fifteen", 15, 3, model.StandingFlagged},
+		{"Three in ten", 10, 3, model.StandingRestricted},
+		{"Never shows", 4, 4, model.StandingRestricted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Standing(tt.games, tt.noShows); got != tt.want {
+				t.Errorf("Standing(%d, %d) = %q, want %q", tt.games, tt.noShows, got, tt.want)
+			}
+		})
+	}
+}
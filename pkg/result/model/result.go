@@ -0,0 +1,259 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+const (
+	KindSets   = "sets"
+	KindGoals  = "goals"
+	KindPoints = "points"
+
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusDisputed  = "disputed"
+	StatusResolved  = "resolved"
+	StatusVoid      = "void"
+
+	SideA = "a"
+	SideB = "b"
+	// Draw is the winner of a drawn match.
+	Draw = "draw"
+
+	MaxReasonLength = 1000
+)
+
+// SetRule says when a set is won: first to Target, by Margin, or first to
+// Cap when it is set. The deciding set of a match may have its own target,
+// as in volleyball.
+type SetRule struct {
+	Target         int
+	Margin         int
+	Cap            int
+	DecidingTarget int
+}
+
+// Format is how a sport is scored.
+type Format struct {
+	Kind string `json:"kind"`
+	// BestOf lists the match lengths allowed, in sets.
+	BestOf    []int   `json:"bestOf,omitempty"`
+	Set       SetRule `json:"-"`
+	AllowDraw bool    `json:"allowDraw"`
+}
+
+var formats = map[string]Format{
+	"tennis":       {Kind: KindSets, BestOf: []int{3, 5}, Set: SetRule{Target: 6, Margin: 2, Cap: 7}},
+	"padel":        {Kind: KindSets, BestOf: []int{3}, Set: SetRule{Target: 6, Margin: 2, Cap: 7}},
+	"badminton":    {Kind: KindSets, BestOf: []int{3}, Set: SetRule{Target: 21, Margin: 2, Cap: 30}},
+	"table tennis": {Kind: KindSets, BestOf: []int{5, 7}, Set: SetRule{Target: 11, Margin: 2}},
+	"volleyball":   {Kind: KindSets, BestOf: []int{3, 5}, Set: SetRule{Target: 25, Margin: 2, DecidingTarget: 15}},
+	"squash":       {Kind: KindSets, BestOf: []int{5}, Set: SetRule{Target: 11, Margin: 2}},
+	"football":     {Kind: KindGoals, AllowDraw: true},
+	"futsal":       {Kind: KindGoals, AllowDraw: true},
+	"hockey":       {Kind: KindGoals, AllowDraw: true},
+	"handball":     {Kind: KindGoals, AllowDraw: true},
+	"basketball":   {Kind: KindPoints},
+}
+
+// FormatFor returns the scoring format of sport. Sports without a known
+// format are scored as points, draws allowed.
+func FormatFor(sport string) Format {
+	if f, ok := formats[usermodel.NormalizeSport(sport)]; ok {
+		return f
+	}
+	return Format{Kind: KindPoints, AllowDraw: true}
+}
+
+type SetScore struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+// Score holds either Sets, for set-based sports, or the A and B totals.
+type Score struct {
+	Sets []SetScore `json:"sets,omitempty"`
+	// BestOf is the match length for sports that allow more than one. It
+	// defaults to the shortest match the sets fit in.
+	BestOf int `json:"bestOf,omitempty"`
+	A      int `json:"a"`
+	B      int `json:"b"`
+}
+
+type Result struct {
+	ID             int64     `json:"id"`
+	GameID         int64     `json:"gameId"`
+	ReportedBy     int64     `json:"reportedBy"`
+	SideA          []int64   `json:"sideA"`
+	SideB          []int64   `json:"sideB"`
+	Score          Score     `json:"score"`
+	Winner         string    `json:"winner"`
+	Status         string    `json:"status"`
+	ConfirmedBy    *int64    `json:"confirmedBy,omitempty"`
+	DisputedBy     *int64    `json:"disputedBy,omitempty"`
+	DisputeReason  string    `json:"disputeReason,omitempty"`
+	ResolvedBy     *int64    `json:"resolvedBy,omitempty"`
+	ResolutionNote string    `json:"resolutionNote,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// SideOf returns which side userID played on, or "" if neither.
+func (r *Result) SideOf(userID int64) string {
+	for _, id := range r.SideA {
+		if id == userID {
+			return SideA
+		}
+	}
+	for _, id := range r.SideB {
+		if id == userID {
+			return SideB
+		}
+	}
+	return ""
+}
+
+// SideOfPlayers returns the side that players, such as the members of a
+// team, played on, or "" if none of them played or they played on both.
+func (r *Result) SideOfPlayers(players map[int64]bool) string {
+	side := ""
+	for _, id := range r.SideA {
+		if players[id] {
+			side = SideA
+		}
+	}
+	for _, id := range r.SideB {
+		if players[id] {
+			if side == SideA {
+				return ""
+			}
+			side = SideB
+		}
+	}
+	return side
+}
+
+// ValidateResult checks the sides and that the score is possible in format
+// f, and works out the winner.
+func (r *Result) ValidateResult(f Format) error {
+	var errors []string
+
+	if len(r.SideA) == 0 || len(r.SideB) == 0 {
+		errors = append(errors, "Both sides need at least one player")
+	}
+	seen := make(map[int64]bool)
+	for _, id := range append(append([]int64{}, r.SideA...), r.SideB...) {
+		if seen[id] {
+			errors = append(errors, "A player can only be on one side, once")
+			break
+		}
+		seen[id] = true
+	}
+	if err := r.Score.validate(f); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	r.Winner = r.Score.Winner()
+	return nil
+}
+
+func (s *Score) validate(f Format) error {
+	if f.Kind != KindSets {
+		s.Sets = nil
+		s.BestOf = 0
+		if s.A < 0 || s.B < 0 {
+			return fmt.Errorf("Scores cannot be negative")
+		}
+		if s.A == s.B && !f.AllowDraw {
+			return fmt.Errorf("This sport cannot end in a draw")
+		}
+		return nil
+	}
+
+	length := 0
+	for _, n := range f.BestOf {
+		if s.BestOf == n || (s.BestOf == 0 && len(s.Sets) <= n && length == 0) {
+			length = n
+		}
+	}
+	if length == 0 {
+		return fmt.Errorf("Matches are best of %s sets", joinInts(f.BestOf))
+	}
+	if len(s.Sets) == 0 || len(s.Sets) > length {
+		return fmt.Errorf("Enter between 1 and %d sets", length)
+	}
+	s.BestOf = length
+	need := length/2 + 1
+	s.A, s.B = 0, 0
+	for i, set := range s.Sets {
+		if s.A == need || s.B == need {
+			return fmt.Errorf("Set %d was played after the match was won", i+1)
+		}
+		rule := f.Set
+		if i == length-1 && rule.DecidingTarget > 0 {
+			rule.Target = rule.DecidingTarget
+		}
+		if !rule.valid(set) {
+			return fmt.Errorf("Set %d score %d-%d is not possible", i+1, set.A, set.B)
+		}
+		if set.A > set.B {
+			s.A++
+		} else {
+			s.B++
+		}
+	}
+	if s.A != need && s.B != need {
+		return fmt.Errorf("Neither side won %d sets", need)
+	}
+	return nil
+}
+
+func (r SetRule) valid(s SetScore) bool {
+	win, lose := s.A, s.B
+	if lose > win {
+		win, lose = lose, win
+	}
+	if lose < 0 || win == lose {
+		return false
+	}
+	switch {
+	case win == r.Target:
+		return win-lose >= r.Margin
+	case win < r.Target, lose < r.Target-r.Margin+1:
+		// Short of the target, or the set would have ended at the target.
+		return false
+	case r.Cap > 0 && win == r.Cap:
+		return win-lose <= r.Margin
+	case r.Cap > 0 && win > r.Cap:
+		return false
+	}
+	// Past the target the set goes on until one side leads by the margin.
+	return win-lose == r.Margin
+}
+
+// Winner returns SideA, SideB or Draw. For set-based sports A and B count
+// the sets won.
+func (s Score) Winner() string {
+	switch {
+	case s.A > s.B:
+		return SideA
+	case s.B > s.A:
+		return SideB
+	}
+	return Draw
+}
+
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, " or ")
+}
@@ -0,0 +1,89 @@
+package model
+
+import "testing"
+
+func sets(scores ...int) []SetScore {
+	out := make([]SetScore, 0, len(scores)/2)
+	for i := 0; i+1 < len(scores); i += 2 {
+		out = append(out, SetScore{A: scores[i], B: scores[i+1]})
+	}
+	return out
+}
+
+func TestFormatFor(t *testing.T) {
+	if f := FormatFor(" Tennis "); f.Kind != KindSets {
+		t.Errorf("tennis is scored in %s, want sets", f.Kind)
+	}
+	if f := FormatFor("football"); f.Kind != KindGoals || !f.AllowDraw {
+		t.Errorf("football format = %+v, want goals with draws", f)
+	}
+	if f := FormatFor("basketball"); f.Kind != KindPoints || f.AllowDraw {
+		t.Errorf("basketball format = %+v, want points without draws", f)
+	}
+	if f := FormatFor("ultimate"); f.Kind != KindPoints || !f.AllowDraw {
+		t.Errorf("unknown sport format = %+v, want points with draws", f)
+	}
+}
+
+func TestValidateScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		sport      string
+		score      Score
+		wantErr    bool
+		wantWinner string
+	}{
+		{"Tennis straight sets", "tennis", Score{Sets: sets(6, 4, 6, 3)}, false, SideA},
+		{"Tennis tiebreak and 7-5", "tennis", Score{Sets: sets(7, 6, 5, 7, 6, 7)}, false, SideB},
+		{"Tennis best of five", "tennis", Score{BestOf: 5, Sets: sets(6, 0, 6, 0, 6, 0)}, false, SideA},
+		{"Tennis set too short", "tennis", Score{Sets: sets(6, 5, 6, 3)}, true, ""},
+		{"Tennis impossible 7-3", "tennis", Score{Sets: sets(7, 3, 6, 3)}, true, ""},
+		{"Tennis unfinished", "tennis", Score{Sets: sets(6, 4, 3, 6)}, true, ""},
+		{"Tennis extra set", "tennis", Score{Sets: sets(6, 4, 6, 4, 6, 4)}, true, ""},
+		{"Tennis bad match length", "tennis", Score{BestOf: 4, Sets: sets(6, 4, 6, 4)}, true, ""},
+		{"Badminton deuce", "badminton", Score{Sets: sets(22, 20, 30, 29)}, false, SideA},
+		{"Badminton past cap", "badminton", Score{Sets: sets(31, 29, 21, 5)}, true, ""},
+		{"Badminton impossible 30-27", "badminton", Score{Sets: sets(30, 27, 21, 5)}, true, ""},
+		{"Volleyball deciding set to 15", "volleyball", Score{Sets: sets(25, 20, 20, 25, 15, 13)}, false, SideA},
+		{"Volleyball long deuce", "volleyball", Score{Sets: sets(31, 29, 25, 10)}, false, SideA},
+		{"Football draw", "football", Score{A: 2, B: 2}, false, Draw},
+		{"Football win", "football", Score{A: 0, B: 1}, false, SideB},
+		{"Negative goals", "football", Score{A: -1, B: 0}, true, ""},
+		{"Basketball draw", "basketball", Score{A: 80, B: 80}, true, ""},
+		{"Basketball win", "basketball", Score{A: 81, B: 80}, false, SideA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Result{SideA: []int64{1}, SideB: []int64{2}, Score: tt.score}
+			err := r.ValidateResult(FormatFor(tt.sport))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && r.Winner != tt.wantWinner {
+				t.Errorf("Winner = %q, want %q", r.Winner, tt.wantWinner)
+			}
+		})
+	}
+}
+
+func TestValidateSides(t *testing.T) {
+	f := FormatFor("football")
+	tests := []struct {
+		name         string
+		sideA, sideB []int64
+		wantErr      bool
+	}{
+		{"Teams", []int64{1, 2}, []int64{3, 4}, false},
+		{"Empty side", []int64{1}, nil, true},
+		{"Player on both sides", []int64{1, 2}, []int64{2, 3}, true},
+		{"Player twice", []int64{1, 1}, []int64{3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Result{SideA: tt.sideA, SideB: tt.sideB, Score: Score{A: 1}}
+			if err := r.ValidateResult(f); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package result
+
+import (
+	"errors"
+	"time"
+
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/result/model"
+)
+
+var (
+	ErrResultNotFound    = errors.New("no result has been recorded for this game")
+	ErrResultExists      = errors.New("a result has already been recorded for this game")
+	ErrGameNotFinished   = errors.New("results can only be recorded once the game has ended")
+	ErrGameCancelled     = errors.New("cancelled games have no result")
+	ErrNotParticipant    = errors.New("every player on a side must have taken part in the game")
+	ErrCannotReport      = errors.New("only players or the organizer can record the result")
+	ErrNotOpponent       = errors.New("only the other side can confirm or dispute this result")
+	ErrNotPending        = errors.New("this result is no longer awaiting confirmation")
+	ErrNotDisputed       = errors.New("only disputed results can be resolved")
+	ErrCannotResolve     = errors.New("disputes are resolved by an organizer who did not play, or an admin")
+	ErrReasonRequired    = errors.New("please say why you dispute the result")
+	ErrCorrectionMissing = errors.New("a corrected score is required")
+)
+
+const (
+	DecisionUphold  = "uphold"
+	DecisionCorrect = "correct"
+	DecisionVoid    = "void"
+)
+
+// CheckReport validates recording r for game g, whose participants are
+// given. The reporter must be a player in the result or the organizer.
+func CheckReport(g gamemodel.Game, participants []int64, r model.Result, now time.Time) error {
+	if g.Status == gamemodel.StatusCancelled {
+		return ErrGameCancelled
+	}
+	if now.Before(g.EndsAt) {
+		return ErrGameNotFinished
+	}
+	joined := make(map[int64]bool, len(participants))
+	for _, id := range participants {
+		joined[id] = true
+	}
+	for _, id := range append(append([]int64{}, r.SideA...), r.SideB...) {
+		if !joined[id] {
+			return ErrNotParticipant
+		}
+	}
+	if r.SideOf(r.ReportedBy) == "" && r.ReportedBy != g.OrganizerID {
+		return ErrCannotReport
+	}
+	return nil
+}
+
+// canAnswer reports whether userID may confirm or dispute r: a player on the
+// side that did not report it, or either side when the organizer reported
+// without playing.
+func canAnswer(r *model.Result, userID int64) bool {
+	side := r.SideOf(userID)
+	if side == "" {
+		return false
+	}
+	return side != r.SideOf(r.ReportedBy)
+}
+
+func Confirm(r *model.Result, userID int64) error {
+	if r.Status != model.StatusPending {
+		return ErrNotPending
+	}
+	if !canAnswer(r, userID) {
+		return ErrNotOpponent
+	}
+	r.Status = model.StatusConfirmed
+	r.ConfirmedBy = &userID
+	return nil
+}
+
+func Dispute(r *model.Result, userID int64, reason string) error {
+	if r.Status != model.StatusPending {
+		return ErrNotPending
+	}
+	if !canAnswer(r, userID) {
+		return ErrNotOpponent
+	}
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	r.Status = model.StatusDisputed
+	r.DisputedBy = &userID
+	r.DisputeReason = reason
+	return nil
+}
+
+// CanResolve reports whether userID may settle a dispute over a result of
+// game g. Organizers who played are too close to it and must leave it to an
+// admin.
+func CanResolve(g gamemodel.Game, r *model.Result, userID int64, admin bool) bool {
+	if admin {
+		return true
+	}
+	return userID == g.OrganizerID && r.SideOf(userID) == ""
+}
+
+// Resolve settles a disputed result: upholding the reported score, replacing
+// it with a corrected one, or voiding the result altogether. corrected must
+// already be validated.
+func Resolve(r *model.Result, resolverID int64, decision string, corrected *model.Score, note string) error {
+	if r.Status != model.StatusDisputed {
+		return ErrNotDisputed
+	}
+	switch decision {
+	case DecisionUphold:
+		r.Status = model.StatusResolved
+	case DecisionCorrect:
+		if corrected == nil {
+			return ErrCorrectionMissing
+		}
+		r.Score = *corrected
+		r.Winner = corrected.Winner()
+		r.Status = model.StatusResolved
+	case DecisionVoid:
+		r.Status = model.StatusVoid
+	default:
+		return errors.New("decision must be uphold, correct or void")
+	}
+	r.ResolvedBy = &resolverID
+	r.ResolutionNote = note
+	return nil
+}
+
+// Final reports whether r counts, for ratings and statistics.
+func Final(r model.Result) bool {
+	return r.Status == model.StatusConfirmed || r.Status == model.StatusResolved
+}
@@ -0,0 +1,148 @@
+package result
+
+import (
+	"testing"
+	"time"
+
+	gamemodel "github.com/dudeiebot/sportPeerGo/pkg/game/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/result/model"
+)
+
+var (
+	now    = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	played = gamemodel.Game{OrganizerID: 9, Status: gamemodel.StatusOpen, EndsAt: now.Add(-time.Hour)}
+)
+
+func pending(reporter int64) model.Result {
+	return model.Result{
+		ReportedBy: reporter,
+		SideA:      []int64{1, 2},
+		SideB:      []int64{3, 4},
+		Status:     model.StatusPending,
+	}
+}
+
+func TestCheckReport(t *testing.T) {
+	everyone := []int64{1, 2, 3, 4, 9}
+	later := played
+	later.EndsAt = now.Add(time.Hour)
+	cancelled := played
+	cancelled.Status = gamemodel.StatusCancelled
+	outsider := pending(1)
+	outsider.SideB = []int64{3, 5}
+
+	tests := []struct {
+		name string
+		g    gamemodel.Game
+		r    model.Result
+		want error
+	}{
+		{"Player reports", played, pending(1), nil},
+		{"Organizer reports", played, pending(9), nil},
+		{"Bystander reports", played, pending(7), ErrCannotReport},
+		{"Game not over", later, pending(1), ErrGameNotFinished},
+		{"Cancelled", cancelled, pending(1), ErrGameCancelled},
+		{"Player did not take part", played, outsider, ErrNotParticipant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckReport(tt.g, everyone, tt.r, now); err != tt.want {
+				t.Errorf("CheckReport = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmAndDispute(t *testing.T) {
+	tests := []struct {
+		name     string
+		reporter int64
+		user     int64
+		want     error
+	}{
+		{"Opponent", 1, 3, nil},
+		{"Teammate", 1, 2, ErrNotOpponent},
+		{"Reporter", 1, 1, ErrNotOpponent},
+		{"Outsider", 1, 7, ErrNotOpponent},
+		{"Either side after organizer reports", 9, 2, nil},
+		{"Organizer after own report", 9, 9, ErrNotOpponent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := pending(tt.reporter)
+			if err := Confirm(&r, tt.user); err != tt.want {
+				t.Fatalf("Confirm = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (r.Status != model.StatusConfirmed || *r.ConfirmedBy != tt.user) {
+				t.Errorf("after Confirm status = %s", r.Status)
+			}
+
+			r = pending(tt.reporter)
+			if err := Dispute(&r, tt.user, "wrong score"); err != tt.want {
+				t.Fatalf("Dispute = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && r.Status != model.StatusDisputed {
+				t.Errorf("after Dispute status = %s", r.Status)
+			}
+		})
+	}
+
+	r := pending(1)
+	if err := Dispute(&r, 3, ""); err != ErrReasonRequired {
+		t.Errorf("Dispute without reason = %v, want %v", err, ErrReasonRequired)
+	}
+	r.Status = model.StatusConfirmed
+	if err := Confirm(&r, 3); err != ErrNotPending {
+		t.Errorf("Confirm twice = %v, want %v", err, ErrNotPending)
+	}
+}
+
+func TestCanResolve(t *testing.T) {
+	r := pending(1)
+	if !CanResolve(played, &r, 9, false) {
+		t.Error("organizer who did not play cannot resolve")
+	}
+	if CanResolve(played, &r, 3, false) {
+		t.Error("a player can resolve their own dispute")
+	}
+	playing := played
+	playing.OrganizerID = 1
+	if CanResolve(playing, &r, 1, false) {
+		t.Error("organizer who played can resolve")
+	}
+	if !CanResolve(playing, &r, 42, true) {
+		t.Error("admin cannot resolve")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	disputed := func() model.Result {
+		r := pending(1)
+		r.Status = model.StatusDisputed
+		r.Score = model.Score{A: 3, B: 1}
+		r.Winner = model.SideA
+		return r
+	}
+	corrected := &model.Score{A: 1, B: 3}
+
+	r := disputed()
+	if err := Resolve(&r, 9, DecisionUphold, nil, "looks right"); err != nil || r.Status != model.StatusResolved {
+		t.Errorf("uphold: err %v, status %s", err, r.Status)
+	}
+	r = disputed()
+	if err := Resolve(&r, 9, DecisionCorrect, corrected, ""); err != nil || r.Winner != model.SideB || r.Score.B != 3 {
+		t.Errorf("correct: err %v, result %+v", err, r)
+	}
+	r = disputed()
+	if err := Resolve(&r, 9, DecisionCorrect, nil, ""); err != ErrCorrectionMissing {
+		t.Errorf("correct without score = %v, want %v", err, ErrCorrectionMissing)
+	}
+	r = disputed()
+	if err := Resolve(&r, 9, DecisionVoid, nil, ""); err != nil || r.Status != model.StatusVoid || Final(r) {
+		t.Errorf("void: err %v, status %s", err, r.Status)
+	}
+	r = pending(1)
+	if err := Resolve(&r, 9, DecisionUphold, nil, ""); err != ErrNotDisputed {
+		t.Errorf("resolve pending = %v, want %v", err, ErrNotDisputed)
+	}
+}
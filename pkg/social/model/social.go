@@ -0,0 +1,92 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	AudienceEveryone = "everyone"
+	AudienceFriends  = "friends"
+	AudienceNobody   = "nobody"
+
+	RequestPending  = "pending"
+	RequestAccepted = "accepted"
+	RequestDeclined = "declined"
+
+	MaxLocationFuzzKm = 50
+)
+
+// Privacy controls what other users can see of, and do to, a user. The user
+// always sees everything of their own.
+type Privacy struct {
+	UserID         int64   `json:"userId"`
+	Profile        string  `json:"profile"`
+	Games          string  `json:"games"`
+	Location       string  `json:"location"`
+	LocationFuzzKm float64 `json:"locationFuzzKm"`
+	Invites        string  `json:"invites"`
+	FriendRequests string  `json:"friendRequests"`
+}
+
+// DefaultPrivacy applies to users who never changed their settings.
+func DefaultPrivacy(userID int64) Privacy {
+	return Privacy{
+		UserID:         userID,
+		Profile:        AudienceEveryone,
+		Games:          AudienceEveryone,
+		Location:       AudienceFriends,
+		LocationFuzzKm: 1,
+		Invites:        AudienceEveryone,
+		FriendRequests: AudienceEveryone,
+	}
+}
+
+type Friend struct {
+	UserID      int64     `json:"userId"`
+	Username    string    `json:"username"`
+	Since       time.Time `json:"since"`
+	MutualCount int       `json:"mutualFriends"`
+}
+
+type FriendRequest struct {
+	RequesterID int64     `json:"requesterId"`
+	AddresseeID int64     `json:"addresseeId"`
+	Username    string    `json:"username"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (p *Privacy) ValidatePrivacy() error {
+	var errors []string
+
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"profile", &p.Profile},
+		{"games", &p.Games},
+		{"location", &p.Location},
+		{"invites", &p.Invites},
+		{"friendRequests", &p.FriendRequests},
+	} {
+		*field.value = strings.ToLower(strings.TrimSpace(*field.value))
+		switch *field.value {
+		case AudienceEveryone, AudienceFriends, AudienceNobody:
+		default:
+			errors = append(errors, fmt.Sprintf("%s must be everyone, friends or nobody", field.name))
+		}
+	}
+	if p.FriendRequests == AudienceFriends {
+		errors = append(errors, "friendRequests must be everyone or nobody")
+	}
+	if p.LocationFuzzKm < 0 || p.LocationFuzzKm > MaxLocationFuzzKm {
+		errors = append(errors, fmt.Sprintf("locationFuzzKm must be between 0 and %d", MaxLocationFuzzKm))
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
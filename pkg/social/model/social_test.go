@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestValidatePrivacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(p *Privacy)
+		wantErr bool
+	}{
+		{"Defaults", func(p *Privacy) {}, false},
+		{"Mixed case audience", func(p *Privacy) { p.Games = " Friends " }, false},
+		{"Unknown audience", func(p *Privacy) { p.Profile = "public" }, true},
+		{"Missing audience", func(p *Privacy) { p.Invites = "" }, true},
+		{"Friend requests from friends", func(p *Privacy) { p.FriendRequests = AudienceFriends }, true},
+		{"Negative fuzz", func(p *Privacy) { p.LocationFuzzKm = -1 }, true},
+		{"Fuzz too large", func(p *Privacy) { p.LocationFuzzKm = MaxLocationFuzzKm + 1 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultPrivacy(1)
+			tt.mutate(&p)
+			err := p.ValidatePrivacy()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePrivacy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package social
+
+import (
+	"errors"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/social/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+var (
+	ErrSelf             = errors.New("you cannot befriend yourself")
+	ErrAlreadyFriends   = errors.New("you are already friends")
+	ErrRequestPending   = errors.New("a friend request is already pending")
+	ErrRequestNotFound  = errors.New("no pending friend request from that user")
+	ErrNotFriends       = errors.New("you are not friends")
+	ErrRequestsDisabled = errors.New("this user is not accepting friend requests")
+	ErrPrivate          = errors.New("this user's profile is private")
+	ErrGamesPrivate     = errors.New("this user's games are private")
+	ErrInviteNotAllowed = errors.New("this user does not accept invites from you")
+)
+
+// Relation is how a viewer relates to the user whose data is being read. The
+// zero value is an anonymous stranger.
+type Relation struct {
+	Self   bool
+	Friend bool
+	// Blocked is true when either user has blocked the other, which hides
+	// everything regardless of the audience.
+	Blocked bool
+}
+
+// Allows reports whether audience includes a viewer with relation r.
+func Allows(audience string, r Relation) bool {
+	if r.Self {
+		return true
+	}
+	if r.Blocked {
+		return false
+	}
+	switch audience {
+	case model.AudienceEveryone:
+		return true
+	case model.AudienceFriends:
+		return r.Friend
+	}
+	return false
+}
+
+// CheckRequest validates sending a friend request to a user with the given
+// privacy settings. pendingFromThem requests are not an error: the caller
+// accepts them instead of sending a new one.
+func CheckRequest(r Relation, pendingFromMe bool, target model.Privacy) error {
+	switch {
+	case r.Self:
+		return ErrSelf
+	case r.Blocked:
+		return ErrRequestsDisabled
+	case r.Friend:
+		return ErrAlreadyFriends
+	case pendingFromMe:
+		return ErrRequestPending
+	case target.FriendRequests == model.AudienceNobody:
+		return ErrRequestsDisabled
+	}
+	return nil
+}
+
+// ApplyProfilePrivacy trims p down to what a viewer with relation r may see,
+// or returns ErrPrivate when they may not see the profile at all. Locations
+// visible to the viewer are still coarsened to the owner's fuzz radius unless
+// the viewer is the owner.
+func ApplyProfilePrivacy(p *usermodel.Profile, settings model.Privacy, r Relation) error {
+	if !Allows(settings.Profile, r) {
+		return ErrPrivate
+	}
+	if r.Self || p.Location == nil {
+		return nil
+	}
+	if !Allows(settings.Location, r) {
+		p.Location = nil
+		return nil
+	}
+	coarse := geo.Coarsen(*p.Location, settings.LocationFuzzKm)
+	p.Location = &coarse
+	return nil
+}
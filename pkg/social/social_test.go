@@ -0,0 +1,97 @@
+package social
+
+import (
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/social/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+var (
+	stranger = Relation{}
+	friend   = Relation{Friend: true}
+	self     = Relation{Self: true}
+)
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		audience string
+		r        Relation
+		want     bool
+	}{
+		{model.AudienceEveryone, stranger, true},
+		{model.AudienceFriends, stranger, false},
+		{model.AudienceFriends, friend, true},
+		{model.AudienceNobody, friend, false},
+		{model.AudienceNobody, self, true},
+		{model.AudienceEveryone, Relation{Blocked: true}, false},
+		{model.AudienceFriends, Relation{Friend: true, Blocked: true}, false},
+		{"", stranger, false},
+	}
+	for _, tt := range tests {
+		if got := Allows(tt.audience, tt.r); got != tt.want {
+			t.Errorf("Allows(%q, %+v) = %v, want %v", tt.audience, tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRequest(t *testing.T) {
+	open := model.DefaultPrivacy(2)
+	closed := open
+	closed.FriendRequests = model.AudienceNobody
+
+	tests := []struct {
+		name          string
+		r             Relation
+		pendingFromMe bool
+		target        model.Privacy
+		want          error
+	}{
+		{"Stranger", stranger, false, open, nil},
+		{"Self", self, false, open, ErrSelf},
+		{"Already friends", friend, false, open, ErrAlreadyFriends},
+		{"Already asked", stranger, true, open, ErrRequestPending},
+		{"Requests disabled", stranger, false, closed, ErrRequestsDisabled},
+		{"Blocked", Relation{Blocked: true}, false, open, ErrRequestsDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckRequest(tt.r, tt.pendingFromMe, tt.target); err != tt.want {
+				t.Errorf("CheckRequest = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProfilePrivacy(t *testing.T) {
+	home := geo.Point{Lat: 6.52437, Lng: 3.37921}
+	profile := func() *usermodel.Profile {
+		loc := home
+		return &usermodel.Profile{UserID: 2, Location: &loc}
+	}
+	settings := model.DefaultPrivacy(2)
+
+	p := profile()
+	if err := ApplyProfilePrivacy(p, settings, stranger); err != nil || p.Location != nil {
+		t.Errorf("stranger sees location %v (err %v), want it hidden", p.Location, err)
+	}
+
+	p = profile()
+	if err := ApplyProfilePrivacy(p, settings, friend); err != nil || p.Location == nil {
+		t.Fatalf("friend cannot see location (err %v)", err)
+	}
+	if *p.Location == home || geo.Distance(home, *p.Location) > settings.LocationFuzzKm {
+		t.Errorf("friend sees %v, want a point coarsened to within %.0fkm", *p.Location, settings.LocationFuzzKm)
+	}
+
+	p = profile()
+	if err := ApplyProfilePrivacy(p, settings, self); err != nil || *p.Location != home {
+		t.Errorf("owner sees %v, want the exact location", p.Location)
+	}
+
+	settings.Profile = model.AudienceFriends
+	if err := ApplyProfilePrivacy(profile(), settings, stranger); err != ErrPrivate {
+		t.Errorf("friends-only profile for stranger = %v, want %v", err, ErrPrivate)
+	}
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	mediamodel "github.com/dudeiebot/sportPeerGo/pkg/media/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+const (
+	RoleOwner   = "owner"
+	RoleCaptain = "captain"
+	RoleMember  = "member"
+
+	KindTeam = "team"
+	KindClub = "club"
+
+	InvitePending  = "pending"
+	InviteAccepted = "accepted"
+	InviteDeclined = "declined"
+
+	MaxNameLength = 60
+)
+
+type Team struct {
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Sport       string            `json:"sport"`
+	Kind        string            `json:"kind"`
+	Description string            `json:"description"`
+	OwnerID     int64             `json:"ownerId"`
+	MemberCount int               `json:"memberCount"`
+	Members     []Member          `json:"members,omitempty"`
+	Photo       *mediamodel.Image `json:"photo,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+type Member struct {
+	TeamID   int64     `json:"teamId"`
+	UserID   int64     `json:"userId"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+type Invite struct {
+	TeamID    int64     `json:"teamId"`
+	TeamName  string    `json:"teamName"`
+	UserID    int64     `json:"userId"`
+	InvitedBy int64     `json:"invitedBy"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Filter struct {
+	Sport  string
+	Name   string
+	Limit  int
+	Offset int
+}
+
+func (t *Team) ValidateTeam() error {
+	var errors []string
+
+	t.Name = strings.TrimSpace(t.Name)
+	t.Sport = usermodel.NormalizeSport(t.Sport)
+	t.Description = strings.TrimSpace(t.Description)
+	if t.Kind == "" {
+		t.Kind = KindTeam
+	}
+
+	if t.Name == "" || len(t.Name) > MaxNameLength {
+		errors = append(errors, fmt.Sprintf("Name must be between 1 and %d characters", MaxNameLength))
+	}
+	if t.Sport == "" {
+		errors = append(errors, "Sport is required")
+	}
+	if t.Kind != KindTeam && t.Kind != KindClub {
+		errors = append(errors, "Kind must be team or club")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package model
+
+import "testing"
+
+func TestValidateTeam(t *testing.T) {
+	tests := []struct {
+		name    string
+		team    Team
+		wantErr bool
+	}{
+		{"Valid team", Team{Name: "Tuesday FC", Sport: "Football"}, false},
+		{"Valid club", Team{Name: "Riverside Tennis", Sport: "tennis", Kind: KindClub}, false},
+		{"Missing name", Team{Name: "  ", Sport: "football"}, true},
+		{"Missing sport", Team{Name: "Tuesday FC"}, true},
+		{"Unknown kind", Team{Name: "Tuesday FC", Sport: "football", Kind: "league"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.team.ValidateTeam()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTeam() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	team := Team{Name: " Tuesday FC ", Sport: " Football "}
+	team.ValidateTeam()
+	if team.Name != "Tuesday FC" || team.Sport != "football" || team.Kind != KindTeam {
+		t.Errorf("ValidateTeam did not normalize: %+v", team)
+	}
+}
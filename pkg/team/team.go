@@ -0,0 +1,82 @@
+package team
+
+import (
+	"errors"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/team/model"
+)
+
+var (
+	ErrTeamNotFound    = errors.New("team not found")
+	ErrNotMember       = errors.New("not a member of this team")
+	ErrForbidden       = errors.New("your team role does not allow that")
+	ErrAlreadyMember   = errors.New("already a member of this team")
+	ErrInviteNotFound  = errors.New("no pending invite to this team")
+	ErrOwnerLeave      = errors.New("the owner cannot leave, transfer ownership first")
+	ErrInvalidRole     = errors.New("role must be captain or member")
+	ErrSelf            = errors.New("you cannot do that to yourself")
+	ErrAlreadyHasOwner = errors.New("use an ownership transfer to make someone owner")
+)
+
+var rank = map[string]int{
+	model.RoleMember:  1,
+	model.RoleCaptain: 2,
+	model.RoleOwner:   3,
+}
+
+// AtLeast reports whether role is min or above. Unknown roles rank below
+// every real one.
+func AtLeast(role, min string) bool {
+	return rank[role] >= rank[min] && rank[role] > 0
+}
+
+// CheckKick validates actor removing target. Owners may remove anyone else;
+// captains only plain members.
+func CheckKick(actor, target model.Member) error {
+	if actor.UserID == target.UserID {
+		return ErrSelf
+	}
+	if !AtLeast(actor.Role, model.RoleCaptain) || rank[target.Role] >= rank[actor.Role] {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CheckSetRole validates the owner promoting or demoting target.
+func CheckSetRole(actor, target model.Member, role string) error {
+	if role == model.RoleOwner {
+		return ErrAlreadyHasOwner
+	}
+	if role != model.RoleCaptain && role != model.RoleMember {
+		return ErrInvalidRole
+	}
+	if actor.Role != model.RoleOwner {
+		return ErrForbidden
+	}
+	if actor.UserID == target.UserID {
+		return ErrSelf
+	}
+	return nil
+}
+
+// CheckLeave validates a member leaving the team.
+func CheckLeave(m model.Member) error {
+	if m.Role == model.RoleOwner {
+		return ErrOwnerLeave
+	}
+	return nil
+}
+
+// Transfer hands ownership from owner to target. The previous owner stays on
+// as a captain.
+func Transfer(owner, target *model.Member) error {
+	if owner.Role != model.RoleOwner {
+		return ErrForbidden
+	}
+	if owner.UserID == target.UserID {
+		return ErrSelf
+	}
+	owner.Role = model.RoleCaptain
+	target.Role = model.RoleOwner
+	return nil
+}
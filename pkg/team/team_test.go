@@ -0,0 +1,104 @@
+package team
+
+import (
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/team/model"
+)
+
+var (
+	owner   = model.Member{UserID: 1, Role: model.RoleOwner}
+	captain = model.Member{UserID: 2, Role: model.RoleCaptain}
+	member  = model.Member{UserID: 3, Role: model.RoleMember}
+	other   = model.Member{UserID: 4, Role: model.RoleMember}
+)
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		role, min string
+		want      bool
+	}{
+		{model.RoleOwner, model.RoleCaptain, true},
+		{model.RoleCaptain, model.RoleCaptain, true},
+		{model.RoleMember, model.RoleCaptain, false},
+		{model.RoleMember, model.RoleMember, true},
+		{"", model.RoleMember, false},
+		{"admin", "", false},
+	}
+	for _, tt := range tests {
+		if got := AtLeast(tt.role, tt.min); got != tt.want {
+			t.Errorf("AtLeast(%q, %q) = %v, want %v", tt.role, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestCheckKick(t *testing.T) {
+	tests := []struct {
+		name          string
+		actor, target model.Member
+		want          error
+	}{
+		{"Owner kicks captain", owner, captain, nil},
+		{"Owner kicks member", owner, member, nil},
+		{"Captain kicks member", captain, member, nil},
+		{"Captain kicks owner", captain, owner, ErrForbidden},
+		{"Captain kicks captain", captain, model.Member{UserID: 9, Role: model.RoleCaptain}, ErrForbidden},
+		{"Member kicks member", member, other, ErrForbidden},
+		{"Kick self", owner, owner, ErrSelf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckKick(tt.actor, tt.target); err != tt.want {
+				t.Errorf("CheckKick = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSetRole(t *testing.T) {
+	tests := []struct {
+		name          string
+		actor, target model.Member
+		role          string
+		want          error
+	}{
+		{"Promote member", owner, member, model.RoleCaptain, nil},
+		{"Demote captain", owner, captain, model.RoleMember, nil},
+		{"Captain promotes", captain, member, model.RoleCaptain, ErrForbidden},
+		{"Make owner", owner, member, model.RoleOwner, ErrAlreadyHasOwner},
+		{"Unknown role", owner, member, "coach", ErrInvalidRole},
+		{"Demote self", owner, owner, model.RoleMember, ErrSelf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckSetRole(tt.actor, tt.target, tt.role); err != tt.want {
+				t.Errorf("CheckSetRole = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLeave(t *testing.T) {
+	if err := CheckLeave(owner); err != ErrOwnerLeave {
+		t.Errorf("owner leave = %v, want %v", err, ErrOwnerLeave)
+	}
+	if err := CheckLeave(captain); err != nil {
+		t.Errorf("captain leave = %v", err)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	o, m := owner, member
+	if err := Transfer(&m, &o); err != ErrForbidden {
+		t.Errorf("Transfer by member = %v, want %v", err, ErrForbidden)
+	}
+	if err := Transfer(&o, &o); err != ErrSelf {
+		t.Errorf("Transfer to self = %v, want %v", err, ErrSelf)
+	}
+	if err := Transfer(&o, &m); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if o.Role != model.RoleCaptain || m.Role != model.RoleOwner {
+		t.Errorf("after Transfer roles are %s and %s", o.Role, m.Role)
+	}
+}
@@ -0,0 +1,486 @@
+package tournament
+
+import (
+	"sort"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/tournament/model"
+)
+
+// Points for a round-robin win or draw.
+const (
+	PointsWin  = 3
+	PointsDraw = 1
+)
+
+// Generate draws the matches of t between entrants, who must be in seed
+// order. Matches get IDs from 1 in the order they should be stored and link
+// to each other by those IDs. Byes are already played out.
+func Generate(t model.Tournament, entrants []model.Entrant) ([]model.Match, error) {
+	min := model.MinEntrants
+	if t.Format == model.FormatDouble {
+		min = 3
+	}
+	if len(entrants) < min {
+		return nil, ErrNotEnoughEntrants
+	}
+
+	b := &builder{tournamentID: t.ID}
+	switch t.Format {
+	case model.FormatSingle:
+		b.elimination(entrants)
+	case model.FormatDouble:
+		b.doubleElimination(entrants)
+	default:
+		b.roundRobin(entrants, t.GroupSize)
+	}
+	d := newDraw(b.matches)
+	d.settle()
+	return b.matches, nil
+}
+
+type builder struct {
+	tournamentID int64
+	matches      []model.Match
+}
+
+func (b *builder) add(bracket string, round, position int) int64 {
+	id := int64(len(b.matches) + 1)
+	b.matches = append(b.matches, model.Match{
+		ID:           id,
+		TournamentID: b.tournamentID,
+		Bracket:      bracket,
+		Round:        round,
+		Position:     position,
+		Status:       model.MatchPending,
+	})
+	return id
+}
+
+func (b *builder) get(id int64) *model.Match {
+	return &b.matches[id-1]
+}
+
+func (b *builder) winnerTo(from, to int64, slot string) {
+	m := b.get(from)
+	m.NextMatchID, m.NextSlot = &to, slot
+}
+
+func (b *builder) loserTo(from, to int64, slot string) {
+	m := b.get(from)
+	m.LoserMatchID, m.LoserSlot = &to, slot
+}
+
+// elimination builds a winners bracket, padded with byes to a power of two
+// so that the top seeds get them, and returns the match IDs of each round.
+func (b *builder) elimination(entrants []model.Entrant) [][]int64 {
+	size := 2
+	for size < len(entrants) {
+		size *= 2
+	}
+	order := seedOrder(size)
+
+	var rounds [][]int64
+	first := make([]int64, size/2)
+	for i := range first {
+		id := b.add(model.BracketWinners, 1, i+1)
+		m := b.get(id)
+		if s := order[2*i]; s <= len(entrants) {
+			m.EntrantA = &entrants[s-1].ID
+		}
+		if s := order[2*i+1]; s <= len(entrants) {
+			m.EntrantB = &entrants[s-1].ID
+		}
+		first[i] = id
+	}
+	rounds = append(rounds, first)
+
+	for prev := first; len(prev) > 1; {
+		next := make([]int64, len(prev)/2)
+		for i := range next {
+			next[i] = b.add(model.BracketWinners, len(rounds)+1, i+1)
+			b.winnerTo(prev[2*i], next[i], model.SlotA)
+			b.winnerTo(prev[2*i+1], next[i], model.SlotB)
+		}
+		rounds = append(rounds, next)
+		prev = next
+	}
+	return rounds
+}
+
+// doubleElimination adds a losers bracket and a grand final to the winners
+// bracket. Losers of the first round meet each other; losers of each later
+// round drop in against the survivors of the losers bracket, in reverse
+// order every other round to put off rematches. The grand final is a single
+// match.
+func (b *builder) doubleElimination(entrants []model.Entrant) {
+	winners := b.elimination(entrants)
+
+	round := 1
+	var survivors []int64
+	first := winners[0]
+	for i := 0; i < len(first)/2; i++ {
+		id := b.add(model.BracketLosers, round, i+1)
+		b.loserTo(first[2*i], id, model.SlotA)
+		b.loserTo(first[2*i+1], id, model.SlotB)
+		survivors = append(survivors, id)
+	}
+
+	for r := 1; r < len(winners); r++ {
+		// Losers of winners round r+1 drop in.
+		round++
+		dropping := winners[r]
+		merged := make([]int64, len(survivors))
+		for i, from := range survivors {
+			id := b.add(model.BracketLosers, round, i+1)
+			b.winnerTo(from, id, model.SlotA)
+			drop := dropping[i]
+			if r%2 == 1 {
+				drop = dropping[len(dropping)-1-i]
+			}
+			b.loserTo(drop, id, model.SlotB)
+			merged[i] = id
+		}
+		survivors = merged
+		if len(survivors) == 1 {
+			break
+		}
+
+		// The survivors play each other down to half.
+		round++
+		halved := make([]int64, len(survivors)/2)
+		for i := range halved {
+			halved[i] = b.add(model.BracketLosers, round, i+1)
+			b.winnerTo(survivors[2*i], halved[i], model.SlotA)
+			b.winnerTo(survivors[2*i+1], halved[i], model.SlotB)
+		}
+		survivors = halved
+	}
+
+	final := b.add(model.BracketFinal, 1, 1)
+	b.winnerTo(winners[len(winners)-1][0], final, model.SlotA)
+	b.winnerTo(survivors[0], final, model.SlotB)
+}
+
+// roundRobin snakes the seeds into groups of at most groupSize, so every
+// group gets a fair share of strong entrants, and has everyone in a group
+// play each other once using the circle method.
+func (b *builder) roundRobin(entrants []model.Entrant, groupSize int) {
+	groups := (len(entrants) + groupSize - 1) / groupSize
+	members := make([][]int64, groups)
+	for i, e := range entrants {
+		col := i % groups
+		if (i/groups)%2 == 1 {
+			col = groups - 1 - col
+		}
+		members[col] = append(members[col], e.ID)
+	}
+
+	for g, ids := range members {
+		if len(ids)%2 == 1 {
+			ids = append(ids, 0)
+		}
+		n := len(ids)
+		for r := 0; r < n-1; r++ {
+			position := 0
+			for i := 0; i < n/2; i++ {
+				a, c := ids[i], ids[n-1-i]
+				if a == 0 || c == 0 {
+					continue
+				}
+				position++
+				id := b.add(model.BracketGroup, r+1, position)
+				m := b.get(id)
+				m.Group = g + 1
+				m.EntrantA, m.EntrantB = &a, &c
+				m.Status = model.MatchReady
+			}
+			// Keep the first entrant in place and rotate the rest.
+			last := ids[n-1]
+			copy(ids[2:], ids[1:n-1])
+			ids[1] = last
+		}
+	}
+}
+
+// seedOrder returns the seeds of a bracket of size in draw order, so that
+// pairing neighbours gives 1 v size, and the top two seeds can only meet in
+// the final.
+func seedOrder(size int) []int {
+	order := []int{1, 2}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, s := range order {
+			next = append(next, s, len(order)*2+1-s)
+		}
+		order = next
+	}
+	return order
+}
+
+// draw moves entrants through a bracket as matches finish.
+type draw struct {
+	matches []*model.Match
+	byID    map[int64]*model.Match
+	feeders map[int64]map[string]feeder
+	changed map[int64]bool
+}
+
+// feeder is the match that fills a slot, with its winner or its loser.
+type feeder struct {
+	id    int64
+	loser bool
+}
+
+func newDraw(matches []model.Match) *draw {
+	d := &draw{
+		byID:    make(map[int64]*model.Match, len(matches)),
+		feeders: make(map[int64]map[string]feeder),
+		changed: make(map[int64]bool),
+	}
+	for i := range matches {
+		m := &matches[i]
+		d.matches = append(d.matches, m)
+		d.byID[m.ID] = m
+	}
+	for _, m := range d.matches {
+		if m.NextMatchID != nil {
+			d.feed(*m.NextMatchID, m.NextSlot, feeder{id: m.ID})
+		}
+		if m.LoserMatchID != nil {
+			d.feed(*m.LoserMatchID, m.LoserSlot, feeder{id: m.ID, loser: true})
+		}
+	}
+	return d
+}
+
+func (d *draw) feed(to int64, slot string, f feeder) {
+	if d.feeders[to] == nil {
+		d.feeders[to] = make(map[string]feeder)
+	}
+	d.feeders[to][slot] = f
+}
+
+// Complete records the winner of match id, or a draw when winnerID is nil,
+// moves the entrants on and plays out any byes that follow. matches is the
+// whole draw of the tournament and is updated in place; the matches that
+// changed are returned.
+func Complete(matches []model.Match, id int64, winnerID *int64) ([]model.Match, error) {
+	d := newDraw(matches)
+	m, ok := d.byID[id]
+	if !ok {
+		return nil, ErrMatchNotFound
+	}
+	if m.Status != model.MatchReady {
+		return nil, ErrMatchNotReady
+	}
+
+	switch {
+	case winnerID == nil:
+		if m.Bracket != model.BracketGroup {
+			return nil, ErrDrawNotAllowed
+		}
+		m.Status = model.MatchCompleted
+		d.changed[m.ID] = true
+	case *winnerID == *m.EntrantA:
+		d.finish(m, *m.EntrantA, m.EntrantB, model.MatchCompleted)
+	case *winnerID == *m.EntrantB:
+		d.finish(m, *m.EntrantB, m.EntrantA, model.MatchCompleted)
+	default:
+		return nil, ErrNotInMatch
+	}
+	d.settle()
+
+	var out []model.Match
+	for _, m := range d.matches {
+		if d.changed[m.ID] {
+			out = append(out, *m)
+		}
+	}
+	return out, nil
+}
+
+func (d *draw) finish(m *model.Match, winner int64, loser *int64, status string) {
+	m.WinnerID = &winner
+	m.Status = status
+	d.changed[m.ID] = true
+	if m.NextMatchID != nil {
+		d.place(*m.NextMatchID, m.NextSlot, winner)
+	}
+	if loser != nil && m.LoserMatchID != nil {
+		d.place(*m.LoserMatchID, m.LoserSlot, *loser)
+	}
+}
+
+func (d *draw) place(id int64, slot string, entrant int64) {
+	m := d.byID[id]
+	if slot == model.SlotA {
+		m.EntrantA = &entrant
+	} else {
+		m.EntrantB = &entrant
+	}
+	d.changed[id] = true
+}
+
+// settle marks matches with both entrants ready, sends lone entrants
+// through byes and voids matches nobody can reach, until nothing changes.
+func (d *draw) settle() {
+	for again := true; again; {
+		again = false
+		for _, m := range d.matches {
+			if m.Bracket == model.BracketGroup || m.Status != model.MatchPending {
+				continue
+			}
+			deadA, deadB := d.dead(m, model.SlotA), d.dead(m, model.SlotB)
+			switch {
+			case m.EntrantA != nil && m.EntrantB != nil:
+				m.Status = model.MatchReady
+				d.changed[m.ID] = true
+			case deadA && deadB:
+				m.Status = model.MatchVoid
+				d.changed[m.ID] = true
+				again = true
+			case m.EntrantA != nil && deadB:
+				d.finish(m, *m.EntrantA, nil, model.MatchBye)
+				again = true
+			case m.EntrantB != nil && deadA:
+				d.finish(m, *m.EntrantB, nil, model.MatchBye)
+				again = true
+			}
+		}
+	}
+}
+
+// dead reports whether slot of m is empty and will never be filled: it has
+// no feeder, its feeder was void, or it waits for the loser of a bye.
+func (d *draw) dead(m *model.Match, slot string) bool {
+	if slot == model.SlotA && m.EntrantA != nil || slot == model.SlotB && m.EntrantB != nil {
+		return false
+	}
+	f, ok := d.feeders[m.ID][slot]
+	if !ok {
+		return true
+	}
+	switch d.byID[f.id].Status {
+	case model.MatchVoid:
+		return true
+	case model.MatchBye:
+		return f.loser
+	}
+	return false
+}
+
+// Finished reports whether every match of the draw is decided, and for
+// elimination formats who won.
+func Finished(matches []model.Match) (bool, *int64) {
+	var champion *int64
+	for _, m := range matches {
+		switch m.Status {
+		case model.MatchPending, model.MatchReady:
+			return false, nil
+		}
+		if m.Bracket != model.BracketGroup && m.NextMatchID == nil {
+			champion = m.WinnerID
+		}
+	}
+	return true, champion
+}
+
+// Tree arranges an elimination draw as a tree from the final, each match
+// holding the matches whose winners fill its slots. In double elimination
+// the grand final's B side is the losers bracket. Losers dropping out of
+// the winners bracket show as entrants rather than branches.
+func Tree(matches []model.Match) *model.Node {
+	d := newDraw(matches)
+	var root *model.Match
+	for _, m := range d.matches {
+		if m.Bracket != model.BracketGroup && m.NextMatchID == nil {
+			root = m
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	var node func(m *model.Match) *model.Node
+	node = func(m *model.Match) *model.Node {
+		n := &model.Node{Match: *m}
+		if f, ok := d.feeders[m.ID][model.SlotA]; ok && !f.loser {
+			n.A = node(d.byID[f.id])
+		}
+		if f, ok := d.feeders[m.ID][model.SlotB]; ok && !f.loser {
+			n.B = node(d.byID[f.id])
+		}
+		return n
+	}
+	return node(root)
+}
+
+// Groups returns every round-robin group with its matches and standings,
+// ranked by points, then wins, then entrant ID.
+func Groups(matches []model.Match) []model.Group {
+	byGroup := make(map[int]*model.Group)
+	records := make(map[int]map[int64]*model.Standing)
+	var order []int
+	record := func(g int, id int64) *model.Standing {
+		if records[g][id] == nil {
+			records[g][id] = &model.Standing{EntrantID: id}
+		}
+		return records[g][id]
+	}
+
+	for _, m := range matches {
+		if m.Bracket != model.BracketGroup {
+			continue
+		}
+		g := byGroup[m.Group]
+		if g == nil {
+			g = &model.Group{Group: m.Group}
+			byGroup[m.Group] = g
+			records[m.Group] = make(map[int64]*model.Standing)
+			order = append(order, m.Group)
+		}
+		g.Matches = append(g.Matches, m)
+
+		a, b := record(m.Group, *m.EntrantA), record(m.Group, *m.EntrantB)
+		if m.Status != model.MatchCompleted {
+			continue
+		}
+		a.Played++
+		b.Played++
+		switch {
+		case m.WinnerID == nil:
+			a.Drawn++
+			b.Drawn++
+			a.Points += PointsDraw
+			b.Points += PointsDraw
+		case *m.WinnerID == *m.EntrantA:
+			a.Won++
+			b.Lost++
+			a.Points += PointsWin
+		default:
+			b.Won++
+			a.Lost++
+			b.Points += PointsWin
+		}
+	}
+
+	sort.Ints(order)
+	out := make([]model.Group, 0, len(order))
+	for _, n := range order {
+		g := byGroup[n]
+		for _, s := range records[n] {
+			g.Standings = append(g.Standings, *s)
+		}
+		sort.Slice(g.Standings, func(i, j int) bool {
+			a, b := g.Standings[i], g.Standings[j]
+			if a.Points != b.Points {
+				return a.Points > b.Points
+			}
+			if a.Won != b.Won {
+				return a.Won > b.Won
+			}
+			return a.EntrantID < b.EntrantID
+		})
+		out = append(out, *g)
+	}
+	return out
+}
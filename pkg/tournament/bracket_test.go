@@ -0,0 +1,233 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/tournament/model"
+)
+
+// seeded returns n entrants in seed order, with entrant ID 100+seed.
+func seeded(n int) []model.Entrant {
+	out := make([]model.Entrant, n)
+	for i := range out {
+		out[i] = model.Entrant{ID: int64(101 + i), Seed: i + 1}
+	}
+	return out
+}
+
+func generate(t *testing.T, format string, n int) []model.Match {
+	t.Helper()
+	matches, err := Generate(model.Tournament{Format: format, GroupSize: 4}, seeded(n))
+	if err != nil {
+		t.Fatalf("Generate(%s, %d) error = %v", format, n, err)
+	}
+	return matches
+}
+
+// play completes the first ready match, always won by the better seed, and
+// reports whether there was one.
+func play(t *testing.T, matches []model.Match) bool {
+	t.Helper()
+	for _, m := range matches {
+		if m.Status != model.MatchReady {
+			continue
+		}
+		winner := m.EntrantA
+		if *m.EntrantB < *m.EntrantA {
+			winner = m.EntrantB
+		}
+		if _, err := Complete(matches, m.ID, winner); err != nil {
+			t.Fatalf("Complete(%d) error = %v", m.ID, err)
+		}
+		return true
+	}
+	return false
+}
+
+func playOut(t *testing.T, matches []model.Match) *int64 {
+	t.Helper()
+	for play(t, matches) {
+	}
+	done, champion := Finished(matches)
+	if !done {
+		t.Fatal("draw not finished after every ready match was played")
+	}
+	return champion
+}
+
+func TestSeedOrder(t *testing.T) {
+	if got, want := seedOrder(8), []int{1, 8, 4, 5, 2, 7, 3, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("seedOrder(8) = %v, want %v", got, want)
+	}
+}
+
+func TestSingleEliminationWithByes(t *testing.T) {
+	matches := generate(t, model.FormatSingle, 5)
+	if len(matches) != 7 {
+		t.Fatalf("got %d matches, want 7", len(matches))
+	}
+
+	statuses := make(map[string]int)
+	for _, m := range matches[:4] {
+		statuses[m.Status]++
+	}
+	if statuses[model.MatchBye] != 3 || statuses[model.MatchReady] != 1 {
+		t.Errorf("first round statuses = %v, want 3 byes and 1 ready", statuses)
+	}
+	// Seeds 2 and 3 both had byes into the same second round match.
+	if m := matches[5]; m.Status != model.MatchReady || *m.EntrantA != 102 || *m.EntrantB != 103 {
+		t.Errorf("second round match = %+v, want seeds 2 v 3 ready", m)
+	}
+
+	if champion := playOut(t, matches); champion == nil || *champion != 101 {
+		t.Errorf("champion = %v, want top seed", champion)
+	}
+
+	tree := Tree(matches)
+	if tree == nil || tree.A == nil || tree.B == nil || tree.A.A == nil || tree.Round != 3 {
+		t.Fatalf("Tree = %+v, want the final with both semi-finals below it", tree)
+	}
+}
+
+func TestDoubleElimination(t *testing.T) {
+	for _, n := range []int{3, 4, 6, 8} {
+		matches := generate(t, model.FormatDouble, n)
+
+		// The second seed loses to the top seed in the winners final but
+		// comes back through the losers bracket.
+		if champion := playOut(t, matches); champion == nil || *champion != 101 {
+			t.Errorf("%d entrants: champion = %v, want top seed", n, champion)
+		}
+		final := matches[len(matches)-1]
+		if final.Bracket != model.BracketFinal || *final.EntrantB != 102 {
+			t.Errorf("%d entrants: grand final = %+v, want seed 2 from the losers bracket", n, final)
+		}
+
+		losses := make(map[int64]int)
+		for _, m := range matches {
+			if m.Status != model.MatchCompleted {
+				continue
+			}
+			loser := *m.EntrantA
+			if loser == *m.WinnerID {
+				loser = *m.EntrantB
+			}
+			losses[loser]++
+		}
+		for id, l := range losses {
+			if l > 2 || (l == 2 && id == 101) {
+				t.Errorf("%d entrants: entrant %d lost %d times", n, id, l)
+			}
+		}
+		for id := int64(103); id <= int64(100+n); id++ {
+			if losses[id] != 2 {
+				t.Errorf("%d entrants: entrant %d was knocked out after %d losses", n, id, losses[id])
+			}
+		}
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	matches := generate(t, model.FormatRoundRobin, 5)
+
+	// Snake seeding puts seeds 1, 4 and 5 in group 1, and 2 and 3 in group 2.
+	pairs := make(map[[2]int64]bool)
+	for _, m := range matches {
+		if m.Status != model.MatchReady {
+			t.Errorf("group match %d is %s, want ready", m.ID, m.Status)
+		}
+		a, b := *m.EntrantA, *m.EntrantB
+		if a > b {
+			a, b = b, a
+		}
+		if pairs[[2]int64{a, b}] {
+			t.Errorf("%d v %d drawn twice", a, b)
+		}
+		pairs[[2]int64{a, b}] = true
+	}
+	want := [][2]int64{{101, 104}, {101, 105}, {104, 105}, {102, 103}}
+	if len(pairs) != len(want) {
+		t.Fatalf("got %d matches, want %d", len(pairs), len(want))
+	}
+	for _, p := range want {
+		if !pairs[p] {
+			t.Errorf("missing %d v %d", p[0], p[1])
+		}
+	}
+
+	for _, m := range matches {
+		var winner *int64
+		if m.Group == 1 && *m.EntrantA != 101 && *m.EntrantB != 101 {
+			winner = nil // 104 and 105 draw
+		} else {
+			winner = m.EntrantA
+			if *m.EntrantB < *m.EntrantA {
+				winner = m.EntrantB
+			}
+		}
+		if _, err := Complete(matches, m.ID, winner); err != nil {
+			t.Fatalf("Complete(%d) error = %v", m.ID, err)
+		}
+	}
+	if done, champion := Finished(matches); !done || champion != nil {
+		t.Errorf("Finished = %v, %v, want done without a champion", done, champion)
+	}
+
+	groups := Groups(matches)
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	top := groups[0].Standings[0]
+	if top.EntrantID != 101 || top.Points != 2*PointsWin || top.Won != 2 {
+		t.Errorf("group 1 leader = %+v", top)
+	}
+	if s := groups[0].Standings[1]; s.Drawn != 1 || s.Points != PointsDraw {
+		t.Errorf("group 1 runner-up = %+v, want one draw", s)
+	}
+}
+
+func TestCompleteErrors(t *testing.T) {
+	matches := generate(t, model.FormatSingle, 4)
+	ready := matches[0]
+	outsider := int64(999)
+
+	tests := []struct {
+		name   string
+		id     int64
+		winner *int64
+		want   error
+	}{
+		{"Draw in elimination", ready.ID, nil, ErrDrawNotAllowed},
+		{"Winner not in match", ready.ID, &outsider, ErrNotInMatch},
+		{"Match still waiting", matches[2].ID, &outsider, ErrMatchNotReady},
+		{"Unknown match", 42, ready.EntrantA, ErrMatchNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Complete(matches, tt.id, tt.winner); err != tt.want {
+				t.Errorf("Complete = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	changed, err := Complete(matches, ready.ID, ready.EntrantA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 2 || changed[1].ID != matches[2].ID {
+		t.Errorf("changed = %+v, want the match and the final", changed)
+	}
+	if _, err := Complete(matches, ready.ID, ready.EntrantA); err != ErrMatchNotReady {
+		t.Errorf("completing twice = %v, want %v", err, ErrMatchNotReady)
+	}
+}
+
+func TestGenerateNeedsEntrants(t *testing.T) {
+	if _, err := Generate(model.Tournament{Format: model.FormatSingle}, seeded(1)); err != ErrNotEnoughEntrants {
+		t.Errorf("single elimination with 1 entrant = %v", err)
+	}
+	if _, err := Generate(model.Tournament{Format: model.FormatDouble}, seeded(2)); err != ErrNotEnoughEntrants {
+		t.Errorf("double elimination with 2 entrants = %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+const (
+	FormatSingle     = "single_elimination"
+	FormatDouble     = "double_elimination"
+	FormatRoundRobin = "round_robin"
+
+	EntrantPlayer = "player"
+	EntrantTeam   = "team"
+
+	SeedingManual = "manual"
+	SeedingRating = "rating"
+
+	StatusRegistration = "registration"
+	StatusInProgress   = "in_progress"
+	StatusCompleted    = "completed"
+	StatusCancelled    = "cancelled"
+
+	BracketWinners = "winners"
+	BracketLosers  = "losers"
+	BracketFinal   = "final"
+	BracketGroup   = "group"
+
+	// MatchPending waits for one or both entrants.
+	MatchPending = "pending"
+	// MatchReady has both entrants and can be played.
+	MatchReady = "ready"
+	// MatchCompleted has a winner, or a draw in a group.
+	MatchCompleted = "completed"
+	// MatchBye had a single entrant, who went through without playing.
+	MatchBye = "bye"
+	// MatchVoid had no entrants at all and is never played.
+	MatchVoid = "void"
+
+	SlotA = "a"
+	SlotB = "b"
+
+	MinEntrants      = 2
+	MaxEntrants      = 128
+	DefaultGroupSize = 4
+	MaxNameLength    = 100
+)
+
+type Tournament struct {
+	ID          int64     `json:"id"`
+	OrganizerID int64     `json:"organizerId"`
+	Name        string    `json:"name"`
+	Sport       string    `json:"sport"`
+	Format      string    `json:"format"`
+	EntrantType string    `json:"entrantType"`
+	Seeding     string    `json:"seeding"`
+	MaxEntrants int       `json:"maxEntrants"`
+	GroupSize   int       `json:"groupSize,omitempty"`
+	Status      string    `json:"status"`
+	StartsAt    time.Time `json:"startsAt"`
+	// ChampionID is the winning entrant of a finished elimination tournament.
+	ChampionID *int64    `json:"championId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Entrant is a player or a team registered for a tournament. Seed is 0
+// until the tournament starts, unless the organizer seeded it by hand.
+type Entrant struct {
+	ID           int64     `json:"id"`
+	TournamentID int64     `json:"tournamentId"`
+	UserID       *int64    `json:"userId,omitempty"`
+	TeamID       *int64    `json:"teamId,omitempty"`
+	Name         string    `json:"name"`
+	Seed         int       `json:"seed"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+// Match is one match of a bracket or group. Winners move on to NextMatchID
+// in NextSlot and, in double elimination, losers drop to LoserMatchID.
+type Match struct {
+	ID           int64  `json:"id"`
+	TournamentID int64  `json:"tournamentId"`
+	Bracket      string `json:"bracket"`
+	Round        int    `json:"round"`
+	Position     int    `json:"position"`
+	Group        int    `json:"group,omitempty"`
+	EntrantA     *int64 `json:"entrantA,omitempty"`
+	EntrantB     *int64 `json:"entrantB,omitempty"`
+	// WinnerID is nil for an unfinished match and for a drawn group match.
+	WinnerID     *int64 `json:"winnerId,omitempty"`
+	Status       string `json:"status"`
+	GameID       *int64 `json:"gameId,omitempty"`
+	NextMatchID  *int64 `json:"nextMatchId,omitempty"`
+	NextSlot     string `json:"nextSlot,omitempty"`
+	LoserMatchID *int64 `json:"loserMatchId,omitempty"`
+	LoserSlot    string `json:"loserSlot,omitempty"`
+}
+
+// Node is a match in the bracket tree, with the matches whose winners feed
+// each of its slots.
+type Node struct {
+	Match
+	A *Node `json:"a,omitempty"`
+	B *Node `json:"b,omitempty"`
+}
+
+// Standing is an entrant's record in a round-robin group.
+type Standing struct {
+	EntrantID int64 `json:"entrantId"`
+	Played    int   `json:"played"`
+	Won       int   `json:"won"`
+	Drawn     int   `json:"drawn"`
+	Lost      int   `json:"lost"`
+	Points    int   `json:"points"`
+}
+
+type Group struct {
+	Group     int        `json:"group"`
+	Standings []Standing `json:"standings"`
+	Matches   []Match    `json:"matches"`
+}
+
+// Bracket is the whole draw of a tournament. Elimination formats fill Root
+// and round robin fills Groups.
+type Bracket struct {
+	Tournament Tournament `json:"tournament"`
+	Entrants   []Entrant  `json:"entrants"`
+	Root       *Node      `json:"root,omitempty"`
+	Groups     []Group    `json:"groups,omitempty"`
+}
+
+type Filter struct {
+	Sport  string
+	Status string
+	Limit  int
+	Offset int
+}
+
+func (t *Tournament) ValidateTournament() error {
+	var errors []string
+
+	t.Name = strings.TrimSpace(t.Name)
+	t.Sport = usermodel.NormalizeSport(t.Sport)
+	if t.EntrantType == "" {
+		t.EntrantType = EntrantPlayer
+	}
+	if t.Seeding == "" {
+		t.Seeding = SeedingRating
+	}
+	if t.Format == FormatRoundRobin && t.GroupSize == 0 {
+		t.GroupSize = DefaultGroupSize
+	}
+
+	if t.Name == "" || len(t.Name) > MaxNameLength {
+		errors = append(errors, fmt.Sprintf("Name is required and cannot exceed %d characters", MaxNameLength))
+	}
+	if t.Sport == "" {
+		errors = append(errors, "Sport is required")
+	}
+	switch t.Format {
+	case FormatSingle, FormatDouble:
+		t.GroupSize = 0
+	case FormatRoundRobin:
+		if t.GroupSize < 2 || t.GroupSize > MaxEntrants {
+			errors = append(errors, fmt.Sprintf("Group size must be between 2 and %d", MaxEntrants))
+		}
+	default:
+		errors = append(errors, "Format must be single_elimination, double_elimination or round_robin")
+	}
+	if t.EntrantType != EntrantPlayer && t.EntrantType != EntrantTeam {
+		errors = append(errors, "Entrant type must be player or team")
+	}
+	if t.Seeding != SeedingManual && t.Seeding != SeedingRating {
+		errors = append(errors, "Seeding must be manual or rating")
+	}
+	min := MinEntrants
+	if t.Format == FormatDouble {
+		// With two entrants the losers bracket would only be a rematch.
+		min = 3
+	}
+	if t.MaxEntrants < min || t.MaxEntrants > MaxEntrants {
+		errors = append(errors, fmt.Sprintf("Max entrants must be between %d and %d", min, MaxEntrants))
+	}
+	if t.StartsAt.IsZero() {
+		errors = append(errors, "Start time is required")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
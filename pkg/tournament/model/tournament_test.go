@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTournament(t *testing.T) {
+	starts := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	valid := func(format string) Tournament {
+		return Tournament{Name: "Summer Open", Sport: "Tennis", Format: format, MaxEntrants: 16, StartsAt: starts}
+	}
+	tooSmall := valid(FormatDouble)
+	tooSmall.MaxEntrants = 2
+	noStart := valid(FormatSingle)
+	noStart.StartsAt = time.Time{}
+	badGroups := valid(FormatRoundRobin)
+	badGroups.GroupSize = 1
+	teams := valid(FormatSingle)
+	teams.EntrantType = EntrantTeam
+	teams.Seeding = SeedingManual
+
+	tests := []struct {
+		name    string
+		t       Tournament
+		wantErr bool
+	}{
+		{"Single elimination", valid(FormatSingle), false},
+		{"Round robin", valid(FormatRoundRobin), false},
+		{"Teams seeded by hand", teams, false},
+		{"Unknown format", valid("swiss"), true},
+		{"Double elimination for two", tooSmall, true},
+		{"No start", noStart, true},
+		{"Group of one", badGroups, true},
+		{"No name", Tournament{Sport: "tennis", Format: FormatSingle, MaxEntrants: 8, StartsAt: starts}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.t.ValidateTournament(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTournament() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	rr := valid(FormatRoundRobin)
+	if err := rr.ValidateTournament(); err != nil {
+		t.Fatal(err)
+	}
+	if rr.GroupSize != DefaultGroupSize || rr.EntrantType != EntrantPlayer || rr.Seeding != SeedingRating {
+		t.Errorf("defaults not applied: %+v", rr)
+	}
+}
@@ -0,0 +1,104 @@
+package tournament
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/tournament/model"
+)
+
+var (
+	ErrTournamentNotFound = errors.New("tournament not found")
+	ErrNotOrganizer       = errors.New("only the organizer can manage this tournament")
+	ErrRegistrationClosed = errors.New("registration for this tournament is closed")
+	ErrTournamentFull     = errors.New("this tournament is full")
+	ErrAlreadyRegistered  = errors.New("already registered for this tournament")
+	ErrNotRegistered      = errors.New("not registered for this tournament")
+	ErrWrongEntrantType   = errors.New("this tournament is not open to that kind of entrant")
+	ErrTeamSport          = errors.New("the team does not play this tournament's sport")
+	ErrNotEnoughEntrants  = errors.New("not enough entrants to start the tournament")
+	ErrAlreadyStarted     = errors.New("the tournament has already started")
+	ErrNotStarted         = errors.New("the tournament has not started")
+	ErrMatchNotFound      = errors.New("match not found in this tournament")
+	ErrMatchNotReady      = errors.New("this match is not ready to be played")
+	ErrMatchLinked        = errors.New("this match already has a game")
+	ErrGameMismatch       = errors.New("the game must be a game of the tournament's sport that you organize")
+	ErrNotInMatch         = errors.New("the winner must be one of the match's entrants")
+	ErrDrawNotAllowed     = errors.New("elimination matches cannot end in a draw")
+	ErrResultMismatch     = errors.New("the result's sides do not match the entrants of the match")
+	ErrInvalidSeed        = errors.New("seeds must be unique and between 1 and the number of entrants")
+)
+
+// CanRegister checks that t still takes entrants, given how many it has.
+func CanRegister(t model.Tournament, entrants int, now time.Time) error {
+	if t.Status != model.StatusRegistration || !now.Before(t.StartsAt) {
+		return ErrRegistrationClosed
+	}
+	if entrants >= t.MaxEntrants {
+		return ErrTournamentFull
+	}
+	return nil
+}
+
+// Seed orders entrants for the draw and numbers them from 1. Manual seeding
+// keeps the organizer's seeds and puts unseeded entrants after them, in
+// registration order. Rating seeding ranks entrants by ratings, keyed by
+// entrant ID, highest first. entrants must be in registration order.
+func Seed(entrants []model.Entrant, mode string, ratings map[int64]float64) []model.Entrant {
+	out := append([]model.Entrant{}, entrants...)
+	if mode == model.SeedingRating {
+		sort.SliceStable(out, func(i, j int) bool {
+			return ratings[out[i].ID] > ratings[out[j].ID]
+		})
+	} else {
+		sort.SliceStable(out, func(i, j int) bool {
+			a, b := out[i].Seed, out[j].Seed
+			if a == 0 || b == 0 {
+				return a != 0 && b == 0
+			}
+			return a < b
+		})
+	}
+	for i := range out {
+		out[i].Seed = i + 1
+	}
+	return out
+}
+
+// ValidSeeds checks manual seeds: each in range and none repeated. Zero
+// leaves an entrant unseeded.
+func ValidSeeds(seeds map[int64]int, entrants int) error {
+	used := make(map[int]bool, len(seeds))
+	for _, seed := range seeds {
+		if seed == 0 {
+			continue
+		}
+		if seed < 0 || seed > entrants || used[seed] {
+			return ErrInvalidSeed
+		}
+		used[seed] = true
+	}
+	return nil
+}
+
+// WinningEntrant works out which entrant of m won the game result r, given
+// the players of each entrant. It returns nil for a draw.
+func WinningEntrant(m model.Match, playersA, playersB map[int64]bool, r resultmodel.Result) (*int64, error) {
+	if m.EntrantA == nil || m.EntrantB == nil {
+		return nil, ErrMatchNotReady
+	}
+	sideA := r.SideOfPlayers(playersA)
+	sideB := r.SideOfPlayers(playersB)
+	if sideA == "" || sideB == "" || sideA == sideB {
+		return nil, ErrResultMismatch
+	}
+	switch r.Winner {
+	case sideA:
+		return m.EntrantA, nil
+	case sideB:
+		return m.EntrantB, nil
+	}
+	return nil, nil
+}
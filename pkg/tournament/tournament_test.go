@@ -0,0 +1,132 @@
+package tournament
+
+import (
+	"testing"
+	"time"
+
+	resultmodel "github.com/dudeiebot/sportPeerGo/pkg/result/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/tournament/model"
+)
+
+func ids(entrants []model.Entrant) []int64 {
+	out := make([]int64, len(entrants))
+	for i, e := range entrants {
+		out[i] = e.ID
+	}
+	return out
+}
+
+func TestSeed(t *testing.T) {
+	registered := []model.Entrant{{ID: 1}, {ID: 2, Seed: 2}, {ID: 3}, {ID: 4, Seed: 1}}
+
+	manual := Seed(registered, model.SeedingManual, nil)
+	if got, want := ids(manual), []int64{4, 2, 1, 3}; !equal(got, want) {
+		t.Errorf("manual seeding = %v, want %v", got, want)
+	}
+	for i, e := range manual {
+		if e.Seed != i+1 {
+			t.Errorf("entrant %d seeded %d, want %d", e.ID, e.Seed, i+1)
+		}
+	}
+
+	ratings := map[int64]float64{1: 1500, 2: 1700, 4: 1500}
+	if got, want := ids(Seed(registered, model.SeedingRating, ratings)), []int64{2, 1, 4, 3}; !equal(got, want) {
+		t.Errorf("rating seeding = %v, want %v", got, want)
+	}
+	if registered[0].Seed != 0 {
+		t.Error("Seed changed its input")
+	}
+}
+
+func TestValidSeeds(t *testing.T) {
+	tests := []struct {
+		name  string
+		seeds map[int64]int
+		want  error
+	}{
+		{"Some seeded", map[int64]int{1: 1, 2: 0, 3: 2}, nil},
+		{"Repeated", map[int64]int{1: 1, 2: 1}, ErrInvalidSeed},
+		{"Out of range", map[int64]int{1: 4}, ErrInvalidSeed},
+		{"Negative", map[int64]int{1: -1}, ErrInvalidSeed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidSeeds(tt.seeds, 3); err != tt.want {
+				t.Errorf("ValidSeeds = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanRegister(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	open := model.Tournament{Status: model.StatusRegistration, MaxEntrants: 4, StartsAt: now.Add(time.Hour)}
+	started := open
+	started.Status = model.StatusInProgress
+	late := open
+	late.StartsAt = now
+
+	tests := []struct {
+		name     string
+		t        model.Tournament
+		entrants int
+		want     error
+	}{
+		{"Open", open, 3, nil},
+		{"Full", open, 4, ErrTournamentFull},
+		{"Started", started, 0, ErrRegistrationClosed},
+		{"Start time passed", late, 0, ErrRegistrationClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CanRegister(tt.t, tt.entrants, now); err != tt.want {
+				t.Errorf("CanRegister = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWinningEntrant(t *testing.T) {
+	a, b := int64(11), int64(12)
+	m := model.Match{EntrantA: &a, EntrantB: &b}
+	teamA := map[int64]bool{1: true, 2: true}
+	teamB := map[int64]bool{3: true, 4: true}
+
+	tests := []struct {
+		name    string
+		r       resultmodel.Result
+		want    *int64
+		wantErr error
+	}{
+		{"A on side a wins", resultmodel.Result{SideA: []int64{1, 2}, SideB: []int64{3, 4}, Winner: "a"}, &a, nil},
+		{"A on side b wins", resultmodel.Result{SideA: []int64{3}, SideB: []int64{1}, Winner: "b"}, &a, nil},
+		{"B wins", resultmodel.Result{SideA: []int64{4}, SideB: []int64{2}, Winner: "a"}, &b, nil},
+		{"Only one entrant played", resultmodel.Result{SideA: []int64{1}, SideB: []int64{2}, Winner: "a"}, nil, ErrResultMismatch},
+		{"Draw", resultmodel.Result{SideA: []int64{2}, SideB: []int64{3}, Winner: resultmodel.Draw}, nil, nil},
+		{"Mixed sides", resultmodel.Result{SideA: []int64{1, 3}, SideB: []int64{2, 4}, Winner: "a"}, nil, ErrResultMismatch},
+		{"Stranger game", resultmodel.Result{SideA: []int64{7}, SideB: []int64{8}, Winner: "a"}, nil, ErrResultMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WinningEntrant(m, teamA, teamB, tt.r)
+			if err != tt.wantErr {
+				t.Fatalf("WinningEntrant error = %v, want %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("WinningEntrant = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
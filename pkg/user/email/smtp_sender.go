@@ -67,6 +67,12 @@ func SendOtpEmail(info *UserInfo, r *http.Request) error {
 	return SendEmail(info.RecipientEmail, "Password Changing Link", content, r)
 }
 
+// SendNotificationEmail is the email channel of in-app notifications, used
+// both for single notifications and for digests.
+func SendNotificationEmail(recipientEmail, subject, content string) error {
+	return SendEmail(recipientEmail, subject, content, nil)
+}
+
 func getScheme(r *http.Request) string {
 	if r.TLS != nil {
 		return "https"
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	achievementmodel "github.com/dudeiebot/sportPeerGo/pkg/achievement/model"
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	mediamodel "github.com/dudeiebot/sportPeerGo/pkg/media/model"
+)
+
+const (
+	MinSkill = 1
+	MaxSkill = 10
+
+	MaxCityLength = 100
+)
+
+type SportSkill struct {
+	Sport string `json:"sport"`
+	Skill int    `json:"skill"`
+	// Rating is the user's rating from confirmed results, 0 until they have
+	// one. It is read-only and ignored when the profile is saved.
+	Rating      int  `json:"rating,omitempty"`
+	Provisional bool `json:"provisional,omitempty"`
+}
+
+// Availability is a weekly window, in minutes from midnight, when the user is
+// usually free to play.
+type Availability struct {
+	Weekday     int `json:"weekday"`
+	StartMinute int `json:"startMinute"`
+	EndMinute   int `json:"endMinute"`
+}
+
+type Profile struct {
+	UserID   int    `json:"userId"`
+	Username string `json:"username"`
+	Bio      string `json:"bio"`
+	// Avatar is read-only here; it is uploaded through its own endpoint.
+	Avatar *mediamodel.Image `json:"avatar,omitempty"`
+	// City places the user on their city's leaderboards. Unlike Location it
+	// is always public, so users only set it if they want to be ranked there.
+	City         string         `json:"city,omitempty"`
+	Location     *geo.Point     `json:"location,omitempty"`
+	Sports       []SportSkill   `json:"sports"`
+	Availability []Availability `json:"availability"`
+	// Badges are read-only; they are earned by playing.
+	Badges []achievementmodel.Badge `json:"badges"`
+}
+
+func (p *Profile) ValidateProfile() error {
+	var errors []string
+
+	p.City = NormalizeCity(p.City)
+	if len(p.City) > MaxCityLength {
+		errors = append(errors, fmt.Sprintf("City cannot exceed %d characters", MaxCityLength))
+	}
+	if p.Location != nil && !geo.Valid(*p.Location) {
+		errors = append(errors, "Invalid location coordinates")
+	}
+
+	seen := make(map[string]bool)
+	for i := range p.Sports {
+		s := &p.Sports[i]
+		s.Sport = NormalizeSport(s.Sport)
+		switch {
+		case s.Sport == "":
+			errors = append(errors, "Sport name is required")
+		case seen[s.Sport]:
+			errors = append(errors, fmt.Sprintf("Sport %s listed more than once", s.Sport))
+		case s.Skill < MinSkill || s.Skill > MaxSkill:
+			errors = append(
+				errors,
+				fmt.Sprintf("Skill for %s must be between %d and %d", s.Sport, MinSkill, MaxSkill),
+			)
+		}
+		seen[s.Sport] = true
+	}
+
+	for _, a := range p.Availability {
+		if a.Weekday < 0 || a.Weekday > 6 {
+			errors = append(errors, "Availability weekday must be between 0 (Sunday) and 6")
+		}
+		if a.StartMinute < 0 || a.EndMinute > 24*60 || a.StartMinute >= a.EndMinute {
+			errors = append(errors, "Availability window must be within the day and end after it starts")
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+// SkillFor returns the user's self-reported skill for sport and whether they
+// play it at all.
+func (p *Profile) SkillFor(sport string) (int, bool) {
+	s, ok := p.SportFor(sport)
+	return s.Skill, ok
+}
+
+// SportFor returns the user's entry for sport and whether they play it.
+func (p *Profile) SportFor(sport string) (SportSkill, bool) {
+	sport = NormalizeSport(sport)
+	for _, s := range p.Sports {
+		if s.Sport == sport {
+			return s, true
+		}
+	}
+	return SportSkill{}, false
+}
+
+func NormalizeSport(sport string) string {
+	return strings.ToLower(strings.TrimSpace(sport))
+}
+
+// NormalizeCity lowercases city and collapses its whitespace, so that
+// "New  York" and "new york" share a leaderboard.
+func NormalizeCity(city string) string {
+	return strings.ToLower(strings.Join(strings.Fields(city), " "))
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+)
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Profile
+		want bool
+	}{
+		{
+			name: "Valid profile",
+			p: Profile{
+				Location:     &geo.Point{Lat: 6.5, Lng: 3.4},
+				Sports:       []SportSkill{{Sport: "Tennis", Skill: 5}},
+				Availability: []Availability{{Weekday: 2, StartMinute: 1080, EndMinute: 1200}},
+			},
+			want: false,
+		},
+		{name: "Empty profile", p: Profile{}, want: false},
+		{name: "Bad location", p: Profile{Location: &geo.Point{Lat: 100}}, want: true},
+		{name: "City too long", p: Profile{City: strings.Repeat("a", MaxCityLength+1)}, want: true},
+		{name: "Skill too high", p: Profile{Sports: []SportSkill{{Sport: "tennis", Skill: 11}}}, want: true},
+		{name: "Missing sport", p: Profile{Sports: []SportSkill{{Skill: 3}}}, want: true},
+		{
+			name: "Duplicate sport",
+			p:    Profile{Sports: []SportSkill{{Sport: "tennis", Skill: 3}, {Sport: " Tennis", Skill: 4}}},
+			want: true,
+		},
+		{name: "Bad weekday", p: Profile{Availability: []Availability{{Weekday: 7, EndMinute: 60}}}, want: true},
+		{
+			name: "Window ends before start",
+			p:    Profile{Availability: []Availability{{Weekday: 1, StartMinute: 600, EndMinute: 540}}},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.ValidateProfile()
+			if (err != nil) != tt.want {
+				t.Errorf("ValidateProfile() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkillFor(t *testing.T) {
+	p := Profile{Sports: []SportSkill{{Sport: "football", Skill: 7}}}
+	if skill, ok := p.SkillFor(" Football "); !ok || skill != 7 {
+		t.Errorf("SkillFor(football) = %d, %v, want 7, true", skill, ok)
+	}
+	if _, ok := p.SkillFor("tennis"); ok {
+		t.Error("SkillFor(tennis) reported a sport the user does not play")
+	}
+}
+
+func TestNormalizeCity(t *testing.T) {
+	if got := NormalizeCity("  New   York "); got != "new york" {
+		t.Errorf("NormalizeCity = %q, want %q", got, "new york")
+	}
+}
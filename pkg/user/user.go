@@ -110,7 +110,7 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		claims, err := ValidateToken(token)
-		if err != nil {
+		if err != nil || claims.Subject <= 0 {
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
@@ -119,6 +119,21 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware is AuthMiddleware for public routes that show more
+// to signed-in users: a valid token puts the userId in the context, while a
+// missing or invalid one lets the request through anonymously.
+func OptionalAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
+		if token != "" {
+			if claims, err := ValidateToken(token); err == nil && claims.Subject > 0 {
+				r = r.WithContext(context.WithValue(r.Context(), "userId", claims.Subject))
+			}
+		}
+		next(w, r)
+	}
+}
+
 func ValidateToken(token string) (*Claim, error) {
 	parts := strings.Split(token, ".")
 
@@ -0,0 +1,202 @@
+package venue
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+const defaultHoldWindow = 15 * time.Minute
+
+var (
+	ErrCourtInactive    = errors.New("court is not available for booking")
+	ErrVenueClosed      = errors.New("venue is closed for part of that slot")
+	ErrSlotTaken        = errors.New("court is already booked for part of that slot")
+	ErrSlotInPast       = errors.New("slot has already started")
+	ErrBookingNotFound  = errors.New("booking not found")
+	ErrBookingNotHeld   = errors.New("booking is not waiting for confirmation")
+	ErrHoldExpired      = errors.New("booking hold has expired")
+	ErrBookingNotActive = errors.New("booking is already cancelled or expired")
+	ErrCancelTooLate    = errors.New("venue policy does not allow cancelling this close to the start")
+	ErrCourtBooked      = errors.New("game has a court booking; cancel it before moving the game")
+)
+
+// HoldWindow is how long a court stays held for an unconfirmed booking. Set
+// BOOKING_HOLD_WINDOW to override.
+var HoldWindow = holdWindowFromEnv()
+
+func holdWindowFromEnv() time.Duration {
+	v := os.Getenv("BOOKING_HOLD_WINDOW")
+	if v == "" {
+		return defaultHoldWindow
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid BOOKING_HOLD_WINDOW %q, using %s", v, defaultHoldWindow)
+		return defaultHoldWindow
+	}
+	return d
+}
+
+type cancellationRule struct {
+	freeBefore time.Duration
+	allowLate  bool
+}
+
+// Confirmed bookings can be cancelled for free until freeBefore the start.
+// After that a flexible or moderate venue still releases the court but the
+// cancellation is marked late, while a strict venue keeps the booking.
+var cancellationRules = map[string]cancellationRule{
+	model.PolicyFlexible: {freeBefore: 0, allowLate: true},
+	model.PolicyModerate: {freeBefore: 24 * time.Hour, allowLate: true},
+	model.PolicyStrict:   {freeBefore: 48 * time.Hour, allowLate: false},
+}
+
+// CheckBooking reports why b cannot be placed on court at v, given the
+// court's other bookings.
+func CheckBooking(v model.Venue, court model.Court, b model.Booking, existing []model.Booking, now time.Time) error {
+	if v.Status != model.StatusApproved {
+		return ErrVenueNotOpen
+	}
+	if court.VenueID != v.ID {
+		return ErrCourtNotFound
+	}
+	if !court.Active {
+		return ErrCourtInactive
+	}
+	if !b.StartsAt.After(now) {
+		return ErrSlotInPast
+	}
+	if !IsOpen(v, b.StartsAt, b.EndsAt) {
+		return ErrVenueClosed
+	}
+	for _, e := range existing {
+		if e.ID != b.ID && e.CourtID == court.ID && e.Blocks(now) && e.Overlaps(b.StartsAt, b.EndsAt) {
+			return ErrSlotTaken
+		}
+	}
+	return nil
+}
+
+// Confirm turns a live hold into a confirmed booking.
+func Confirm(b *model.Booking, now time.Time) error {
+	if b.Status != model.BookingHeld {
+		return ErrBookingNotHeld
+	}
+	if !b.Blocks(now) {
+		return ErrHoldExpired
+	}
+	b.Status = model.BookingConfirmed
+	b.HoldExpiresAt = nil
+	return nil
+}
+
+// Cancel releases the booking under the venue's cancellation policy. Holds
+// can always be dropped; confirmed bookings inside the free window are either
+// refused or marked as a late cancellation.
+func Cancel(b *model.Booking, policy string, now time.Time) error {
+	if !b.Blocks(now) {
+		return ErrBookingNotActive
+	}
+	if !b.StartsAt.After(now) {
+		return ErrSlotInPast
+	}
+	if b.Status == model.BookingConfirmed {
+		rule, ok := cancellationRules[policy]
+		if !ok {
+			rule = cancellationRules[model.PolicyModerate]
+		}
+		if b.StartsAt.Sub(now) < rule.freeBefore {
+			if !rule.allowLate {
+				return ErrCancelTooLate
+			}
+			b.LateCancel = true
+		}
+	}
+	b.Status = model.BookingCancelled
+	b.HoldExpiresAt = nil
+	return nil
+}
+
+const (
+	SlotFree   = "free"
+	SlotHeld   = "held"
+	SlotBooked = "booked"
+	SlotClosed = "closed"
+	SlotPast   = "past"
+)
+
+type Slot struct {
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	State    string    `json:"state"`
+}
+
+type CourtAvailability struct {
+	Court model.Court `json:"court"`
+	Slots []Slot      `json:"slots"`
+}
+
+// DayBounds returns the start and end of day, a date in the venue's
+// timezone. Days are not always 24 hours long around DST changes.
+func DayBounds(v model.Venue, day time.Time) (time.Time, time.Time) {
+	loc := v.Loc()
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Availability lays out day at the venue as a grid of slot-long cells for
+// every active court.
+func Availability(
+	v model.Venue,
+	day time.Time,
+	slot time.Duration,
+	bookings []model.Booking,
+	now time.Time,
+) []CourtAvailability {
+	dayStart, dayEnd := DayBounds(v, day)
+
+	out := []CourtAvailability{}
+	for _, c := range v.Courts {
+		if !c.Active {
+			continue
+		}
+		ca := CourtAvailability{Court: c, Slots: []Slot{}}
+		for start := dayStart; start.Before(dayEnd); start = start.Add(slot) {
+			end := start.Add(slot)
+			if end.After(dayEnd) {
+				end = dayEnd
+			}
+			ca.Slots = append(ca.Slots, Slot{
+				StartsAt: start,
+				EndsAt:   end,
+				State:    slotState(v, c, start, end, bookings, now),
+			})
+		}
+		out = append(out, ca)
+	}
+	return out
+}
+
+func slotState(v model.Venue, c model.Court, start, end time.Time, bookings []model.Booking, now time.Time) string {
+	if !start.After(now) {
+		return SlotPast
+	}
+	if !IsOpen(v, start, end) {
+		return SlotClosed
+	}
+	state := SlotFree
+	for _, b := range bookings {
+		if b.CourtID != c.ID || !b.Blocks(now) || !b.Overlaps(start, end) {
+			continue
+		}
+		if b.Status == model.BookingConfirmed {
+			return SlotBooked
+		}
+		state = SlotHeld
+	}
+	return state
+}
@@ -0,0 +1,192 @@
+package venue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+var bookingNow = time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+
+func bookingVenue() (model.Venue, model.Court) {
+	court := model.Court{ID: 10, VenueID: 1, Sport: "tennis", Active: true}
+	v := model.Venue{
+		ID:       1,
+		Status:   model.StatusApproved,
+		Timezone: "UTC",
+		// Open 08:00-22:00 every day.
+		OpeningHours: func() []model.OpeningHours {
+			var hours []model.OpeningHours
+			for d := 0; d < 7; d++ {
+				hours = append(hours, model.OpeningHours{Weekday: d, OpenMinute: 480, CloseMinute: 1320})
+			}
+			return hours
+		}(),
+		Courts: []model.Court{court},
+	}
+	return v, court
+}
+
+func slotAt(hour, minutes int) model.Booking {
+	start := time.Date(2026, 6, 2, hour, 0, 0, 0, time.UTC)
+	return model.Booking{CourtID: 10, StartsAt: start, EndsAt: start.Add(time.Duration(minutes) * time.Minute)}
+}
+
+func TestCheckBooking(t *testing.T) {
+	v, court := bookingVenue()
+	liveHold := bookingNow.Add(5 * time.Minute)
+	lapsedHold := bookingNow.Add(-time.Minute)
+
+	confirmed := slotAt(18, 60)
+	confirmed.ID, confirmed.Status = 1, model.BookingConfirmed
+	held := slotAt(12, 60)
+	held.ID, held.Status, held.HoldExpiresAt = 2, model.BookingHeld, &liveHold
+	lapsed := slotAt(14, 60)
+	lapsed.ID, lapsed.Status, lapsed.HoldExpiresAt = 3, model.BookingHeld, &lapsedHold
+	cancelled := slotAt(16, 60)
+	cancelled.ID, cancelled.Status = 4, model.BookingCancelled
+	existing := []model.Booking{confirmed, held, lapsed, cancelled}
+
+	inactive := court
+	inactive.Active = false
+	otherVenue := court
+	otherVenue.VenueID = 2
+
+	tests := []struct {
+		name  string
+		court model.Court
+		b     model.Booking
+		want  error
+	}{
+		{"Free slot", court, slotAt(10, 60), nil},
+		{"Back to back with a booking", court, slotAt(17, 60), nil},
+		{"Overlaps confirmed booking", court, slotAt(18, 30), ErrSlotTaken},
+		{"Straddles confirmed booking", court, slotAt(17, 120), ErrSlotTaken},
+		{"Overlaps live hold", court, slotAt(12, 90), ErrSlotTaken},
+		{"Lapsed hold frees the slot", court, slotAt(14, 60), nil},
+		{"Cancelled booking frees the slot", court, slotAt(16, 60), nil},
+		{"Before opening", court, slotAt(7, 90), ErrVenueClosed},
+		{"Past closing", court, slotAt(21, 90), ErrVenueClosed},
+		{"Inactive court", inactive, slotAt(10, 60), ErrCourtInactive},
+		{"Court of another venue", otherVenue, slotAt(10, 60), ErrCourtNotFound},
+		{"Already started", court, model.Booking{StartsAt: bookingNow, EndsAt: bookingNow.Add(time.Hour)}, ErrSlotInPast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckBooking(v, tt.court, tt.b, existing, bookingNow)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CheckBooking = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// A booking never conflicts with itself, so it can be re-checked.
+	if err := CheckBooking(v, court, confirmed, existing, bookingNow); err != nil {
+		t.Errorf("booking conflicts with itself: %v", err)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	expires := bookingNow.Add(HoldWindow)
+	b := slotAt(10, 60)
+	b.Status, b.HoldExpiresAt = model.BookingHeld, &expires
+
+	if err := Confirm(&b, expires.Add(time.Second)); err != ErrHoldExpired {
+		t.Errorf("Confirm after the hold = %v, want %v", err, ErrHoldExpired)
+	}
+	if err := Confirm(&b, bookingNow); err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if b.Status != model.BookingConfirmed || b.HoldExpiresAt != nil {
+		t.Errorf("Confirm left %+v", b)
+	}
+	if err := Confirm(&b, bookingNow); err != ErrBookingNotHeld {
+		t.Errorf("second Confirm = %v, want %v", err, ErrBookingNotHeld)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	start := bookingNow.Add(30 * time.Hour)
+	confirmed := model.Booking{Status: model.BookingConfirmed, StartsAt: start, EndsAt: start.Add(time.Hour)}
+	expires := bookingNow.Add(time.Minute)
+	held := confirmed
+	held.Status, held.HoldExpiresAt = model.BookingHeld, &expires
+
+	tests := []struct {
+		name     string
+		b        model.Booking
+		policy   string
+		now      time.Time
+		want     error
+		wantLate bool
+	}{
+		{"Flexible, same day", confirmed, model.PolicyFlexible, start.Add(-time.Hour), nil, false},
+		{"Moderate, two days out", confirmed, model.PolicyModerate, bookingNow, nil, false},
+		{"Moderate, inside a day", confirmed, model.PolicyModerate, start.Add(-time.Hour), nil, true},
+		{"Strict, inside two days", confirmed, model.PolicyStrict, bookingNow, ErrCancelTooLate, false},
+		{"Strict hold", held, model.PolicyStrict, bookingNow, nil, false},
+		{"Started", confirmed, model.PolicyFlexible, start, ErrSlotInPast, false},
+		{"Already cancelled", model.Booking{Status: model.BookingCancelled, StartsAt: start}, model.PolicyFlexible, bookingNow, ErrBookingNotActive, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.b
+			err := Cancel(&b, tt.policy, tt.now)
+			if err != tt.want {
+				t.Fatalf("Cancel = %v, want %v", err, tt.want)
+			}
+			if err == nil && (b.Status != model.BookingCancelled || b.LateCancel != tt.wantLate) {
+				t.Errorf("Cancel left status %q late %v, want late %v", b.Status, b.LateCancel, tt.wantLate)
+			}
+		})
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	v, _ := bookingVenue()
+	v.Courts = append(v.Courts, model.Court{ID: 11, VenueID: 1, Active: false})
+
+	liveHold := bookingNow.Add(time.Minute)
+	confirmed := slotAt(18, 90)
+	confirmed.Status = model.BookingConfirmed
+	held := slotAt(12, 60)
+	held.Status, held.HoldExpiresAt = model.BookingHeld, &liveHold
+
+	grid := Availability(v, slotAt(0, 0).StartsAt, time.Hour, []model.Booking{confirmed, held}, bookingNow)
+	if len(grid) != 1 {
+		t.Fatalf("grid has %d courts, want only the active one", len(grid))
+	}
+	slots := grid[0].Slots
+	if len(slots) != 24 {
+		t.Fatalf("grid has %d slots, want 24", len(slots))
+	}
+	want := map[int]string{
+		7: SlotClosed, 8: SlotFree, 12: SlotHeld, 13: SlotFree,
+		18: SlotBooked, 19: SlotBooked, 20: SlotFree, 22: SlotClosed,
+	}
+	for hour, state := range want {
+		if slots[hour].State != state {
+			t.Errorf("slot at %02d:00 = %s, want %s", hour, slots[hour].State, state)
+		}
+	}
+
+	today := Availability(v, bookingNow, time.Hour, nil, bookingNow)
+	if today[0].Slots[9].State != SlotPast || today[0].Slots[10].State != SlotFree {
+		t.Errorf("slots around now = %s, %s", today[0].Slots[9].State, today[0].Slots[10].State)
+	}
+}
+
+func TestAvailabilityAcrossDST(t *testing.T) {
+	v, _ := bookingVenue()
+	v.Timezone = "Europe/London"
+	v.OpeningHours = nil
+
+	// Clocks go forward on 29 March 2026, so that day is 23 hours long.
+	day := time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC)
+	grid := Availability(v, day, time.Hour, nil, bookingNow.AddDate(-1, 0, 0))
+	if n := len(grid[0].Slots); n != 23 {
+		t.Errorf("DST day has %d hourly slots, want 23", n)
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	BookingHeld      = "held"
+	BookingConfirmed = "confirmed"
+	BookingCancelled = "cancelled"
+	BookingExpired   = "expired"
+
+	MinBookingLength = 30 * time.Minute
+	MaxBookingLength = 8 * time.Hour
+)
+
+type Booking struct {
+	ID            int64      `json:"id"`
+	VenueID       int64      `json:"venueId"`
+	CourtID       int64      `json:"courtId"`
+	UserID        int64      `json:"userId"`
+	GameID        *int64     `json:"gameId,omitempty"`
+	StartsAt      time.Time  `json:"startsAt"`
+	EndsAt        time.Time  `json:"endsAt"`
+	Status        string     `json:"status"`
+	HoldExpiresAt *time.Time `json:"holdExpiresAt,omitempty"`
+	LateCancel    bool       `json:"lateCancel,omitempty"`
+}
+
+func (b *Booking) ValidateBooking() error {
+	var errors []string
+
+	if b.CourtID <= 0 {
+		errors = append(errors, "Court is required")
+	}
+	if b.StartsAt.IsZero() || b.EndsAt.IsZero() {
+		errors = append(errors, "Start and end times are required")
+	} else {
+		length := b.EndsAt.Sub(b.StartsAt)
+		if length < MinBookingLength || length > MaxBookingLength {
+			errors = append(
+				errors,
+				fmt.Sprintf("Bookings must last between %s and %s", MinBookingLength, MaxBookingLength),
+			)
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+// Blocks reports whether the booking keeps its court from being booked by
+// anyone else: confirmed bookings always do, holds only until they lapse.
+func (b *Booking) Blocks(now time.Time) bool {
+	switch b.Status {
+	case BookingConfirmed:
+		return true
+	case BookingHeld:
+		return b.HoldExpiresAt != nil && b.HoldExpiresAt.After(now)
+	}
+	return false
+}
+
+// Overlaps reports whether the booking shares any time with [start, end).
+func (b *Booking) Overlaps(start, end time.Time) bool {
+	return b.StartsAt.Before(end) && start.Before(b.EndsAt)
+}
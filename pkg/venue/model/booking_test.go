@@ -0,0 +1,61 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateBooking(t *testing.T) {
+	start := time.Date(2026, 6, 2, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		b       Booking
+		wantErr bool
+	}{
+		{"Valid", Booking{CourtID: 1, StartsAt: start, EndsAt: start.Add(time.Hour)}, false},
+		{"Missing court", Booking{StartsAt: start, EndsAt: start.Add(time.Hour)}, true},
+		{"Missing times", Booking{CourtID: 1}, true},
+		{"Too short", Booking{CourtID: 1, StartsAt: start, EndsAt: start.Add(15 * time.Minute)}, true},
+		{"Ends before start", Booking{CourtID: 1, StartsAt: start, EndsAt: start.Add(-time.Hour)}, true},
+		{"Too long", Booking{CourtID: 1, StartsAt: start, EndsAt: start.Add(9 * time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.b.ValidateBooking()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBooking() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBlocksAndOverlaps(t *testing.T) {
+	now := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+	start := now.Add(time.Hour)
+	b := Booking{StartsAt: start, EndsAt: start.Add(time.Hour)}
+
+	for _, tt := range []struct {
+		status  string
+		expires *time.Time
+		want    bool
+	}{
+		{BookingConfirmed, nil, true},
+		{BookingHeld, &later, true},
+		{BookingHeld, &earlier, false},
+		{BookingCancelled, nil, false},
+		{BookingExpired, nil, false},
+	} {
+		b.Status, b.HoldExpiresAt = tt.status, tt.expires
+		if got := b.Blocks(now); got != tt.want {
+			t.Errorf("Blocks(%s) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+
+	if b.Overlaps(start.Add(-time.Hour), start) || b.Overlaps(start.Add(time.Hour), start.Add(2*time.Hour)) {
+		t.Error("adjacent slots reported as overlapping")
+	}
+	if !b.Overlaps(start.Add(30*time.Minute), start.Add(90*time.Minute)) {
+		t.Error("overlapping slot not detected")
+	}
+}
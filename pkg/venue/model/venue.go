@@ -0,0 +1,154 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	mediamodel "github.com/dudeiebot/sportPeerGo/pkg/media/model"
+	usermodel "github.com/dudeiebot/sportPeerGo/pkg/user/model"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+
+	PolicyFlexible = "flexible"
+	PolicyModerate = "moderate"
+	PolicyStrict   = "strict"
+)
+
+// OpeningHours is one weekly opening window in minutes from midnight.
+type OpeningHours struct {
+	Weekday     int `json:"weekday"`
+	OpenMinute  int `json:"openMinute"`
+	CloseMinute int `json:"closeMinute"`
+}
+
+type Court struct {
+	ID      int64  `json:"id"`
+	VenueID int64  `json:"venueId"`
+	Name    string `json:"name"`
+	Sport   string `json:"sport"`
+	Surface string `json:"surface"`
+	Active  bool   `json:"active"`
+}
+
+type Venue struct {
+	ID                 int64              `json:"id"`
+	Name               string             `json:"name"`
+	Address            string             `json:"address"`
+	Location           geo.Point          `json:"location"`
+	Timezone           string             `json:"timezone"`
+	OpeningHours       []OpeningHours     `json:"openingHours"`
+	Surfaces           []string           `json:"surfaces"`
+	Amenities          []string           `json:"amenities"`
+	PricingNotes       string             `json:"pricingNotes"`
+	CancellationPolicy string             `json:"cancellationPolicy"`
+	Courts             []Court            `json:"courts"`
+	Photos             []mediamodel.Image `json:"photos,omitempty"`
+	Status             string             `json:"status"`
+	SubmittedBy        int64              `json:"submittedBy"`
+	ReviewNote         string             `json:"reviewNote,omitempty"`
+	DistanceKm         *float64           `json:"distanceKm,omitempty"`
+}
+
+type Filter struct {
+	Near     *geo.Point
+	RadiusKm float64
+	Sport    string
+	Limit    int
+}
+
+func (v *Venue) ValidateVenue() error {
+	var errors []string
+
+	v.Name = strings.TrimSpace(v.Name)
+	v.Address = strings.TrimSpace(v.Address)
+	if v.Name == "" {
+		errors = append(errors, "Name is required")
+	}
+	if v.Address == "" {
+		errors = append(errors, "Address is required")
+	}
+	if !geo.Valid(v.Location) || v.Location == (geo.Point{}) {
+		errors = append(errors, "Valid coordinates are required")
+	}
+	if v.Timezone == "" {
+		v.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(v.Timezone); err != nil {
+		errors = append(errors, "Unknown timezone")
+	}
+	switch v.CancellationPolicy {
+	case "":
+		v.CancellationPolicy = PolicyModerate
+	case PolicyFlexible, PolicyModerate, PolicyStrict:
+	default:
+		errors = append(errors, "Cancellation policy must be flexible, moderate or strict")
+	}
+	for _, h := range v.OpeningHours {
+		if h.Weekday < 0 || h.Weekday > 6 || h.OpenMinute < 0 || h.CloseMinute > 24*60 || h.OpenMinute >= h.CloseMinute {
+			errors = append(errors, "Opening hours must be within a day and close after opening")
+			break
+		}
+	}
+	v.Surfaces = normalizeList(v.Surfaces)
+	v.Amenities = normalizeList(v.Amenities)
+	for i := range v.Courts {
+		if err := v.Courts[i].ValidateCourt(); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+func (c *Court) ValidateCourt() error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Sport = usermodel.NormalizeSport(c.Sport)
+	c.Surface = strings.ToLower(strings.TrimSpace(c.Surface))
+	if c.Name == "" || c.Sport == "" {
+		return fmt.Errorf("Every court needs a name and a sport")
+	}
+	return nil
+}
+
+// Loc returns the venue's timezone, which its opening hours are given in.
+func (v *Venue) Loc() *time.Location {
+	loc, err := time.LoadLocation(v.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// HasSport reports whether any active court is for sport.
+func (v *Venue) HasSport(sport string) bool {
+	sport = usermodel.NormalizeSport(sport)
+	for _, c := range v.Courts {
+		if c.Active && c.Sport == sport {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeList(items []string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, item := range items {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+)
+
+func TestValidateVenue(t *testing.T) {
+	valid := func() Venue {
+		return Venue{
+			Name:         "Rec Centre",
+			Address:      "1 Main St",
+			Location:     geo.Point{Lat: 6.5, Lng: 3.4},
+			OpeningHours: []OpeningHours{{Weekday: 1, OpenMinute: 480, CloseMinute: 1320}},
+			Surfaces:     []string{"Clay", "clay ", "Hard"},
+			Courts:       []Court{{Name: "Court 1", Sport: "Tennis", Surface: "Clay"}},
+		}
+	}
+
+	v := valid()
+	if err := v.ValidateVenue(); err != nil {
+		t.Fatalf("ValidateVenue: %v", err)
+	}
+	if len(v.Surfaces) != 2 || v.Courts[0].Sport != "tennis" || v.Timezone != "UTC" ||
+		v.CancellationPolicy != PolicyModerate {
+		t.Errorf("ValidateVenue did not normalize: %+v", v)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(v *Venue)
+	}{
+		{"Missing name", func(v *Venue) { v.Name = "" }},
+		{"Missing address", func(v *Venue) { v.Address = " " }},
+		{"Missing coordinates", func(v *Venue) { v.Location = geo.Point{} }},
+		{"Bad timezone", func(v *Venue) { v.Timezone = "Mars/Olympus" }},
+		{"Closes before opening", func(v *Venue) { v.OpeningHours[0].CloseMinute = 60 }},
+		{"Unknown cancellation policy", func(v *Venue) { v.CancellationPolicy = "never" }},
+		{"Court without sport", func(v *Venue) { v.Courts[0].Sport = "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := valid()
+			tt.mutate(&v)
+			if err := v.ValidateVenue(); err == nil {
+				t.Error("ValidateVenue accepted an invalid venue")
+			}
+		})
+	}
+}
+
+func TestHasSport(t *testing.T) {
+	v := Venue{Courts: []Court{
+		{Sport: "tennis", Active: true},
+		{Sport: "squash", Active: false},
+	}}
+	if !v.HasSport("Tennis") {
+		t.Error("HasSport(tennis) = false")
+	}
+	if v.HasSport("squash") {
+		t.Error("HasSport counted an inactive court")
+	}
+}
@@ -0,0 +1,112 @@
+package venue
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+const DefaultRadiusKm = 10.0
+
+var (
+	ErrVenueNotFound   = errors.New("venue not found")
+	ErrCourtNotFound   = errors.New("court not found")
+	ErrNotAllowed      = errors.New("you are not allowed to change this venue")
+	ErrVenueNotPending = errors.New("venue has already been reviewed")
+	ErrVenueNotOpen    = errors.New("venue is not approved for use")
+)
+
+// CanView reports whether a user may see the venue. Approved venues are
+// public; pending and rejected ones only to their submitter and admins.
+func CanView(v model.Venue, userID int64, isAdmin bool) bool {
+	return v.Status == model.StatusApproved || isAdmin || (userID != 0 && v.SubmittedBy == userID)
+}
+
+// CanEdit reports whether a user may change the venue. Submitters can edit
+// their venue until it is reviewed; afterwards only admins can.
+func CanEdit(v model.Venue, userID int64, isAdmin bool) bool {
+	return isAdmin || (v.SubmittedBy == userID && v.Status == model.StatusPending)
+}
+
+// Review moves a pending venue to approved or rejected.
+func Review(v *model.Venue, approve bool, note string) error {
+	if v.Status != model.StatusPending {
+		return ErrVenueNotPending
+	}
+	v.Status = model.StatusRejected
+	if approve {
+		v.Status = model.StatusApproved
+	}
+	v.ReviewNote = note
+	return nil
+}
+
+// Search keeps the venues within the filter's radius that have a court for
+// its sport, nearest first. Without a location venues are kept in name order.
+func Search(venues []model.Venue, f model.Filter) []model.Venue {
+	radius := f.RadiusKm
+	if radius <= 0 {
+		radius = DefaultRadiusKm
+	}
+
+	out := []model.Venue{}
+	for _, v := range venues {
+		if f.Sport != "" && !v.HasSport(f.Sport) {
+			continue
+		}
+		if f.Near != nil {
+			d := geo.Distance(*f.Near, v.Location)
+			if d > radius {
+				continue
+			}
+			v.DistanceKm = &d
+		}
+		out = append(out, v)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].DistanceKm != nil && out[j].DistanceKm != nil && *out[i].DistanceKm != *out[j].DistanceKm {
+			return *out[i].DistanceKm < *out[j].DistanceKm
+		}
+		return out[i].Name < out[j].Name
+	})
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out
+}
+
+// HoursOn returns the opening windows for t's weekday in the venue's timezone.
+func HoursOn(v model.Venue, t time.Time) []model.OpeningHours {
+	weekday := int(t.In(v.Loc()).Weekday())
+	var out []model.OpeningHours
+	for _, h := range v.OpeningHours {
+		if h.Weekday == weekday {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// IsOpen reports whether the venue is open for the whole of [start, end).
+// Venues without listed hours are treated as always open.
+func IsOpen(v model.Venue, start, end time.Time) bool {
+	if len(v.OpeningHours) == 0 {
+		return true
+	}
+	start, end = start.In(v.Loc()), end.In(v.Loc())
+	if start.YearDay() != end.Add(-time.Nanosecond).YearDay() || start.Year() != end.Year() {
+		return false
+	}
+	from := start.Hour()*60 + start.Minute()
+	to := from + int(end.Sub(start)/time.Minute)
+	for _, h := range HoursOn(v, start) {
+		if from >= h.OpenMinute && to <= h.CloseMinute {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package venue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dudeiebot/sportPeerGo/pkg/geo"
+	"github.com/dudeiebot/sportPeerGo/pkg/venue/model"
+)
+
+var center = geo.Point{Lat: 51.5074, Lng: -0.1278}
+
+func venueAt(name string, lat, lng float64, sports ...string) model.Venue {
+	v := model.Venue{Name: name, Location: geo.Point{Lat: lat, Lng: lng}, Status: model.StatusApproved}
+	for _, s := range sports {
+		v.Courts = append(v.Courts, model.Court{Sport: s, Active: true})
+	}
+	return v
+}
+
+func TestSearch(t *testing.T) {
+	venues := []model.Venue{
+		venueAt("Far", 51.60, -0.13, "tennis"),
+		venueAt("Near", 51.51, -0.13, "tennis"),
+		venueAt("Squash only", 51.508, -0.128, "squash"),
+		venueAt("Out of range", 52.5, -0.13, "tennis"),
+	}
+
+	got := Search(venues, model.Filter{Near: &center, RadiusKm: 15, Sport: "tennis"})
+	if len(got) != 2 || got[0].Name != "Near" || got[1].Name != "Far" {
+		t.Fatalf("Search = %+v, want Near then Far", got)
+	}
+	if got[0].DistanceKm == nil || *got[0].DistanceKm > 1 {
+		t.Errorf("distance not set: %v", got[0].DistanceKm)
+	}
+
+	got = Search(venues, model.Filter{Limit: 2})
+	if len(got) != 2 || got[0].Name != "Far" || got[1].Name != "Near" {
+		t.Errorf("Search without location = %+v, want name order", got)
+	}
+}
+
+func TestCanViewAndEdit(t *testing.T) {
+	v := model.Venue{SubmittedBy: 5, Status: model.StatusPending}
+	if CanView(v, 6, false) || !CanView(v, 5, false) || !CanView(v, 6, true) {
+		t.Error("pending venue visibility wrong")
+	}
+	if !CanEdit(v, 5, false) || CanEdit(v, 6, false) {
+		t.Error("pending venue edit rights wrong")
+	}
+
+	v.Status = model.StatusApproved
+	if !CanView(v, 0, false) {
+		t.Error("approved venue hidden from anonymous users")
+	}
+	if CanEdit(v, 5, false) || !CanEdit(v, 6, true) {
+		t.Error("approved venue edit rights wrong")
+	}
+}
+
+func TestReview(t *testing.T) {
+	v := model.Venue{Status: model.StatusPending}
+	if err := Review(&v, false, "duplicate"); err != nil || v.Status != model.StatusRejected {
+		t.Fatalf("Review reject = %v, status %s", err, v.Status)
+	}
+	if err := Review(&v, true, ""); err != ErrVenueNotPending {
+		t.Errorf("second Review error = %v, want %v", err, ErrVenueNotPending)
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	v := model.Venue{
+		Timezone: "Africa/Lagos",
+		OpeningHours: []model.OpeningHours{
+			{Weekday: int(time.Monday), OpenMinute: 8 * 60, CloseMinute: 22 * 60},
+		},
+	}
+	lagos := v.Loc()
+	monday := func(h int) time.Time { return time.Date(2026, 5, 4, h, 0, 0, 0, lagos) }
+
+	if !IsOpen(v, monday(9), monday(10)) {
+		t.Error("IsOpen = false inside opening hours")
+	}
+	if IsOpen(v, monday(21), monday(23)) {
+		t.Error("IsOpen = true for a slot running past closing")
+	}
+	if IsOpen(v, monday(9).AddDate(0, 0, 1), monday(10).AddDate(0, 0, 1)) {
+		t.Error("IsOpen = true on a day with no hours")
+	}
+	// 08:00 UTC is 09:00 in Lagos.
+	if !IsOpen(v, time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC), time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)) {
+		t.Error("IsOpen ignored the venue timezone")
+	}
+	if !IsOpen(model.Venue{}, monday(3), monday(4)) {
+		t.Error("venue without hours should always be open")
+	}
+}